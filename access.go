@@ -0,0 +1,63 @@
+package csvstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrAccessDenied is returned by operations refused by the authorizer of the
+// store (see WithAuthorizer)
+var ErrAccessDenied = errors.New("access denied")
+
+// AccessRequest describes an operation submitted to an Authorizer
+type AccessRequest struct {
+	Operation  string // One of the Op* constants
+	Table      string // Empty for store-wide operations such as ListTables
+	Write      bool   // Whether the operation modifies the store (see IsWriteOperation)
+	Conditions []QueryCondition
+	// Unmasked is set for reads through UnmaskedView
+	Unmasked bool
+	// Context is the context of operations run through WithContext, e.g. to
+	// carry the identity of the caller; context.Background otherwise
+	Context context.Context
+}
+
+// Authorizer decides whether an operation may run. It returns nil to allow it,
+// or an error to refuse it.
+type Authorizer func(req AccessRequest) error
+
+// WithAuthorizer consults authorize before every operation of the store, so
+// per-table read and write rules live in one place. Refused operations fail
+// with an error wrapping both ErrAccessDenied and the error of authorize.
+func WithAuthorizer(authorize Authorizer) Option {
+	return func(cs *CSVStore) {
+		cs.authorizer = authorize
+	}
+}
+
+// authorize consults the authorizer of the store, if any, about an operation
+func (cs *CSVStore) authorize(op *Operation) error {
+	if cs.authorizer == nil {
+		return nil
+	}
+	ctx := op.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := cs.authorizer(AccessRequest{
+		Operation:  op.Name,
+		Table:      op.Table,
+		Write:      IsWriteOperation(op.Name),
+		Conditions: payloadConditions(op.Payload),
+		Unmasked:   op.Unmasked,
+		Context:    ctx,
+	})
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrAccessDenied) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrAccessDenied, err)
+}
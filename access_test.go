@@ -0,0 +1,102 @@
+package csvstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type userKey struct{}
+
+func TestAuthorizer(t *testing.T) {
+	var requests []AccessRequest
+	authorize := func(req AccessRequest) error {
+		requests = append(requests, req)
+		if req.Table != "salaries" {
+			return nil
+		}
+		if user, _ := req.Context.Value(userKey{}).(string); user == "admin" {
+			return nil
+		}
+		if req.Write {
+			return errors.New("only admins may change salaries")
+		}
+		if len(req.Conditions) == 0 {
+			return ErrAccessDenied
+		}
+		return nil
+	}
+	store := NewMemoryStore(WithAuthorizer(authorize))
+	defer store.Close()
+
+	if err := store.CreateTable("salaries", []string{"id", "name", "amount"}); !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("Expected creating the table to be denied, got %v", err)
+	}
+	admin := store.WithContext(context.WithValue(context.Background(), userKey{}, "admin"))
+	store.authorizer = nil
+	if err := store.CreateTable("salaries", []string{"id", "name", "amount"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	store.authorizer = authorize
+
+	_, err := store.Insert("salaries", CSVRecord{"name": "alice", "amount": "100"})
+	if !errors.Is(err, ErrAccessDenied) || err.Error() != "access denied: only admins may change salaries" {
+		t.Fatalf("Expected the insert to be denied, got %v", err)
+	}
+
+	if _, err := admin.Insert("salaries", CSVRecord{"name": "alice", "amount": "100"}); err != nil {
+		t.Fatalf("Failed to insert as admin: %v", err)
+	}
+
+	if _, err := store.Query("salaries", nil); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected an unconditional read to be denied, got %v", err)
+	}
+	conditions := []QueryCondition{{Column: "name", Operator: "=", Value: "alice"}}
+	result, err := store.Query("salaries", conditions)
+	if err != nil || result.Count != 1 {
+		t.Fatalf("Expected a conditional read to be allowed, got %v (%v)", result, err)
+	}
+
+	last := requests[len(requests)-1]
+	if last.Operation != OpQuery || last.Table != "salaries" || last.Write || len(last.Conditions) != 1 {
+		t.Errorf("Unexpected access request: %+v", last)
+	}
+	if last.Context == nil {
+		t.Error("Expected a context in every access request")
+	}
+}
+
+func TestAuthorizerReadOnlyPrincipal(t *testing.T) {
+	var writes []string
+	readOnly := func(req AccessRequest) error {
+		if req.Write {
+			writes = append(writes, req.Operation)
+			return errors.New("read-only principal")
+		}
+		return nil
+	}
+	store := NewMemoryStore(WithAuthorizer(readOnly))
+	defer store.Close()
+
+	expected := map[string][]ColumnDef{"audit": {{Name: "id"}, {Name: "event"}}}
+	drifts, err := store.CheckSchema(expected, CheckSchemaOptions{})
+	if err != nil {
+		t.Fatalf("Expected checking the schema to be allowed, got %v", err)
+	}
+	if len(drifts) != 1 || !drifts[0].MissingTable {
+		t.Errorf("Expected the missing table to be reported, got %+v", drifts)
+	}
+
+	if _, err := store.CheckSchema(expected, CheckSchemaOptions{Repair: true}); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected repairing the schema to be denied, got %v", err)
+	}
+	if store.CheckTableExists("audit") {
+		t.Error("Expected the denied repair not to create the table")
+	}
+	if err := store.Flush(); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected flushing to be denied, got %v", err)
+	}
+	if len(writes) != 2 || writes[0] != OpRepairSchema || writes[1] != OpFlush {
+		t.Errorf("Expected RepairSchema and Flush to be writes, got %v", writes)
+	}
+}
@@ -0,0 +1,186 @@
+package csvstore
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ArchivePolicy moves the rows of a table older than a maximum age to an
+// archive table (see WithArchivePolicy)
+type ArchivePolicy struct {
+	Table string
+	// ArchiveTable receives the archived rows; "<Table>_archive" when empty
+	ArchiveTable string
+	// Column holds the RFC 3339 timestamp the age of a row is measured from;
+	// the creation timestamp column when empty. Rows whose timestamp is empty
+	// or invalid are never archived.
+	Column string
+	MaxAge time.Duration
+	// Compression is the compression of the archive table file
+	Compression Compression
+}
+
+// archiveTable returns the name of the archive table of the policy
+func (p ArchivePolicy) archiveTable() string {
+	if p.ArchiveTable == "" {
+		return p.Table + "_archive"
+	}
+	return p.ArchiveTable
+}
+
+// WithArchivePolicy archives rows according to policy when ArchiveNow runs,
+// or periodically with StartArchiver
+func WithArchivePolicy(policy ArchivePolicy) Option {
+	return func(cs *CSVStore) {
+		cs.archivePolicies = append(cs.archivePolicies, policy)
+		if policy.Compression != NoCompression {
+			archive := policy.archiveTable()
+			cs.tableOptions[archive] = append(cs.tableOptions[archive], WithCompression(policy.Compression))
+		}
+	}
+}
+
+// Archive moves the records of a table matching conditions to archiveTable,
+// which is created with the headers of the table when missing, and returns the
+// moved records. Ids and timestamps are kept. The rows are removed from the
+// table as by Delete, with its hooks, history, and change log. Configure the
+// archive table with WithCompression to keep it compressed.
+func (cs *CSVStore) Archive(
+	tableName string,
+	conditions []QueryCondition,
+	archiveTable string,
+) (*QueryResult, error) {
+	op := Operation{Name: OpArchive, Table: tableName, Payload: conditions}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		conditions = resolveConditions(conditions, cs.columnResolver(tableName))
+		if err := cs.checkConditions(tableName, conditions); err != nil {
+			return nil, err
+		}
+		matcher := cs.newConditionMatcher(tableName)
+		return cs.archiveMatching(tableName, archiveTable, func(record CSVRecord) bool {
+			return matcher.matchesConditions(record, conditions)
+		})
+	})
+}
+
+// ArchiveNow applies the policies set with WithArchivePolicy and returns the
+// number of rows archived by table
+func (cs *CSVStore) ArchiveNow() (map[string]int, error) {
+	return runOperation(cs, Operation{Name: OpArchiveNow}, func() (map[string]int, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.applyArchivePolicies(time.Now())
+	})
+}
+
+// StartArchiver applies the policies set with WithArchivePolicy once per
+// interval, in the background. Errors are passed to onError when it is not nil.
+// The returned function stops the archiver; closing the store stops it as well.
+// The interval must be positive.
+func (cs *CSVStore) StartArchiver(interval time.Duration, onError func(error)) (func(), error) {
+	return cs.startPeriodic(interval, func(now time.Time) error {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		_, err := cs.applyArchivePolicies(now)
+		return err
+	}, onError)
+}
+
+// applyArchivePolicies archives the rows older than the maximum age of every
+// policy at now.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) applyArchivePolicies(now time.Time) (map[string]int, error) {
+	archived := make(map[string]int)
+	for _, policy := range cs.archivePolicies {
+		if !cs.fileExists(cs.getTableFile(policy.Table)) {
+			continue
+		}
+		column := policy.Column
+		if column == "" {
+			column = cs.tableConfig(policy.Table).reservedColumns().CreatedAt
+		}
+		cutoff := now.Add(-policy.MaxAge)
+		result, err := cs.archiveMatching(policy.Table, policy.archiveTable(), func(record CSVRecord) bool {
+			timestamp, err := time.Parse(time.RFC3339Nano, record[column])
+			return err == nil && timestamp.Before(cutoff)
+		})
+		if err != nil {
+			return archived, fmt.Errorf("failed to archive rows of table %s: %w", policy.Table, err)
+		}
+		archived[policy.Table] += result.Count
+	}
+	return archived, nil
+}
+
+// archiveMatching moves the records of a table matching match to archiveTable.
+// The records are appended to the archive before they are deleted, and dropped
+// from the archive again when the delete fails, e.g. in a BeforeDelete hook.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) archiveMatching(
+	tableName string,
+	archiveTable string,
+	match func(CSVRecord) bool,
+) (*QueryResult, error) {
+	if archiveTable == "" || archiveTable == tableName {
+		return nil, fmt.Errorf("invalid archive table %q for table %s", archiveTable, tableName)
+	}
+	records, err := cs.loadTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	matching := slices.DeleteFunc(records, func(record CSVRecord) bool { return !match(record) })
+	if len(matching) == 0 {
+		return &QueryResult{Records: matching}, nil
+	}
+
+	if !cs.fileExists(cs.getTableFile(archiveTable)) {
+		headers, err := cs.getHeaders(tableName)
+		if err != nil {
+			return nil, err
+		}
+		if err := cs.createTable(archiveTable, headers); err != nil {
+			return nil, err
+		}
+	}
+	archiveHeaders, err := cs.getHeaders(archiveTable)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, len(matching))
+	for i, record := range matching {
+		rows[i] = recordRow(archiveHeaders, record)
+	}
+	if err := cs.appendRows(archiveTable, rows); err != nil {
+		return nil, err
+	}
+
+	result, err := cs.deleteMatching(tableName, match)
+	if err != nil {
+		if restoreErr := cs.dropLastRows(archiveTable, len(rows)); restoreErr != nil {
+			return nil, fmt.Errorf("failed to restore archive table %s: %w (after %w)", archiveTable, restoreErr, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// dropLastRows removes the last n rows of a table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) dropLastRows(tableName string, n int) error {
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return err
+	}
+	records, err := cs.loadTable(tableName)
+	if err != nil {
+		return err
+	}
+	return cs.saveTable(tableName, headers, records[:max(len(records)-n, 0)])
+}
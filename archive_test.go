@@ -0,0 +1,164 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchive(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("orders", []string{"id", "status"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	var ids []string
+	for _, status := range []string{"shipped", "open", "shipped"} {
+		record, err := store.Insert("orders", CSVRecord{"status": status})
+		if err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+		ids = append(ids, record["id"])
+	}
+
+	conditions := []QueryCondition{{Column: "status", Operator: "=", Value: "shipped"}}
+	result, err := store.Archive("orders", conditions, "orders_old")
+	if err != nil {
+		t.Fatalf("Failed to archive: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected 2 archived records, got %d", result.Count)
+	}
+
+	remaining, err := store.Query("orders", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if remaining.Count != 1 || remaining.Records[0]["id"] != ids[1] {
+		t.Errorf("Expected only the open order to remain, got %v", remaining.Records)
+	}
+	archived, err := store.Query("orders_old", nil)
+	if err != nil {
+		t.Fatalf("Failed to query archive: %v", err)
+	}
+	if archived.Count != 2 || archived.Records[0]["id"] != ids[0] || archived.Records[1]["id"] != ids[2] {
+		t.Errorf("Expected the shipped orders with their ids in the archive, got %v", archived.Records)
+	}
+
+	if _, err := store.Archive("orders", nil, "orders"); err == nil {
+		t.Error("Expected error archiving a table into itself")
+	}
+}
+
+func TestArchiveRollback(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	refuse := errors.New("deletes are frozen")
+	store.ConfigureTable("orders", WithHook(BeforeDelete, func(CSVRecord) error { return refuse }))
+	if err := store.CreateTable("orders", []string{"id", "status"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := store.CreateTable("orders_archive", []string{"id", "status"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("orders_archive", CSVRecord{"status": "old"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Insert("orders", CSVRecord{"status": "shipped"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	if _, err := store.Archive("orders", nil, "orders_archive"); !errors.Is(err, refuse) {
+		t.Fatalf("Expected the hook error, got %v", err)
+	}
+	archived, err := store.Query("orders_archive", nil)
+	if err != nil {
+		t.Fatalf("Failed to query archive: %v", err)
+	}
+	if archived.Count != 1 || archived.Records[0]["status"] != "old" {
+		t.Errorf("Expected the archive to be restored, got %v", archived.Records)
+	}
+}
+
+func TestArchivePolicy(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir, WithArchivePolicy(ArchivePolicy{
+		Table:       "events",
+		Column:      "at",
+		MaxAge:      24 * time.Hour,
+		Compression: GzipCompression,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("events", []string{"id", "at"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	now := time.Now()
+	for _, at := range []string{
+		now.Add(-72 * time.Hour).Format(time.RFC3339),
+		now.Add(-time.Hour).Format(time.RFC3339),
+		"",
+		now.Add(-48 * time.Hour).Format(time.RFC3339),
+	} {
+		if _, err := store.Insert("events", CSVRecord{"at": at}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	archived, err := store.ArchiveNow()
+	if err != nil {
+		t.Fatalf("Failed to apply archive policies: %v", err)
+	}
+	if archived["events"] != 2 {
+		t.Errorf("Expected 2 archived events, got %v", archived)
+	}
+	if path := store.GetTablePath("events_archive"); !strings.HasSuffix(path, ".csv.gz") {
+		t.Errorf("Expected a compressed archive table, got %s", path)
+	}
+	remaining, err := store.Query("events", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if remaining.Count != 2 {
+		t.Errorf("Expected the recent and undated events to remain, got %v", remaining.Records)
+	}
+
+	// The archiver applies the policies in the background
+	if _, err := store.Insert("events", CSVRecord{"at": now.Add(-30 * time.Hour).Format(time.RFC3339)}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	stop, err := store.StartArchiver(10*time.Millisecond, func(err error) { t.Errorf("Archiver failed: %v", err) })
+	if err != nil {
+		t.Fatalf("Failed to start archiver: %v", err)
+	}
+	defer stop()
+	deadline := time.Now().Add(time.Second)
+	for {
+		result, err := store.Query("events_archive", nil)
+		if err != nil {
+			t.Fatalf("Failed to query archive: %v", err)
+		}
+		if result.Count == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the archiver to archive the new event, got %d archived", result.Count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
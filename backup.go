@@ -0,0 +1,421 @@
+package csvstore
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BackupManifestName is the name of the manifest entry inside a backup archive
+const BackupManifestName = "backup.json"
+
+// RestorePolicy controls what RestoreBackup does with files that already exist
+type RestorePolicy int
+
+const (
+	// RestoreFailOnConflict aborts the restore, without changing anything,
+	// if any file in the backup already exists in the store
+	RestoreFailOnConflict RestorePolicy = iota
+	// RestoreOverwrite replaces existing files with the backed up ones
+	RestoreOverwrite
+	// RestoreSkipExisting keeps existing files and restores only missing ones
+	RestoreSkipExisting
+)
+
+// BackupManifest describes the contents of a backup archive
+type BackupManifest struct {
+	CreatedAt      time.Time `json:"created_at"`
+	ChangeSequence uint64    `json:"change_sequence"`
+	// Incremental is set for backups produced by BackupIncremental
+	Incremental bool `json:"incremental,omitempty"`
+	// Files lists the files contained in the archive
+	Files []string `json:"files"`
+	// Deleted lists files of the base backup that no longer exist in the store
+	Deleted []string `json:"deleted,omitempty"`
+	// Checksums holds the SHA-256 of every file of the store at backup time,
+	// including files left out of an incremental backup
+	Checksums map[string]string `json:"checksums"`
+}
+
+// Backup writes a gzip-compressed tar archive of all tables and metadata files
+// of the store to w. The archive is a consistent copy: writes are blocked while
+// it is produced.
+func (cs *CSVStore) Backup(w io.Writer) error {
+	_, err := runOperation(cs, Operation{Name: OpBackup}, func() (any, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		_, err := cs.backup(w, nil)
+		return nil, err
+	})
+	return err
+}
+
+// BackupIncremental writes a backup archive like Backup, but containing only the
+// files that changed since the backup described by since (see ReadBackupManifest).
+// Files removed since then are listed in the Deleted field of the manifest.
+// To restore, restore the full backup followed by each incremental backup in order,
+// using RestoreOverwrite.
+func (cs *CSVStore) BackupIncremental(w io.Writer, since *BackupManifest) (*BackupManifest, error) {
+	if since == nil {
+		return nil, errors.New("incremental backup requires the manifest of a previous backup")
+	}
+	return runOperation(cs, Operation{Name: OpBackup, Payload: since},
+		func() (*BackupManifest, error) {
+			cs.mu.RLock()
+			defer cs.mu.RUnlock()
+
+			return cs.backup(w, since)
+		})
+}
+
+// backup writes a backup archive of the store to w, containing only files that
+// changed since the given manifest when it is not nil.
+// The caller must hold cs.mu.
+func (cs *CSVStore) backup(w io.Writer, since *BackupManifest) (*BackupManifest, error) {
+	names, err := cs.storeFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &BackupManifest{
+		CreatedAt:      time.Now(),
+		ChangeSequence: cs.changeSeq,
+		Incremental:    since != nil,
+		Files:          make([]string, 0, len(names)),
+		Checksums:      make(map[string]string, len(names)),
+	}
+	for _, name := range names {
+		checksum, err := fileChecksum(cs.fs, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		manifest.Checksums[name] = checksum
+
+		if since != nil && since.Checksums[name] == checksum {
+			continue
+		}
+		manifest.Files = append(manifest.Files, name)
+	}
+	if since != nil {
+		for name := range since.Checksums {
+			if _, exists := manifest.Checksums[name]; !exists {
+				manifest.Deleted = append(manifest.Deleted, name)
+			}
+		}
+		slices.Sort(manifest.Deleted)
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+	if err := writeTarEntry(tarWriter, BackupManifestName, manifestData); err != nil {
+		return nil, err
+	}
+
+	for _, name := range manifest.Files {
+		if err := writeTarFile(tarWriter, cs.fs, name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup archive: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// ReadBackupManifest reads the manifest of a backup archive without extracting it
+func ReadBackupManifest(r io.Reader) (*BackupManifest, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup archive: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("backup archive has no %s", BackupManifestName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		if header.Name != BackupManifestName {
+			continue
+		}
+
+		manifest := &BackupManifest{}
+		if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+			return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+		}
+		return manifest, nil
+	}
+}
+
+// storeFiles returns the names of the regular files in the store directory, except
+// checksums.json, which describes the files of a store rather than its data
+func (cs *CSVStore) storeFiles() ([]string, error) {
+	entries, err := cs.fs.ReadDir(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	files := make([]string, 0)
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && entry.Name() != ChecksumsFileName {
+			files = append(files, entry.Name())
+		}
+	}
+
+	return files, nil
+}
+
+// writeTarEntry writes a regular file entry to a tar archive
+func writeTarEntry(tarWriter *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write backup entry %s: %w", name, err)
+	}
+	if _, err := tarWriter.Write(data); err != nil {
+		return fmt.Errorf("failed to write backup entry %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeTarFile writes a file of fsys to a tar archive as a regular file entry
+func writeTarFile(tarWriter *tar.Writer, fsys FS, name string) error {
+	info, err := fsys.Stat(name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	file, err := fsys.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer file.Close()
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write backup entry %s: %w", name, err)
+	}
+	if _, err := io.Copy(tarWriter, file); err != nil {
+		return fmt.Errorf("failed to write backup entry %s: %w", name, err)
+	}
+	return nil
+}
+
+// fileChecksum returns the hex encoded SHA-256 of a file of fsys
+func fileChecksum(fsys FS, name string) (string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// RestoreBackup restores the tables and metadata files of a backup archive produced
+// by Backup into the store. policy decides what happens to files that already exist.
+// It returns the manifest of the restored backup.
+func (cs *CSVStore) RestoreBackup(r io.Reader, policy RestorePolicy) (*BackupManifest, error) {
+	return runOperation(cs, Operation{Name: OpRestoreBackup, Payload: policy},
+		func() (*BackupManifest, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return cs.restoreBackup(r, policy)
+		})
+}
+
+// restoreBackup restores a backup archive into the store.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) restoreBackup(r io.Reader, policy RestorePolicy) (*BackupManifest, error) {
+	// Extract into a staging directory first, so a broken archive or a conflict
+	// leaves the store untouched
+	stagingDir, err := cs.makeTempDir(".restore-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer cs.fs.Remove(stagingDir)
+
+	manifest, files, err := cs.extractBackup(r, stagingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cs.applyStagedBackup(stagingDir, manifest, files, policy); err != nil {
+		return nil, err
+	}
+
+	if cs.changeLog {
+		seq, err := cs.lastChangeSequence()
+		if err != nil {
+			return nil, err
+		}
+		cs.changeSeq = max(cs.changeSeq, seq)
+	}
+
+	return manifest, nil
+}
+
+// applyStagedBackup moves the files of a backup extracted into stagingDir into the store.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) applyStagedBackup(
+	stagingDir string,
+	manifest *BackupManifest,
+	files []string,
+	policy RestorePolicy,
+) error {
+	if policy == RestoreFailOnConflict {
+		for _, name := range files {
+			if cs.fileExists(name) {
+				return fmt.Errorf("cannot restore backup: %s already exists", name)
+			}
+		}
+	}
+
+	for _, name := range files {
+		if policy == RestoreSkipExisting && cs.fileExists(name) {
+			continue
+		}
+		if err := cs.fs.Rename(path.Join(stagingDir, name), name); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+		if tableName, ok := tableNameFromFile(name); ok {
+			cs.trackWrite(tableName)
+		}
+	}
+
+	// Files deleted since the base of an incremental backup
+	if policy == RestoreOverwrite {
+		for _, name := range manifest.Deleted {
+			if err := cs.removeStoreFile(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// removeStoreFile removes a file from the store directory if it exists.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) removeStoreFile(name string) error {
+	if !isPlainFileName(name) {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+
+	err := cs.fs.Remove(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", name, err)
+	}
+	if tableName, ok := tableNameFromFile(name); ok {
+		cs.trackWrite(tableName)
+	}
+
+	return nil
+}
+
+// extractBackup extracts the files of a backup archive into the directory dir of
+// the store and returns the manifest and the names of the extracted files
+func (cs *CSVStore) extractBackup(r io.Reader, dir string) (*BackupManifest, []string, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read backup archive: %w", err)
+	}
+	defer gzipReader.Close()
+
+	var manifest *BackupManifest
+	files := make([]string, 0)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read backup archive: %w", err)
+		}
+
+		name := header.Name
+		if header.Typeflag != tar.TypeReg || !isPlainFileName(name) {
+			return nil, nil, fmt.Errorf("unexpected entry %q in backup archive", name)
+		}
+
+		if name == BackupManifestName {
+			manifest = &BackupManifest{}
+			if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+				return nil, nil, fmt.Errorf("failed to read backup manifest: %w", err)
+			}
+			continue
+		}
+
+		if err := writeFileFrom(cs.fs, path.Join(dir, name), tarReader); err != nil {
+			return nil, nil, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		files = append(files, name)
+	}
+
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("backup archive has no %s", BackupManifestName)
+	}
+
+	return manifest, files, nil
+}
+
+// isPlainFileName reports whether name is a file name without any directory part
+func isPlainFileName(name string) bool {
+	return name != "" && name != "." && name != ".." &&
+		!strings.ContainsAny(name, `/\`) && fs.ValidPath(name)
+}
+
+// writeFileFrom writes the contents of r to a new file of fsys
+func writeFileFrom(fsys FS, name string, r io.Reader) error {
+	if _, err := fsys.Stat(name); err == nil {
+		return fs.ErrExist
+	}
+	file, err := fsys.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
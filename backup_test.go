@@ -0,0 +1,155 @@
+package csvstore
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir, WithChangeLog())
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	for _, tableName := range []string{"users", "orders"} {
+		if err := store.CreateTable(tableName, []string{"id", "name"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		if _, err := store.Insert(tableName, CSVRecord{"id": "1", "name": tableName}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	var backup bytes.Buffer
+	if err := store.Backup(&backup); err != nil {
+		t.Fatalf("Failed to back up store: %v", err)
+	}
+
+	// Restoring over the existing files fails by default and changes nothing
+	if _, err := store.RestoreBackup(bytes.NewReader(backup.Bytes()), RestoreFailOnConflict); err == nil {
+		t.Error("Expected conflict error when restoring over existing tables")
+	}
+
+	// Lose data, then restore it
+	if _, err := store.Delete("users", nil); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if err := os.Remove(store.GetTablePath("orders")); err != nil {
+		t.Fatalf("Failed to remove table file: %v", err)
+	}
+
+	manifest, err := store.RestoreBackup(bytes.NewReader(backup.Bytes()), RestoreSkipExisting)
+	if err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+	if manifest.ChangeSequence != 4 {
+		t.Errorf("Expected manifest change sequence 4, got %d", manifest.ChangeSequence)
+	}
+	if result, err := store.Query("orders", nil); err != nil || result.Count != 1 {
+		t.Errorf("Expected missing orders table to be restored, got %v (err %v)", result, err)
+	}
+	if result, err := store.Query("users", nil); err != nil || result.Count != 0 {
+		t.Errorf("Expected existing users table to be kept, got %v (err %v)", result, err)
+	}
+
+	if _, err := store.RestoreBackup(bytes.NewReader(backup.Bytes()), RestoreOverwrite); err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+	if result, err := store.Query("users", nil); err != nil || result.Count != 1 {
+		t.Errorf("Expected users table to be overwritten, got %v (err %v)", result, err)
+	}
+
+	// Restore into a fresh store
+	otherDir := getTestDir()
+	defer os.RemoveAll(otherDir)
+	other, err := NewCSVStore(otherDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	if _, err := other.RestoreBackup(bytes.NewReader(backup.Bytes()), RestoreFailOnConflict); err != nil {
+		t.Fatalf("Failed to restore backup into new store: %v", err)
+	}
+	tables, err := other.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Errorf("Expected 2 restored tables, got %v", tables)
+	}
+}
+
+func TestBackupIncremental(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	for _, tableName := range []string{"users", "orders", "logs"} {
+		if err := store.CreateTable(tableName, []string{"id", "name"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+
+	var full bytes.Buffer
+	if err := store.Backup(&full); err != nil {
+		t.Fatalf("Failed to back up store: %v", err)
+	}
+	base, err := ReadBackupManifest(bytes.NewReader(full.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to read backup manifest: %v", err)
+	}
+	if len(base.Files) != 3 {
+		t.Errorf("Expected 3 files in full backup, got %v", base.Files)
+	}
+
+	if _, err := store.Insert("users", CSVRecord{"id": "1", "name": "John"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if err := os.Remove(store.GetTablePath("logs")); err != nil {
+		t.Fatalf("Failed to remove table file: %v", err)
+	}
+
+	var incremental bytes.Buffer
+	manifest, err := store.BackupIncremental(&incremental, base)
+	if err != nil {
+		t.Fatalf("Failed to create incremental backup: %v", err)
+	}
+	if !manifest.Incremental {
+		t.Error("Expected manifest to be marked incremental")
+	}
+	if len(manifest.Files) != 1 || manifest.Files[0] != "users.csv" {
+		t.Errorf("Expected only users.csv in incremental backup, got %v", manifest.Files)
+	}
+	if len(manifest.Deleted) != 1 || manifest.Deleted[0] != "logs.csv" {
+		t.Errorf("Expected logs.csv to be listed as deleted, got %v", manifest.Deleted)
+	}
+
+	// Replay full + incremental into a new store
+	otherDir := getTestDir()
+	defer os.RemoveAll(otherDir)
+	other, err := NewCSVStore(otherDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	for _, archive := range []*bytes.Buffer{&full, &incremental} {
+		if _, err := other.RestoreBackup(archive, RestoreOverwrite); err != nil {
+			t.Fatalf("Failed to restore backup: %v", err)
+		}
+	}
+
+	tables, err := other.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Errorf("Expected 2 tables after restoring the chain, got %v", tables)
+	}
+	if result, err := other.Query("users", nil); err != nil || result.Count != 1 {
+		t.Errorf("Expected users table from the incremental backup, got %v (err %v)", result, err)
+	}
+}
@@ -0,0 +1,154 @@
+package csvstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"time"
+)
+
+// ChangeLogFileName is the name of the change log file inside the store directory
+const ChangeLogFileName = "changes.ndjson"
+
+// Change operations recorded in a ChangeEvent
+const (
+	ChangeInsert      = "insert"
+	ChangeUpdate      = "update"
+	ChangeDelete      = "delete"
+	ChangeCreateTable = "create_table"
+)
+
+// ChangeEvent describes a single change made by the store
+type ChangeEvent struct {
+	Sequence  uint64    `json:"seq"`
+	Operation string    `json:"op"` // "insert", "update", "delete", "create_table"
+	Table     string    `json:"table"`
+	Record    CSVRecord `json:"record,omitempty"`
+	Headers   []string  `json:"headers,omitempty"` // Set for "create_table"
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// WithChangeLog makes the store append every inserted, updated, and deleted record
+// to an append-only change log (changes.ndjson) in the store directory, one JSON
+// encoded ChangeEvent per line, so other systems can tail and replicate the data
+func WithChangeLog() Option {
+	return func(cs *CSVStore) {
+		cs.changeLog = true
+	}
+}
+
+// lastChangeSequence returns the sequence number of the last entry in the change log
+func (cs *CSVStore) lastChangeSequence() (uint64, error) {
+	var last uint64
+	err := cs.readChangeLog(func(event ChangeEvent) error {
+		last = event.Sequence
+		return nil
+	})
+	return last, err
+}
+
+// readChangeLog calls fn for every entry of the change log, in order.
+// A missing change log has no entries.
+func (cs *CSVStore) readChangeLog(fn func(ChangeEvent) error) error {
+	file, err := cs.fs.Open(ChangeLogFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open change log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event ChangeEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("failed to parse change log: %w", err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read change log: %w", err)
+	}
+
+	return nil
+}
+
+// emitChanges records changes to the change log and notifies subscribers.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) emitChanges(op string, tableName string, records []CSVRecord) error {
+	if len(records) == 0 || (!cs.changeLog && len(cs.subscribers) == 0) {
+		return nil
+	}
+
+	events := make([]ChangeEvent, len(records))
+	for i, record := range records {
+		events[i] = ChangeEvent{
+			Operation: op,
+			Table:     tableName,
+			Record:    maps.Clone(record),
+		}
+	}
+
+	return cs.emitEvents(events)
+}
+
+// emitEvents numbers and timestamps events, records them to the change log,
+// and notifies subscribers.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) emitEvents(events []ChangeEvent) error {
+	if !cs.changeLog && len(cs.subscribers) == 0 {
+		return nil
+	}
+	if cs.stagedEvents != nil {
+		// Emitted once the running migration is committed
+		*cs.stagedEvents = append(*cs.stagedEvents, events...)
+		return nil
+	}
+
+	now := time.Now()
+	for i := range events {
+		cs.changeSeq++
+		events[i].Sequence = cs.changeSeq
+		events[i].Timestamp = now
+	}
+
+	if cs.changeLog {
+		if err := cs.writeChangeLog(events); err != nil {
+			return err
+		}
+		cs.wakeReplication()
+	}
+	cs.notifySubscribers(events)
+
+	return nil
+}
+
+// writeChangeLog appends events to the change log
+func (cs *CSVStore) writeChangeLog(events []ChangeEvent) error {
+	file, err := cs.fs.Append(ChangeLogFileName)
+	if err != nil {
+		return fmt.Errorf("failed to open change log: %w", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to write change log: %w", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write change log: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package csvstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readChangeLog(t *testing.T, basePath string) []ChangeEvent {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join(basePath, ChangeLogFileName))
+	if err != nil {
+		t.Fatalf("Failed to open change log: %v", err)
+	}
+	defer file.Close()
+
+	events := make([]ChangeEvent, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event ChangeEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Failed to parse change log line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestChangeLog(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir, WithChangeLog())
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "users"
+	err = store.CreateTable(tableName, []string{"id", "name"})
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	for _, record := range []CSVRecord{{"id": "1", "name": "John"}, {"id": "2", "name": "Jane"}} {
+		if _, err := store.Insert(tableName, record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	conditions := []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}
+	if _, err := store.Update(tableName, CSVRecord{"name": "Johnny"}, conditions); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if _, err := store.Delete(tableName, conditions); err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+
+	events := readChangeLog(t, testDir)
+	if len(events) != 5 {
+		t.Fatalf("Expected 5 change events, got %d", len(events))
+	}
+
+	expectedOps := []string{ChangeCreateTable, ChangeInsert, ChangeInsert, ChangeUpdate, ChangeDelete}
+	for i, event := range events {
+		if event.Sequence != uint64(i+1) {
+			t.Errorf("Event %d: expected sequence %d, got %d", i, i+1, event.Sequence)
+		}
+		if event.Operation != expectedOps[i] {
+			t.Errorf("Event %d: expected op %s, got %s", i, expectedOps[i], event.Operation)
+		}
+		if event.Table != tableName {
+			t.Errorf("Event %d: expected table %s, got %s", i, tableName, event.Table)
+		}
+	}
+	if len(events[0].Headers) != 2 {
+		t.Errorf("Expected create_table event to carry the headers, got %v", events[0].Headers)
+	}
+	if events[3].Record["name"] != "Johnny" {
+		t.Errorf("Expected update event to carry the new record, got %v", events[3].Record)
+	}
+
+	// Reopening the store continues the sequence
+	reopened, err := NewCSVStore(testDir, WithChangeLog())
+	if err != nil {
+		t.Fatalf("Failed to reopen CSVStore: %v", err)
+	}
+	if _, err := reopened.Insert(tableName, CSVRecord{"id": "3", "name": "Bob"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	events = readChangeLog(t, testDir)
+	if last := events[len(events)-1]; last.Sequence != 6 {
+		t.Errorf("Expected sequence 6 after reopening, got %d", last.Sequence)
+	}
+}
@@ -0,0 +1,45 @@
+package csvstore
+
+import (
+	"errors"
+)
+
+// ErrClosed is returned by operations on a closed store
+var ErrClosed = errors.New("csvstore: store is closed")
+
+// Close stops background work started on the store (watchers, sweepers), cancels
+// all subscriptions, and marks the store unusable: every later operation returns
+// ErrClosed. Close waits for in-flight operations to finish. Closing a closed
+// store is a no-op.
+func (cs *CSVStore) Close() error {
+	cs.mu.Lock()
+	if cs.closed.Load() {
+		cs.mu.Unlock()
+		return nil
+	}
+	cs.closed.Store(true)
+
+	closers := cs.closers
+	cs.closers = nil
+
+	for _, sub := range cs.subscribers {
+		close(sub.events)
+	}
+	cs.subscribers = nil
+	cs.mu.Unlock()
+
+	var errs []error
+	for _, closer := range closers {
+		if err := closer(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// addCloser registers a function stopping background work when the store is closed.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) addCloser(closer func() error) {
+	cs.closers = append(cs.closers, closer)
+}
@@ -0,0 +1,54 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "users"
+	if err := store.CreateTable(tableName, []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	if _, err := store.WatchExternalChanges(); err != nil {
+		t.Fatalf("Failed to start watcher: %v", err)
+	}
+	if _, err := store.StartExpirySweeper(time.Hour, nil); err != nil {
+		t.Fatalf("Failed to start sweeper: %v", err)
+	}
+	events, _ := store.Subscribe(tableName)
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Errorf("Expected closing twice to be a no-op, got %v", err)
+	}
+
+	if _, ok := <-events; ok {
+		t.Error("Expected subscription channel to be closed")
+	}
+
+	if _, err := store.Query(tableName, nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from Query, got %v", err)
+	}
+	if _, err := store.Insert(tableName, CSVRecord{"id": "1"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from Insert, got %v", err)
+	}
+	if err := store.CreateTable("other", []string{"id"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from CreateTable, got %v", err)
+	}
+	if _, err := store.WatchExternalChanges(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from WatchExternalChanges, got %v", err)
+	}
+}
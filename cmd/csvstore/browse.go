@@ -0,0 +1,475 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/csvstore"
+)
+
+const (
+	// maxColumnWidth bounds the width of grid columns; longer values are truncated
+	maxColumnWidth = 30
+	// maxTableListWidth bounds the width of the table list
+	maxTableListWidth = 24
+	// browseHelp is shown in the status line when there is nothing to report
+	browseHelp = "Tab switch pane  Enter open/edit  / filter  r reload  q quit"
+)
+
+// pane identifies the part of the browser receiving keys
+type pane int
+
+const (
+	tablesPane pane = iota
+	gridPane
+)
+
+// inputLine is a line of text being typed in the status line
+type inputLine struct {
+	label  string
+	text   []rune
+	cursor int
+	done   func(text string) error
+}
+
+// browser is a terminal UI listing the tables of a store and showing the
+// records of one of them in a scrollable grid
+type browser struct {
+	store  *csvstore.CSVStore
+	screen tcell.Screen
+
+	tables     []string
+	tableIndex int
+	focus      pane
+
+	// Open table
+	table      string
+	headers    []string
+	widths     []int
+	records    []csvstore.CSVRecord
+	filter     string
+	conditions []csvstore.QueryCondition
+
+	row, col             int // Selected cell
+	rowOffset, colOffset int // First row and column shown
+
+	input  *inputLine
+	status string
+	quit   bool
+}
+
+func runBrowse(store *csvstore.CSVStore, args []string, _ io.Writer) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return fmt.Errorf("failed to open terminal: %w", err)
+	}
+	if err := screen.Init(); err != nil {
+		return fmt.Errorf("failed to open terminal: %w", err)
+	}
+	defer screen.Fini()
+
+	b, err := newBrowser(store, screen)
+	if err != nil {
+		return err
+	}
+	return b.run()
+}
+
+// newBrowser returns a browser drawing on an initialized screen
+func newBrowser(store *csvstore.CSVStore, screen tcell.Screen) (*browser, error) {
+	b := &browser{store: store, screen: screen, status: browseHelp}
+	if err := b.loadTables(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// run handles events until the user quits
+func (b *browser) run() error {
+	for !b.quit {
+		b.draw()
+		switch ev := b.screen.PollEvent().(type) {
+		case nil:
+			return nil
+		case *tcell.EventResize:
+			b.screen.Sync()
+		case *tcell.EventKey:
+			b.handleKey(ev)
+		}
+	}
+	return nil
+}
+
+// loadTables reads the table list
+func (b *browser) loadTables() error {
+	tables, err := b.store.ListTables()
+	if err != nil {
+		return err
+	}
+	slices.Sort(tables)
+	b.tables = tables
+	b.tableIndex = min(b.tableIndex, max(len(tables)-1, 0))
+	return nil
+}
+
+// openTable shows the records of a table, without filter
+func (b *browser) openTable(table string) error {
+	headers, err := b.store.Headers(table)
+	if err != nil {
+		return err
+	}
+	b.table, b.headers = table, headers
+	b.filter, b.conditions = "", nil
+	b.row, b.col, b.rowOffset, b.colOffset = 0, 0, 0, 0
+	return b.loadRecords()
+}
+
+// loadRecords reads the records of the open table matching the filter
+func (b *browser) loadRecords() error {
+	result, err := b.store.Query(b.table, b.conditions)
+	if err != nil {
+		return err
+	}
+	b.records = result.Records
+
+	b.widths = make([]int, len(b.headers))
+	for i, header := range b.headers {
+		width := len([]rune(header))
+		for _, record := range b.records {
+			width = max(width, len([]rune(cellText(record[header]))))
+		}
+		b.widths[i] = min(max(width, 1), maxColumnWidth)
+	}
+	b.row = min(b.row, max(len(b.records)-1, 0))
+	return nil
+}
+
+// report shows an error, or the help when err is nil, in the status line
+func (b *browser) report(err error) {
+	if err != nil {
+		b.status = "error: " + err.Error()
+	} else {
+		b.status = browseHelp
+	}
+}
+
+// handleKey dispatches a key press
+func (b *browser) handleKey(ev *tcell.EventKey) {
+	if b.input != nil {
+		b.handleInputKey(ev)
+		return
+	}
+	b.status = browseHelp
+
+	switch {
+	case ev.Key() == tcell.KeyCtrlC || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q'):
+		b.quit = true
+		return
+	case ev.Key() == tcell.KeyTab || ev.Key() == tcell.KeyBacktab:
+		if b.focus == tablesPane && b.table != "" {
+			b.focus = gridPane
+		} else {
+			b.focus = tablesPane
+		}
+		return
+	case ev.Key() == tcell.KeyRune && ev.Rune() == 'r':
+		err := b.loadTables()
+		if err == nil && b.table != "" {
+			err = b.loadRecords()
+		}
+		b.report(err)
+		return
+	case ev.Key() == tcell.KeyRune && ev.Rune() == '/':
+		b.startFilter()
+		return
+	}
+
+	if b.focus == tablesPane {
+		b.handleTablesKey(ev)
+	} else {
+		b.handleGridKey(ev)
+	}
+}
+
+// handleTablesKey handles a key press in the table list
+func (b *browser) handleTablesKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyUp:
+		b.tableIndex = max(b.tableIndex-1, 0)
+	case tcell.KeyDown:
+		b.tableIndex = min(b.tableIndex+1, max(len(b.tables)-1, 0))
+	case tcell.KeyEnter:
+		if len(b.tables) == 0 {
+			return
+		}
+		if err := b.openTable(b.tables[b.tableIndex]); err != nil {
+			b.report(err)
+			return
+		}
+		b.focus = gridPane
+	}
+}
+
+// handleGridKey handles a key press in the record grid
+func (b *browser) handleGridKey(ev *tcell.EventKey) {
+	_, height := b.screen.Size()
+	page := max(b.visibleRows(height), 1)
+	last := max(len(b.records)-1, 0)
+
+	switch ev.Key() {
+	case tcell.KeyUp:
+		b.row = max(b.row-1, 0)
+	case tcell.KeyDown:
+		b.row = min(b.row+1, last)
+	case tcell.KeyPgUp:
+		b.row = max(b.row-page, 0)
+	case tcell.KeyPgDn:
+		b.row = min(b.row+page, last)
+	case tcell.KeyHome:
+		b.row = 0
+	case tcell.KeyEnd:
+		b.row = last
+	case tcell.KeyLeft:
+		b.col = max(b.col-1, 0)
+	case tcell.KeyRight:
+		b.col = min(b.col+1, max(len(b.headers)-1, 0))
+	case tcell.KeyEscape:
+		b.focus = tablesPane
+	case tcell.KeyEnter:
+		b.startEdit()
+	}
+}
+
+// startFilter opens the filter box of the open table
+func (b *browser) startFilter() {
+	if b.table == "" {
+		b.status = "open a table first"
+		return
+	}
+	b.input = &inputLine{
+		label:  "filter (e.g. price > 10 AND name CONTAINS pen): ",
+		text:   []rune(b.filter),
+		cursor: len([]rune(b.filter)),
+		done: func(text string) error {
+			var conditions []csvstore.QueryCondition
+			if strings.TrimSpace(text) != "" {
+				var err error
+				if conditions, err = parseConditions(text); err != nil {
+					return err
+				}
+			}
+			b.filter, b.conditions = text, conditions
+			b.row, b.rowOffset = 0, 0
+			return b.loadRecords()
+		},
+	}
+}
+
+// startEdit opens an input to edit the selected cell, which is saved with an
+// update of the record by id
+func (b *browser) startEdit() {
+	if len(b.records) == 0 || len(b.headers) == 0 {
+		return
+	}
+	column := b.headers[b.col]
+	record := b.records[b.row]
+	id, ok := record[csvstore.DefaultIDColumn]
+	switch {
+	case !ok || id == "":
+		b.status = fmt.Sprintf("cannot edit: table %s has no %s column", b.table, csvstore.DefaultIDColumn)
+		return
+	case column == csvstore.DefaultIDColumn:
+		b.status = "cannot edit the id column"
+		return
+	}
+
+	value := []rune(record[column])
+	b.input = &inputLine{
+		label:  column + ": ",
+		text:   value,
+		cursor: len(value),
+		done: func(text string) error {
+			conditions := []csvstore.QueryCondition{{Column: csvstore.DefaultIDColumn, Operator: "=", Value: id}}
+			if _, err := b.store.Update(b.table, csvstore.CSVRecord{column: text}, conditions); err != nil {
+				return err
+			}
+			return b.loadRecords()
+		},
+	}
+}
+
+// handleInputKey edits the input line
+func (b *browser) handleInputKey(ev *tcell.EventKey) {
+	in := b.input
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		b.input = nil
+		b.report(nil)
+	case tcell.KeyEnter:
+		b.input = nil
+		b.report(in.done(string(in.text)))
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if in.cursor > 0 {
+			in.text = slices.Delete(in.text, in.cursor-1, in.cursor)
+			in.cursor--
+		}
+	case tcell.KeyDelete:
+		if in.cursor < len(in.text) {
+			in.text = slices.Delete(in.text, in.cursor, in.cursor+1)
+		}
+	case tcell.KeyLeft:
+		in.cursor = max(in.cursor-1, 0)
+	case tcell.KeyRight:
+		in.cursor = min(in.cursor+1, len(in.text))
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		in.cursor = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		in.cursor = len(in.text)
+	case tcell.KeyRune:
+		in.text = slices.Insert(in.text, in.cursor, ev.Rune())
+		in.cursor++
+	}
+}
+
+// visibleRows returns the number of records shown on a screen of a height
+func (b *browser) visibleRows(height int) int {
+	// Title, grid header, and status lines
+	return height - 3
+}
+
+// tableListWidth returns the width of the table list
+func (b *browser) tableListWidth() int {
+	width := len("tables")
+	for _, table := range b.tables {
+		width = max(width, len([]rune(table)))
+	}
+	return min(width+2, maxTableListWidth)
+}
+
+// draw renders the browser
+func (b *browser) draw() {
+	s := b.screen
+	s.Clear()
+	s.HideCursor()
+	width, height := s.Size()
+	if width < 10 || height < 4 {
+		return
+	}
+
+	plain := tcell.StyleDefault
+	bold := plain.Bold(true)
+	reverse := plain.Reverse(true)
+
+	title := " csvstore"
+	if b.table != "" {
+		title += "  table: " + b.table + fmt.Sprintf("  %d records", len(b.records))
+		if b.filter != "" {
+			title += "  filter: " + b.filter
+		}
+	}
+	fillLine(s, 0, width, reverse)
+	drawText(s, 0, 0, width, title, reverse)
+
+	// Table list
+	listWidth := b.tableListWidth()
+	drawText(s, 1, 1, listWidth-1, "tables", bold)
+	for i, table := range b.tables {
+		y := i + 2
+		if y >= height-1 {
+			break
+		}
+		style := plain
+		if i == b.tableIndex {
+			style = bold
+			if b.focus == tablesPane {
+				style = reverse
+			}
+		}
+		drawText(s, 1, y, listWidth-1, table, style)
+	}
+	for y := 1; y < height-1; y++ {
+		s.SetContent(listWidth, y, tcell.RuneVLine, nil, plain)
+	}
+
+	// Record grid
+	gridX := listWidth + 2
+	gridWidth := width - gridX
+	if b.table != "" && gridWidth > 0 {
+		b.scrollIntoView(gridWidth, height)
+		x := gridX
+		for i := b.colOffset; i < len(b.headers) && x < width; i++ {
+			drawText(s, x, 1, min(b.widths[i], width-x), b.headers[i], bold)
+			for r := b.rowOffset; r < len(b.records) && r-b.rowOffset < b.visibleRows(height); r++ {
+				style := plain
+				if r == b.row && i == b.col && b.focus == gridPane {
+					style = reverse
+				} else if r == b.row {
+					style = bold
+				}
+				drawText(s, x, r-b.rowOffset+2, min(b.widths[i], width-x), cellText(b.records[r][b.headers[i]]), style)
+			}
+			x += b.widths[i] + 2
+		}
+	}
+
+	// Status or input line
+	if in := b.input; in != nil {
+		drawText(s, 0, height-1, width, in.label+string(in.text), plain)
+		s.ShowCursor(min(len([]rune(in.label))+in.cursor, width-1), height-1)
+	} else {
+		drawText(s, 0, height-1, width, b.status, plain)
+	}
+	s.Show()
+}
+
+// scrollIntoView adjusts the offsets so the selected cell is visible
+func (b *browser) scrollIntoView(gridWidth, height int) {
+	rows := max(b.visibleRows(height), 1)
+	if b.row < b.rowOffset {
+		b.rowOffset = b.row
+	}
+	if b.row >= b.rowOffset+rows {
+		b.rowOffset = b.row - rows + 1
+	}
+
+	if b.col < b.colOffset {
+		b.colOffset = b.col
+	}
+	for b.colOffset < b.col {
+		right := 0
+		for i := b.colOffset; i <= b.col; i++ {
+			right += b.widths[i] + 2
+		}
+		if right <= gridWidth {
+			break
+		}
+		b.colOffset++
+	}
+}
+
+// drawText draws text at x, y, truncated with an ellipsis to width cells
+func drawText(s tcell.Screen, x, y, width int, text string, style tcell.Style) {
+	runes := []rune(text)
+	if len(runes) > width {
+		if width <= 0 {
+			return
+		}
+		runes = append(runes[:width-1], '…')
+	}
+	for i, r := range runes {
+		s.SetContent(x+i, y, r, nil, style)
+	}
+}
+
+// fillLine paints a whole screen line with a style
+func fillLine(s tcell.Screen, y, width int, style tcell.Style) {
+	for x := 0; x < width; x++ {
+		s.SetContent(x, y, ' ', nil, style)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jiyeol-lee/csvstore"
+)
+
+func newTestBrowser(t *testing.T) (*browser, tcell.SimulationScreen, *csvstore.CSVStore) {
+	t.Helper()
+
+	store := newShellStore(t)
+	for _, record := range []csvstore.CSVRecord{
+		{"id": "1", "name": "pen", "price": "10"},
+		{"id": "2", "name": "desk", "price": "200"},
+		{"id": "3", "name": "lamp", "price": "30"},
+	} {
+		if _, err := store.Insert("products", record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to init screen: %v", err)
+	}
+	t.Cleanup(screen.Fini)
+	screen.SetSize(80, 10)
+
+	b, err := newBrowser(store, screen)
+	if err != nil {
+		t.Fatalf("Failed to create browser: %v", err)
+	}
+	return b, screen, store
+}
+
+// screenText returns the lines shown on a simulation screen
+func screenText(screen tcell.SimulationScreen) []string {
+	cells, width, height := screen.GetContents()
+	lines := make([]string, height)
+	for y := range height {
+		var line strings.Builder
+		for x := range width {
+			runes := cells[y*width+x].Runes
+			if len(runes) == 0 {
+				line.WriteRune(' ')
+			} else {
+				line.WriteRune(runes[0])
+			}
+		}
+		lines[y] = strings.TrimRight(line.String(), " ")
+	}
+	return lines
+}
+
+func pressKeys(b *browser, keys ...any) {
+	for _, key := range keys {
+		switch key := key.(type) {
+		case tcell.Key:
+			b.handleKey(tcell.NewEventKey(key, 0, tcell.ModNone))
+		case string:
+			for _, r := range key {
+				b.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+			}
+		}
+	}
+	b.draw()
+}
+
+func TestBrowse(t *testing.T) {
+	b, screen, store := newTestBrowser(t)
+
+	pressKeys(b, tcell.KeyEnter)
+	lines := screenText(screen)
+	if !strings.Contains(lines[0], "table: products  3 records") {
+		t.Errorf("Expected title with table, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "id  name  price") || !strings.Contains(lines[3], "2   desk  200") {
+		t.Errorf("Unexpected grid: %q", lines[:5])
+	}
+
+	// Filter
+	pressKeys(b, "/", "price > 20", tcell.KeyEnter)
+	if len(b.records) != 2 || !strings.Contains(screenText(screen)[0], "filter: price > 20") {
+		t.Errorf("Expected 2 filtered records, got %v", b.records)
+	}
+	pressKeys(b, "/", "price >", tcell.KeyEnter)
+	if !strings.HasPrefix(b.status, "error:") || len(b.records) != 2 {
+		t.Errorf("Expected an invalid filter to be reported, got %q", b.status)
+	}
+
+	// Edit the price of lamp
+	pressKeys(b, tcell.KeyDown, tcell.KeyRight, tcell.KeyRight, tcell.KeyEnter)
+	if b.input == nil || string(b.input.text) != "30" {
+		t.Fatalf("Expected the edit input to hold 30, got %+v", b.input)
+	}
+	pressKeys(b, tcell.KeyBackspace2, tcell.KeyBackspace2, "35", tcell.KeyEnter)
+	result, err := store.Query("products", []csvstore.QueryCondition{{Column: "id", Operator: "=", Value: "3"}})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Records[0]["price"] != "35" {
+		t.Errorf("Expected price 35 after edit, got %v", result.Records[0])
+	}
+
+	// The id column cannot be edited
+	pressKeys(b, tcell.KeyLeft, tcell.KeyLeft, tcell.KeyEnter)
+	if b.input != nil || b.status != "cannot edit the id column" {
+		t.Errorf("Expected id edit to be refused, got %q", b.status)
+	}
+
+	// Escape cancels an edit
+	pressKeys(b, tcell.KeyRight, tcell.KeyEnter, "x", tcell.KeyEscape)
+	if b.input != nil || b.records[1]["name"] != "lamp" {
+		t.Errorf("Expected canceled edit, got %v", b.records[1])
+	}
+
+	pressKeys(b, "q")
+	if !b.quit {
+		t.Error("Expected q to quit")
+	}
+}
+
+func TestBrowseScroll(t *testing.T) {
+	b, _, store := newTestBrowser(t)
+	for i := range 20 {
+		if _, err := store.Insert("products", csvstore.CSVRecord{"name": "item", "price": string(rune('a' + i))}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	pressKeys(b, tcell.KeyEnter, tcell.KeyEnd)
+	if b.row != 22 || b.rowOffset != 22-b.visibleRows(10)+1 {
+		t.Errorf("Expected last row visible, got row %d offset %d", b.row, b.rowOffset)
+	}
+	pressKeys(b, tcell.KeyHome)
+	if b.row != 0 || b.rowOffset != 0 {
+		t.Errorf("Expected first row visible, got row %d offset %d", b.row, b.rowOffset)
+	}
+}
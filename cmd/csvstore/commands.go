@@ -0,0 +1,418 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+// tableArgs splits the table name from the remaining arguments of a command
+func tableArgs(args []string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", nil, errUsage
+	}
+	return args[0], args[1:], nil
+}
+
+// parseFlags parses the flags of a command
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errUsage
+		}
+		return err
+	}
+	return nil
+}
+
+// checkTable returns an error when a table does not exist
+func checkTable(store *csvstore.CSVStore, table string) error {
+	if !store.CheckTableExists(table) {
+		return fmt.Errorf("table %s does not exist", table)
+	}
+	return nil
+}
+
+func runList(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	tables, err := store.ListTables()
+	if err != nil {
+		return err
+	}
+	slices.Sort(tables)
+	for _, table := range tables {
+		fmt.Fprintln(stdout, table)
+	}
+	return nil
+}
+
+func runDescribe(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	table, rest, err := tableArgs(args)
+	if err != nil || len(rest) != 0 {
+		return errUsage
+	}
+	if err := checkTable(store, table); err != nil {
+		return err
+	}
+
+	headers, err := store.Headers(table)
+	if err != nil {
+		return err
+	}
+	result, err := store.Query(table, nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "table:   %s\n", table)
+	fmt.Fprintf(stdout, "file:    %s\n", store.GetTablePath(table))
+	fmt.Fprintf(stdout, "rows:    %d\n", result.Count)
+	fmt.Fprintln(stdout, "columns:")
+	for _, header := range headers {
+		fmt.Fprintf(stdout, "  %s\n", header)
+	}
+	return nil
+}
+
+func runQuery(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	table, rest, err := tableArgs(args)
+	if err != nil {
+		return err
+	}
+
+	var opts csvstore.QueryOptions
+	var where conditionsFlag
+	var columns string
+	flags := flag.NewFlagSet("query", flag.ContinueOnError)
+	flags.Var(&where, "where", "filter column:operator:value")
+	flags.StringVar(&opts.SortField, "sort", "", "sort column")
+	flags.StringVar(&opts.SortBy, "order", "asc", "sort order")
+	flags.IntVar(&opts.Limit, "limit", 0, "maximum number of records")
+	flags.IntVar(&opts.Offset, "offset", 0, "number of records to skip")
+	flags.StringVar(&columns, "columns", "", "comma-separated columns")
+	format := flags.String("format", "table", "output format")
+	if err := parseFlags(flags, rest); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+	if err := checkTable(store, table); err != nil {
+		return err
+	}
+
+	opts.Conditions = where
+	if columns != "" {
+		opts.Columns = strings.Split(columns, ",")
+	}
+	result, err := store.QueryWithOptions(table, opts)
+	if err != nil {
+		return err
+	}
+
+	headers := opts.Columns
+	if headers == nil {
+		if headers, err = store.Headers(table); err != nil {
+			return err
+		}
+	}
+	return writeRecords(stdout, *format, headers, result.Records)
+}
+
+func runInsert(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	table, rest, err := tableArgs(args)
+	if err != nil || len(rest) == 0 {
+		return errUsage
+	}
+	record, err := parseAssignments(rest)
+	if err != nil {
+		return err
+	}
+	if err := checkTable(store, table); err != nil {
+		return err
+	}
+
+	inserted, err := store.Insert(table, record)
+	if err != nil {
+		return err
+	}
+	headers, err := store.Headers(table)
+	if err != nil {
+		return err
+	}
+	return writeRecords(stdout, "table", headers, []csvstore.CSVRecord{inserted})
+}
+
+// writeFlags parses the flags and assignments of update and delete, refusing
+// to change every record without -all
+func writeFlags(name string, args []string) (csvstore.CSVRecord, []csvstore.QueryCondition, error) {
+	var where conditionsFlag
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Var(&where, "where", "filter column:operator:value")
+	all := flags.Bool("all", false, "change every record")
+	if err := parseFlags(flags, args); err != nil {
+		return nil, nil, err
+	}
+	if len(where) == 0 && !*all {
+		return nil, nil, errors.New("refusing to change every record without -where filters; pass -all to confirm")
+	}
+	record, err := parseAssignments(flags.Args())
+	if err != nil {
+		return nil, nil, err
+	}
+	return record, where, nil
+}
+
+func runUpdate(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	table, rest, err := tableArgs(args)
+	if err != nil {
+		return err
+	}
+	updates, conditions, err := writeFlags("update", rest)
+	if err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		return errUsage
+	}
+	if err := checkTable(store, table); err != nil {
+		return err
+	}
+
+	result, err := store.Update(table, updates, conditions)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "updated %d records\n", result.Count)
+	return nil
+}
+
+func runDelete(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	table, rest, err := tableArgs(args)
+	if err != nil {
+		return err
+	}
+	record, conditions, err := writeFlags("delete", rest)
+	if err != nil {
+		return err
+	}
+	if len(record) != 0 {
+		return errUsage
+	}
+	if err := checkTable(store, table); err != nil {
+		return err
+	}
+
+	result, err := store.Delete(table, conditions)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "deleted %d records\n", result.Count)
+	return nil
+}
+
+func runImport(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	table, rest, err := tableArgs(args)
+	if err != nil {
+		return err
+	}
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "input format, from the file extension when empty")
+	unknown := flags.String("unknown", "ignore", "policy for unknown columns")
+	if err := parseFlags(flags, rest); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+	path := flags.Arg(0)
+
+	var policy csvstore.UnknownColumnPolicy
+	switch *unknown {
+	case "ignore":
+		policy = csvstore.IgnoreUnknownColumns
+	case "error":
+		policy = csvstore.ErrorOnUnknownColumns
+	case "add":
+		policy = csvstore.AddUnknownColumns
+	default:
+		return fmt.Errorf("invalid unknown column policy %q", *unknown)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if !store.CheckTableExists(table) {
+		if *format != "csv" {
+			return fmt.Errorf("table %s does not exist", table)
+		}
+		if err := createFromCSV(store, table, file); err != nil {
+			return err
+		}
+	}
+
+	var result *csvstore.QueryResult
+	switch *format {
+	case "csv":
+		result, err = store.ImportCSV(table, file, nil, csvstore.CSVImportOptions{UnknownColumns: policy})
+	case "json", "ndjson", "jsonl":
+		result, err = store.ImportJSON(table, file, csvstore.JSONImportOptions{UnknownColumns: policy})
+	default:
+		return fmt.Errorf("unsupported import format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "imported %d records\n", result.Count)
+	return nil
+}
+
+// createFromCSV creates a table with the columns of the header row of a CSV
+// file, then rewinds the file
+func createFromCSV(store *csvstore.CSVStore, table string, file *os.File) error {
+	headers, err := csv.NewReader(file).Read()
+	if err != nil {
+		return fmt.Errorf("failed to read headers of %s: %w", file.Name(), err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind %s: %w", file.Name(), err)
+	}
+	return store.CreateTable(table, headers)
+}
+
+func runExport(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	table, rest, err := tableArgs(args)
+	if err != nil {
+		return err
+	}
+	var where conditionsFlag
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.Var(&where, "where", "filter column:operator:value")
+	format := flags.String("format", "csv", "output format")
+	dialect := flags.String("dialect", string(csvstore.DialectSQLite), "SQL dialect")
+	output := flags.String("o", "", "output file, standard output when empty")
+	if err := parseFlags(flags, rest); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+	if len(where) != 0 && (*format == "sql" || *format == "xlsx") {
+		return fmt.Errorf("-where is not supported with format %s", *format)
+	}
+	if err := checkTable(store, table); err != nil {
+		return err
+	}
+
+	return writeOutput(*output, stdout, func(w io.Writer) error {
+		switch *format {
+		case "csv":
+			return store.QueryToCSV(table, where, w)
+		case "json":
+			return store.ExportJSON(table, w, where, csvstore.JSONExportOptions{Format: csvstore.JSONArray})
+		case "ndjson":
+			return store.ExportJSON(table, w, where, csvstore.JSONExportOptions{Format: csvstore.NDJSON})
+		case "sql":
+			return store.ExportSQL(table, w, csvstore.SQLDialect(*dialect))
+		case "xlsx":
+			return store.ExportXLSX(w, table)
+		default:
+			return fmt.Errorf("unsupported export format %q", *format)
+		}
+	})
+}
+
+func runCompact(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	table, rest, err := tableArgs(args)
+	if err != nil {
+		return err
+	}
+	flags := flag.NewFlagSet("compact", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", 0, "drop tombstones older than this")
+	if err := parseFlags(flags, rest); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+	if err := checkTable(store, table); err != nil {
+		return err
+	}
+
+	var horizon time.Time
+	if *olderThan > 0 {
+		horizon = time.Now().Add(-*olderThan)
+	}
+	removed, err := store.Compact(table, horizon)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "removed %d tombstones\n", removed)
+	return nil
+}
+
+func runNormalize(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	table, rest, err := tableArgs(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errUsage
+	}
+	if err := checkTable(store, table); err != nil {
+		return err
+	}
+	return store.Normalize(table)
+}
+
+func runBackup(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	output := flags.String("o", "", "output file, standard output when empty")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+	return writeOutput(*output, stdout, store.Backup)
+}
+
+// writeOutput runs write on the file at path, or on stdout when path is empty.
+// A partially written file is removed when write fails.
+func writeOutput(path string, stdout io.Writer, write func(io.Writer) error) error {
+	if path == "" {
+		return write(stdout)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Command csvstore inspects and edits the tables of a csvstore directory.
+//
+// Usage:
+//
+//	csvstore [-dir path] <command> [arguments]
+//
+// The store directory defaults to $CSVSTORE_DIR, or the current directory.
+// Commands:
+//
+//	ls                                        list tables
+//	describe <table>                          show the columns and row count of a table
+//	query <table> [flags]                     print the records matching -where filters
+//	insert <table> column=value...            insert a record
+//	update <table> [flags] column=value...    update the records matching -where filters
+//	delete <table> [flags]                    delete the records matching -where filters
+//	import <table> <file>                     insert the records of a CSV or JSON file,
+//	                                          creating the table from a CSV header row
+//	export <table> [flags]                    write a table as CSV, JSON, NDJSON, SQL, or XLSX
+//	compact <table> [flags]                   drop superseded and old tombstones
+//	normalize <table>                         rewrite a table in canonical form for clean diffs
+//	backup [-o file]                          write a backup archive of the store
+//	shell                                     run SQL-like statements interactively
+//	browse                                    browse and edit tables in a terminal UI
+//
+// Filters take the form column:operator:value, e.g. -where age:>=:18, with the
+// operators of csvstore.QueryCondition. update and delete refuse to change every
+// record of a table unless -all is passed.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+// command runs a subcommand against an open store
+type command struct {
+	usage string
+	run   func(store *csvstore.CSVStore, args []string, stdout io.Writer) error
+}
+
+// commands lists the subcommands by name
+var commands = map[string]command{
+	"ls":        {"ls", runList},
+	"describe":  {"describe <table>", runDescribe},
+	"query":     {"query <table> [-where column:operator:value]... [-sort column] [-order asc|desc] [-limit n] [-offset n] [-columns a,b] [-format table|csv|json]", runQuery},
+	"insert":    {"insert <table> column=value...", runInsert},
+	"update":    {"update <table> [-where column:operator:value]... [-all] column=value...", runUpdate},
+	"delete":    {"delete <table> [-where column:operator:value]... [-all]", runDelete},
+	"import":    {"import <table> [-format csv|json] [-unknown ignore|error|add] <file>", runImport},
+	"export":    {"export <table> [-where column:operator:value]... [-format csv|json|ndjson|sql|xlsx] [-dialect sqlite|postgres|mysql] [-o file]", runExport},
+	"compact":   {"compact <table> [-older-than duration]", runCompact},
+	"normalize": {"normalize <table>", runNormalize},
+	"backup":    {"backup [-o file]", runBackup},
+	"shell":     {"shell", runShell},
+	"browse":    {"browse", runBrowse},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "csvstore: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags, opens the store, and runs a command
+func run(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("csvstore", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("dir", defaultDir(), "store directory")
+	flags.Usage = func() { usage(stderr) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		usage(stderr)
+		return flag.ErrHelp
+	}
+
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	store, err := openStore(*dir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := cmd.run(store, flags.Args()[1:], stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			return fmt.Errorf("usage: csvstore %s", cmd.usage)
+		}
+		return err
+	}
+	return nil
+}
+
+// defaultDir returns the store directory used without -dir
+func defaultDir() string {
+	if dir := os.Getenv("CSVSTORE_DIR"); dir != "" {
+		return dir
+	}
+	return "."
+}
+
+// openStore opens the store in dir, which must exist so a mistyped path does
+// not silently create an empty store
+func openStore(dir string) (*csvstore.CSVStore, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("failed to open store: %s is not a directory", dir)
+	}
+	return csvstore.NewCSVStore(dir)
+}
+
+// usage prints the list of commands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: csvstore [-dir path] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, name := range slices.Sorted(maps.Keys(commands)) {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+}
+
+// errUsage reports arguments not matching the usage of a command
+var errUsage = errors.New("invalid arguments")
+
+// conditionsFlag collects repeated -where filters
+type conditionsFlag []csvstore.QueryCondition
+
+func (c *conditionsFlag) String() string {
+	filters := make([]string, len(*c))
+	for i, condition := range *c {
+		filters[i] = condition.Column + ":" + condition.Operator + ":" + condition.Value
+	}
+	return strings.Join(filters, " ")
+}
+
+func (c *conditionsFlag) Set(filter string) error {
+	parts := strings.SplitN(filter, ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid filter %q, expected column:operator:value", filter)
+	}
+	condition := csvstore.QueryCondition{Column: parts[0], Operator: parts[1]}
+	if len(parts) == 3 {
+		condition.Value = parts[2]
+	}
+	*c = append(*c, condition)
+	return nil
+}
+
+// parseAssignments parses column=value arguments into a record
+func parseAssignments(args []string) (csvstore.CSVRecord, error) {
+	record := make(csvstore.CSVRecord, len(args))
+	for _, arg := range args {
+		column, value, ok := strings.Cut(arg, "=")
+		if !ok || column == "" {
+			return nil, fmt.Errorf("invalid assignment %q, expected column=value", arg)
+		}
+		record[column] = value
+	}
+	return record, nil
+}
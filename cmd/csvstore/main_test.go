@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCommand runs the CLI against dir and returns its standard output
+func runCommand(t *testing.T, dir string, args ...string) (string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	err := run(append([]string{"-dir", dir}, args...), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func mustRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	out, err := runCommand(t, dir, args...)
+	if err != nil {
+		t.Fatalf("Failed to run %v: %v", args, err)
+	}
+	return out
+}
+
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(t.TempDir(), "products.csv")
+	if err := os.WriteFile(source, []byte("id,name,price\n1,pen,10\n2,desk,200\n3,lamp,30\n"), 0644); err != nil {
+		t.Fatalf("Failed to write source file: %v", err)
+	}
+
+	if out := mustRun(t, dir, "import", "products", source); out != "imported 3 records\n" {
+		t.Errorf("Unexpected import output: %q", out)
+	}
+	if out := mustRun(t, dir, "ls"); out != "products\n" {
+		t.Errorf("Unexpected ls output: %q", out)
+	}
+	if out := mustRun(t, dir, "describe", "products"); !strings.Contains(out, "rows:    3") || !strings.Contains(out, "  price\n") {
+		t.Errorf("Unexpected describe output: %q", out)
+	}
+
+	out := mustRun(t, dir, "query", "products", "-where", "price:>:20", "-sort", "price", "-order", "desc", "-columns", "name,price")
+	expected := "name  price\ndesk  200\nlamp  30\n(2 records)\n"
+	if out != expected {
+		t.Errorf("Expected query output %q, got %q", expected, out)
+	}
+
+	mustRun(t, dir, "insert", "products", "name=chair", "price=80")
+	if out := mustRun(t, dir, "update", "products", "-where", "name:=:pen", "price=12"); out != "updated 1 records\n" {
+		t.Errorf("Unexpected update output: %q", out)
+	}
+	if out := mustRun(t, dir, "delete", "products", "-where", "price:<:50"); out != "deleted 2 records\n" {
+		t.Errorf("Unexpected delete output: %q", out)
+	}
+
+	out = mustRun(t, dir, "export", "products", "-format", "csv")
+	if !strings.HasPrefix(out, "id,name,price\n2,desk,200\n") || !strings.Contains(out, ",chair,80\n") {
+		t.Errorf("Unexpected export output: %q", out)
+	}
+
+	backup := filepath.Join(t.TempDir(), "backup.tar.gz")
+	mustRun(t, dir, "backup", "-o", backup)
+	if info, err := os.Stat(backup); err != nil || info.Size() == 0 {
+		t.Errorf("Expected a backup file, got %v", err)
+	}
+
+	if out := mustRun(t, dir, "compact", "products"); out != "removed 0 tombstones\n" {
+		t.Errorf("Unexpected compact output: %q", out)
+	}
+	if out := mustRun(t, dir, "normalize", "products"); out != "" {
+		t.Errorf("Unexpected normalize output: %q", out)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := runCommand(t, filepath.Join(dir, "missing"), "ls"); err == nil {
+		t.Error("Expected error for a missing store directory")
+	}
+	if _, err := runCommand(t, dir, "bogus"); err == nil {
+		t.Error("Expected error for an unknown command")
+	}
+	if _, err := runCommand(t, dir, "query"); err == nil || !strings.Contains(err.Error(), "usage:") {
+		t.Errorf("Expected usage error, got %v", err)
+	}
+	if _, err := runCommand(t, dir, "query", "missing"); err == nil {
+		t.Error("Expected error for a missing table")
+	}
+
+	source := filepath.Join(t.TempDir(), "t.csv")
+	if err := os.WriteFile(source, []byte("id,name\n1,a\n"), 0644); err != nil {
+		t.Fatalf("Failed to write source file: %v", err)
+	}
+	mustRun(t, dir, "import", "t", source)
+
+	if _, err := runCommand(t, dir, "delete", "t"); err == nil || !strings.Contains(err.Error(), "-all") {
+		t.Errorf("Expected refusal to delete every record, got %v", err)
+	}
+	if out := mustRun(t, dir, "delete", "t", "-all"); out != "deleted 1 records\n" {
+		t.Errorf("Unexpected delete output: %q", out)
+	}
+	if _, err := runCommand(t, dir, "insert", "t", "name"); err == nil {
+		t.Error("Expected error for an invalid assignment")
+	}
+}
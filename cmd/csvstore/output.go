@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+// writeRecords writes records in a format: "table" for aligned columns, "csv",
+// or "json" for an array of objects
+func writeRecords(w io.Writer, format string, headers []string, records []csvstore.CSVRecord) error {
+	switch format {
+	case "table":
+		return writeTable(w, headers, records)
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(headers); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		row := make([]string, len(headers))
+		for _, record := range records {
+			for i, header := range headers {
+				row[i] = record[header]
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV: %w", err)
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if records == nil {
+			records = []csvstore.CSVRecord{}
+		}
+		return encoder.Encode(records)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// writeTable writes records as columns aligned with spaces, headers first,
+// followed by the number of records
+func writeTable(w io.Writer, headers []string, records []csvstore.CSVRecord) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+	row := make([]string, len(headers))
+	for _, record := range records {
+		for i, header := range headers {
+			row[i] = cellText(record[header])
+		}
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write records: %w", err)
+	}
+
+	noun := "records"
+	if len(records) == 1 {
+		noun = "record"
+	}
+	_, err := fmt.Fprintf(w, "(%d %s)\n", len(records), noun)
+	return err
+}
+
+// cellText returns a cell value printable on a single table line
+func cellText(value string) string {
+	return strings.NewReplacer("\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(value)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jiyeol-lee/csvstore"
+	"github.com/peterh/liner"
+)
+
+// shellPrompt is the prompt of the interactive shell
+const shellPrompt = "csvstore> "
+
+// shellHelp describes the statements and commands of the shell
+const shellHelp = `Statements:
+  SELECT [DISTINCT] * | column, ... FROM table [WHERE condition [AND condition]...]
+      [ORDER BY column [ASC | DESC]] [LIMIT n [OFFSET n]]
+  INSERT INTO table (column, ...) VALUES (value, ...)[, (value, ...)]...
+  UPDATE table SET column = value, ... WHERE condition [AND condition]...
+  DELETE FROM table WHERE condition [AND condition]...
+
+Conditions: column = | != | < | <= | > | >= | CONTAINS | STARTS_WITH | ENDS_WITH |
+  HAS_ANY | HAS_ALL | HAS_NONE value, or column IS [NOT] NULL.
+  Quote values with 'single quotes' and names with "double quotes".
+
+Commands:
+  .tables            list tables
+  .describe <table>  show the columns and row count of a table
+  .format <format>   print records as table, csv, or json
+  .help              show this help
+  .quit              leave the shell
+`
+
+// metaCommands lists the commands of the shell, for completion
+var metaCommands = []string{".tables", ".describe", ".format", ".help", ".quit", ".exit"}
+
+// errQuit ends the shell
+var errQuit = errors.New("quit")
+
+// lineReader reads the lines typed into the shell
+type lineReader interface {
+	Prompt(prompt string) (string, error)
+}
+
+// scannerReader reads lines from a non-interactive input without prompting
+type scannerReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r scannerReader) Prompt(string) (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// shell executes statements typed against a store
+type shell struct {
+	store  *csvstore.CSVStore
+	out    io.Writer
+	format string
+}
+
+func runShell(store *csvstore.CSVStore, args []string, stdout io.Writer) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	sh := &shell{store: store, out: stdout, format: "table"}
+
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return sh.run(scannerReader{scanner: bufio.NewScanner(os.Stdin)}, nil)
+	}
+
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetWordCompleter(sh.complete)
+	fmt.Fprintln(stdout, `Type ".help" for help.`)
+	return sh.run(line, line.AppendHistory)
+}
+
+// run executes the lines read from r until the input ends or .quit is typed.
+// Errors of statements are printed and do not end the shell. addHistory, when
+// not nil, records the lines executed.
+func (sh *shell) run(r lineReader, addHistory func(string)) error {
+	for {
+		input, err := r.Prompt(shellPrompt)
+		if errors.Is(err, io.EOF) || errors.Is(err, liner.ErrPromptAborted) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		if addHistory != nil {
+			addHistory(input)
+		}
+		if err := sh.execute(input); err != nil {
+			if errors.Is(err, errQuit) {
+				return nil
+			}
+			fmt.Fprintf(sh.out, "error: %v\n", err)
+		}
+	}
+}
+
+// execute runs a statement or a command
+func (sh *shell) execute(input string) error {
+	if strings.HasPrefix(input, ".") {
+		return sh.executeCommand(strings.Fields(input))
+	}
+
+	stmt, err := parseStatement(input)
+	if err != nil {
+		return err
+	}
+	if !sh.store.CheckTableExists(stmt.table) {
+		return fmt.Errorf("table %s does not exist", stmt.table)
+	}
+
+	switch stmt.kind {
+	case selectStatement:
+		result, err := sh.store.QueryWithOptions(stmt.table, stmt.opts)
+		if err != nil {
+			return err
+		}
+		headers := stmt.opts.Columns
+		if headers == nil {
+			if headers, err = sh.store.Headers(stmt.table); err != nil {
+				return err
+			}
+		}
+		return writeRecords(sh.out, sh.format, headers, result.Records)
+	case insertStatement:
+		for _, record := range stmt.records {
+			if _, err := sh.store.Insert(stmt.table, record); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(sh.out, "inserted %d records\n", len(stmt.records))
+	case updateStatement:
+		result, err := sh.store.Update(stmt.table, stmt.records[0], stmt.opts.Conditions)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(sh.out, "updated %d records\n", result.Count)
+	case deleteStatement:
+		result, err := sh.store.Delete(stmt.table, stmt.opts.Conditions)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(sh.out, "deleted %d records\n", result.Count)
+	}
+	return nil
+}
+
+// executeCommand runs a command starting with a dot
+func (sh *shell) executeCommand(fields []string) error {
+	switch fields[0] {
+	case ".tables":
+		return runList(sh.store, fields[1:], sh.out)
+	case ".describe":
+		err := runDescribe(sh.store, fields[1:], sh.out)
+		if errors.Is(err, errUsage) {
+			return errors.New("usage: .describe <table>")
+		}
+		return err
+	case ".format":
+		if len(fields) != 2 || !slices.Contains([]string{"table", "csv", "json"}, fields[1]) {
+			return errors.New("usage: .format table|csv|json")
+		}
+		sh.format = fields[1]
+		return nil
+	case ".help":
+		fmt.Fprint(sh.out, shellHelp)
+		return nil
+	case ".quit", ".exit":
+		return errQuit
+	default:
+		return fmt.Errorf("unknown command %s; type .help for help", fields[0])
+	}
+}
+
+// complete completes the word before the cursor with commands, keywords, table
+// names, and the columns of the tables named in the line
+func (sh *shell) complete(line string, pos int) (string, []string, string) {
+	head, tail := line[:pos], line[pos:]
+	start := strings.LastIndexAny(head, " \t,()=<>!") + 1
+	word := head[start:]
+	head = head[:start]
+
+	var candidates []string
+	if strings.TrimSpace(head) == "" && strings.HasPrefix(word, ".") {
+		candidates = metaCommands
+	} else {
+		tables, _ := sh.store.ListTables()
+		candidates = append(candidates, tables...)
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool { return !isWordChar(r) }) {
+			if !slices.Contains(tables, field) {
+				continue
+			}
+			if headers, err := sh.store.Headers(field); err == nil {
+				candidates = append(candidates, headers...)
+			}
+		}
+		for _, keyword := range keywords {
+			// Keywords follow the case of the word being typed
+			if word != "" && strings.ToLower(word) == word {
+				keyword = strings.ToLower(keyword)
+			}
+			candidates = append(candidates, keyword)
+		}
+	}
+
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) && !slices.Contains(completions, candidate) {
+			completions = append(completions, candidate)
+		}
+	}
+	slices.Sort(completions)
+	return head, completions, tail
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+func TestParseStatement(t *testing.T) {
+	stmt, err := parseStatement(`select name, "unit price" from products where price >= 10 and name <> 'it''s' and note is not null order by price desc limit 5 offset 2;`)
+	if err != nil {
+		t.Fatalf("Failed to parse SELECT: %v", err)
+	}
+	expected := csvstore.QueryOptions{
+		Columns: []string{"name", "unit price"},
+		Conditions: []csvstore.QueryCondition{
+			{Column: "price", Operator: ">=", Value: "10"},
+			{Column: "name", Operator: "!=", Value: "it's"},
+			{Column: "note", Operator: "is_not_null"},
+		},
+		SortField: "price",
+		SortBy:    "desc",
+		Limit:     5,
+		Offset:    2,
+	}
+	if stmt.kind != selectStatement || stmt.table != "products" || !reflect.DeepEqual(stmt.opts, expected) {
+		t.Errorf("Unexpected SELECT statement: %+v", stmt)
+	}
+
+	stmt, err = parseStatement(`SELECT DISTINCT category FROM products`)
+	if err != nil {
+		t.Fatalf("Failed to parse SELECT DISTINCT: %v", err)
+	}
+	if !stmt.opts.Distinct || !reflect.DeepEqual(stmt.opts.Columns, []string{"category"}) {
+		t.Errorf("Unexpected SELECT DISTINCT statement: %+v", stmt)
+	}
+
+	stmt, err = parseStatement(`INSERT INTO products (name, price) VALUES ('pen', 1.5), (desk, 200)`)
+	if err != nil {
+		t.Fatalf("Failed to parse INSERT: %v", err)
+	}
+	records := []csvstore.CSVRecord{{"name": "pen", "price": "1.5"}, {"name": "desk", "price": "200"}}
+	if stmt.kind != insertStatement || !reflect.DeepEqual(stmt.records, records) {
+		t.Errorf("Unexpected INSERT statement: %+v", stmt)
+	}
+
+	stmt, err = parseStatement(`UPDATE products SET price = 3 WHERE name CONTAINS 'pe'`)
+	if err != nil {
+		t.Fatalf("Failed to parse UPDATE: %v", err)
+	}
+	if stmt.kind != updateStatement || stmt.records[0]["price"] != "3" || stmt.opts.Conditions[0].Operator != "contains" {
+		t.Errorf("Unexpected UPDATE statement: %+v", stmt)
+	}
+
+	for _, input := range []string{
+		"SELECT FROM products",
+		"SELECT * FROM products WHERE a = 1 OR b = 2",
+		"SELECT * FROM products LIMIT -1",
+		"SELECT * FROM products extra",
+		"SELECT * FROM 'products'",
+		"INSERT INTO products (a, b) VALUES (1)",
+		"DELETE FROM products",
+		"UPDATE products SET a = 1",
+		"SELECT * FROM products WHERE a = 'open",
+		"DROP TABLE products",
+	} {
+		if _, err := parseStatement(input); err == nil {
+			t.Errorf("Expected error parsing %q", input)
+		}
+	}
+}
+
+func newShellStore(t *testing.T) *csvstore.CSVStore {
+	t.Helper()
+
+	store := csvstore.NewMemoryStore()
+	t.Cleanup(func() { store.Close() })
+	if err := store.CreateTable("products", []string{"id", "name", "price"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	return store
+}
+
+func TestShell(t *testing.T) {
+	store := newShellStore(t)
+
+	var out bytes.Buffer
+	sh := &shell{store: store, out: &out, format: "table"}
+	input := strings.Join([]string{
+		"INSERT INTO products (id, name, price) VALUES (1, pen, 10), (2, desk, 200)",
+		"UPDATE products SET price = 12 WHERE name = pen",
+		"SELECT name, price FROM products ORDER BY price DESC",
+		"SELECT * FROM missing",
+		".format csv",
+		"SELECT name FROM products WHERE price < 100",
+		"DELETE FROM products WHERE id = 2",
+		".quit",
+		"SELECT * FROM products",
+	}, "\n")
+	if err := sh.run(scannerReader{scanner: bufio.NewScanner(strings.NewReader(input))}, nil); err != nil {
+		t.Fatalf("Failed to run shell: %v", err)
+	}
+
+	expected := "inserted 2 records\n" +
+		"updated 1 records\n" +
+		"name  price\ndesk  200\npen   12\n(2 records)\n" +
+		"error: table missing does not exist\n" +
+		"name\npen\n" +
+		"deleted 1 records\n"
+	if out.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, out.String())
+	}
+}
+
+func TestShellComplete(t *testing.T) {
+	store := newShellStore(t)
+	sh := &shell{store: store, out: &bytes.Buffer{}, format: "table"}
+
+	tests := []struct {
+		line     string
+		head     string
+		expected []string
+	}{
+		{"sel", "", []string{"select"}},
+		{"SELECT * FROM pro", "SELECT * FROM ", []string{"products"}},
+		{"SELECT na", "SELECT ", nil},
+		{"SELECT * FROM products WHERE pr", "SELECT * FROM products WHERE ", []string{"price", "products"}},
+		{".d", "", []string{".describe"}},
+	}
+	for _, test := range tests {
+		head, completions, tail := sh.complete(test.line, len(test.line))
+		if head != test.head || tail != "" || !reflect.DeepEqual(completions, test.expected) {
+			t.Errorf("complete(%q) = %q, %v, %q; expected %q, %v", test.line, head, completions, tail, test.head, test.expected)
+		}
+	}
+}
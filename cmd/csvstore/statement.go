@@ -0,0 +1,473 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+// The shell accepts a subset of SQL:
+//
+//	SELECT [DISTINCT] * | column, ... FROM table [WHERE condition [AND condition]...]
+//	    [ORDER BY column [ASC | DESC]] [LIMIT n [OFFSET n]]
+//	INSERT INTO table (column, ...) VALUES (value, ...)[, (value, ...)]...
+//	UPDATE table SET column = value, ... WHERE condition [AND condition]...
+//	DELETE FROM table WHERE condition [AND condition]...
+//
+// A condition is column operator value, with the operators =, ==, !=, <>, <,
+// <=, >, >=, CONTAINS, STARTS_WITH, ENDS_WITH, HAS_ANY, HAS_ALL, and HAS_NONE,
+// or column IS [NOT] NULL. Values are numbers, bare words, or 'quoted strings'
+// with '' escaping a quote; "double quotes" delimit column and table names.
+
+// statementKind is the kind of a parsed statement
+type statementKind int
+
+const (
+	selectStatement statementKind = iota
+	insertStatement
+	updateStatement
+	deleteStatement
+)
+
+// statement is a parsed shell statement
+type statement struct {
+	kind  statementKind
+	table string
+	// opts holds the columns, conditions, sorting, and paging of SELECT, and
+	// the conditions of UPDATE and DELETE
+	opts csvstore.QueryOptions
+	// records holds the records of INSERT, or the single update of UPDATE
+	records []csvstore.CSVRecord
+}
+
+// tokenKind is the kind of a lexical token
+type tokenKind int
+
+const (
+	wordToken   tokenKind = iota // Keyword, name, or bare value
+	quotedName                   // "name"
+	stringToken                  // 'value'
+	symbolToken                  // Punctuation and comparison operators
+)
+
+// token is a lexical token of a statement
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // Index of the first rune of the token in the statement
+}
+
+// is reports whether a token is the keyword or symbol s, ignoring case
+func (t token) is(s string) bool {
+	return (t.kind == wordToken || t.kind == symbolToken) && strings.EqualFold(t.text, s)
+}
+
+// keywords lists the reserved words of the shell, for completion
+var keywords = []string{
+	"SELECT", "DISTINCT", "FROM", "WHERE", "AND", "ORDER", "BY", "ASC", "DESC", "LIMIT", "OFFSET",
+	"INSERT", "INTO", "VALUES", "UPDATE", "SET", "DELETE", "IS", "NOT", "NULL",
+	"CONTAINS", "STARTS_WITH", "ENDS_WITH", "HAS_ANY", "HAS_ALL", "HAS_NONE",
+}
+
+// isWordChar reports whether r can appear in a bare word
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:+", r)
+}
+
+// tokenize splits a statement into tokens
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			var text strings.Builder
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == r {
+					if j+1 < len(runes) && runes[j+1] == r {
+						text.WriteRune(r)
+						j++
+						continue
+					}
+					break
+				}
+				text.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("unterminated quote starting at %q", string(runes[i:]))
+			}
+			kind := stringToken
+			if r == '"' {
+				kind = quotedName
+			}
+			tokens = append(tokens, token{kind: kind, text: text.String(), pos: i})
+			i = j + 1
+		case isWordChar(r):
+			j := i
+			for j < len(runes) && isWordChar(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: wordToken, text: string(runes[i:j]), pos: i})
+			i = j
+		case strings.ContainsRune("<>!=", r):
+			j := i + 1
+			if j < len(runes) && strings.ContainsRune("<>=", runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: symbolToken, text: string(runes[i:j]), pos: i})
+			i = j
+		case strings.ContainsRune(",()*;", r):
+			tokens = append(tokens, token{kind: symbolToken, text: string(r), pos: i})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+// parser parses the tokens of a statement
+type parser struct {
+	input  []rune
+	tokens []token
+	pos    int
+}
+
+// errIncomplete reports a statement ending early
+var errIncomplete = errors.New("unexpected end of statement")
+
+// peek returns the next token without consuming it
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// next consumes the next token
+func (p *parser) next() (token, error) {
+	t, ok := p.peek()
+	if !ok {
+		return token{}, errIncomplete
+	}
+	p.pos++
+	return t, nil
+}
+
+// accept consumes the next token if it is the keyword or symbol s
+func (p *parser) accept(s string) bool {
+	if t, ok := p.peek(); ok && t.is(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the keywords or symbols of s, which must come next
+func (p *parser) expect(s ...string) error {
+	for _, want := range s {
+		t, err := p.next()
+		if err != nil {
+			return fmt.Errorf("expected %s: %w", want, err)
+		}
+		if !t.is(want) {
+			return fmt.Errorf("expected %s, got %q", want, t.text)
+		}
+	}
+	return nil
+}
+
+// name consumes a table or column name
+func (p *parser) name() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", fmt.Errorf("expected a name: %w", err)
+	}
+	if t.kind != wordToken && t.kind != quotedName {
+		return "", fmt.Errorf("expected a name, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+// value consumes a value
+func (p *parser) value() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", fmt.Errorf("expected a value: %w", err)
+	}
+	if t.kind != wordToken && t.kind != stringToken {
+		return "", fmt.Errorf("expected a value, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+// names consumes a comma-separated list of names
+func (p *parser) names() ([]string, error) {
+	var names []string
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.accept(",") {
+			return names, nil
+		}
+	}
+}
+
+// values consumes a parenthesized, comma-separated list of values
+func (p *parser) values() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var values []string
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return values, p.expect(")")
+}
+
+// conditions consumes conditions joined by AND, parsed by the store
+func (p *parser) conditions() ([]csvstore.QueryCondition, error) {
+	start := len(p.input)
+	if t, ok := p.peek(); ok {
+		start = t.pos
+	}
+	conditions, rest, err := csvstore.ParseConditions(string(p.input[start:]))
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tokenize(rest)
+	if err != nil {
+		return nil, err
+	}
+	p.input, p.tokens, p.pos = []rune(rest), tokens, 0
+	return conditions, nil
+}
+
+// end checks that the whole statement was consumed, allowing a final semicolon
+func (p *parser) end() error {
+	p.accept(";")
+	if t, ok := p.peek(); ok {
+		return fmt.Errorf("unexpected %q", t.text)
+	}
+	return nil
+}
+
+// parseStatement parses a shell statement
+func parseStatement(input string) (*statement, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{input: []rune(input), tokens: tokens}
+
+	first, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var stmt *statement
+	switch {
+	case first.is("SELECT"):
+		stmt, err = p.parseSelect()
+	case first.is("INSERT"):
+		stmt, err = p.parseInsert()
+	case first.is("UPDATE"):
+		stmt, err = p.parseUpdate()
+	case first.is("DELETE"):
+		stmt, err = p.parseDelete()
+	default:
+		return nil, fmt.Errorf("unknown statement %q", first.text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := p.end(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseConditions parses conditions joined by AND, as written after WHERE
+func parseConditions(input string) ([]csvstore.QueryCondition, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{input: []rune(input), tokens: tokens}
+	conditions, err := p.conditions()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.end(); err != nil {
+		return nil, err
+	}
+	return conditions, nil
+}
+
+func (p *parser) parseSelect() (*statement, error) {
+	stmt := &statement{kind: selectStatement}
+	stmt.opts.Distinct = p.accept("DISTINCT")
+	if !p.accept("*") {
+		columns, err := p.names()
+		if err != nil {
+			return nil, err
+		}
+		stmt.opts.Columns = columns
+	}
+
+	var err error
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	if stmt.table, err = p.name(); err != nil {
+		return nil, err
+	}
+	if p.accept("WHERE") {
+		if stmt.opts.Conditions, err = p.conditions(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		if stmt.opts.SortField, err = p.name(); err != nil {
+			return nil, err
+		}
+		if p.accept("DESC") {
+			stmt.opts.SortBy = "desc"
+		} else {
+			p.accept("ASC")
+		}
+	}
+	if p.accept("LIMIT") {
+		if stmt.opts.Limit, err = p.count("LIMIT"); err != nil {
+			return nil, err
+		}
+		if p.accept("OFFSET") {
+			if stmt.opts.Offset, err = p.count("OFFSET"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stmt, nil
+}
+
+// count consumes the non-negative number following a keyword
+func (p *parser) count(keyword string) (int, error) {
+	value, err := p.value()
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", keyword, value)
+	}
+	return n, nil
+}
+
+func (p *parser) parseInsert() (*statement, error) {
+	stmt := &statement{kind: insertStatement}
+	var err error
+	if err := p.expect("INTO"); err != nil {
+		return nil, err
+	}
+	if stmt.table, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	columns, err := p.names()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")", "VALUES"); err != nil {
+		return nil, err
+	}
+	for {
+		values, err := p.values()
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(columns) {
+			return nil, fmt.Errorf("expected %d values, got %d", len(columns), len(values))
+		}
+		record := make(csvstore.CSVRecord, len(columns))
+		for i, column := range columns {
+			record[column] = values[i]
+		}
+		stmt.records = append(stmt.records, record)
+		if !p.accept(",") {
+			return stmt, nil
+		}
+	}
+}
+
+func (p *parser) parseUpdate() (*statement, error) {
+	stmt := &statement{kind: updateStatement}
+	var err error
+	if stmt.table, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("SET"); err != nil {
+		return nil, err
+	}
+	updates := make(csvstore.CSVRecord)
+	for {
+		column, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		if updates[column], err = p.value(); err != nil {
+			return nil, err
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	stmt.records = []csvstore.CSVRecord{updates}
+	return stmt, p.where(stmt)
+}
+
+func (p *parser) parseDelete() (*statement, error) {
+	stmt := &statement{kind: deleteStatement}
+	var err error
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	if stmt.table, err = p.name(); err != nil {
+		return nil, err
+	}
+	return stmt, p.where(stmt)
+}
+
+// where consumes the mandatory WHERE clause of UPDATE and DELETE
+func (p *parser) where(stmt *statement) error {
+	if !p.accept("WHERE") {
+		return errors.New("refusing to change every record without WHERE; use the update or delete command with -all")
+	}
+	var err error
+	stmt.opts.Conditions, err = p.conditions()
+	return err
+}
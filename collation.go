@@ -0,0 +1,37 @@
+package csvstore
+
+import (
+	"strconv"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// WithCollation compares and sorts the non-numeric values of a table by the
+// rules of a locale, e.g. language.German, so "Ärger" sorts between "Apfel" and
+// "Birne" instead of after "Zucker". It applies to the >, <, >=, and <=
+// operators and to QuerySortedRange. Values that are both numbers still compare
+// numerically.
+func WithCollation(tag language.Tag) TableOption {
+	return func(c *tableConfig) {
+		c.collation = &tag
+	}
+}
+
+// valueComparer returns the function comparing two values of the table, which
+// returns -1, 0, or 1. The function must not be used concurrently.
+func (c *tableConfig) valueComparer() func(a, b string) int {
+	if c.collation == nil {
+		return compareNumeric
+	}
+
+	collator := collate.New(*c.collation)
+	return func(a, b string) int {
+		numA, errA := strconv.ParseFloat(a, 64)
+		numB, errB := strconv.ParseFloat(b, 64)
+		if errA != nil || errB != nil {
+			return collator.CompareString(a, b)
+		}
+		return compareFloats(numA, numB)
+	}
+}
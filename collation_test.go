@@ -0,0 +1,59 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestCollation(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	for _, table := range []string{"bytes", "german"} {
+		if err := store.CreateTable(table, []string{"word"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		for _, word := range []string{"Zucker", "Ärger", "Apfel", "Birne"} {
+			if _, err := store.Insert(table, CSVRecord{"word": word}); err != nil {
+				t.Fatalf("Failed to insert record: %v", err)
+			}
+		}
+	}
+	store.ConfigureTable("german", WithCollation(language.German))
+
+	tests := []struct {
+		table    string
+		expected []string
+	}{
+		{"bytes", []string{"Apfel", "Birne", "Zucker", "Ärger"}},
+		{"german", []string{"Apfel", "Ärger", "Birne", "Zucker"}},
+	}
+	for _, tt := range tests {
+		sorted, err := store.QuerySortedRange(tt.table, "word", "asc", 4)
+		if err != nil {
+			t.Fatalf("Failed to query sorted records: %v", err)
+		}
+		for i, record := range sorted.Records {
+			if record["word"] != tt.expected[i] {
+				t.Errorf("Expected %v for %s, got %v", tt.expected, tt.table, sorted.Records)
+				break
+			}
+		}
+	}
+
+	result, err := store.Query("german", []QueryCondition{{Column: "word", Operator: "<", Value: "B"}})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected Apfel and Ärger before B, got %v", result.Records)
+	}
+}
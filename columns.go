@@ -0,0 +1,62 @@
+package csvstore
+
+import (
+	"slices"
+	"strings"
+)
+
+// WithCaseInsensitiveColumns makes the column names of conditions, Select
+// projections, sort fields, and Insert and Update records match the headers of
+// the table regardless of case, so "email" and "EMAIL" both refer to an "Email"
+// column. Records returned by the store keep the header names.
+func WithCaseInsensitiveColumns() TableOption {
+	return func(c *tableConfig) {
+		c.caseInsensitiveColumns = true
+	}
+}
+
+// columnResolver returns a function mapping the column names given by callers to
+// the headers of a table. Names without a matching header are returned as is.
+// The caller must hold cs.mu.
+func (cs *CSVStore) columnResolver(tableName string) func(string) string {
+	if !cs.tableConfig(tableName).caseInsensitiveColumns {
+		return func(column string) string { return column }
+	}
+	// A missing table is reported by the operation itself
+	headers, _ := cs.getHeaders(tableName)
+	return headerResolver(headers)
+}
+
+// headerResolver returns a function mapping column names to the headers they
+// match regardless of case
+func headerResolver(headers []string) func(string) string {
+	return func(column string) string {
+		if slices.Contains(headers, column) {
+			return column
+		}
+		for _, header := range headers {
+			if strings.EqualFold(header, column) {
+				return header
+			}
+		}
+		return column
+	}
+}
+
+// resolveConditions returns conditions with their columns mapped by resolve
+func resolveConditions(conditions []QueryCondition, resolve func(string) string) []QueryCondition {
+	resolved := slices.Clone(conditions)
+	for i := range resolved {
+		resolved[i].Column = resolve(resolved[i].Column)
+	}
+	return resolved
+}
+
+// resolveRecord returns record with its columns mapped by resolve
+func resolveRecord(record CSVRecord, resolve func(string) string) CSVRecord {
+	resolved := make(CSVRecord, len(record))
+	for column, value := range record {
+		resolved[resolve(column)] = value
+	}
+	return resolved
+}
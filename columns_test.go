@@ -0,0 +1,74 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCaseInsensitiveColumns(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir, WithTableDefaults(WithCaseInsensitiveColumns()))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "Email", "Age"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, record := range []CSVRecord{
+		{"email": "a@example.com", "AGE": "30"},
+		{"EMAIL": "b@example.com", "age": "40"},
+	} {
+		if _, err := store.Insert("users", record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	result, err := store.Query("users", []QueryCondition{{Column: "eMaIl", Operator: "=", Value: "b@example.com"}})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["Age"] != "40" {
+		t.Errorf("Unexpected records: %v", result.Records)
+	}
+
+	selected, err := store.Select("users", []string{"email"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to select records: %v", err)
+	}
+	if selected.Count != 2 || selected.Records[0]["Email"] != "a@example.com" || len(selected.Records[0]) != 1 {
+		t.Errorf("Unexpected selected records: %v", selected.Records)
+	}
+
+	sorted, err := store.QuerySortedRange("users", "age", "desc", 1)
+	if err != nil {
+		t.Fatalf("Failed to query sorted records: %v", err)
+	}
+	if sorted.Records[0]["Email"] != "b@example.com" {
+		t.Errorf("Unexpected sorted records: %v", sorted.Records)
+	}
+
+	updated, err := store.Update("users", CSVRecord{"age": "31"}, []QueryCondition{
+		{Column: "EMAIL", Operator: "=", Value: "a@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update records: %v", err)
+	}
+	if updated.Count != 1 || updated.Records[0]["Age"] != "31" {
+		t.Errorf("Unexpected updated records: %v", updated.Records)
+	}
+	if _, exists := updated.Records[0]["age"]; exists {
+		t.Errorf("Expected no lowercase column in %v", updated.Records[0])
+	}
+
+	deleted, err := store.Delete("users", []QueryCondition{{Column: "age", Operator: ">", Value: "35"}})
+	if err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if deleted.Count != 1 {
+		t.Errorf("Expected 1 deleted record, got %d", deleted.Count)
+	}
+}
@@ -0,0 +1,97 @@
+package csvstore
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// ColumnStats profiles the values of a column
+type ColumnStats struct {
+	Column   string
+	Count    int    // Number of records
+	Empty    int    // Number of null cells
+	Distinct int    // Number of distinct values that are not null
+	Min      string // Least value that is not null, as ordered by queries
+	Max      string // Greatest value that is not null, as ordered by queries
+	// Numeric is set when every value that is not null is a number, and at least
+	// one is; Mean and StdDev (the population standard deviation) are set then
+	Numeric bool
+	Mean    float64
+	StdDev  float64
+}
+
+// ColumnStats computes statistics of a column over all records of a table, for
+// profiling unfamiliar data. Min and Max follow the ordering of queries, so
+// numbers compare numerically and the collation of the table applies. Values
+// of masked columns are profiled masked.
+func (cs *CSVStore) ColumnStats(tableName, column string) (*ColumnStats, error) {
+	op := Operation{Name: OpColumnStats, Table: tableName, Payload: column}
+	return runOperation(cs, op, func() (*ColumnStats, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		return cs.columnStats(tableName, column)
+	})
+}
+
+// columnStats computes statistics of a column over all records of a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) columnStats(tableName, column string) (*ColumnStats, error) {
+	column = cs.columnResolver(tableName)(column)
+	matcher := cs.newConditionMatcher(tableName)
+	compare := cs.tableConfig(tableName).valueComparer()
+	mask := cs.recordMasker(tableName)
+
+	stats := &ColumnStats{Column: column, Numeric: true}
+	distinct := make(map[string]struct{})
+	var mean, sumSquares float64 // Running mean and sum of squared deviations
+	onHeaders := func(headers []string) error {
+		if !slices.Contains(headers, column) {
+			return fmt.Errorf("column %s does not exist in table %s", column, tableName)
+		}
+		return nil
+	}
+	err := cs.scanTable(tableName, nil, onHeaders, func(record CSVRecord) error {
+		stats.Count++
+		value := mask(record)[column]
+		if matcher.isNull(value) {
+			stats.Empty++
+			return nil
+		}
+
+		if len(distinct) == 0 || compare(value, stats.Min) < 0 {
+			stats.Min = value
+		}
+		if len(distinct) == 0 || compare(value, stats.Max) > 0 {
+			stats.Max = value
+		}
+		distinct[value] = struct{}{}
+
+		if !stats.Numeric {
+			return nil
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			stats.Numeric = false
+			return nil
+		}
+		n := float64(stats.Count - stats.Empty)
+		delta := number - mean
+		mean += delta / n
+		sumSquares += delta * (number - mean)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Distinct = len(distinct)
+	stats.Numeric = stats.Numeric && stats.Distinct > 0
+	if stats.Numeric {
+		stats.Mean = mean
+		stats.StdDev = math.Sqrt(sumSquares / float64(stats.Count-stats.Empty))
+	}
+	return stats, nil
+}
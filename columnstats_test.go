@@ -0,0 +1,60 @@
+package csvstore
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestColumnStats(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("orders", []string{"id", "item", "amount"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	orders := []CSVRecord{
+		{"item": "pen", "amount": "2"},
+		{"item": "desk", "amount": "10"},
+		{"item": "pen", "amount": "4"},
+		{"item": "", "amount": "4"},
+		{"item": "lamp", "amount": ""},
+	}
+	for _, order := range orders {
+		if _, err := store.Insert("orders", order); err != nil {
+			t.Fatalf("Failed to insert order: %v", err)
+		}
+	}
+
+	stats, err := store.ColumnStats("orders", "amount")
+	if err != nil {
+		t.Fatalf("Failed to compute column stats: %v", err)
+	}
+	if stats.Count != 5 || stats.Empty != 1 || stats.Distinct != 3 {
+		t.Errorf("Expected 5 records, 1 empty, and 3 distinct, got %+v", stats)
+	}
+	if stats.Min != "2" || stats.Max != "10" {
+		t.Errorf("Expected numeric min 2 and max 10, got %q and %q", stats.Min, stats.Max)
+	}
+	if !stats.Numeric || stats.Mean != 5 || math.Abs(stats.StdDev-math.Sqrt(9)) > 1e-9 {
+		t.Errorf("Expected mean 5 and standard deviation 3, got %+v", stats)
+	}
+
+	stats, err = store.ColumnStats("orders", "item")
+	if err != nil {
+		t.Fatalf("Failed to compute column stats: %v", err)
+	}
+	if stats.Numeric || stats.Empty != 1 || stats.Distinct != 3 || stats.Min != "desk" || stats.Max != "pen" {
+		t.Errorf("Expected non-numeric stats from desk to pen, got %+v", stats)
+	}
+
+	if _, err := store.ColumnStats("orders", "missing"); err == nil {
+		t.Errorf("Expected an error for a missing column")
+	}
+}
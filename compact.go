@@ -0,0 +1,197 @@
+package csvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// Compact rewrites the tombstone table of a table (see WithTombstones), keeping
+// only the latest tombstone of every id and dropping tombstones recorded before
+// horizon. Tombstones are only needed until every store synced with this one has
+// seen them, so pass the time of the oldest such Sync; a zero horizon keeps the
+// latest tombstone of every id. It returns the number of tombstones removed.
+func (cs *CSVStore) Compact(tableName string, horizon time.Time) (int, error) {
+	return runOperation(cs, Operation{Name: OpCompact, Table: tableName, Payload: horizon},
+		func() (int, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return cs.compact(tableName, horizon)
+		})
+}
+
+// compact rewrites the tombstone table of a table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) compact(tableName string, horizon time.Time) (int, error) {
+	headers, kept, removed, err := cs.splitTombstones(tableName, horizon)
+	if err != nil || headers == nil {
+		return 0, err
+	}
+	if len(removed) > 0 {
+		tombstoneTable := TombstoneTableName(tableName)
+		if err := cs.saveTable(tombstoneTable, headers, kept); err != nil {
+			return 0, fmt.Errorf("failed to compact tombstone table %s: %w", tombstoneTable, err)
+		}
+	}
+	if err := cs.recordCompaction(tableName, time.Now()); err != nil {
+		return 0, err
+	}
+	return len(removed), nil
+}
+
+// splitTombstones splits the tombstone table of a table into the tombstones
+// compacting with horizon keeps and removes. The headers are nil when there
+// is no tombstone table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) splitTombstones(
+	tableName string,
+	horizon time.Time,
+) (headers []string, kept, removed []CSVRecord, err error) {
+	tombstoneTable := TombstoneTableName(tableName)
+	headers, err = cs.getHeaders(tombstoneTable)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	records, err := cs.loadTable(tombstoneTable)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tombstones, err := cs.tombstones(tableName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	idColumn := cs.reservedColumns(tableName).ID
+	kept = make([]CSVRecord, 0, len(tombstones))
+	for _, record := range records {
+		id := record[idColumn]
+		deletedAt, ok := tombstones[id]
+		if !ok {
+			// Already kept
+			removed = append(removed, record)
+			continue
+		}
+		if deletedAt.Before(horizon) {
+			delete(tombstones, id)
+			removed = append(removed, record)
+			continue
+		}
+		if recorded, _ := time.Parse(time.RFC3339Nano, record[TombstoneDeletedAtColumn]); recorded.Equal(deletedAt) {
+			kept = append(kept, record)
+			delete(tombstones, id)
+			continue
+		}
+		removed = append(removed, record)
+	}
+	return headers, kept, removed, nil
+}
+
+// CompactionsFileName is the name of the file of the store directory holding
+// the time of the last compaction of every table
+const CompactionsFileName = "compactions.json"
+
+// CompactionStats describes the tombstones of a table (see WithTombstones), to
+// tell when Compact is worth running
+type CompactionStats struct {
+	// LiveRows is the number of rows of the table
+	LiveRows int
+	// Tombstones is the number of tombstones Compact keeps with a zero horizon:
+	// the latest tombstone of every deleted id
+	Tombstones int
+	// DeadRows is the number of superseded tombstones Compact removes with a
+	// zero horizon
+	DeadRows int
+	// ReclaimableBytes is the size of the dead rows, before compression
+	ReclaimableBytes int64
+	// LastCompaction is the time Compact last ran on the table, or zero
+	LastCompaction time.Time
+}
+
+// CompactionStats reports the live rows of a table and the tombstones that
+// Compact would keep and remove, without changing anything
+func (cs *CSVStore) CompactionStats(tableName string) (*CompactionStats, error) {
+	return runOperation(cs, Operation{Name: OpCompactionStats, Table: tableName},
+		func() (*CompactionStats, error) {
+			cs.mu.RLock()
+			defer cs.mu.RUnlock()
+
+			return cs.compactionStats(tableName)
+		})
+}
+
+// compactionStats reports the live rows and tombstones of a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) compactionStats(tableName string) (*CompactionStats, error) {
+	var stats CompactionStats
+	err := cs.scanTable(tableName, nil, nil, func(CSVRecord) error {
+		stats.LiveRows++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	headers, kept, removed, err := cs.splitTombstones(tableName, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	stats.Tombstones, stats.DeadRows = len(kept), len(removed)
+	if len(removed) > 0 {
+		rows := make([][]string, len(removed))
+		for i, record := range removed {
+			rows[i] = recordRow(headers, record)
+		}
+		if stats.ReclaimableBytes, err = cs.encodedSize(TombstoneTableName(tableName), rows); err != nil {
+			return nil, err
+		}
+	}
+
+	compactions, err := cs.compactions()
+	if err != nil {
+		return nil, err
+	}
+	stats.LastCompaction = compactions[tableName]
+	return &stats, nil
+}
+
+// compactions reads the time of the last compaction of every table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) compactions() (map[string]time.Time, error) {
+	compactions := make(map[string]time.Time)
+	data, err := cs.readFile(CompactionsFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return compactions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compactions: %w", err)
+	}
+	if err := json.Unmarshal(data, &compactions); err != nil {
+		return nil, fmt.Errorf("failed to parse compactions: %w", err)
+	}
+	return compactions, nil
+}
+
+// recordCompaction records the time of the last compaction of a table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) recordCompaction(tableName string, at time.Time) error {
+	compactions, err := cs.compactions()
+	if err != nil {
+		return err
+	}
+	compactions[tableName] = at
+	data, err := json.MarshalIndent(compactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode compactions: %w", err)
+	}
+	if err := cs.writeFile(CompactionsFileName, data); err != nil {
+		return fmt.Errorf("failed to write compactions: %w", err)
+	}
+	return nil
+}
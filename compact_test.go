@@ -0,0 +1,106 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCompact(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	store.ConfigureTable("users", WithTombstones())
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	// id 1 is deleted twice, id 2 once
+	for _, id := range []string{"1", "1", "2"} {
+		if _, err := store.Insert("users", CSVRecord{"id": id, "name": "n" + id}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+		if _, err := store.Delete("users", []QueryCondition{{Column: "id", Operator: "=", Value: id}}); err != nil {
+			t.Fatalf("Failed to delete record: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	removed, err := store.Compact("users", time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to compact: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 superseded tombstone removed, got %d", removed)
+	}
+	tombstones, err := store.Query(TombstoneTableName("users"), nil)
+	if err != nil {
+		t.Fatalf("Failed to query tombstones: %v", err)
+	}
+	if tombstones.Count != 2 {
+		t.Fatalf("Expected 2 tombstones, got %v", tombstones.Records)
+	}
+
+	removed, err = store.Compact("users", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to compact: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 tombstones before the horizon removed, got %d", removed)
+	}
+
+	// Tables without tombstones have nothing to compact
+	if removed, err := store.Compact("missing", time.Time{}); err != nil || removed != 0 {
+		t.Errorf("Expected nothing to compact, got %d, %v", removed, err)
+	}
+}
+
+func TestCompactionStats(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+	store.ConfigureTable("users", WithTombstones())
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, id := range []string{"1", "1", "2", "3"} {
+		if _, err := store.Insert("users", CSVRecord{"id": id, "name": "n" + id}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+		if id == "3" {
+			break
+		}
+		if _, err := store.Delete("users", []QueryCondition{{Column: "id", Operator: "=", Value: id}}); err != nil {
+			t.Fatalf("Failed to delete record: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	stats, err := store.CompactionStats("users")
+	if err != nil {
+		t.Fatalf("Failed to get compaction stats: %v", err)
+	}
+	if stats.LiveRows != 1 || stats.Tombstones != 2 || stats.DeadRows != 1 {
+		t.Errorf("Expected 1 live row, 2 tombstones and 1 dead row, got %+v", stats)
+	}
+	if stats.ReclaimableBytes <= 0 || !stats.LastCompaction.IsZero() {
+		t.Errorf("Expected reclaimable bytes and no compaction yet, got %+v", stats)
+	}
+
+	before := time.Now()
+	if _, err := store.Compact("users", time.Time{}); err != nil {
+		t.Fatalf("Failed to compact: %v", err)
+	}
+	stats, err = store.CompactionStats("users")
+	if err != nil {
+		t.Fatalf("Failed to get compaction stats: %v", err)
+	}
+	if stats.DeadRows != 0 || stats.ReclaimableBytes != 0 || stats.LastCompaction.Before(before) {
+		t.Errorf("Expected nothing to reclaim after compacting, got %+v", stats)
+	}
+}
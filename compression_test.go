@@ -0,0 +1,125 @@
+package csvstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGzipCompression(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+	store.ConfigureTable("archive", WithCompression(GzipCompression))
+
+	if err := store.CreateTable("archive", []string{"id", "event"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, event := range []string{"login", "logout", "login"} {
+		if _, err := store.Insert("archive", CSVRecord{"event": event}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	path := store.GetTablePath("archive")
+	if !strings.HasSuffix(path, ".csv.gz") {
+		t.Fatalf("Expected a .csv.gz table file, got %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		t.Errorf("Expected a gzip table file")
+	}
+
+	deleted, err := store.Delete("archive", []QueryCondition{{Column: "event", Operator: "=", Value: "logout"}})
+	if err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if deleted.Count != 1 {
+		t.Errorf("Expected 1 deleted record, got %d", deleted.Count)
+	}
+	result, err := store.Query("archive", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 2 || result.Records[0]["event"] != "login" {
+		t.Errorf("Unexpected records: %v", result.Records)
+	}
+
+	// A gzip file dropped into the store is readable without configuration
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write([]byte("name\nAlice\n"))
+	writer.Close()
+	if err := os.WriteFile(filepath.Join(testDir, "imported.csv.gz"), buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	tables, err := store.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Errorf("Expected 2 tables, got %v", tables)
+	}
+	imported, err := store.Query("imported", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if imported.Count != 1 || imported.Records[0]["name"] != "Alice" {
+		t.Errorf("Unexpected records: %v", imported.Records)
+	}
+}
+
+func TestZstdCompression(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir, WithTableDefaults(WithCompression(ZstdCompression)))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	if err := store.CreateTable("metrics", []string{"name", "value"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, name := range []string{"cpu", "memory", "disk"} {
+		if _, err := store.Insert("metrics", CSVRecord{"name": name, "value": "1"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := store.Update("metrics", CSVRecord{"value": "2"}, []QueryCondition{
+		{Column: "name", Operator: "=", Value: "memory"},
+	}); err != nil {
+		t.Fatalf("Failed to update records: %v", err)
+	}
+	if _, err := store.Insert("metrics", CSVRecord{"name": "network", "value": "3"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	path := store.GetTablePath("metrics")
+	if !strings.HasSuffix(path, ".csv.zst") {
+		t.Fatalf("Expected a .csv.zst table file, got %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte{0x28, 0xb5, 0x2f, 0xfd}) {
+		t.Errorf("Expected a zstd table file")
+	}
+
+	result, err := store.Query("metrics", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 4 || result.Records[1]["value"] != "2" || result.Records[3]["name"] != "network" {
+		t.Errorf("Unexpected records: %v", result.Records)
+	}
+}
@@ -1,7 +1,6 @@
 package csvstore
 
 import (
-	"encoding/csv"
 	"fmt"
 	"maps"
 	"os"
@@ -9,14 +8,55 @@ import (
 	"slices"
 	"strconv"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // CSVStore represents a CSV-based storage system
 type CSVStore struct {
-	basePath string
-	mu       sync.RWMutex
+	basePath   string
+	fs         FS
+	readOnly   bool
+	writeGuard bool
+	mu         storeMutex
+
+	tableDefaults []TableOption
+	tableOptions  map[string][]TableOption
+
+	changeLog   bool
+	changeSeq   uint64
+	subscribers []*subscription
+	// stagedEvents collects the change events of a running migration
+	stagedEvents *[]ChangeEvent
+	middleware   []Middleware
+
+	triggerDepth int
+
+	watchers   int
+	fileStates map[string]fileState
+
+	writeBack *writeBackConfig
+
+	migrations []Migration
+	checksums  map[string]string // Checksums of table files by name, nil when disabled
+	views      map[string]MaterializedView
+
+	savedQueries map[string]*savedQuery // Queries registered by SaveQuery
+
+	replication     *replicationConfig
+	stats           *storeStats // nil unless WithStats
+	queryCache      *queryCache // nil unless WithQueryCache
+	tracer          Tracer      // nil unless WithTracer
+	slowLog         *slowLogConfig
+	writeLimiter    *WriteLimiter
+	quota           Quota
+	usage           map[string]tableUsage // Row counts of tables, for quotas
+	tenants         map[string]*CSVStore  // Tenant stores opened by Tenant
+	authorizer      Authorizer
+	archivePolicies []ArchivePolicy
+
+	closed  atomic.Bool
+	closers []func() error
 }
 
 // CSVRecord represents a row in CSV
@@ -25,69 +65,151 @@ type CSVRecord map[string]string
 // QueryCondition represents a filter condition
 type QueryCondition struct {
 	Column   string
-	Operator string // "=", "!=", ">", "<", ">=", "<=", "contains", "starts_with", "ends_with"
+	Operator string // "=", "!=", ">", "<", ">=", "<=", "contains", "starts_with", "ends_with", "is_null", "is_not_null", "has_any", "has_all", "has_none", "json_path"
 	Value    string
+	Path     string // JSON path into the cell for "json_path", e.g. "$.plan"
 }
 
 // QueryResult represents query results
 type QueryResult struct {
 	Records []CSVRecord
 	Count   int
+	// Skipped lists the rows QueryTolerant could not parse
+	Skipped []SkippedRow
+
+	// Paginated is set for results of QueryWithOptions with a Limit or Offset,
+	// which fill in the fields below, so callers can page without counting
+	Paginated bool
+	// TotalCount is the number of records matching before paging
+	TotalCount int
+	// HasMore reports whether records follow the page
+	HasMore bool
+	// Limit and Offset are the paging applied to the records
+	Limit  int
+	Offset int
 }
 
 // NewCSVStore creates a new CSV-based storage system
-func NewCSVStore(basePath string) (*CSVStore, error) {
-	if err := os.MkdirAll(basePath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+func NewCSVStore(basePath string, opts ...Option) (*CSVStore, error) {
+	cs := &CSVStore{
+		basePath:     basePath,
+		tableOptions: make(map[string][]TableOption),
+		usage:        make(map[string]tableUsage),
 	}
+	for _, opt := range opts {
+		opt(cs)
+	}
+	cs.mu.stats = cs.stats
 
-	return &CSVStore{
-		basePath: basePath,
-	}, nil
+	if cs.fs == nil {
+		if err := os.MkdirAll(basePath, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+		cs.fs = OSFS(basePath)
+	}
+	if observer, ok := cs.fs.(cacheObserver); ok && cs.stats != nil {
+		observer.observeCache(cs.stats.addCacheLookup)
+	}
+	if cs.writeBack != nil {
+		cs.startWriteBack()
+	}
+
+	if cs.changeLog {
+		seq, err := cs.lastChangeSequence()
+		if err != nil {
+			return nil, err
+		}
+		cs.changeSeq = seq
+	}
+	if cs.replication != nil {
+		if err := cs.startReplication(); err != nil {
+			cs.Close()
+			return nil, err
+		}
+	}
+
+	if cs.checksums != nil {
+		if err := cs.loadChecksums(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cs.migrations) > 0 {
+		if _, err := cs.Migrate(); err != nil {
+			cs.Close()
+			return nil, err
+		}
+	}
+
+	return cs, nil
 }
 
-// getTablePath returns the file path for a table
+// getTablePath returns the file path for a table.
+// The caller must hold cs.mu.
 func (cs *CSVStore) getTablePath(tableName string) string {
-	return filepath.Join(cs.basePath, tableName+".csv")
+	return filepath.Join(cs.basePath, filepath.FromSlash(cs.getTableFile(tableName)))
+}
+
+// getTableFile returns the name of the file of a table in the store file system.
+// The caller must hold cs.mu.
+func (cs *CSVStore) getTableFile(tableName string) string {
+	return cs.resolveTableFile(tableName)
 }
 
 // CheckTableExists checks if a table exists
 func (cs *CSVStore) CheckTableExists(tableName string) bool {
-	cs.mu.RLock()
-	defer cs.mu.RUnlock()
+	exists, _ := runOperation(cs, Operation{Name: OpCheckTableExists, Table: tableName},
+		func() (bool, error) {
+			cs.mu.RLock()
+			defer cs.mu.RUnlock()
+
+			return cs.tableExists(tableName), nil
+		})
+	return exists
+}
 
-	tablePath := cs.getTablePath(tableName)
-	_, err := os.Stat(tablePath)
-	return !os.IsNotExist(err)
+// tableExists checks if a table exists
+func (cs *CSVStore) tableExists(tableName string) bool {
+	return cs.fileExists(cs.getTableFile(tableName))
 }
 
 // CreateTable creates a new CSV table with headers
-func (cs *CSVStore) CreateTable(tableName string, headers []string) error {
-	cs.mu.Lock()
-	defer cs.mu.Unlock()
+func (cs *CSVStore) CreateTable(tableName string, headers []string, opts ...CreateTableOption) error {
+	_, err := runOperation(cs, Operation{Name: OpCreateTable, Table: tableName, Payload: headers},
+		func() (any, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			var config createTableConfig
+			for _, opt := range opts {
+				opt(&config)
+			}
+			if config.injectReserved {
+				headers = cs.injectReservedColumns(tableName, headers)
+			}
 
-	tablePath := cs.getTablePath(tableName)
+			return nil, cs.createTable(tableName, headers)
+		})
+	return err
+}
 
+// createTable creates a new CSV table with headers.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) createTable(tableName string, headers []string) error {
 	// Check if table already exists
-	if _, err := os.Stat(tablePath); err == nil {
+	if cs.fileExists(cs.getTableFile(tableName)) {
 		return fmt.Errorf("table %s already exists", tableName)
 	}
 
-	file, err := os.Create(tablePath)
-	if err != nil {
-		return fmt.Errorf("failed to create table file: %w", err)
+	if err := cs.saveTable(tableName, headers, nil); err != nil {
+		return err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write headers
-	if err := writer.Write(headers); err != nil {
-		return fmt.Errorf("failed to write headers: %w", err)
-	}
-
-	return nil
+	return cs.emitEvents([]ChangeEvent{{
+		Operation: ChangeCreateTable,
+		Table:     tableName,
+		Headers:   slices.Clone(headers),
+	}})
 }
 
 // QuerySortedRange retrieves a limited number of records from a table, sorted by a specific field.
@@ -100,9 +222,31 @@ func (cs *CSVStore) QuerySortedRange(
 	sortBy string,
 	limit int,
 ) (*QueryResult, error) {
-	cs.mu.RLock()
-	defer cs.mu.RUnlock()
+	op := Operation{
+		Name:    OpQuerySortedRange,
+		Table:   tableName,
+		Payload: SortedRangePayload{SortField: sortField, SortBy: sortBy, Limit: limit},
+	}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
 
+		result, err := cs.querySortedRange(tableName, sortField, sortBy, limit)
+		if err != nil {
+			return nil, err
+		}
+		return cs.maskResult(tableName, result), nil
+	})
+}
+
+// querySortedRange retrieves a limited number of sorted records from a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) querySortedRange(
+	tableName string,
+	sortField string,
+	sortBy string,
+	limit int,
+) (*QueryResult, error) {
 	if limit < 0 {
 		return nil, fmt.Errorf("limit (%d) cannot be negative", limit)
 	}
@@ -110,6 +254,7 @@ func (cs *CSVStore) QuerySortedRange(
 	if sortBy != "asc" && sortBy != "desc" {
 		return nil, fmt.Errorf("sortBy must be either 'asc' or 'desc', got '%s'", sortBy)
 	}
+	sortField = cs.columnResolver(tableName)(sortField)
 
 	records, err := cs.loadTable(tableName)
 	if err != nil {
@@ -139,35 +284,7 @@ func (cs *CSVStore) QuerySortedRange(
 		}, nil
 	}
 
-	// Sort records based on sortBy parameter
-	slices.SortFunc(records, func(a, b CSVRecord) int {
-		valA, okA := a[sortField]
-		valB, okB := b[sortField]
-
-		if !okA && !okB {
-			return 0 // Both missing, treat as equal
-		}
-		if !okA {
-			result := -1 // A is missing, B is not. A comes first (is "lesser").
-			if sortBy == "desc" {
-				result = -result
-			}
-			return result
-		}
-		if !okB {
-			result := 1 // B is missing, A is not. B comes first (A is "greater").
-			if sortBy == "desc" {
-				result = -result
-			}
-			return result
-		}
-		// Both fields exist, compare them using the existing numeric/string comparison logic
-		result := compareNumeric(valA, valB)
-		if sortBy == "desc" {
-			result = -result
-		}
-		return result
-	})
+	slices.SortFunc(records, cs.recordComparer(tableName, sortField, sortBy))
 
 	// Apply limit, ensuring it doesn't exceed record count
 	actualLimit := min(limit, len(records))
@@ -180,21 +297,44 @@ func (cs *CSVStore) QuerySortedRange(
 
 // Query executes a query on the CSV table
 func (cs *CSVStore) Query(tableName string, conditions []QueryCondition) (*QueryResult, error) {
-	cs.mu.RLock()
-	defer cs.mu.RUnlock()
+	op := Operation{Name: OpQuery, Table: tableName, Payload: conditions}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		result, err := cs.cachedQuery(tableName, nil, conditions, func() (*QueryResult, error) {
+			return cs.query(tableName, conditions)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return cs.maskResult(tableName, result), nil
+	})
+}
 
-	records, err := cs.loadTable(tableName)
+// query executes a query on the CSV table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) query(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	conditions = resolveConditions(conditions, cs.columnResolver(tableName))
+	records, err := cs.loadTableMatching(tableName, conditions)
 	if err != nil {
 		return nil, err
 	}
 
 	// Apply filters
+	matcher := cs.newConditionMatcher(tableName)
+	if err := matcher.checkValues(conditions); err != nil {
+		return nil, err
+	}
 	filteredRecords := make([]CSVRecord, 0)
 	for _, record := range records {
-		if cs.matchesConditions(record, conditions) {
+		if matcher.matchesConditions(record, conditions) {
 			filteredRecords = append(filteredRecords, record)
 		}
 	}
+	if err := matcher.err(tableName); err != nil {
+		return nil, err
+	}
 
 	return &QueryResult{
 		Records: filteredRecords,
@@ -202,33 +342,150 @@ func (cs *CSVStore) Query(tableName string, conditions []QueryCondition) (*Query
 	}, nil
 }
 
+// recordComparer returns a function ordering records by sortField, "asc" or
+// "desc" according to sortBy. Records missing the field or holding null in it
+// sort first in ascending order.
+// The caller must hold cs.mu.
+func (cs *CSVStore) recordComparer(tableName, sortField, sortBy string) func(a, b CSVRecord) int {
+	config := cs.tableConfig(tableName)
+	null := config.null
+	compare := config.columnComparer(sortField)
+	return func(a, b CSVRecord) int {
+		valA, okA := a[sortField]
+		valB, okB := b[sortField]
+		okA = okA && (null == "" || valA != null)
+		okB = okB && (null == "" || valB != null)
+
+		if !okA && !okB {
+			return 0 // Both missing, treat as equal
+		}
+		if !okA {
+			result := -1 // A is missing, B is not. A comes first (is "lesser").
+			if sortBy == "desc" {
+				result = -result
+			}
+			return result
+		}
+		if !okB {
+			result := 1 // B is missing, A is not. B comes first (A is "greater").
+			if sortBy == "desc" {
+				result = -result
+			}
+			return result
+		}
+		// Both fields exist, compare them using the existing numeric/string comparison logic
+		result := compare(valA, valB)
+		if sortBy == "desc" {
+			result = -result
+		}
+		return result
+	}
+}
+
 // Select retrieves specific columns from query results
 func (cs *CSVStore) Select(
 	tableName string,
 	columns []string,
 	conditions []QueryCondition,
 ) (*QueryResult, error) {
-	result, err := cs.Query(tableName, conditions)
+	op := Operation{
+		Name:    OpSelect,
+		Table:   tableName,
+		Payload: SelectPayload{Columns: columns, Conditions: conditions},
+	}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		return cs.cachedQuery(tableName, columns, conditions, func() (*QueryResult, error) {
+			result, err := cs.query(tableName, conditions)
+			if err != nil {
+				return nil, err
+			}
+			// Mask before projecting, so computed columns see masked values
+			return cs.projectColumns(tableName, cs.maskResult(tableName, result), columns)
+		})
+	})
+}
+
+// selectColumns retrieves specific columns from query results.
+// The caller must hold cs.mu.
+func (cs *CSVStore) selectColumns(
+	tableName string,
+	columns []string,
+	conditions []QueryCondition,
+) (*QueryResult, error) {
+	result, err := cs.query(tableName, conditions)
 	if err != nil {
 		return nil, err
 	}
+	return cs.projectColumns(tableName, result, columns)
+}
+
+// projectColumns keeps only the given columns of the records of a result, or
+// all of them when columns is empty. Columns that are not headers of the table
+// may be function calls computing values from the record, e.g.
+// "concat(first_name, ' ', upper(last_name)) AS name", with the functions:
+//
+//   - concat(value, ...) joins values, skipping nulls
+//   - substr(value, start[, length]) takes characters from start, counting from 1
+//   - upper(value) and lower(value) change the case of a value
+//   - coalesce(value, ...) returns the first value that is not null
+//   - format_time(value, layout) formats an RFC 3339 timestamp or a date with
+//     a Go time layout, e.g. format_time(created_at, '2006-01-02')
+//
+// Arguments are column names, "quoted column names", 'quoted strings',
+// numbers, or function calls. The result holds the value under the alias, or
+// under the expression as written without one. Null values stay null in
+// functions other than concat and coalesce.
+// The caller must hold cs.mu.
+func (cs *CSVStore) projectColumns(tableName string, result *QueryResult, columns []string) (*QueryResult, error) {
+	resolve := cs.columnResolver(tableName)
 
 	// If no columns specified, return all columns
 	if len(columns) == 0 {
 		return result, nil
 	}
 
-	// Project only selected columns
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(columns))
+	computed := make([]*computedColumn, 0)
+	for _, column := range columns {
+		if name := resolve(column); slices.Contains(headers, name) {
+			names = append(names, name)
+			continue
+		}
+		c, ok, err := parseComputedColumn(column, resolve)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Missing columns are left out
+			names = append(names, resolve(column))
+			continue
+		}
+		computed = append(computed, c)
+	}
+
+	isNull := cs.newConditionMatcher(tableName).isNull
 	projectedRecords := make([]CSVRecord, len(result.Records))
 	for i, record := range result.Records {
-		projectedRecord := make(CSVRecord)
-		maps.Copy(projectedRecord, record)
-		// Keep only selected columns
-		for key := range projectedRecord {
-			if !slices.Contains(columns, key) {
-				delete(projectedRecord, key)
+		projectedRecord := make(CSVRecord, len(columns))
+		for _, name := range names {
+			if value, exists := record[name]; exists {
+				projectedRecord[name] = value
 			}
 		}
+		for _, c := range computed {
+			value, err := c.expr.eval(record, isNull)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute column %s: %w", c.name, err)
+			}
+			projectedRecord[c.name] = value
+		}
 		projectedRecords[i] = projectedRecord
 	}
 
@@ -240,38 +497,68 @@ func (cs *CSVStore) Select(
 
 // Insert adds a new record to the table
 func (cs *CSVStore) Insert(tableName string, record CSVRecord) (CSVRecord, error) {
-	cs.mu.Lock()
-	defer cs.mu.Unlock()
+	op := Operation{Name: OpInsert, Table: tableName, Payload: record}
+	return runOperation(cs, op, func() (CSVRecord, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
 
-	tablePath := cs.getTablePath(tableName)
+		return cs.insert(tableName, record)
+	})
+}
 
+// insert adds a new record to the table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) insert(tableName string, record CSVRecord) (CSVRecord, error) {
 	// Read existing data to get headers
 	headers, err := cs.getHeaders(tableName)
 	if err != nil {
 		return nil, err
 	}
 
-	// Open file in append mode
-	file, err := os.OpenFile(tablePath, os.O_WRONLY|os.O_APPEND, 0644)
+	row, insertedRecord, err := cs.prepareInsert(tableName, headers, record, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open table file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	if err := cs.commitInserts(tableName, [][]string{row}, []CSVRecord{insertedRecord}); err != nil {
+		return nil, err
+	}
+	return insertedRecord, nil
+}
 
-	// Convert record to row based on headers order
+// prepareInsert returns the row and the record to write for a record inserted
+// into a table with headers, after filling in its id and timestamps and running
+// the checks of the table. Generated ids are kept out of usedIDs, and added to
+// it, when it is not nil.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) prepareInsert(
+	tableName string,
+	headers []string,
+	record CSVRecord,
+	usedIDs map[string]bool,
+) ([]string, CSVRecord, error) {
+	config := cs.tableConfig(tableName)
+	reserved := config.reservedColumns()
+	record = resolveRecord(record, cs.columnResolver(tableName))
+
+	// Convert record to row based on headers order; columns left out are null
 	row := make([]string, len(headers))
 	for i, header := range headers {
-		row[i] = record[header]
+		value, exists := record[header]
+		if !exists {
+			value = config.null
+		}
+		row[i] = value
 	}
 
 	// Add id if not provided
-	if record["id"] == "" && slices.Contains(headers, "id") {
+	if record[reserved.ID] == "" && slices.Contains(headers, reserved.ID) {
 		for i, header := range headers {
-			if header == "id" {
+			if header == reserved.ID {
 				row[i] = strconv.Itoa(int(time.Now().UnixNano())) // Use timestamp as unique ID
+				if usedIDs != nil {
+					row[i] = uniqueID(usedIDs)
+					usedIDs[row[i]] = true
+				}
 				break
 			}
 		}
@@ -279,33 +566,79 @@ func (cs *CSVStore) Insert(tableName string, record CSVRecord) (CSVRecord, error
 
 	rfc3339Now := time.Now().Format(time.RFC3339Nano)
 	// Add created_at if not provided
-	if record["created_at"] == "" && slices.Contains(headers, "created_at") {
+	if !config.noTimestamps && record[reserved.CreatedAt] == "" && slices.Contains(headers, reserved.CreatedAt) {
 		for i, header := range headers {
-			if header == "created_at" {
+			if header == reserved.CreatedAt {
 				row[i] = rfc3339Now
 				break
 			}
 		}
 	}
 	// Add updated_at if not provided
-	if record["updated_at"] == "" && slices.Contains(headers, "updated_at") {
+	if !config.noTimestamps && record[reserved.UpdatedAt] == "" && slices.Contains(headers, reserved.UpdatedAt) {
 		for i, header := range headers {
-			if header == "updated_at" {
+			if header == reserved.UpdatedAt {
 				row[i] = rfc3339Now
 				break
 			}
 		}
 	}
 
-	if err := writer.Write(row); err != nil {
-		return nil, fmt.Errorf("failed to write record: %w", err)
-	}
-
 	insertedRecord := make(CSVRecord)
 	for i, header := range headers {
 		insertedRecord[header] = row[i]
 	}
-	return insertedRecord, nil
+
+	if err := config.runHooks(tableName, BeforeInsert, insertedRecord); err != nil {
+		return nil, nil, err
+	}
+	if err := config.checkTypes(insertedRecord, func(string) bool { return true }); err != nil {
+		return nil, nil, err
+	}
+	if err := config.validateInsert(tableName, insertedRecord); err != nil {
+		return nil, nil, err
+	}
+	err := config.hashColumns(insertedRecord, func(string) bool { return true })
+	if err != nil {
+		return nil, nil, err
+	}
+	cs.setRowChecksums(tableName, headers, []CSVRecord{insertedRecord})
+	for i, header := range headers {
+		row[i] = insertedRecord[header]
+	}
+
+	return row, insertedRecord, nil
+}
+
+// commitInserts appends the rows of records prepared by prepareInsert to a
+// table in one write, then emits their changes and runs the triggers and after
+// hooks of the table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) commitInserts(tableName string, rows [][]string, records []CSVRecord) error {
+	config := cs.tableConfig(tableName)
+	if err := cs.appendRows(tableName, rows); err != nil {
+		return err
+	}
+
+	if err := cs.emitChanges(ChangeInsert, tableName, records); err != nil {
+		return err
+	}
+
+	if err := cs.runTriggers(tableName, config, ChangeInsert, nil, records); err != nil {
+		return err
+	}
+
+	if err := cs.refreshViews(tableName); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := config.runHooks(tableName, AfterInsert, maps.Clone(record)); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Update updates records matching conditions
@@ -314,9 +647,51 @@ func (cs *CSVStore) Update(
 	updates CSVRecord,
 	conditions []QueryCondition,
 ) (*QueryResult, error) {
-	cs.mu.Lock()
-	defer cs.mu.Unlock()
+	op := Operation{
+		Name:    OpUpdate,
+		Table:   tableName,
+		Payload: UpdatePayload{Updates: updates, Conditions: conditions},
+	}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.update(tableName, updates, conditions)
+	})
+}
 
+// update updates records matching conditions.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) update(
+	tableName string,
+	updates CSVRecord,
+	conditions []QueryCondition,
+) (*QueryResult, error) {
+	if err := cs.checkWriteGuard(tableName, conditions); err != nil {
+		return nil, err
+	}
+	resolve := cs.columnResolver(tableName)
+	updates = resolveRecord(updates, resolve)
+	conditions = resolveConditions(conditions, resolve)
+	if err := cs.checkConditions(tableName, conditions); err != nil {
+		return nil, err
+	}
+	matcher := cs.newConditionMatcher(tableName)
+	return cs.updateMatching(tableName, func(record CSVRecord) CSVRecord {
+		if !matcher.matchesConditions(record, conditions) {
+			return nil
+		}
+		return updates
+	})
+}
+
+// updateMatching applies to every record the updates updatesFor returns for it,
+// leaving records for which it returns nil unchanged.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) updateMatching(
+	tableName string,
+	updatesFor func(CSVRecord) CSVRecord,
+) (*QueryResult, error) {
 	records, err := cs.loadTable(tableName)
 	if err != nil {
 		return nil, err
@@ -327,18 +702,37 @@ func (cs *CSVStore) Update(
 		return nil, err
 	}
 
+	config := cs.tableConfig(tableName)
+	updatedAtColumn := config.reservedColumns().UpdatedAt
 	updatedRecords := make([]CSVRecord, 0)
+	originalRecords := make([]CSVRecord, 0)
 	for i, record := range records {
-		if cs.matchesConditions(record, conditions) {
+		if updates := updatesFor(record); updates != nil {
 			// Store the original record before updating
 			originalRecord := make(CSVRecord)
 			maps.Copy(originalRecord, record)
+			originalRecords = append(originalRecords, originalRecord)
 
 			// Apply updates
 			maps.Copy(records[i], updates)
 			// Update timestamp
-			if slices.Contains(headers, "updated_at") {
-				records[i]["updated_at"] = time.Now().Format(time.RFC3339Nano)
+			if !config.noTimestamps && slices.Contains(headers, updatedAtColumn) {
+				records[i][updatedAtColumn] = time.Now().Format(time.RFC3339Nano)
+			}
+			if err := config.runHooks(tableName, BeforeUpdate, records[i]); err != nil {
+				return nil, err
+			}
+			changed := func(column string) bool {
+				return records[i][column] != originalRecord[column]
+			}
+			if err := config.checkTypes(records[i], changed); err != nil {
+				return nil, err
+			}
+			if err := config.validateUpdate(tableName, originalRecord, records[i]); err != nil {
+				return nil, err
+			}
+			if err := config.hashColumns(records[i], changed); err != nil {
+				return nil, err
 			}
 
 			// Store the updated record
@@ -358,6 +752,24 @@ func (cs *CSVStore) Update(
 		if err != nil {
 			return nil, err
 		}
+		if err := cs.recordHistory(tableName, headers, historyOpUpdate, originalRecords); err != nil {
+			return nil, err
+		}
+		if err := cs.emitChanges(ChangeUpdate, tableName, updatedRecords); err != nil {
+			return nil, err
+		}
+		err = cs.runTriggers(tableName, config, ChangeUpdate, originalRecords, updatedRecords)
+		if err != nil {
+			return nil, err
+		}
+		if err := cs.refreshViews(tableName); err != nil {
+			return nil, err
+		}
+		for _, record := range updatedRecords {
+			if err := config.runHooks(tableName, AfterUpdate, maps.Clone(record)); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return result, nil
@@ -365,9 +777,37 @@ func (cs *CSVStore) Update(
 
 // Delete removes records matching conditions
 func (cs *CSVStore) Delete(tableName string, conditions []QueryCondition) (*QueryResult, error) {
-	cs.mu.Lock()
-	defer cs.mu.Unlock()
+	op := Operation{Name: OpDelete, Table: tableName, Payload: conditions}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.delete(tableName, conditions)
+	})
+}
 
+// delete removes records matching conditions.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) delete(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	if err := cs.checkWriteGuard(tableName, conditions); err != nil {
+		return nil, err
+	}
+	conditions = resolveConditions(conditions, cs.columnResolver(tableName))
+	if err := cs.checkConditions(tableName, conditions); err != nil {
+		return nil, err
+	}
+	matcher := cs.newConditionMatcher(tableName)
+	return cs.deleteMatching(tableName, func(record CSVRecord) bool {
+		return matcher.matchesConditions(record, conditions)
+	})
+}
+
+// deleteMatching removes records for which match returns true.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) deleteMatching(
+	tableName string,
+	match func(CSVRecord) bool,
+) (*QueryResult, error) {
 	records, err := cs.loadTable(tableName)
 	if err != nil {
 		return nil, err
@@ -378,13 +818,17 @@ func (cs *CSVStore) Delete(tableName string, conditions []QueryCondition) (*Quer
 		return nil, err
 	}
 
+	config := cs.tableConfig(tableName)
 	filteredRecords := make([]CSVRecord, 0)
 	deletedRecords := make([]CSVRecord, 0)
 
 	for _, record := range records {
-		if !cs.matchesConditions(record, conditions) {
+		if !match(record) {
 			filteredRecords = append(filteredRecords, record)
 		} else {
+			if err := config.runHooks(tableName, BeforeDelete, maps.Clone(record)); err != nil {
+				return nil, err
+			}
 			// Store the deleted record
 			deletedRecord := make(CSVRecord)
 			maps.Copy(deletedRecord, record)
@@ -402,6 +846,29 @@ func (cs *CSVStore) Delete(tableName string, conditions []QueryCondition) (*Quer
 		if err != nil {
 			return nil, err
 		}
+		if err := cs.recordHistory(tableName, headers, historyOpDelete, deletedRecords); err != nil {
+			return nil, err
+		}
+		if err := cs.recordTombstones(tableName, headers, deletedRecords); err != nil {
+			return nil, err
+		}
+		if err := cs.emitChanges(ChangeDelete, tableName, deletedRecords); err != nil {
+			return nil, err
+		}
+		if err := cs.runTriggers(tableName, config, ChangeDelete, deletedRecords, nil); err != nil {
+			return nil, err
+		}
+		if err := cs.applyOnDelete(tableName, deletedRecords); err != nil {
+			return nil, err
+		}
+		if err := cs.refreshViews(tableName); err != nil {
+			return nil, err
+		}
+		for _, record := range deletedRecords {
+			if err := config.runHooks(tableName, AfterDelete, maps.Clone(record)); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return result, nil
@@ -409,35 +876,35 @@ func (cs *CSVStore) Delete(tableName string, conditions []QueryCondition) (*Quer
 
 // loadTable loads all records from a CSV table
 func (cs *CSVStore) loadTable(tableName string) ([]CSVRecord, error) {
-	tablePath := cs.getTablePath(tableName)
-
-	file, err := os.Open(tablePath)
+	records := make([]CSVRecord, 0)
+	err := cs.scanTable(tableName, nil, nil, func(record CSVRecord) error {
+		records = append(records, record)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open table file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	rows, err := reader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
-	}
+	return records, nil
+}
 
-	if len(rows) < 1 {
-		return []CSVRecord{}, nil
+// loadTableMatching loads the records of a table, skipping the files of the
+// table that cannot hold records matching conditions. Records are not filtered.
+// The caller must hold cs.mu.
+func (cs *CSVStore) loadTableMatching(tableName string, conditions []QueryCondition) ([]CSVRecord, error) {
+	scanner, err := cs.openTableMatching(tableName, conditions)
+	if err != nil {
+		return nil, err
 	}
+	defer scanner.close()
 
-	headers := rows[0]
-	records := make([]CSVRecord, 0, len(rows)-1)
-
-	for _, row := range rows[1:] {
-		record := make(CSVRecord)
-		for i, value := range row {
-			if i < len(headers) {
-				record[headers[i]] = value
-			}
-		}
+	records := make([]CSVRecord, 0)
+	err = cs.scanRecords(tableName, scanner, nil, nil, func(record CSVRecord) error {
 		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return records, nil
@@ -445,15 +912,13 @@ func (cs *CSVStore) loadTable(tableName string) ([]CSVRecord, error) {
 
 // getHeaders retrieves the headers of a CSV table
 func (cs *CSVStore) getHeaders(tableName string) ([]string, error) {
-	tablePath := cs.getTablePath(tableName)
-
-	file, err := os.Open(tablePath)
+	file, err := cs.openTableFile(tableName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open table file: %w", err)
+		return nil, err
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
+	reader := cs.newTableReader(tableName, file)
 	headers, err := reader.Read()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read headers: %w", err)
@@ -464,16 +929,53 @@ func (cs *CSVStore) getHeaders(tableName string) ([]string, error) {
 
 // saveTable saves the records back to the CSV file
 func (cs *CSVStore) saveTable(tableName string, headers []string, records []CSVRecord) error {
-	tablePath := cs.getTablePath(tableName)
+	defer cs.trackWrite(tableName)
+	cs.setRowChecksums(tableName, headers, records)
+
+	quotaRows := -1
+	if cs.quotasEnabled(tableName) {
+		rows := make([][]string, 0, len(records)+1)
+		rows = append(rows, headers)
+		for _, record := range records {
+			rows = append(rows, recordRow(headers, record))
+		}
+		var err error
+		if quotaRows, err = cs.checkQuota(tableName, rows, true); err != nil {
+			return err
+		}
+	}
+
+	if config := cs.tableConfig(tableName); config.partitioning != nil {
+		var partitions map[string][]CSVRecord
+		records, partitions = cs.splitPartitions(tableName, records)
+		if err := cs.savePartitions(tableName, headers, partitions); err != nil {
+			return err
+		}
+	} else if config.segmented() {
+		var err error
+		if records, err = cs.saveSegments(tableName, headers, records); err != nil {
+			return err
+		}
+	}
+
+	comments, err := cs.leadingComments(tableName)
+	if err != nil {
+		return err
+	}
 
-	file, err := os.Create(tablePath)
+	file, err := cs.createTableFile(tableName)
 	if err != nil {
-		return fmt.Errorf("failed to create table file: %w", err)
+		return err
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	writer := cs.newTableWriter(tableName, file)
+
+	for _, comment := range comments {
+		if err := writer.WriteComment(comment); err != nil {
+			return fmt.Errorf("failed to write comment: %w", err)
+		}
+	}
 
 	// Write headers
 	if err := writer.Write(headers); err != nil {
@@ -482,22 +984,121 @@ func (cs *CSVStore) saveTable(tableName string, headers []string, records []CSVR
 
 	// Write records
 	for _, record := range records {
-		row := make([]string, len(headers))
-		for i, header := range headers {
-			row[i] = record[header]
-		}
-		if err := writer.Write(row); err != nil {
+		if err := writer.Write(recordRow(headers, record)); err != nil {
 			return fmt.Errorf("failed to write record: %w", err)
 		}
 	}
 
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write table file: %w", err)
+	}
+	if quotaRows >= 0 {
+		cs.recordUsage(tableName, quotaRows)
+	}
+
 	return nil
 }
 
+// recordRow returns the cells of a record in the order of headers
+func recordRow(headers []string, record CSVRecord) []string {
+	row := make([]string, len(headers))
+	for i, header := range headers {
+		row[i] = record[header]
+	}
+	return row
+}
+
+// appendRows appends rows to the end of a CSV table
+func (cs *CSVStore) appendRows(tableName string, rows [][]string) error {
+	defer cs.trackWrite(tableName)
+	if err := cs.setRowChecksumCells(tableName, rows); err != nil {
+		return err
+	}
+
+	quotaRows := -1
+	if cs.quotasEnabled(tableName) {
+		var err error
+		if quotaRows, err = cs.checkQuota(tableName, rows, false); err != nil {
+			return err
+		}
+	}
+
+	if cs.tableConfig(tableName).segmented() {
+		if err := cs.rotateSegment(tableName); err != nil {
+			return err
+		}
+	}
+	if cs.tableConfig(tableName).partitioning != nil {
+		var err error
+		if rows, err = cs.appendPartitions(tableName, rows); err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			// Every row went to a partition
+			if quotaRows >= 0 {
+				cs.recordUsage(tableName, quotaRows)
+			}
+			return nil
+		}
+	}
+
+	file, err := cs.appendTableFile(tableName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := cs.newTableWriter(tableName, file)
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write table file: %w", err)
+	}
+	if quotaRows >= 0 {
+		cs.recordUsage(tableName, quotaRows)
+	}
+
+	return nil
+}
+
+// conditionMatcher checks the records of a table against query conditions
+type conditionMatcher struct {
+	null           string            // Null sentinel of the table, if any
+	listSeparators map[string]string // Separators of list columns
+	compare        func(a, b string) int
+	strictNumeric  bool
+	failures       []EvaluationFailure // Failures of strict numeric comparisons
+}
+
+// newConditionMatcher returns the matcher for the records of a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) newConditionMatcher(tableName string) *conditionMatcher {
+	config := cs.tableConfig(tableName)
+	return &conditionMatcher{
+		null:           config.null,
+		listSeparators: config.listSeparators,
+		compare:        config.valueComparer(),
+		strictNumeric:  config.strictNumeric,
+	}
+}
+
+// isNull reports whether a cell is null. Without a null sentinel, empty cells are null.
+func (m *conditionMatcher) isNull(value string) bool {
+	if m.null == "" {
+		return value == ""
+	}
+	return value == m.null
+}
+
 // matchesConditions checks if a record matches all conditions
-func (cs *CSVStore) matchesConditions(record CSVRecord, conditions []QueryCondition) bool {
+func (m *conditionMatcher) matchesConditions(record CSVRecord, conditions []QueryCondition) bool {
 	for _, condition := range conditions {
-		if !cs.matchesCondition(record, condition) {
+		if !m.matchesCondition(record, condition) {
 			return false // AND logic
 		}
 	}
@@ -505,31 +1106,58 @@ func (cs *CSVStore) matchesConditions(record CSVRecord, conditions []QueryCondit
 }
 
 // matchesCondition checks if a record matches a single condition
-func (cs *CSVStore) matchesCondition(record CSVRecord, condition QueryCondition) bool {
+func (m *conditionMatcher) matchesCondition(record CSVRecord, condition QueryCondition) bool {
+	if condition.Operator == allRowsOperator {
+		return true
+	}
 	value, exists := record[condition.Column]
 	if !exists {
 		return false
 	}
 
+	switch condition.Operator {
+	case "is_null":
+		return m.isNull(value)
+	case "is_not_null":
+		return !m.isNull(value)
+	}
+	if m.null != "" && value == m.null {
+		// Null is neither equal nor unequal to anything
+		return false
+	}
+
+	if isOrderingOperator(condition.Operator) {
+		order, ok := m.order(record, condition, value)
+		if !ok {
+			return false
+		}
+		switch condition.Operator {
+		case ">":
+			return order > 0
+		case "<":
+			return order < 0
+		case ">=":
+			return order >= 0
+		default:
+			return order <= 0
+		}
+	}
+
 	switch condition.Operator {
 	case "=", "==":
 		return value == condition.Value
 	case "!=":
 		return value != condition.Value
-	case ">":
-		return compareNumeric(value, condition.Value) > 0
-	case "<":
-		return compareNumeric(value, condition.Value) < 0
-	case ">=":
-		return compareNumeric(value, condition.Value) >= 0
-	case "<=":
-		return compareNumeric(value, condition.Value) <= 0
 	case "contains":
 		return strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value))
 	case "starts_with":
 		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(condition.Value))
 	case "ends_with":
 		return strings.HasSuffix(strings.ToLower(value), strings.ToLower(condition.Value))
+	case "has_any", "has_all", "has_none":
+		return m.matchesList(value, condition)
+	case "json_path":
+		return matchesJSONPath(value, condition.Path, condition.Value)
 	default:
 		return false
 	}
@@ -545,10 +1173,15 @@ func compareNumeric(a, b string) int {
 		return strings.Compare(a, b)
 	}
 
-	if numA < numB {
+	return compareFloats(numA, numB)
+}
+
+// compareFloats compares two numbers and returns -1, 0, or 1
+func compareFloats(a, b float64) int {
+	if a < b {
 		return -1
 	}
-	if numA > numB {
+	if a > b {
 		return 1
 	}
 	return 0
@@ -556,20 +1189,42 @@ func compareNumeric(a, b string) int {
 
 // GetTablePath returns the file path for a table (for external access)
 func (cs *CSVStore) GetTablePath(tableName string) string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
 	return cs.getTablePath(tableName)
 }
 
 // ListTables returns all available tables
 func (cs *CSVStore) ListTables() ([]string, error) {
-	files, err := os.ReadDir(cs.basePath)
+	return runOperation(cs, Operation{Name: OpListTables}, cs.listTables)
+}
+
+// Headers returns the column names of a table, in file order
+func (cs *CSVStore) Headers(tableName string) ([]string, error) {
+	return runOperation(cs, Operation{Name: OpHeaders, Table: tableName}, func() ([]string, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		return cs.getHeaders(tableName)
+	})
+}
+
+// listTables returns all available tables
+func (cs *CSVStore) listTables() ([]string, error) {
+	files, err := cs.fs.ReadDir(".")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
 	tables := make([]string, 0)
 	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".csv") {
-			tableName := strings.TrimSuffix(file.Name(), ".csv")
+		if file.IsDir() {
+			continue
+		}
+		tableName, ok := tableNameFromFile(file.Name())
+		if ok && !slices.Contains(tables, tableName) &&
+			!cs.isPartitionTable(tableName) && !cs.isSegmentTable(tableName) {
 			tables = append(tables, tableName)
 		}
 	}
@@ -579,11 +579,11 @@ func TestQuerySortedRange(t *testing.T) {
 	if err1 != nil {
 		t.Fatalf("Test Case 1 (Asc Limit 2): Expected no error, got %v", err1)
 	}
-	if len(res1) != 2 {
-		t.Errorf("Test Case 1 (Asc Limit 2): Expected 2 records, got %d", len(res1))
+	if res1.Count != 2 {
+		t.Errorf("Test Case 1 (Asc Limit 2): Expected 2 records, got %d", res1.Count)
 	} else {
-		if res1[0]["name"] != "ItemE" || res1[1]["name"] != "ItemA" {
-			t.Errorf("Test Case 1 (Asc Limit 2): Records not in expected order. Got: %v, %v", res1[0]["name"], res1[1]["name"])
+		if res1.Records[0]["name"] != "ItemE" || res1.Records[1]["name"] != "ItemA" {
+			t.Errorf("Test Case 1 (Asc Limit 2): Records not in expected order. Got: %v, %v", res1.Records[0]["name"], res1.Records[1]["name"])
 		}
 	}
 
@@ -593,11 +593,11 @@ func TestQuerySortedRange(t *testing.T) {
 	if err2 != nil {
 		t.Fatalf("Test Case 2 (Desc Limit 3): Expected no error, got %v", err2)
 	}
-	if len(res2) != 3 {
-		t.Errorf("Test Case 2 (Desc Limit 3): Expected 3 records, got %d", len(res2))
+	if res2.Count != 3 {
+		t.Errorf("Test Case 2 (Desc Limit 3): Expected 3 records, got %d", res2.Count)
 	} else {
-		if res2[0]["name"] != "ItemD" || res2[1]["name"] != "ItemB" || res2[2]["name"] != "ItemC" {
-			t.Errorf("Test Case 2 (Desc Limit 3): Records not in expected order. Got: %v, %v, %v", res2[0]["name"], res2[1]["name"], res2[2]["name"])
+		if res2.Records[0]["name"] != "ItemD" || res2.Records[1]["name"] != "ItemB" || res2.Records[2]["name"] != "ItemC" {
+			t.Errorf("Test Case 2 (Desc Limit 3): Records not in expected order. Got: %v, %v, %v", res2.Records[0]["name"], res2.Records[1]["name"], res2.Records[2]["name"])
 		}
 	}
 
@@ -606,8 +606,8 @@ func TestQuerySortedRange(t *testing.T) {
 	if err3 != nil {
 		t.Fatalf("Test Case 3 (Asc Limit 0): Expected no error, got %v", err3)
 	}
-	if len(res3) != 0 {
-		t.Errorf("Test Case 3 (Asc Limit 0): Expected 0 records, got %d", len(res3))
+	if res3.Count != 0 {
+		t.Errorf("Test Case 3 (Asc Limit 0): Expected 0 records, got %d", res3.Count)
 	}
 
 	// Test Case 4: Ascending order, limit > total (e.g., 10)
@@ -616,11 +616,11 @@ func TestQuerySortedRange(t *testing.T) {
 	if err4 != nil {
 		t.Fatalf("Test Case 4 (Asc Limit > Total): Expected no error, got %v", err4)
 	}
-	if len(res4) != 5 {
-		t.Errorf("Test Case 4 (Asc Limit > Total): Expected 5 records, got %d", len(res4))
+	if res4.Count != 5 {
+		t.Errorf("Test Case 4 (Asc Limit > Total): Expected 5 records, got %d", res4.Count)
 	} else {
-		if res4[0]["name"] != "ItemE" || res4[1]["name"] != "ItemA" || res4[2]["name"] != "ItemC" || res4[3]["name"] != "ItemB" || res4[4]["name"] != "ItemD" {
-			t.Errorf("Test Case 4 (Asc Limit > Total): Records not in expected order. Got: %v, %v, %v, %v, %v", res4[0]["name"], res4[1]["name"], res4[2]["name"], res4[3]["name"], res4[4]["name"])
+		if res4.Records[0]["name"] != "ItemE" || res4.Records[1]["name"] != "ItemA" || res4.Records[2]["name"] != "ItemC" || res4.Records[3]["name"] != "ItemB" || res4.Records[4]["name"] != "ItemD" {
+			t.Errorf("Test Case 4 (Asc Limit > Total): Records not in expected order. Got: %v, %v, %v, %v, %v", res4.Records[0]["name"], res4.Records[1]["name"], res4.Records[2]["name"], res4.Records[3]["name"], res4.Records[4]["name"])
 		}
 	}
 
@@ -646,8 +646,8 @@ func TestQuerySortedRange(t *testing.T) {
 	if err7 != nil {
 		t.Fatalf("Test Case 7 (Empty Table): Expected no error, got %v", err7)
 	}
-	if len(res7) != 0 {
-		t.Errorf("Test Case 7 (Empty Table): Expected 0 records, got %d", len(res7))
+	if res7.Count != 0 {
+		t.Errorf("Test Case 7 (Empty Table): Expected 0 records, got %d", res7.Count)
 	}
 
 	// Test Case 8: Invalid sortOrder string
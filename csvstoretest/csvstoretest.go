@@ -0,0 +1,187 @@
+// Package csvstoretest provides helpers for tests of code using csvstore: stores
+// cleaned up with the test, assertions on rows that ignore the generated id and
+// timestamp columns, and comparisons of tables with golden files.
+//
+//	store := csvstoretest.NewTempStore(t)
+//	...
+//	csvstoretest.AssertRowCount(t, store, "users", 2)
+//	csvstoretest.AssertGoldenTable(t, store, "users", "testdata/users.csv")
+//
+// Run the tests with CSVSTORETEST_UPDATE=1 to write the golden files from the
+// current tables.
+package csvstoretest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+// UpdateEnv is the environment variable that makes AssertGoldenTable write the
+// golden files instead of comparing with them, when set to 1
+const UpdateEnv = "CSVSTORETEST_UPDATE"
+
+// generatedColumns are the columns the store fills in, whose values differ
+// between runs
+var generatedColumns = []string{
+	csvstore.DefaultIDColumn,
+	csvstore.DefaultCreatedAtColumn,
+	csvstore.DefaultUpdatedAtColumn,
+}
+
+// NewTempStore creates a store in a temporary directory, which is closed and
+// removed when the test ends
+func NewTempStore(t testing.TB, opts ...csvstore.Option) *csvstore.CSVStore {
+	t.Helper()
+
+	store, err := csvstore.NewCSVStore(t.TempDir(), opts...)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() {
+		store.Close()
+	})
+	return store
+}
+
+// NewMemoryStore creates an in-memory store, which is closed when the test ends
+func NewMemoryStore(t testing.TB, opts ...csvstore.Option) *csvstore.CSVStore {
+	t.Helper()
+
+	opts = append([]csvstore.Option{csvstore.WithFS(csvstore.NewMemFS())}, opts...)
+	store, err := csvstore.NewCSVStore("", opts...)
+	if err != nil {
+		t.Fatalf("Failed to create memory store: %v", err)
+	}
+	t.Cleanup(func() {
+		store.Close()
+	})
+	return store
+}
+
+// AssertRowCount fails the test unless a table holds want rows
+func AssertRowCount(t testing.TB, store csvstore.Store, tableName string, want int) {
+	t.Helper()
+
+	result, err := store.Query(tableName, nil)
+	if err != nil {
+		t.Fatalf("Failed to query table %s: %v", tableName, err)
+	}
+	if result.Count != want {
+		t.Errorf("Expected %d rows in table %s, got %d", want, tableName, result.Count)
+	}
+}
+
+// AssertRecordEqual fails the test unless two records hold the same values,
+// ignoring the id, created_at, and updated_at columns. A column missing from a
+// record equals an empty value.
+func AssertRecordEqual(t testing.TB, want, got csvstore.CSVRecord) {
+	t.Helper()
+
+	if column, ok := recordDiff(want, got); !ok {
+		t.Errorf("Expected record %v, got %v (column %s differs)", want, got, column)
+	}
+}
+
+// AssertRecordsEqual fails the test unless two lists of records hold the same
+// values in the same order, ignoring the columns AssertRecordEqual ignores
+func AssertRecordsEqual(t testing.TB, want, got []csvstore.CSVRecord) {
+	t.Helper()
+
+	if len(want) != len(got) {
+		t.Errorf("Expected %d records, got %d: %v", len(want), len(got), got)
+		return
+	}
+	for i := range want {
+		if column, ok := recordDiff(want[i], got[i]); !ok {
+			t.Errorf("Expected record %d to be %v, got %v (column %s differs)", i, want[i], got[i], column)
+		}
+	}
+}
+
+// recordDiff returns the first column, in sorted order, whose values differ
+// between two records, ignoring the generated columns
+func recordDiff(want, got csvstore.CSVRecord) (string, bool) {
+	columns := make([]string, 0, len(want)+len(got))
+	for column := range want {
+		columns = append(columns, column)
+	}
+	for column := range got {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+	for _, column := range slices.Compact(columns) {
+		if slices.Contains(generatedColumns, column) {
+			continue
+		}
+		if want[column] != got[column] {
+			return column, false
+		}
+	}
+	return "", true
+}
+
+// AssertGoldenTable fails the test unless the rows of a table match the golden
+// CSV file at path. The table is rendered as CSV in file order with its header
+// row, leaving out the id, created_at, and updated_at columns. With
+// CSVSTORETEST_UPDATE=1, the golden file is written from the table instead.
+func AssertGoldenTable(t testing.TB, store *csvstore.CSVStore, tableName string, path string) {
+	t.Helper()
+
+	got, err := renderTable(store, tableName)
+	if err != nil {
+		t.Fatalf("Failed to render table %s: %v", tableName, err)
+	}
+
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with %s=1 to create it): %v", UpdateEnv, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Table %s does not match golden file %s:\nwant:\n%s\ngot:\n%s", tableName, path, want, got)
+	}
+}
+
+// renderTable encodes the rows of a table as CSV, leaving out the generated
+// columns
+func renderTable(store *csvstore.CSVStore, tableName string) ([]byte, error) {
+	headers, err := store.Headers(tableName)
+	if err != nil {
+		return nil, err
+	}
+	headers = slices.DeleteFunc(headers, func(header string) bool {
+		return slices.Contains(generatedColumns, header)
+	})
+	result, err := store.Query(tableName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(headers)
+	for _, record := range result.Records {
+		row := make([]string, len(headers))
+		for i, header := range headers {
+			row[i] = record[header]
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
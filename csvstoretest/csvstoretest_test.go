@@ -0,0 +1,88 @@
+package csvstoretest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+// recorder records the failures of an assertion instead of failing the test
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	store := NewTempStore(t)
+	if err := store.CreateTable("users", []string{"id", "name", "created_at"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	inserted, err := store.Insert("users", csvstore.CSVRecord{"name": "alice"})
+	if err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	AssertRowCount(t, store, "users", 1)
+	AssertRecordEqual(t, csvstore.CSVRecord{"name": "alice"}, inserted)
+	AssertRecordsEqual(t, []csvstore.CSVRecord{{"name": "alice"}}, []csvstore.CSVRecord{inserted})
+
+	r := &recorder{}
+	AssertRowCount(r, store, "users", 2)
+	AssertRecordEqual(r, csvstore.CSVRecord{"name": "bob"}, inserted)
+	AssertRecordsEqual(r, nil, []csvstore.CSVRecord{inserted})
+	if len(r.failures) != 3 {
+		t.Errorf("Expected 3 failures, got %v", r.failures)
+	}
+}
+
+func TestAssertGoldenTable(t *testing.T) {
+	store := NewMemoryStore(t)
+	if err := store.CreateTable("users", []string{"id", "name", "email", "created_at"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := store.Insert("users", csvstore.CSVRecord{"name": name, "email": name + "@example.com"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	golden := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(golden, []byte("name,email\nalice,alice@example.com\nbob,bob@example.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write golden file: %v", err)
+	}
+	AssertGoldenTable(t, store, "users", golden)
+
+	if _, err := store.Update("users", csvstore.CSVRecord{"email": "bob@example.org"},
+		[]csvstore.QueryCondition{{Column: "name", Operator: "=", Value: "bob"}}); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	r := &recorder{}
+	AssertGoldenTable(r, store, "users", golden)
+	if len(r.failures) != 1 {
+		t.Errorf("Expected the changed table to differ from the golden file, got %v", r.failures)
+	}
+
+	// Updating writes the golden file
+	t.Setenv(UpdateEnv, "1")
+	AssertGoldenTable(t, store, "users", golden)
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if string(data) != "name,email\nalice,alice@example.com\nbob,bob@example.org\n" {
+		t.Errorf("Expected the golden file to be updated, got:\n%s", data)
+	}
+}
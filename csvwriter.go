@@ -0,0 +1,113 @@
+package csvstore
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tableWriter writes CSV rows to a table file. It follows the output of
+// csv.Writer, and additionally quotes fields that a reader configured for the
+// table would otherwise misread, such as a first field starting with the
+// comment character.
+type tableWriter struct {
+	w       *bufio.Writer
+	comma   rune
+	comment rune
+	// quoteAll quotes every field
+	quoteAll bool
+	// useCRLF ends lines with \r\n
+	useCRLF bool
+	err     error
+}
+
+// newCSVWriter returns a tableWriter writing to w with the default settings
+func newCSVWriter(w io.Writer) *tableWriter {
+	return &tableWriter{w: bufio.NewWriter(w), comma: ','}
+}
+
+// Write writes a single row
+func (tw *tableWriter) Write(row []string) error {
+	if tw.err != nil {
+		return tw.err
+	}
+
+	for i, field := range row {
+		if i > 0 {
+			tw.w.WriteRune(tw.comma)
+		}
+		// A lone empty field would be an empty line, which readers skip
+		if !tw.quoteAll && !tw.needsQuotes(field, i == 0) && !(len(row) == 1 && field == "") {
+			tw.w.WriteString(field)
+			continue
+		}
+		field = strings.ReplaceAll(field, `"`, `""`)
+		if tw.useCRLF {
+			field = strings.ReplaceAll(strings.ReplaceAll(field, "\r\n", "\n"), "\n", "\r\n")
+		}
+		tw.w.WriteByte('"')
+		tw.w.WriteString(field)
+		tw.w.WriteByte('"')
+	}
+	return tw.endLine()
+}
+
+// WriteComment writes a comment line, which must start with the comment character
+func (tw *tableWriter) WriteComment(line string) error {
+	if tw.err != nil {
+		return tw.err
+	}
+	tw.w.WriteString(line)
+	return tw.endLine()
+}
+
+// endLine terminates the current line
+func (tw *tableWriter) endLine() error {
+	if tw.useCRLF {
+		_, tw.err = tw.w.WriteString("\r\n")
+	} else {
+		_, tw.err = tw.w.WriteString("\n")
+	}
+	return tw.err
+}
+
+// WriteAll writes rows and flushes the writer
+func (tw *tableWriter) WriteAll(rows [][]string) error {
+	for _, row := range rows {
+		if err := tw.Write(row); err != nil {
+			return err
+		}
+	}
+	tw.Flush()
+	return tw.Error()
+}
+
+// Flush writes buffered data to the underlying writer
+func (tw *tableWriter) Flush() {
+	if err := tw.w.Flush(); err != nil && tw.err == nil {
+		tw.err = err
+	}
+}
+
+// Error returns the first error of a previous Write or Flush
+func (tw *tableWriter) Error() error {
+	return tw.err
+}
+
+// needsQuotes reports whether a field must be quoted
+func (tw *tableWriter) needsQuotes(field string, first bool) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, tw.comma) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+
+	r, _ := utf8.DecodeRuneInString(field)
+	if unicode.IsSpace(r) {
+		return true
+	}
+	return first && tw.comment != 0 && r == tw.comment
+}
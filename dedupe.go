@@ -0,0 +1,93 @@
+package csvstore
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// KeepPolicy selects which row of a group of duplicates Dedupe keeps
+type KeepPolicy int
+
+const (
+	// KeepFirst keeps the first row of the table file
+	KeepFirst KeepPolicy = iota
+	// KeepLast keeps the last row of the table file
+	KeepLast
+	// KeepLatest keeps the most recently updated row, by the updated_at column,
+	// or the created_at column for rows never updated. Rows without a valid
+	// timestamp are the oldest; among equally recent rows the last one is kept.
+	KeepLatest
+)
+
+// Dedupe removes duplicate rows of a table, rows holding the same values in all
+// of keyColumns, keeping one row of each group according to keep. It returns the
+// removed rows. Removals go through Delete's hooks, history, and change log.
+func (cs *CSVStore) Dedupe(tableName string, keyColumns []string, keep KeepPolicy) (*QueryResult, error) {
+	op := Operation{Name: OpDedupe, Table: tableName, Payload: keyColumns}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.dedupe(tableName, keyColumns, keep)
+	})
+}
+
+// dedupe removes duplicate rows of a table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) dedupe(tableName string, keyColumns []string, keep KeepPolicy) (*QueryResult, error) {
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("deduplicating table %s requires key columns", tableName)
+	}
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return nil, err
+	}
+	resolve := cs.columnResolver(tableName)
+	keyColumns = slices.Clone(keyColumns)
+	for i, column := range keyColumns {
+		keyColumns[i] = resolve(column)
+		if !slices.Contains(headers, keyColumns[i]) {
+			return nil, fmt.Errorf("column %s does not exist in table %s", keyColumns[i], tableName)
+		}
+	}
+
+	records, err := cs.loadTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := cs.reservedColumns(tableName)
+
+	// Pick the row to keep of every group
+	kept := make(map[string]int)
+	for i, record := range records {
+		values := make([]string, len(keyColumns))
+		for j, column := range keyColumns {
+			values[j] = record[column]
+		}
+		key := strings.Join(values, "\x00")
+
+		current, seen := kept[key]
+		switch {
+		case !seen, keep == KeepLast:
+			kept[key] = i
+		case keep == KeepLatest && !reserved.lastModified(record).Before(reserved.lastModified(records[current])):
+			kept[key] = i
+		}
+	}
+	remove := make([]bool, len(records))
+	for i := range remove {
+		remove[i] = true
+	}
+	for _, i := range kept {
+		remove[i] = false
+	}
+
+	// Records are loaded again in the same order while the lock is held
+	i := -1
+	return cs.deleteMatching(tableName, func(CSVRecord) bool {
+		i++
+		return remove[i]
+	})
+}
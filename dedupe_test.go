@@ -0,0 +1,64 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	headers := []string{"id", "email", "name", "updated_at"}
+	rows := []CSVRecord{
+		{"id": "1", "email": "a@example.com", "name": "first", "updated_at": "2024-01-03T00:00:00Z"},
+		{"id": "2", "email": "b@example.com", "name": "only", "updated_at": "2024-01-01T00:00:00Z"},
+		{"id": "3", "email": "a@example.com", "name": "latest", "updated_at": "2024-01-05T00:00:00Z"},
+		{"id": "4", "email": "a@example.com", "name": "last", "updated_at": "2024-01-02T00:00:00Z"},
+	}
+	policies := map[KeepPolicy]string{KeepFirst: "first", KeepLast: "last", KeepLatest: "latest"}
+	for keep, name := range policies {
+		table := "contacts_" + name
+		if err := store.CreateTable(table, headers); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		for _, row := range rows {
+			if _, err := store.Insert(table, row); err != nil {
+				t.Fatalf("Failed to insert row: %v", err)
+			}
+		}
+
+		removed, err := store.Dedupe(table, []string{"email"}, keep)
+		if err != nil {
+			t.Fatalf("Failed to dedupe: %v", err)
+		}
+		if removed.Count != 2 {
+			t.Errorf("Expected 2 removed rows with %s, got %v", name, removed.Records)
+		}
+
+		result, err := store.Query(table, []QueryCondition{{Column: "email", Operator: "=", Value: "a@example.com"}})
+		if err != nil {
+			t.Fatalf("Failed to query table: %v", err)
+		}
+		if result.Count != 1 || result.Records[0]["name"] != name {
+			t.Errorf("Expected to keep the %s row, got %v", name, result.Records)
+		}
+		result, err = store.Query(table, nil)
+		if err != nil {
+			t.Fatalf("Failed to query table: %v", err)
+		}
+		if result.Count != 2 {
+			t.Errorf("Expected 2 rows left, got %v", result.Records)
+		}
+	}
+
+	if _, err := store.Dedupe("contacts_first", []string{"missing"}, KeepFirst); err == nil {
+		t.Errorf("Expected an error for a missing key column")
+	}
+}
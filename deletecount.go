@@ -0,0 +1,140 @@
+package csvstore
+
+import (
+	"fmt"
+	"slices"
+)
+
+// DeleteCount removes records matching conditions like Delete, and returns the
+// number of removed records instead of the records, for bulk purges. The table
+// is rewritten while its records are read, without holding them in memory,
+// unless something needs the deleted records: delete hooks, history,
+// tombstones, the change log or subscribers, triggers, foreign keys referencing
+// the table, views refreshed on write, partitions, segments, or quotas. Such
+// deletes run like Delete.
+func (cs *CSVStore) DeleteCount(tableName string, conditions []QueryCondition) (int, error) {
+	op := Operation{Name: OpDeleteCount, Table: tableName, Payload: conditions}
+	return runOperation(cs, op, func() (int, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.deleteCount(tableName, conditions)
+	})
+}
+
+// deleteCount removes records matching conditions and returns their number.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) deleteCount(tableName string, conditions []QueryCondition) (int, error) {
+	if err := cs.checkWriteGuard(tableName, conditions); err != nil {
+		return 0, err
+	}
+	if cs.deleteNeedsRecords(tableName) {
+		result, err := cs.delete(tableName, conditions)
+		if err != nil {
+			return 0, err
+		}
+		return result.Count, nil
+	}
+
+	conditions = resolveConditions(conditions, cs.columnResolver(tableName))
+	matcher := cs.newConditionMatcher(tableName)
+	if err := matcher.checkValues(conditions); err != nil {
+		return 0, err
+	}
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return 0, err
+	}
+	comments, err := cs.leadingComments(tableName)
+	if err != nil {
+		return 0, err
+	}
+	scanner, err := cs.openTable(tableName)
+	if err != nil {
+		return 0, err
+	}
+	defer scanner.close()
+
+	// Write the kept records next to the table file, in the same format
+	name := cs.getTableFile(tableName)
+	tempName := "." + randomSuffix() + "-" + name
+	file, err := cs.createTableFileAt(tableName, name, tempName)
+	if err != nil {
+		return 0, err
+	}
+	defer cs.fs.Remove(tempName)
+	defer file.Close()
+
+	writer := cs.newTableWriter(tableName, file)
+	for _, comment := range comments {
+		if err := writer.WriteComment(comment); err != nil {
+			return 0, fmt.Errorf("failed to write comment: %w", err)
+		}
+	}
+	if err := writer.Write(headers); err != nil {
+		return 0, fmt.Errorf("failed to write headers: %w", err)
+	}
+
+	deleted := 0
+	err = cs.scanRecords(tableName, scanner, nil, nil, func(record CSVRecord) error {
+		if matcher.matchesConditions(record, conditions) {
+			deleted++
+			return nil
+		}
+		if err := writer.Write(recordRow(headers, record)); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := matcher.err(tableName); err != nil {
+		return 0, err
+	}
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return 0, fmt.Errorf("failed to write record: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write table file: %w", err)
+	}
+	if err := scanner.close(); err != nil {
+		return 0, fmt.Errorf("failed to close table file: %w", err)
+	}
+	defer cs.trackWrite(tableName)
+	if err := cs.fs.Rename(tempName, name); err != nil {
+		return 0, fmt.Errorf("failed to replace table file: %w", err)
+	}
+	return deleted, nil
+}
+
+// deleteNeedsRecords reports whether deleting records of a table needs the
+// deleted records, or their number, beyond rewriting the table file.
+// The caller must hold cs.mu.
+func (cs *CSVStore) deleteNeedsRecords(tableName string) bool {
+	config := cs.tableConfig(tableName)
+	if len(config.hooks[BeforeDelete]) > 0 || len(config.hooks[AfterDelete]) > 0 ||
+		config.history || config.tombstones || len(config.triggers) > 0 ||
+		config.partitioning != nil || config.segmented() || cs.quotasEnabled(tableName) ||
+		cs.changeLog || len(cs.subscribers) > 0 {
+		return true
+	}
+	for child := range cs.tableOptions {
+		for _, fk := range cs.tableConfig(child).foreignKeys {
+			if fk.References == tableName && fk.OnDelete != OnDeleteNoAction {
+				return true
+			}
+		}
+	}
+	for _, view := range cs.views {
+		if view.RefreshOnWrite && slices.Contains(view.Sources, tableName) {
+			return true
+		}
+	}
+	return false
+}
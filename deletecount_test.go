@@ -0,0 +1,101 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestDeleteCount(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	store.ConfigureTable("events", WithCompression(GzipCompression))
+	store.ConfigureTable("audited", WithHistory())
+	store.ConfigureTable("metrics", WithStrictNumeric())
+
+	for _, table := range []string{"events", "audited", "metrics"} {
+		if err := store.CreateTable(table, []string{"id", "level", "value"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		for i := range 10 {
+			level := "info"
+			if i%3 == 0 {
+				level = "debug"
+			}
+			record := CSVRecord{"id": strconv.Itoa(i), "level": level, "value": strconv.Itoa(i)}
+			if _, err := store.Insert(table, record); err != nil {
+				t.Fatalf("Failed to insert record: %v", err)
+			}
+		}
+	}
+
+	debug := []QueryCondition{{Column: "level", Operator: "=", Value: "debug"}}
+	for _, table := range []string{"events", "audited"} {
+		deleted, err := store.DeleteCount(table, debug)
+		if err != nil {
+			t.Fatalf("Failed to delete from %s: %v", table, err)
+		}
+		if deleted != 4 {
+			t.Errorf("Expected 4 records deleted from %s, got %d", table, deleted)
+		}
+		result, err := store.Query(table, nil)
+		if err != nil {
+			t.Fatalf("Failed to query: %v", err)
+		}
+		if result.Count != 6 {
+			t.Errorf("Expected 6 records left in %s, got %d", table, result.Count)
+		}
+		for _, record := range result.Records {
+			if record["level"] == "debug" {
+				t.Errorf("Expected debug records to be deleted from %s, got %v", table, record)
+			}
+		}
+	}
+
+	// Deletes that need the deleted records still record them
+	history, err := store.Query(HistoryTableName("audited"), nil)
+	if err != nil {
+		t.Fatalf("Failed to query history: %v", err)
+	}
+	if history.Count != 4 {
+		t.Errorf("Expected 4 history records, got %d", history.Count)
+	}
+
+	deleted, err := store.DeleteCount("events", debug)
+	if err != nil || deleted != 0 {
+		t.Errorf("Expected nothing left to delete, got %d, %v", deleted, err)
+	}
+
+	// Failures leave the table untouched
+	if _, err := store.Update("metrics", CSVRecord{"value": "n/a"}, []QueryCondition{{Column: "id", Operator: "=", Value: "5"}}); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	_, err = store.DeleteCount("metrics", []QueryCondition{{Column: "value", Operator: ">", Value: "3"}})
+	var evalErr *EvaluationError
+	if !errors.As(err, &evalErr) {
+		t.Errorf("Expected an EvaluationError, got %v", err)
+	}
+	result, err := store.Query("metrics", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 10 {
+		t.Errorf("Expected the table to keep 10 records, got %d", result.Count)
+	}
+	entries, err := os.ReadDir(testDir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Name()[0] == '.' {
+			t.Errorf("Expected no temporary files left, got %s", entry.Name())
+		}
+	}
+}
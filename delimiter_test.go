@@ -0,0 +1,61 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDelimiter(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir, WithTableDefaults(WithDelimiter(';')))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+	store.ConfigureTable("logs", WithDelimiter('\t'))
+
+	if err := store.CreateTable("prices", []string{"name", "price"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := store.CreateTable("logs", []string{"level", "message"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("prices", CSVRecord{"name": "Brot", "price": "2,50"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Insert("logs", CSVRecord{"level": "info", "message": "started; ok"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	data, err := os.ReadFile(store.GetTablePath("prices"))
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	if string(data) != "name;price\nBrot;2,50\n" {
+		t.Errorf("Unexpected semicolon-separated file: %q", data)
+	}
+	data, err = os.ReadFile(store.GetTablePath("logs"))
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	if string(data) != "level\tmessage\ninfo\tstarted; ok\n" {
+		t.Errorf("Unexpected tab-separated file: %q", data)
+	}
+
+	result, err := store.Update("prices", CSVRecord{"price": "2,75"}, []QueryCondition{
+		{Column: "name", Operator: "=", Value: "Brot"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Expected 1 updated record, got %d", result.Count)
+	}
+	prices, err := store.Query("prices", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if prices.Count != 1 || prices.Records[0]["price"] != "2,75" {
+		t.Errorf("Unexpected records: %v", prices.Records)
+	}
+}
@@ -0,0 +1,211 @@
+package csvstore
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// StoreDiff describes the differences between two stores, from the store Diff is
+// called on to the other one. It marshals to JSON for machine-readable reports.
+type StoreDiff struct {
+	TablesAdded   []string    `json:"tables_added"`   // Tables only in the other store
+	TablesRemoved []string    `json:"tables_removed"` // Tables only in this store
+	Tables        []TableDiff `json:"tables"`         // Tables in both stores that differ
+}
+
+// Equal reports whether the stores hold the same tables with the same contents
+func (d *StoreDiff) Equal() bool {
+	return len(d.TablesAdded) == 0 && len(d.TablesRemoved) == 0 && len(d.Tables) == 0
+}
+
+// TableDiff describes the differences between the contents of a table in two stores.
+// Rows are matched by the id column when both tables have one, and by their
+// full contents otherwise.
+type TableDiff struct {
+	Table         string      `json:"table"`
+	HeadersBefore []string    `json:"headers_before,omitempty"` // Set when the headers differ
+	HeadersAfter  []string    `json:"headers_after,omitempty"`  // Set when the headers differ
+	Added         []CSVRecord `json:"added"`
+	Removed       []CSVRecord `json:"removed"`
+	Changed       []RowChange `json:"changed"`
+}
+
+// RowChange is a row whose contents differ between two stores
+type RowChange struct {
+	Before CSVRecord `json:"before"`
+	After  CSVRecord `json:"after"`
+}
+
+// DiffStores compares the stores at two base paths, opened with opts
+func DiffStores(basePath, otherBasePath string, opts ...Option) (*StoreDiff, error) {
+	cs, err := NewCSVStore(basePath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cs.Close()
+
+	other, err := NewCSVStore(otherBasePath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer other.Close()
+
+	return cs.Diff(other)
+}
+
+// Diff compares the tables of the store with those of other, e.g. to verify a
+// backup or a replica. Each store is read under its own lock, one after the other.
+func (cs *CSVStore) Diff(other *CSVStore) (*StoreDiff, error) {
+	return runOperation(cs, Operation{Name: OpDiff}, func() (*StoreDiff, error) {
+		before, err := cs.snapshotTables()
+		if err != nil {
+			return nil, err
+		}
+		after, err := other.snapshotTables()
+		if err != nil {
+			return nil, err
+		}
+		return diffSnapshots(before, after, cs.reservedColumns, other.reservedColumns), nil
+	})
+}
+
+// tableSnapshot holds the headers and records of a table
+type tableSnapshot struct {
+	headers []string
+	records []CSVRecord
+}
+
+// snapshotTables reads every table of the store
+func (cs *CSVStore) snapshotTables() (map[string]tableSnapshot, error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	if cs.closed.Load() {
+		return nil, ErrClosed
+	}
+	tables, err := cs.listTables()
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make(map[string]tableSnapshot, len(tables))
+	for _, tableName := range tables {
+		var snapshot tableSnapshot
+		err := cs.scanTable(tableName, nil, func(headers []string) error {
+			snapshot.headers = headers
+			return nil
+		}, func(record CSVRecord) error {
+			snapshot.records = append(snapshot.records, record)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read table %s: %w", tableName, err)
+		}
+		snapshots[tableName] = snapshot
+	}
+	return snapshots, nil
+}
+
+// diffSnapshots compares two sets of tables
+func diffSnapshots(
+	before, after map[string]tableSnapshot,
+	reservedBefore, reservedAfter func(tableName string) ReservedColumns,
+) *StoreDiff {
+	diff := &StoreDiff{TablesAdded: []string{}, TablesRemoved: []string{}, Tables: []TableDiff{}}
+	for _, tableName := range slices.Sorted(maps.Keys(after)) {
+		if _, exists := before[tableName]; !exists {
+			diff.TablesAdded = append(diff.TablesAdded, tableName)
+		}
+	}
+	for _, tableName := range slices.Sorted(maps.Keys(before)) {
+		afterTable, exists := after[tableName]
+		if !exists {
+			diff.TablesRemoved = append(diff.TablesRemoved, tableName)
+			continue
+		}
+		idColumn := reservedBefore(tableName).ID
+		if reservedAfter(tableName).ID != idColumn {
+			idColumn = ""
+		}
+		if tableDiff := diffTable(tableName, before[tableName], afterTable, idColumn); tableDiff != nil {
+			diff.Tables = append(diff.Tables, *tableDiff)
+		}
+	}
+	return diff
+}
+
+// diffTable compares the contents of a table, matching rows by idColumn when both
+// versions have it. It returns nil when they are the same.
+func diffTable(tableName string, before, after tableSnapshot, idColumn string) *TableDiff {
+	diff := &TableDiff{
+		Table:   tableName,
+		Added:   []CSVRecord{},
+		Removed: []CSVRecord{},
+		Changed: []RowChange{},
+	}
+	if !slices.Equal(before.headers, after.headers) {
+		diff.HeadersBefore = before.headers
+		diff.HeadersAfter = after.headers
+	}
+
+	if idColumn != "" && slices.Contains(before.headers, idColumn) && slices.Contains(after.headers, idColumn) {
+		afterByID := make(map[string]CSVRecord, len(after.records))
+		for _, record := range after.records {
+			afterByID[record[idColumn]] = record
+		}
+		matched := make(map[string]bool)
+		for _, record := range before.records {
+			id := record[idColumn]
+			afterRecord, exists := afterByID[id]
+			switch {
+			case !exists || matched[id]:
+				diff.Removed = append(diff.Removed, record)
+			case !maps.Equal(record, afterRecord):
+				diff.Changed = append(diff.Changed, RowChange{Before: record, After: afterRecord})
+			}
+			matched[id] = exists
+		}
+		for _, record := range after.records {
+			if !matched[record[idColumn]] {
+				diff.Added = append(diff.Added, record)
+			}
+		}
+	} else {
+		// Compare rows as a multiset of their contents
+		remaining := make(map[string][]CSVRecord)
+		for _, record := range after.records {
+			key := recordKey(record)
+			remaining[key] = append(remaining[key], record)
+		}
+		for _, record := range before.records {
+			key := recordKey(record)
+			if len(remaining[key]) == 0 {
+				diff.Removed = append(diff.Removed, record)
+				continue
+			}
+			remaining[key] = remaining[key][1:]
+		}
+		for _, record := range after.records {
+			key := recordKey(record)
+			if len(remaining[key]) > 0 {
+				diff.Added = append(diff.Added, remaining[key][0])
+				remaining[key] = remaining[key][1:]
+			}
+		}
+	}
+
+	if diff.HeadersBefore == nil && len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		return nil
+	}
+	return diff
+}
+
+// recordKey returns a string identifying the contents of a record
+func recordKey(record CSVRecord) string {
+	var b strings.Builder
+	for _, column := range slices.Sorted(maps.Keys(record)) {
+		fmt.Fprintf(&b, "%q=%q;", column, record[column])
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package csvstore
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestDiffStores(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+	otherDir := getTestDir() + "-other"
+	defer os.RemoveAll(otherDir)
+
+	for _, dir := range []string{testDir, otherDir} {
+		store, err := NewCSVStore(dir)
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		if err := store.CreateTable("tags", []string{"tag"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		for _, user := range []CSVRecord{{"id": "1", "name": "alice"}, {"id": "2", "name": "bob"}} {
+			if _, err := store.Insert("users", user); err != nil {
+				t.Fatalf("Failed to insert record: %v", err)
+			}
+		}
+		if _, err := store.Insert("tags", CSVRecord{"tag": "x"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+		store.Close()
+	}
+
+	diff, err := DiffStores(testDir, otherDir)
+	if err != nil {
+		t.Fatalf("Failed to diff stores: %v", err)
+	}
+	if !diff.Equal() {
+		t.Errorf("Expected equal stores, got %+v", diff)
+	}
+
+	other, err := NewCSVStore(otherDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer other.Close()
+	if _, err := other.Update("users", CSVRecord{"name": "robert"}, []QueryCondition{{Column: "id", Operator: "=", Value: "2"}}); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if _, err := other.Delete("users", []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}); err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+	if _, err := other.Insert("users", CSVRecord{"id": "3", "name": "carol"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := other.Insert("tags", CSVRecord{"tag": "y"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if err := other.CreateTable("extra", []string{"id"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	diff, err = store.Diff(other)
+	if err != nil {
+		t.Fatalf("Failed to diff stores: %v", err)
+	}
+	if !slices.Equal(diff.TablesAdded, []string{"extra"}) || len(diff.TablesRemoved) != 0 {
+		t.Errorf("Expected table extra to be added, got %+v", diff)
+	}
+	if len(diff.Tables) != 2 || diff.Tables[0].Table != "tags" || diff.Tables[1].Table != "users" {
+		t.Fatalf("Expected tags and users to differ, got %+v", diff.Tables)
+	}
+	tags := diff.Tables[0]
+	if len(tags.Added) != 1 || tags.Added[0]["tag"] != "y" || len(tags.Removed) != 0 {
+		t.Errorf("Expected tag y to be added, got %+v", tags)
+	}
+	users := diff.Tables[1]
+	if len(users.Removed) != 1 || users.Removed[0]["id"] != "1" {
+		t.Errorf("Expected user 1 to be removed, got %+v", users.Removed)
+	}
+	if len(users.Added) != 1 || users.Added[0]["id"] != "3" {
+		t.Errorf("Expected user 3 to be added, got %+v", users.Added)
+	}
+	if len(users.Changed) != 1 || users.Changed[0].After["name"] != "robert" {
+		t.Errorf("Expected user 2 to be changed, got %+v", users.Changed)
+	}
+
+	if _, err := json.Marshal(diff); err != nil {
+		t.Errorf("Failed to marshal diff: %v", err)
+	}
+}
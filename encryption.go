@@ -0,0 +1,264 @@
+package csvstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// encryptedSegmentSize is the amount of plaintext sealed in one encrypted segment
+const encryptedSegmentSize = 64 << 10
+
+// encryptedSegmentHeader is the size of the length prefix and nonce of a segment
+const encryptedSegmentHeader = 4 + 12
+
+// finalSegmentFlag marks the length prefix of the last segment of a write. It is
+// authenticated as part of the additional data of the segment.
+const finalSegmentFlag = 1 << 31
+
+// KeyProvider supplies the AES key of encrypted tables. Keys must be 16, 24, or
+// 32 bytes long, selecting AES-128, AES-192, or AES-256.
+type KeyProvider interface {
+	TableKey(tableName string) ([]byte, error)
+}
+
+// KeyProviderFunc adapts a function to the KeyProvider interface
+type KeyProviderFunc func(tableName string) ([]byte, error)
+
+// TableKey returns the key of a table
+func (f KeyProviderFunc) TableKey(tableName string) ([]byte, error) {
+	return f(tableName)
+}
+
+// StaticKey returns a KeyProvider using the same key for every table
+func StaticKey(key []byte) KeyProvider {
+	return KeyProviderFunc(func(string) ([]byte, error) {
+		return key, nil
+	})
+}
+
+// WithEncryption encrypts new files of a table with AES-GCM, using keys from
+// provider. Encrypted table files carry a .enc extension and are decrypted
+// transparently on read. Existing table files keep the format they were written
+// in, but reading an encrypted file always requires this option. Reading fails
+// for files cut short, or holding segments of another table or partition, except
+// for files cut back exactly to the end of an earlier write.
+func WithEncryption(provider KeyProvider) TableOption {
+	return func(c *tableConfig) {
+		c.keyProvider = provider
+	}
+}
+
+// tableCipher returns the AEAD used to encrypt the files of a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tableCipher(tableName string) (cipher.AEAD, error) {
+	provider := cs.tableConfig(tableName).keyProvider
+	if provider == nil {
+		return nil, fmt.Errorf("table %s is encrypted but has no key provider (WithEncryption)", tableName)
+	}
+
+	key, err := provider.TableKey(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key for table %s: %w", tableName, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key for table %s: %w", tableName, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key for table %s: %w", tableName, err)
+	}
+
+	return aead, nil
+}
+
+// encryptionScope returns the name the segments of an encrypted file of a table
+// are bound to: the table or partition the file holds rows of. Segment files of
+// segmented tables keep the binding of the table file they were rotated from.
+func encryptionScope(tableName, name string) string {
+	scope, _, _ := parseTableFileName(path.Base(name))
+	if suffix, found := strings.CutPrefix(scope, tableName+segmentPrefix); found && segmentPattern.MatchString(suffix) {
+		return tableName
+	}
+	return scope
+}
+
+// segmentAdditionalData binds an encrypted segment to the table or partition of
+// its file, its position, and whether it ends a write, so segments cannot be
+// moved between files or reordered, and files cannot be cut after any segment
+func segmentAdditionalData(scope string, index uint64, final bool) []byte {
+	data := make([]byte, 0, len(scope)+9)
+	data = append(data, scope...)
+	data = binary.BigEndian.AppendUint64(data, index)
+	if final {
+		return append(data, 1)
+	}
+	return append(data, 0)
+}
+
+// segmentLength returns the ciphertext length of the segment with header, and
+// whether the segment is final
+func segmentLength(header []byte) (uint32, bool) {
+	length := binary.BigEndian.Uint32(header)
+	return length &^ finalSegmentFlag, length&finalSegmentFlag != 0
+}
+
+// encryptingWriter seals written data into encrypted segments, each made of a
+// ciphertext length, a nonce, and the ciphertext. The last segment of every
+// write is sealed as final.
+type encryptingWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	scope string
+	index uint64
+	buf   []byte
+	// closed is set once the final segment is sealed
+	closed bool
+}
+
+// newEncryptingWriter returns a writer sealing segments bound to scope onto w,
+// numbering them from index
+func newEncryptingWriter(w io.Writer, aead cipher.AEAD, scope string, index uint64) *encryptingWriter {
+	return &encryptingWriter{w: w, aead: aead, scope: scope, index: index}
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	e.buf = append(e.buf, p...)
+	// Keep the last full segment until Close, which seals it as final
+	for len(e.buf) > encryptedSegmentSize {
+		if err := e.seal(e.buf[:encryptedSegmentSize], false); err != nil {
+			return 0, err
+		}
+		e.buf = e.buf[encryptedSegmentSize:]
+	}
+	return len(p), nil
+}
+
+// Close seals the remaining data, possibly none, as the final segment; it does
+// not close the underlying writer
+func (e *encryptingWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	err := e.seal(e.buf, true)
+	e.buf, e.closed = nil, true
+	return err
+}
+
+// seal writes plaintext as the next segment
+func (e *encryptingWriter) seal(plaintext []byte, final bool) error {
+	header := make([]byte, encryptedSegmentHeader)
+	nonce := header[4:]
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ciphertext := e.aead.Seal(nil, nonce, plaintext, segmentAdditionalData(e.scope, e.index, final))
+	length := uint32(len(ciphertext))
+	if final {
+		length |= finalSegmentFlag
+	}
+	binary.BigEndian.PutUint32(header, length)
+	if _, err := e.w.Write(header); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(ciphertext); err != nil {
+		return err
+	}
+
+	e.index++
+	return nil
+}
+
+// decryptingReader reads the plaintext of encrypted segments, which must end
+// with a final segment
+type decryptingReader struct {
+	r         io.Reader
+	aead      cipher.AEAD
+	scope     string
+	index     uint64
+	final     bool // Whether the last segment read was final
+	plaintext []byte
+}
+
+// newDecryptingReader returns a reader decrypting the segments bound to scope
+// read from r
+func newDecryptingReader(r io.Reader, aead cipher.AEAD, scope string) *decryptingReader {
+	return &decryptingReader{r: r, aead: aead, scope: scope}
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.plaintext) == 0 {
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plaintext)
+	d.plaintext = d.plaintext[n:]
+	return n, nil
+}
+
+// open decrypts the next segment
+func (d *decryptingReader) open() error {
+	header := make([]byte, encryptedSegmentHeader)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		if errors.Is(err, io.EOF) {
+			if !d.final {
+				return errors.New("failed to read encrypted table file: file is truncated")
+			}
+			return io.EOF
+		}
+		return fmt.Errorf("failed to read encrypted table file: %w", err)
+	}
+
+	length, final := segmentLength(header)
+	if length > encryptedSegmentSize+uint32(d.aead.Overhead()) {
+		return errors.New("failed to read encrypted table file: invalid segment length")
+	}
+	ciphertext := make([]byte, length)
+	if _, err := io.ReadFull(d.r, ciphertext); err != nil {
+		return fmt.Errorf("failed to read encrypted table file: %w", err)
+	}
+
+	plaintext, err := d.aead.Open(nil, header[4:], ciphertext, segmentAdditionalData(d.scope, d.index, final))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt table file: %w", err)
+	}
+
+	d.index++
+	d.final = final
+	d.plaintext = plaintext
+	return nil
+}
+
+// countEncryptedSegments returns the number of segments of an encrypted file
+func countEncryptedSegments(fsys FS, name string) (uint64, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open table file: %w", err)
+	}
+	defer file.Close()
+
+	var count uint64
+	header := make([]byte, encryptedSegmentHeader)
+	for {
+		if _, err := io.ReadFull(file, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+			return 0, fmt.Errorf("failed to read encrypted table file: %w", err)
+		}
+		length, _ := segmentLength(header)
+		if _, err := io.CopyN(io.Discard, file, int64(length)); err != nil {
+			return 0, fmt.Errorf("failed to read encrypted table file: %w", err)
+		}
+		count++
+	}
+}
@@ -0,0 +1,169 @@
+package csvstore
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryption(t *testing.T) {
+	testDir := getTestDir()
+	key := bytes.Repeat([]byte{7}, 32)
+	store, err := NewCSVStore(testDir, WithTableDefaults(WithEncryption(StaticKey(key))))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+	store.ConfigureTable("patients", WithCompression(GzipCompression))
+
+	for _, tableName := range []string{"people", "patients"} {
+		if err := store.CreateTable(tableName, []string{"name", "ssn"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+
+	// Enough data for several encrypted segments
+	padding := strings.Repeat("x", 200)
+	for i := 0; i < 1000; i++ {
+		if _, err := store.Insert("people", CSVRecord{"name": "person" + padding, "ssn": "123-45-6789"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := store.Insert("patients", CSVRecord{"name": "Alice", "ssn": "987-65-4321"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Update("people", CSVRecord{"ssn": "000-00-0000"}, nil); err != nil {
+		t.Fatalf("Failed to update records: %v", err)
+	}
+	if _, err := store.Insert("people", CSVRecord{"name": "Bob", "ssn": "111-22-3333"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	for tableName, extension := range map[string]string{"people": ".csv.enc", "patients": ".csv.gz.enc"} {
+		path := store.GetTablePath(tableName)
+		if !strings.HasSuffix(path, extension) {
+			t.Errorf("Expected table file with extension %s, got %s", extension, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read table file: %v", err)
+		}
+		// Markers long enough not to turn up in random ciphertext
+		if bytes.Contains(data, []byte("name,ssn")) || bytes.Contains(data, []byte("123-45-6789")) {
+			t.Errorf("Expected table %s to be encrypted on disk", tableName)
+		}
+	}
+
+	people, err := store.Query("people", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if people.Count != 1001 || people.Records[0]["ssn"] != "000-00-0000" || people.Records[1000]["name"] != "Bob" {
+		t.Errorf("Unexpected records: count %d", people.Count)
+	}
+	patients, err := store.Query("patients", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if patients.Count != 1 || patients.Records[0]["ssn"] != "987-65-4321" {
+		t.Errorf("Unexpected records: %v", patients.Records)
+	}
+
+	// Without the key, or with a different key, encrypted tables are unreadable
+	noKey, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	if _, err := noKey.Query("patients", nil); err == nil {
+		t.Errorf("Expected an error reading an encrypted table without a key")
+	}
+	wrongKey, err := NewCSVStore(testDir, WithTableDefaults(WithEncryption(StaticKey(bytes.Repeat([]byte{8}, 32)))))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	if _, err := wrongKey.Query("patients", nil); err == nil {
+		t.Errorf("Expected an error reading an encrypted table with the wrong key")
+	}
+}
+
+func TestEncryptionTampering(t *testing.T) {
+	testDir := getTestDir()
+	key := bytes.Repeat([]byte{7}, 32)
+	store, err := NewCSVStore(testDir, WithTableDefaults(WithEncryption(StaticKey(key))))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+	store.ConfigureTable("orders", WithPartitioning("region", PartitionByValue))
+	store.ConfigureTable("logs", WithSegmentSize(100))
+
+	if err := store.CreateTable("people", []string{"name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if _, err := store.Insert("people", CSVRecord{"name": "person"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	// Rewrite the table as one write of several segments
+	if _, err := store.Update("people", CSVRecord{"name": strings.Repeat("x", 200)}, nil); err != nil {
+		t.Fatalf("Failed to update records: %v", err)
+	}
+	if err := store.CreateTable("orders", []string{"id", "region"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, region := range []string{"eu", "us"} {
+		if _, err := store.Insert("orders", CSVRecord{"id": region, "region": region}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	// Segment files stay bound to the table file they were rotated from
+	if err := store.CreateTable("logs", []string{"message"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := store.Insert("logs", CSVRecord{"message": strings.Repeat("m", 50)}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if segments, _ := filepath.Glob(filepath.Join(testDir, "logs__seg*.csv.enc")); len(segments) == 0 {
+		t.Errorf("Expected segment files")
+	}
+	if logs, err := store.Query("logs", nil); err != nil || logs.Count != 5 {
+		t.Errorf("Expected the records of every segment, got %v", err)
+	}
+
+	// Cutting the file after a segment that does not end it
+	path := store.GetTablePath("people")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	length, final := segmentLength(data)
+	if final {
+		t.Fatalf("Expected the table file to hold several segments")
+	}
+	if err := os.WriteFile(path, data[:encryptedSegmentHeader+length], 0o644); err != nil {
+		t.Fatalf("Failed to truncate table file: %v", err)
+	}
+	if _, err := store.Query("people", nil); err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("Expected an error reading a truncated table, got %v", err)
+	}
+
+	// Swapping the files of two partitions
+	eu := filepath.Join(testDir, "orders__eu.csv.enc")
+	us := filepath.Join(testDir, "orders__us.csv.enc")
+	euData, err := os.ReadFile(eu)
+	if err != nil {
+		t.Fatalf("Failed to read partition file: %v", err)
+	}
+	if err := os.WriteFile(us, euData, 0o644); err != nil {
+		t.Fatalf("Failed to overwrite partition file: %v", err)
+	}
+	if _, err := store.Query("orders", []QueryCondition{{Column: "region", Operator: "=", Value: "us"}}); err == nil {
+		t.Errorf("Expected an error reading a partition with the segments of another")
+	}
+}
@@ -0,0 +1,196 @@
+package csvstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ErasureMode selects how Erase scrubs the rows of a subject
+type ErasureMode int
+
+const (
+	// EraseDelete deletes the rows of the subject
+	EraseDelete ErasureMode = iota
+	// EraseRedact keeps the rows but overwrites their values
+	EraseRedact
+)
+
+// DefaultRedaction is the value Erase writes over erased cells by default
+const DefaultRedaction = "[ERASED]"
+
+// EraseOptions controls Erase
+type EraseOptions struct {
+	Mode ErasureMode
+	// Redaction replaces erased cells; DefaultRedaction when empty
+	Redaction string
+	// Columns lists the columns to overwrite. When empty, every column is
+	// overwritten except the reserved columns (see WithReservedColumns) and
+	// history metadata.
+	Columns []string
+}
+
+// ErasureReport describes the changes made by Erase
+type ErasureReport struct {
+	Tables           map[string]int // Erased rows per table
+	Total            int            // Erased rows across all tables
+	ChangeLogEntries int            // Redacted change log entries
+}
+
+// erasureKeptColumns are never overwritten unless listed in EraseOptions.Columns,
+// along with the reserved columns of the table
+var erasureKeptColumns = []string{historyOpColumn, historyChangedAtColumn}
+
+// Erase scrubs every row whose keyColumn equals keyValue, in every table that has
+// keyColumn, including history tables, to honor right-to-be-forgotten requests.
+// Rows are deleted or redacted according to opts; no hooks, triggers, or history
+// run. Entries of the change log about the subject are redacted in place, and the
+// change events emitted for the erasure carry redacted records only. Backups taken
+// earlier are not modified.
+func (cs *CSVStore) Erase(keyColumn string, keyValue string, opts EraseOptions) (*ErasureReport, error) {
+	op := Operation{Name: OpErase, Payload: opts}
+	return runOperation(cs, op, func() (*ErasureReport, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.erase(keyColumn, keyValue, opts)
+	})
+}
+
+// erase scrubs the rows of a subject across all tables.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) erase(keyColumn string, keyValue string, opts EraseOptions) (*ErasureReport, error) {
+	if keyColumn == "" || keyValue == "" {
+		return nil, errors.New("erasure requires a key column and value")
+	}
+	if opts.Redaction == "" {
+		opts.Redaction = DefaultRedaction
+	}
+	redact := func(tableName string, record CSVRecord) CSVRecord {
+		reserved := cs.reservedColumns(tableName)
+		kept := append([]string{reserved.ID, reserved.CreatedAt, reserved.UpdatedAt}, erasureKeptColumns...)
+		redacted := maps.Clone(record)
+		for column := range redacted {
+			if len(opts.Columns) > 0 && !slices.Contains(opts.Columns, column) {
+				continue
+			}
+			if len(opts.Columns) == 0 && slices.Contains(kept, column) {
+				continue
+			}
+			redacted[column] = opts.Redaction
+		}
+		return redacted
+	}
+	matches := func(record CSVRecord) bool {
+		value, exists := record[keyColumn]
+		return exists && value == keyValue
+	}
+
+	report := &ErasureReport{Tables: make(map[string]int)}
+	if cs.changeLog {
+		redacted, err := cs.redactChangeLog(matches, redact)
+		if err != nil {
+			return nil, err
+		}
+		report.ChangeLogEntries = redacted
+	}
+
+	tables, err := cs.listTables()
+	if err != nil {
+		return nil, err
+	}
+	for _, tableName := range tables {
+		headers, err := cs.getHeaders(tableName)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(headers, keyColumn) {
+			continue
+		}
+		records, err := cs.loadTable(tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		kept := make([]CSVRecord, 0, len(records))
+		erased := make([]CSVRecord, 0)
+		for _, record := range records {
+			if !matches(record) {
+				kept = append(kept, record)
+				continue
+			}
+			redacted := redact(tableName, record)
+			erased = append(erased, redacted)
+			if opts.Mode == EraseRedact {
+				kept = append(kept, redacted)
+			}
+		}
+		if len(erased) == 0 {
+			continue
+		}
+
+		if err := cs.saveTable(tableName, headers, kept); err != nil {
+			return nil, err
+		}
+		changeOp := ChangeDelete
+		if opts.Mode == EraseRedact {
+			changeOp = ChangeUpdate
+		}
+		if err := cs.emitChanges(changeOp, tableName, erased); err != nil {
+			return nil, err
+		}
+
+		report.Tables[tableName] = len(erased)
+		report.Total += len(erased)
+	}
+
+	return report, nil
+}
+
+// redactChangeLog rewrites the change log with the records of matching entries
+// redacted, and returns the number of redacted entries.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) redactChangeLog(
+	matches func(CSVRecord) bool,
+	redact func(string, CSVRecord) CSVRecord,
+) (int, error) {
+	tempName := ".changes-" + randomSuffix()
+	file, err := cs.fs.Create(tempName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create change log: %w", err)
+	}
+	defer cs.fs.Remove(tempName)
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	redacted := 0
+	err = cs.readChangeLog(func(event ChangeEvent) error {
+		if event.Record != nil && matches(event.Record) {
+			event.Record = redact(event.Table, event.Record)
+			redacted++
+		}
+		return encoder.Encode(event)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if redacted == 0 {
+		return 0, nil
+	}
+
+	if err := writer.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write change log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write change log: %w", err)
+	}
+	if err := cs.fs.Rename(tempName, ChangeLogFileName); err != nil {
+		return 0, fmt.Errorf("failed to replace change log: %w", err)
+	}
+
+	return redacted, nil
+}
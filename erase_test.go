@@ -0,0 +1,90 @@
+package csvstore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestErase(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir, WithChangeLog())
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+	store.ConfigureTable("users", WithHistory())
+
+	if err := store.CreateTable("users", []string{"id", "email", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := store.CreateTable("orders", []string{"email", "item"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := store.CreateTable("products", []string{"name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	if _, err := store.Insert("users", CSVRecord{"id": "1", "email": "alice@example.com", "name": "Alice"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Insert("users", CSVRecord{"id": "2", "email": "bob@example.com", "name": "Bob"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Update("users", CSVRecord{"name": "Alice Smith"}, []QueryCondition{
+		{Column: "id", Operator: "=", Value: "1"},
+	}); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	for _, item := range []string{"book", "pen"} {
+		if _, err := store.Insert("orders", CSVRecord{"email": "alice@example.com", "item": item}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	report, err := store.Erase("email", "alice@example.com", EraseOptions{Mode: EraseRedact})
+	if err != nil {
+		t.Fatalf("Failed to erase subject: %v", err)
+	}
+	if report.Tables["users"] != 1 || report.Tables[HistoryTableName("users")] != 1 || report.Tables["orders"] != 2 {
+		t.Errorf("Unexpected erasure report: %+v", report)
+	}
+	if report.Total != 4 || report.ChangeLogEntries != 4 {
+		t.Errorf("Unexpected erasure totals: %+v", report)
+	}
+
+	users, err := store.Query("users", []QueryCondition{{Column: "id", Operator: "=", Value: "1"}})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if users.Count != 1 || users.Records[0]["email"] != DefaultRedaction || users.Records[0]["name"] != DefaultRedaction {
+		t.Errorf("Expected a redacted record, got %v", users.Records)
+	}
+
+	for _, name := range []string{store.GetTablePath("users"), store.GetTablePath(HistoryTableName("users")),
+		store.GetTablePath("orders"), filepath.Join(testDir, ChangeLogFileName)} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		if strings.Contains(string(data), "alice@example.com") || strings.Contains(string(data), "Alice") {
+			t.Errorf("Expected no trace of the subject in %s", name)
+		}
+	}
+
+	report, err = store.Erase("email", "bob@example.com", EraseOptions{})
+	if err != nil {
+		t.Fatalf("Failed to erase subject: %v", err)
+	}
+	if report.Total != 1 {
+		t.Errorf("Expected 1 erased record, got %+v", report)
+	}
+	remaining, err := store.Query("users", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if remaining.Count != 1 {
+		t.Errorf("Expected the deleted subject to be gone, got %v", remaining.Records)
+	}
+}
@@ -0,0 +1,138 @@
+package csvstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// JSONFormat selects the layout produced by ExportJSON
+type JSONFormat int
+
+const (
+	// JSONArray writes a single JSON array of objects
+	JSONArray JSONFormat = iota
+	// NDJSON writes one JSON object per line
+	NDJSON
+)
+
+// JSONExportOptions controls ExportJSON
+type JSONExportOptions struct {
+	Format JSONFormat
+	// NativeTypes writes cells holding numbers as JSON numbers and cells holding
+	// "true" or "false" as JSON booleans instead of strings
+	NativeTypes bool
+}
+
+// ExportJSON writes the records of a table matching conditions to w as JSON objects
+// whose keys follow the column order of the table
+func (cs *CSVStore) ExportJSON(
+	tableName string,
+	w io.Writer,
+	conditions []QueryCondition,
+	opts JSONExportOptions,
+) error {
+	_, err := runOperation(cs, Operation{Name: OpExportJSON, Table: tableName, Payload: conditions},
+		func() (any, error) {
+			cs.mu.RLock()
+			defer cs.mu.RUnlock()
+
+			return nil, cs.exportJSON(tableName, w, conditions, opts)
+		})
+	return err
+}
+
+// exportJSON writes matching records of a table to w as JSON.
+// The caller must hold cs.mu.
+func (cs *CSVStore) exportJSON(
+	tableName string,
+	w io.Writer,
+	conditions []QueryCondition,
+	opts JSONExportOptions,
+) error {
+	writer := bufio.NewWriter(w)
+	if opts.Format == JSONArray {
+		writer.WriteString("[")
+	}
+
+	var headers []string
+	count := 0
+	mask := cs.recordMasker(tableName)
+	err := cs.scanTable(tableName, conditions,
+		func(h []string) error {
+			headers = h
+			return nil
+		},
+		func(record CSVRecord) error {
+			object, err := encodeJSONObject(headers, mask(record), opts.NativeTypes)
+			if err != nil {
+				return err
+			}
+			if opts.Format == JSONArray {
+				if count > 0 {
+					writer.WriteString(",")
+				}
+				writer.WriteString("\n  ")
+				writer.Write(object)
+			} else {
+				writer.Write(object)
+				writer.WriteString("\n")
+			}
+			count++
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	if opts.Format == JSONArray {
+		if count > 0 {
+			writer.WriteString("\n")
+		}
+		writer.WriteString("]\n")
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	return nil
+}
+
+// encodeJSONObject encodes a record as a JSON object with keys in header order
+func encodeJSONObject(headers []string, record CSVRecord, nativeTypes bool) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, header := range headers {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode column %s: %w", header, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(encodeJSONValue(record[header], nativeTypes))
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// encodeJSONValue encodes a cell as a JSON value
+func encodeJSONValue(value string, nativeTypes bool) []byte {
+	if nativeTypes {
+		if value == "true" || value == "false" {
+			return []byte(value)
+		}
+		// json.Valid rejects forms like "007" or "+1" that would not round-trip
+		if _, err := strconv.ParseFloat(value, 64); err == nil && json.Valid([]byte(value)) {
+			return []byte(value)
+		}
+	}
+	encoded, _ := json.Marshal(value)
+	return encoded
+}
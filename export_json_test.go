@@ -0,0 +1,54 @@
+package csvstore
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExportJSON(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "products"
+	if err := store.CreateTable(tableName, []string{"name", "price", "in_stock", "sku"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	records := []CSVRecord{
+		{"name": "Laptop", "price": "999.99", "in_stock": "true", "sku": "007"},
+		{"name": "Book", "price": "19.99", "in_stock": "false", "sku": "042"},
+	}
+	for _, record := range records {
+		if _, err := store.Insert(tableName, record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	var array strings.Builder
+	err = store.ExportJSON(tableName, &array, nil, JSONExportOptions{Format: JSONArray})
+	if err != nil {
+		t.Fatalf("Failed to export JSON: %v", err)
+	}
+	expected := "[\n" +
+		`  {"name":"Laptop","price":"999.99","in_stock":"true","sku":"007"},` + "\n" +
+		`  {"name":"Book","price":"19.99","in_stock":"false","sku":"042"}` + "\n" +
+		"]\n"
+	if array.String() != expected {
+		t.Errorf("Unexpected JSON array:\n%s\nexpected:\n%s", array.String(), expected)
+	}
+
+	var ndjson strings.Builder
+	conditions := []QueryCondition{{Column: "name", Operator: "=", Value: "Laptop"}}
+	err = store.ExportJSON(tableName, &ndjson, conditions, JSONExportOptions{Format: NDJSON, NativeTypes: true})
+	if err != nil {
+		t.Fatalf("Failed to export NDJSON: %v", err)
+	}
+	expected = `{"name":"Laptop","price":999.99,"in_stock":true,"sku":"007"}` + "\n"
+	if ndjson.String() != expected {
+		t.Errorf("Unexpected NDJSON:\n%s\nexpected:\n%s", ndjson.String(), expected)
+	}
+}
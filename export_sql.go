@@ -0,0 +1,100 @@
+package csvstore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SQLDialect selects the SQL flavor produced by ExportSQL
+type SQLDialect string
+
+// Supported SQL dialects
+const (
+	DialectSQLite   SQLDialect = "sqlite"
+	DialectPostgres SQLDialect = "postgres"
+	DialectMySQL    SQLDialect = "mysql"
+)
+
+// ExportSQL writes a SQL dump of a table to w: a CREATE TABLE statement with one
+// TEXT column per header, followed by one INSERT statement per record, wrapped in
+// a transaction.
+func (cs *CSVStore) ExportSQL(tableName string, w io.Writer, dialect SQLDialect) error {
+	_, err := runOperation(cs, Operation{Name: OpExportSQL, Table: tableName, Payload: dialect},
+		func() (any, error) {
+			cs.mu.RLock()
+			defer cs.mu.RUnlock()
+
+			return nil, cs.exportSQL(tableName, w, dialect)
+		})
+	return err
+}
+
+// exportSQL writes a SQL dump of a table to w.
+// The caller must hold cs.mu.
+func (cs *CSVStore) exportSQL(tableName string, w io.Writer, dialect SQLDialect) error {
+	if dialect != DialectSQLite && dialect != DialectPostgres && dialect != DialectMySQL {
+		return fmt.Errorf("unsupported SQL dialect %q", dialect)
+	}
+
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return err
+	}
+	records, err := cs.loadTable(tableName)
+	if err != nil {
+		return err
+	}
+
+	quotedTable := quoteSQLIdentifier(tableName, dialect)
+	quotedColumns := make([]string, len(headers))
+	columnDefs := make([]string, len(headers))
+	for i, header := range headers {
+		quotedColumns[i] = quoteSQLIdentifier(header, dialect)
+		columnDefs[i] = "  " + quotedColumns[i] + " TEXT"
+	}
+	columnList := strings.Join(quotedColumns, ", ")
+
+	writer := bufio.NewWriter(w)
+	if dialect == DialectMySQL {
+		fmt.Fprintln(writer, "START TRANSACTION;")
+	} else {
+		fmt.Fprintln(writer, "BEGIN;")
+	}
+	fmt.Fprintf(writer, "CREATE TABLE %s (\n%s\n);\n", quotedTable, strings.Join(columnDefs, ",\n"))
+
+	mask := cs.recordMasker(tableName)
+	values := make([]string, len(headers))
+	for _, record := range records {
+		record = mask(record)
+		for i, header := range headers {
+			values[i] = quoteSQLString(record[header], dialect)
+		}
+		fmt.Fprintf(writer, "INSERT INTO %s (%s) VALUES (%s);\n",
+			quotedTable, columnList, strings.Join(values, ", "))
+	}
+	fmt.Fprintln(writer, "COMMIT;")
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write SQL dump: %w", err)
+	}
+
+	return nil
+}
+
+// quoteSQLIdentifier quotes a table or column name for a dialect
+func quoteSQLIdentifier(name string, dialect SQLDialect) string {
+	if dialect == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteSQLString quotes a string literal for a dialect
+func quoteSQLString(value string, dialect SQLDialect) string {
+	if dialect == DialectMySQL {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
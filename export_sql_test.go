@@ -0,0 +1,48 @@
+package csvstore
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExportSQL(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "products"
+	if err := store.CreateTable(tableName, []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert(tableName, CSVRecord{"id": "1", "name": `O'Reilly \ Sons`}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	var postgres strings.Builder
+	if err := store.ExportSQL(tableName, &postgres, DialectPostgres); err != nil {
+		t.Fatalf("Failed to export SQL: %v", err)
+	}
+	expected := "BEGIN;\n" +
+		"CREATE TABLE \"products\" (\n  \"id\" TEXT,\n  \"name\" TEXT\n);\n" +
+		"INSERT INTO \"products\" (\"id\", \"name\") VALUES ('1', 'O''Reilly \\ Sons');\n" +
+		"COMMIT;\n"
+	if postgres.String() != expected {
+		t.Errorf("Unexpected postgres dump:\n%s\nexpected:\n%s", postgres.String(), expected)
+	}
+
+	var mysql strings.Builder
+	if err := store.ExportSQL(tableName, &mysql, DialectMySQL); err != nil {
+		t.Fatalf("Failed to export SQL: %v", err)
+	}
+	if !strings.Contains(mysql.String(), "INSERT INTO `products` (`id`, `name`) VALUES ('1', 'O''Reilly \\\\ Sons');") {
+		t.Errorf("Unexpected mysql dump:\n%s", mysql.String())
+	}
+
+	if err := store.ExportSQL(tableName, &mysql, "oracle"); err == nil {
+		t.Error("Expected error for unsupported dialect")
+	}
+}
@@ -0,0 +1,292 @@
+package csvstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// ExportStore writes a zip archive of the whole store to w: every table file,
+// including partitions and segments, the metadata files such as the change log
+// and compactions.json, and the tables of the tenants. The archive holds a
+// BackupManifest entry listing its files and their checksums. Snapshots and
+// checksums.json, which the importing store rebuilds, are left out. Writes are
+// blocked while the archive is produced. Tables configured with encryption stay
+// encrypted, so the importing store needs the same keys.
+func (cs *CSVStore) ExportStore(w io.Writer) error {
+	_, err := runOperation(cs, Operation{Name: OpExportStore}, func() (any, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		return nil, cs.exportStore(w)
+	})
+	return err
+}
+
+// exportStore writes a zip archive of the store to w.
+// The caller must hold cs.mu.
+func (cs *CSVStore) exportStore(w io.Writer) error {
+	names, err := cs.exportFiles(".")
+	if err != nil {
+		return err
+	}
+
+	manifest := &BackupManifest{
+		CreatedAt:      time.Now(),
+		ChangeSequence: cs.changeSeq,
+		Files:          names,
+		Checksums:      make(map[string]string, len(names)),
+	}
+	for _, name := range names {
+		checksum, err := fileChecksum(cs.fs, name)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		manifest.Checksums[name] = checksum
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export manifest: %w", err)
+	}
+
+	zipWriter := zip.NewWriter(w)
+	entry, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     BackupManifestName,
+		Method:   zip.Deflate,
+		Modified: manifest.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write export entry %s: %w", BackupManifestName, err)
+	}
+	if _, err := entry.Write(manifestData); err != nil {
+		return fmt.Errorf("failed to write export entry %s: %w", BackupManifestName, err)
+	}
+	for _, name := range names {
+		if err := writeZipFile(zipWriter, cs.fs, name); err != nil {
+			return err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write export archive: %w", err)
+	}
+	return nil
+}
+
+// exportFiles returns the paths of the files under the directory dir of the
+// store that ExportStore includes, skipping hidden entries such as snapshots
+// and staging directories, and checksums.json.
+// The caller must hold cs.mu.
+func (cs *CSVStore) exportFiles(dir string) ([]string, error) {
+	entries, err := cs.fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	files := make([]string, 0)
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		switch {
+		case strings.HasPrefix(entry.Name(), "."):
+		case entry.IsDir():
+			nested, err := cs.exportFiles(name)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, nested...)
+		case entry.Type().IsRegular() && entry.Name() != ChecksumsFileName:
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// writeZipFile writes a file of fsys to a zip archive
+func writeZipFile(zipWriter *zip.Writer, fsys FS, name string) error {
+	info, err := fsys.Stat(name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	file, err := fsys.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer file.Close()
+
+	entry, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: info.ModTime(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write export entry %s: %w", name, err)
+	}
+	if _, err := io.Copy(entry, file); err != nil {
+		return fmt.Errorf("failed to write export entry %s: %w", name, err)
+	}
+	return nil
+}
+
+// ImportStore adds the files of a zip archive produced by ExportStore to the
+// store and returns its manifest. The files are checked against the checksums
+// of the manifest, and the import fails without changing anything if any of
+// them already exists in the store. The archive is read into memory. Importing
+// runs no hooks or triggers and records no changes in the change log.
+func (cs *CSVStore) ImportStore(r io.Reader) (*BackupManifest, error) {
+	return runOperation(cs, Operation{Name: OpImportStore}, func() (*BackupManifest, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.importStore(r)
+	})
+}
+
+// importStore adds the files of an exported store to the store.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) importStore(r io.Reader) (*BackupManifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export archive: %w", err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export archive: %w", err)
+	}
+
+	var manifest *BackupManifest
+	entries := make(map[string]*zip.File, len(zipReader.File))
+	for _, entry := range zipReader.File {
+		name := entry.Name
+		if !entry.Mode().IsRegular() || !isExportFileName(name) {
+			return nil, fmt.Errorf("unexpected entry %q in export archive", name)
+		}
+		if name == BackupManifestName {
+			manifest, err = readZipManifest(entry)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		entries[name] = entry
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("export archive has no %s", BackupManifestName)
+	}
+	for _, name := range manifest.Files {
+		if _, ok := entries[name]; !ok {
+			return nil, fmt.Errorf("export archive is missing %s", name)
+		}
+		if cs.fileExists(name) {
+			return nil, fmt.Errorf("cannot import store: %s already exists", name)
+		}
+	}
+
+	// Extract into a staging directory first, so a broken archive leaves the
+	// store untouched
+	stagingDir, err := cs.makeTempDir(".import-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer cs.fs.Remove(stagingDir)
+
+	for _, name := range manifest.Files {
+		staged := path.Join(stagingDir, name)
+		if err := cs.makeDirs(path.Dir(staged)); err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		if err := extractZipFile(cs.fs, entries[name], staged); err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		checksum, err := fileChecksum(cs.fs, staged)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		if checksum != manifest.Checksums[name] {
+			return nil, fmt.Errorf("%s does not match its checksum in the export archive", name)
+		}
+	}
+
+	for _, name := range manifest.Files {
+		if err := cs.makeDirs(path.Dir(name)); err != nil {
+			return nil, fmt.Errorf("failed to import %s: %w", name, err)
+		}
+		if err := cs.fs.Rename(path.Join(stagingDir, name), name); err != nil {
+			return nil, fmt.Errorf("failed to import %s: %w", name, err)
+		}
+		if tableName, ok := tableNameFromFile(name); ok && isPlainFileName(name) {
+			cs.trackWrite(tableName)
+		}
+	}
+
+	if cs.changeLog {
+		seq, err := cs.lastChangeSequence()
+		if err != nil {
+			return nil, err
+		}
+		cs.changeSeq = max(cs.changeSeq, seq)
+	}
+
+	return manifest, nil
+}
+
+// readZipManifest decodes the manifest entry of an export archive
+func readZipManifest(entry *zip.File) (*BackupManifest, error) {
+	file, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export manifest: %w", err)
+	}
+	defer file.Close()
+
+	manifest := &BackupManifest{}
+	if err := json.NewDecoder(file).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to read export manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// extractZipFile writes the contents of a zip entry to a new file of fsys
+func extractZipFile(fsys FS, entry *zip.File, name string) error {
+	file, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return writeFileFrom(fsys, name, file)
+}
+
+// makeDirs creates the directory dir of the store and its parents if they do
+// not exist.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) makeDirs(dir string) error {
+	if dir == "." || cs.fileExists(dir) {
+		return nil
+	}
+	if err := cs.makeDirs(path.Dir(dir)); err != nil {
+		return err
+	}
+	if err := cs.fs.Mkdir(dir); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	return nil
+}
+
+// isExportFileName reports whether name is a valid path of a file in an export
+// archive: a relative path without hidden elements
+func isExportFileName(name string) bool {
+	if !fs.ValidPath(name) || name == "." || strings.Contains(name, `\`) {
+		return false
+	}
+	for _, element := range strings.Split(name, "/") {
+		if strings.HasPrefix(element, ".") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package csvstore
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestExportAndImportStore(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir, WithChangeLog())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	for _, tableName := range []string{"users", "orders"} {
+		if err := store.CreateTable(tableName, []string{"id", "name"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		if _, err := store.Insert(tableName, CSVRecord{"id": "1", "name": tableName}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	tenant, err := store.Tenant("acme")
+	if err != nil {
+		t.Fatalf("Failed to open tenant: %v", err)
+	}
+	if err := tenant.CreateTable("invoices", []string{"id", "total"}); err != nil {
+		t.Fatalf("Failed to create tenant table: %v", err)
+	}
+	if _, err := store.Snapshot("before"); err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+
+	var archive bytes.Buffer
+	if err := store.ExportStore(&archive); err != nil {
+		t.Fatalf("Failed to export store: %v", err)
+	}
+
+	target := NewMemoryStore(WithChangeLog(), WithChecksums())
+	defer target.Close()
+	manifest, err := target.ImportStore(bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to import store: %v", err)
+	}
+	expected := []string{ChangeLogFileName, "orders.csv", "tenants/acme/invoices.csv", "users.csv"}
+	if !slices.Equal(manifest.Files, expected) {
+		t.Errorf("Expected exported files %v, got %v", expected, manifest.Files)
+	}
+
+	tables, err := target.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if !slices.Equal(tables, []string{"orders", "users"}) {
+		t.Errorf("Expected imported tables, got %v", tables)
+	}
+	result, err := target.Query("users", nil)
+	if err != nil {
+		t.Fatalf("Failed to query imported table: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["name"] != "users" {
+		t.Errorf("Expected the imported record, got %v", result.Records)
+	}
+	if report, err := target.Verify(); err != nil || !report.OK() {
+		t.Errorf("Expected imported tables to verify, got %+v (err %v)", report, err)
+	}
+
+	// The change log continues after the imported changes
+	if _, err := target.Insert("users", CSVRecord{"id": "2", "name": "bob"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	seq, err := target.lastChangeSequence()
+	if err != nil {
+		t.Fatalf("Failed to read change log: %v", err)
+	}
+	if seq != manifest.ChangeSequence+1 {
+		t.Errorf("Expected the change log to continue at %d, got %d", manifest.ChangeSequence+1, seq)
+	}
+
+	importedTenant, err := target.Tenant("acme")
+	if err != nil {
+		t.Fatalf("Failed to open tenant: %v", err)
+	}
+	if tables, err := importedTenant.ListTables(); err != nil || !slices.Equal(tables, []string{"invoices"}) {
+		t.Errorf("Expected imported tenant tables, got %v (err %v)", tables, err)
+	}
+
+	// Importing over existing files fails
+	if _, err := target.ImportStore(bytes.NewReader(archive.Bytes())); err == nil {
+		t.Error("Expected error importing over existing tables")
+	}
+}
@@ -0,0 +1,224 @@
+package csvstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xlsxMaxSheetName is the maximum length of a worksheet name
+const xlsxMaxSheetName = 31
+
+// ExportXLSX writes an Excel workbook to w with one worksheet per table. Each
+// worksheet has a bold, frozen header row; all cells are written as text.
+func (cs *CSVStore) ExportXLSX(w io.Writer, tableNames ...string) error {
+	_, err := runOperation(cs, Operation{Name: OpExportXLSX, Payload: tableNames},
+		func() (any, error) {
+			cs.mu.RLock()
+			defer cs.mu.RUnlock()
+
+			return nil, cs.exportXLSX(w, tableNames)
+		})
+	return err
+}
+
+// exportXLSX writes an Excel workbook with one worksheet per table to w.
+// The caller must hold cs.mu.
+func (cs *CSVStore) exportXLSX(w io.Writer, tableNames []string) error {
+	if len(tableNames) == 0 {
+		return errors.New("at least one table is required")
+	}
+
+	zipWriter := zip.NewWriter(w)
+	sheetNames := make([]string, 0, len(tableNames))
+	for i, tableName := range tableNames {
+		headers, err := cs.getHeaders(tableName)
+		if err != nil {
+			return err
+		}
+		result, err := cs.query(tableName, nil)
+		if err != nil {
+			return err
+		}
+		cs.maskResult(tableName, result)
+
+		sheet, err := zipWriter.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return fmt.Errorf("failed to write workbook: %w", err)
+		}
+		if err := writeXLSXSheet(sheet, headers, result.Records); err != nil {
+			return fmt.Errorf("failed to write worksheet for table %s: %w", tableName, err)
+		}
+		sheetNames = append(sheetNames, xlsxSheetName(tableName, sheetNames))
+	}
+
+	parts := map[string]string{
+		"[Content_Types].xml":        xlsxContentTypes(len(sheetNames)),
+		"_rels/.rels":                xlsxRootRels,
+		"xl/workbook.xml":            xlsxWorkbook(sheetNames),
+		"xl/_rels/workbook.xml.rels": xlsxWorkbookRels(len(sheetNames)),
+		"xl/styles.xml":              xlsxStyles,
+	}
+	for _, name := range []string{
+		"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml",
+	} {
+		part, err := zipWriter.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to write workbook: %w", err)
+		}
+		if _, err := io.WriteString(part, parts[name]); err != nil {
+			return fmt.Errorf("failed to write workbook: %w", err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+
+	return nil
+}
+
+// writeXLSXSheet writes a worksheet holding a header row and records
+func writeXLSXSheet(w io.Writer, headers []string, records []CSVRecord) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	buf.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+	buf.WriteString(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	buf.WriteString(`</sheetView></sheetViews><sheetData>`)
+
+	writeRow := func(rowNumber int, values []string, style string) {
+		fmt.Fprintf(&buf, `<row r="%d">`, rowNumber)
+		for i, value := range values {
+			fmt.Fprintf(&buf, `<c r="%s%d" t="inlineStr"%s><is><t xml:space="preserve">`,
+				xlsxColumnName(i), rowNumber, style)
+			xml.EscapeText(&buf, []byte(value))
+			buf.WriteString(`</t></is></c>`)
+		}
+		buf.WriteString(`</row>`)
+	}
+
+	writeRow(1, headers, ` s="1"`)
+	values := make([]string, len(headers))
+	for i, record := range records {
+		for j, header := range headers {
+			values[j] = record[header]
+		}
+		writeRow(i+2, values, "")
+	}
+
+	buf.WriteString(`</sheetData></worksheet>`)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// xlsxColumnName returns the spreadsheet column name (A, B, ..., Z, AA, ...) of a zero-based index
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// xlsxSheetName turns a table name into a valid worksheet name not present in taken
+func xlsxSheetName(tableName string, taken []string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, tableName)
+	if name == "" {
+		name = "Sheet"
+	}
+
+	candidate := truncateRunes(name, xlsxMaxSheetName)
+	for i := 2; containsFold(taken, candidate); i++ {
+		suffix := " (" + strconv.Itoa(i) + ")"
+		candidate = truncateRunes(name, xlsxMaxSheetName-len(suffix)) + suffix
+	}
+	return candidate
+}
+
+// truncateRunes shortens s to at most n runes
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func xlsxContentTypes(sheets int) string {
+	var buf strings.Builder
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	buf.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	buf.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	buf.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	buf.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&buf, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	buf.WriteString(`</Types>`)
+	return buf.String()
+}
+
+const xlsxRootRels = xml.Header +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+func xlsxWorkbook(sheetNames []string) string {
+	var buf strings.Builder
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" `)
+	buf.WriteString(`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range sheetNames {
+		buf.WriteString(`<sheet name="`)
+		xml.EscapeText(&buf, []byte(name))
+		fmt.Fprintf(&buf, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+	}
+	buf.WriteString(`</sheets></workbook>`)
+	return buf.String()
+}
+
+func xlsxWorkbookRels(sheets int) string {
+	var buf strings.Builder
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&buf, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&buf, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	buf.WriteString(`</Relationships>`)
+	return buf.String()
+}
+
+// xlsxStyles defines a default cell style (0) and a bold header style (1)
+const xlsxStyles = xml.Header +
+	`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
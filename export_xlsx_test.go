@@ -0,0 +1,84 @@
+package csvstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExportXLSX(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	if err := store.CreateTable("users", []string{"name", "email"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := store.CreateTable("orders", []string{"item"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("users", CSVRecord{"name": "Tom & Jerry", "email": "tj@example.com"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := store.ExportXLSX(&buf, "users", "orders"); err != nil {
+		t.Fatalf("Failed to export XLSX: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to open workbook: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Name, err)
+		}
+		parts[file.Name] = string(data)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "xl/workbook.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("Expected workbook part %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="users"`) || !strings.Contains(parts["xl/workbook.xml"], `name="orders"`) {
+		t.Errorf("Expected one worksheet per table, got %s", parts["xl/workbook.xml"])
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `state="frozen"`) {
+		t.Errorf("Expected a frozen header row")
+	}
+	for _, cell := range []string{">name<", ">email<", ">Tom &amp; Jerry<", ">tj@example.com<"} {
+		if !strings.Contains(sheet, cell) {
+			t.Errorf("Expected worksheet to contain %s", cell)
+		}
+	}
+
+	if err := store.ExportXLSX(&buf, "missing"); err == nil {
+		t.Errorf("Expected an error when exporting a missing table")
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for index, expected := range cases {
+		if name := xlsxColumnName(index); name != expected {
+			t.Errorf("Expected column %d to be %s, got %s", index, expected, name)
+		}
+	}
+}
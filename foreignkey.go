@@ -0,0 +1,173 @@
+package csvstore
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// OnDeleteAction selects what happens to referencing rows when the row they
+// reference is deleted
+type OnDeleteAction int
+
+const (
+	// OnDeleteNoAction leaves referencing rows as they are
+	OnDeleteNoAction OnDeleteAction = iota
+	// OnDeleteCascade deletes referencing rows along with the referenced row
+	OnDeleteCascade
+	// OnDeleteSetEmpty detaches referencing rows by setting their referencing
+	// column to an empty value, or to the null sentinel of tables using WithNull
+	OnDeleteSetEmpty
+)
+
+// ForeignKey declares that a column of a table references rows of another table
+type ForeignKey struct {
+	Column     string // Referencing column of the configured table
+	References string // Referenced table
+	// ReferencedColumn is the referenced column; the id column of the referenced
+	// table when empty
+	ReferencedColumn string
+	OnDelete         OnDeleteAction
+}
+
+// WithForeignKey declares a foreign key on the table. Deleting rows of the
+// referenced table applies the OnDelete action to the referencing rows of this
+// table in the same operation, e.g. deleting a user with OnDeleteCascade also
+// deletes the user's orders. Cascades continue through the foreign keys of the
+// referencing table.
+func WithForeignKey(fk ForeignKey) TableOption {
+	return func(c *tableConfig) {
+		c.foreignKeys = append(c.foreignKeys, fk)
+	}
+}
+
+// applyOnDelete applies the OnDelete actions of the foreign keys referencing a
+// table to the rows referencing deleted records.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) applyOnDelete(tableName string, deleted []CSVRecord) error {
+	for _, child := range slices.Sorted(maps.Keys(cs.tableOptions)) {
+		for _, fk := range cs.tableConfig(child).foreignKeys {
+			if fk.References != tableName || fk.OnDelete == OnDeleteNoAction {
+				continue
+			}
+			if !cs.tableExists(child) {
+				continue
+			}
+
+			referenced := cs.referencedColumn(fk)
+			keys := make(map[string]bool)
+			for _, record := range deleted {
+				if key := record[referenced]; key != "" {
+					keys[key] = true
+				}
+			}
+			if len(keys) == 0 {
+				continue
+			}
+
+			column := cs.columnResolver(child)(fk.Column)
+			match := func(record CSVRecord) bool {
+				return keys[record[column]]
+			}
+			var err error
+			switch fk.OnDelete {
+			case OnDeleteCascade:
+				_, err = cs.deleteMatching(child, match)
+			case OnDeleteSetEmpty:
+				detach := CSVRecord{column: cs.tableConfig(child).null}
+				_, err = cs.updateMatching(child, func(record CSVRecord) CSVRecord {
+					if !match(record) {
+						return nil
+					}
+					return detach
+				})
+			default:
+				err = fmt.Errorf("unknown on delete action %d", fk.OnDelete)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to apply foreign key %s.%s: %w", child, fk.Column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// OrphanedRow is a row whose foreign key references a row that does not exist
+type OrphanedRow struct {
+	Table      string
+	ForeignKey ForeignKey
+	Record     CSVRecord
+}
+
+// CheckReferences scans the foreign keys declared with WithForeignKey and returns
+// every orphaned row, e.g. for auditing tables written before the foreign keys
+// were declared. Rows with an empty referencing column are not orphaned. Rows
+// referencing a table that does not exist are all orphaned.
+func (cs *CSVStore) CheckReferences() ([]OrphanedRow, error) {
+	return runOperation(cs, Operation{Name: OpCheckReferences}, func() ([]OrphanedRow, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		return cs.checkReferences()
+	})
+}
+
+// checkReferences returns the orphaned rows of every table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) checkReferences() ([]OrphanedRow, error) {
+	orphans := make([]OrphanedRow, 0)
+	for _, child := range slices.Sorted(maps.Keys(cs.tableOptions)) {
+		foreignKeys := cs.tableConfig(child).foreignKeys
+		if len(foreignKeys) == 0 || !cs.tableExists(child) {
+			continue
+		}
+		records, err := cs.loadTable(child)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fk := range foreignKeys {
+			keys, err := cs.referencedKeys(fk)
+			if err != nil {
+				return nil, err
+			}
+			column := cs.columnResolver(child)(fk.Column)
+			for _, record := range records {
+				if key := record[column]; key != "" && !keys[key] {
+					orphans = append(orphans, OrphanedRow{Table: child, ForeignKey: fk, Record: record})
+				}
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// referencedKeys returns the values of the column a foreign key references,
+// or no values when the referenced table does not exist.
+// The caller must hold cs.mu.
+func (cs *CSVStore) referencedKeys(fk ForeignKey) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	if !cs.tableExists(fk.References) {
+		return keys, nil
+	}
+
+	column := cs.referencedColumn(fk)
+	err := cs.scanTable(fk.References, nil, nil, func(record CSVRecord) error {
+		keys[record[column]] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// referencedColumn returns the column of the referenced table a foreign key references.
+// The caller must hold cs.mu.
+func (cs *CSVStore) referencedColumn(fk ForeignKey) string {
+	column := fk.ReferencedColumn
+	if column == "" {
+		column = cs.reservedColumns(fk.References).ID
+	}
+	return cs.columnResolver(fk.References)(column)
+}
@@ -0,0 +1,148 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestForeignKeyOnDelete(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("orders", WithForeignKey(ForeignKey{
+		Column:     "user_id",
+		References: "users",
+		OnDelete:   OnDeleteCascade,
+	}))
+	store.ConfigureTable("order_items", WithForeignKey(ForeignKey{
+		Column:     "order_id",
+		References: "orders",
+		OnDelete:   OnDeleteCascade,
+	}))
+	store.ConfigureTable("tickets", WithForeignKey(ForeignKey{
+		Column:           "owner",
+		References:       "users",
+		ReferencedColumn: "name",
+		OnDelete:         OnDeleteSetEmpty,
+	}))
+
+	tables := map[string][]string{
+		"users":       {"id", "name"},
+		"orders":      {"id", "user_id"},
+		"order_items": {"id", "order_id"},
+		"tickets":     {"id", "owner"},
+	}
+	for table, headers := range tables {
+		if err := store.CreateTable(table, headers); err != nil {
+			t.Fatalf("Failed to create table %s: %v", table, err)
+		}
+	}
+	inserts := []struct {
+		table  string
+		record CSVRecord
+	}{
+		{"users", CSVRecord{"id": "u1", "name": "alice"}},
+		{"users", CSVRecord{"id": "u2", "name": "bob"}},
+		{"orders", CSVRecord{"id": "o1", "user_id": "u1"}},
+		{"orders", CSVRecord{"id": "o2", "user_id": "u2"}},
+		{"order_items", CSVRecord{"id": "i1", "order_id": "o1"}},
+		{"order_items", CSVRecord{"id": "i2", "order_id": "o2"}},
+		{"tickets", CSVRecord{"id": "t1", "owner": "alice"}},
+		{"tickets", CSVRecord{"id": "t2", "owner": "bob"}},
+	}
+	for _, insert := range inserts {
+		if _, err := store.Insert(insert.table, insert.record); err != nil {
+			t.Fatalf("Failed to insert into %s: %v", insert.table, err)
+		}
+	}
+
+	result, err := store.Delete("users", []QueryCondition{{Column: "id", Operator: "=", Value: "u1"}})
+	if err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Expected 1 deleted user, got %d", result.Count)
+	}
+
+	expected := map[string][]string{
+		"orders":      {"o2"},
+		"order_items": {"i2"},
+		"tickets":     {"t1", "t2"},
+	}
+	for table, ids := range expected {
+		result, err := store.Query(table, nil)
+		if err != nil {
+			t.Fatalf("Failed to query %s: %v", table, err)
+		}
+		if result.Count != len(ids) {
+			t.Fatalf("Expected %d rows in %s, got %v", len(ids), table, result.Records)
+		}
+		for i, id := range ids {
+			if result.Records[i]["id"] != id {
+				t.Errorf("Expected %s in %s, got %v", id, table, result.Records[i])
+			}
+		}
+	}
+
+	tickets, err := store.Query("tickets", nil)
+	if err != nil {
+		t.Fatalf("Failed to query tickets: %v", err)
+	}
+	if tickets.Records[0]["owner"] != "" || tickets.Records[1]["owner"] != "bob" {
+		t.Errorf("Expected alice's ticket to be detached, got %v", tickets.Records)
+	}
+}
+
+func TestCheckReferences(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	for _, table := range []string{"users", "orders"} {
+		if err := store.CreateTable(table, []string{"id", "user_id"}); err != nil {
+			t.Fatalf("Failed to create table %s: %v", table, err)
+		}
+	}
+	inserts := []struct {
+		table  string
+		record CSVRecord
+	}{
+		{"users", CSVRecord{"id": "u1"}},
+		{"orders", CSVRecord{"id": "o1", "user_id": "u1"}},
+		{"orders", CSVRecord{"id": "o2", "user_id": "u9"}},
+		{"orders", CSVRecord{"id": "o3", "user_id": ""}},
+	}
+	for _, insert := range inserts {
+		if _, err := store.Insert(insert.table, insert.record); err != nil {
+			t.Fatalf("Failed to insert into %s: %v", insert.table, err)
+		}
+	}
+
+	orphans, err := store.CheckReferences()
+	if err != nil {
+		t.Fatalf("Failed to check references: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("Expected no orphans without foreign keys, got %v", orphans)
+	}
+
+	store.ConfigureTable("orders", WithForeignKey(ForeignKey{Column: "user_id", References: "users"}))
+	orphans, err = store.CheckReferences()
+	if err != nil {
+		t.Fatalf("Failed to check references: %v", err)
+	}
+	if len(orphans) != 1 || orphans[0].Table != "orders" || orphans[0].Record["id"] != "o2" {
+		t.Errorf("Expected order o2 to be orphaned, got %v", orphans)
+	}
+}
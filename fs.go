@@ -0,0 +1,140 @@
+package csvstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS is the file system a store keeps its files in. Names are slash-separated
+// paths relative to the store directory, as with fs.FS; "." names the store
+// directory itself.
+type FS interface {
+	// Open opens a file for reading
+	Open(name string) (io.ReadCloser, error)
+	// Create creates or truncates a file for writing
+	Create(name string) (io.WriteCloser, error)
+	// Append opens a file for writing at its end, creating it if needed
+	Append(name string) (io.WriteCloser, error)
+	// Rename renames a file or directory, replacing an existing file at newName
+	Rename(oldName, newName string) error
+	// Remove removes a file, or a directory and everything it contains
+	Remove(name string) error
+	// Stat describes a file
+	Stat(name string) (fs.FileInfo, error)
+	// ReadDir lists a directory, sorted by name
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// Mkdir creates a directory
+	Mkdir(name string) error
+}
+
+// WithFS keeps the files of the store in fsys instead of the local directory
+// passed to NewCSVStore. The directory path is still reported by GetTablePath.
+func WithFS(fsys FS) Option {
+	return func(cs *CSVStore) {
+		cs.fs = fsys
+	}
+}
+
+// osFS is an FS backed by a directory of the local file system
+type osFS struct {
+	root string
+}
+
+// OSFS returns an FS backed by the local directory root
+func OSFS(root string) FS {
+	return &osFS{root: root}
+}
+
+func (f *osFS) path(name string) string {
+	return filepath.Join(f.root, filepath.FromSlash(name))
+}
+
+func (f *osFS) Open(name string) (io.ReadCloser, error) {
+	return os.Open(f.path(name))
+}
+
+func (f *osFS) Create(name string) (io.WriteCloser, error) {
+	return os.Create(f.path(name))
+}
+
+func (f *osFS) Append(name string) (io.WriteCloser, error) {
+	return os.OpenFile(f.path(name), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
+func (f *osFS) Rename(oldName, newName string) error {
+	return os.Rename(f.path(oldName), f.path(newName))
+}
+
+func (f *osFS) Remove(name string) error {
+	if _, err := os.Lstat(f.path(name)); err != nil {
+		return err
+	}
+	return os.RemoveAll(f.path(name))
+}
+
+func (f *osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(f.path(name))
+}
+
+func (f *osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(f.path(name))
+}
+
+func (f *osFS) Mkdir(name string) error {
+	return os.Mkdir(f.path(name), 0755)
+}
+
+// fileExists reports whether a file exists in the store
+func (cs *CSVStore) fileExists(name string) bool {
+	_, err := cs.fs.Stat(name)
+	return err == nil
+}
+
+// readFile returns the contents of a file in the store
+func (cs *CSVStore) readFile(name string) ([]byte, error) {
+	file, err := cs.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// writeFile replaces the contents of a file in the store
+func (cs *CSVStore) writeFile(name string, data []byte) error {
+	file, err := cs.fs.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// makeTempDir creates a uniquely named directory in the store directory
+func (cs *CSVStore) makeTempDir(prefix string) (string, error) {
+	for {
+		name := prefix + randomSuffix()
+		err := cs.fs.Mkdir(name)
+		if err == nil {
+			return name, nil
+		}
+		if !cs.fileExists(name) {
+			return "", err
+		}
+	}
+}
+
+// randomSuffix returns a random string for temporary file names
+func randomSuffix() string {
+	suffix := make([]byte, 8)
+	rand.Read(suffix)
+	return hex.EncodeToString(suffix)
+}
@@ -0,0 +1,82 @@
+package csvstore
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestMemFSStore(t *testing.T) {
+	testDir := getTestDir()
+	fsys := NewMemFS()
+	store, err := NewCSVStore(testDir, WithFS(fsys), WithChangeLog())
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	if _, err := os.Stat(testDir); !os.IsNotExist(err) {
+		t.Errorf("Expected no directory on the local file system")
+	}
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	store.ConfigureTable("events", WithCompression(GzipCompression))
+	if err := store.CreateTable("events", []string{"kind"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, name := range []string{"Alice", "Bob"} {
+		if _, err := store.Insert("users", CSVRecord{"name": name}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := store.Insert("events", CSVRecord{"kind": "signup"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Delete("users", []QueryCondition{{Column: "name", Operator: "=", Value: "Bob"}}); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+
+	tables, err := store.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Errorf("Expected 2 tables, got %v", tables)
+	}
+	if _, err := fsys.Stat("events.csv.gz"); err != nil {
+		t.Errorf("Expected a compressed table file in the FS: %v", err)
+	}
+	if _, err := fsys.Stat(ChangeLogFileName); err != nil {
+		t.Errorf("Expected the change log in the FS: %v", err)
+	}
+
+	var backup bytes.Buffer
+	if err := store.Backup(&backup); err != nil {
+		t.Fatalf("Failed to back up store: %v", err)
+	}
+	if _, err := store.Insert("users", CSVRecord{"name": "Carol"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.RestoreBackup(&backup, RestoreOverwrite); err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+
+	users, err := store.Query("users", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if users.Count != 1 || users.Records[0]["name"] != "Alice" {
+		t.Errorf("Unexpected records after restore: %v", users.Records)
+	}
+	entries, err := fsys.ReadDir(".")
+	if err != nil {
+		t.Fatalf("Failed to read FS: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected staging files to be cleaned up, got %d entries", len(entries))
+	}
+
+	if _, err := store.WatchExternalChanges(); err == nil {
+		t.Errorf("Expected watching an in-memory FS to fail")
+	}
+}
@@ -0,0 +1,243 @@
+// Package gql generates a GraphQL schema from the typed schemas of csvstore
+// tables and serves it over HTTP, so frontends can query a store directly
+// while prototyping.
+//
+// Every table becomes an object type and a query field of the same name, e.g.
+//
+//	{ products(filter: [{column: price, op: ">=", value: "10"}], sort: price, order: DESC, limit: 5) { id name price } }
+//
+// Filters use the operators of csvstore.QueryCondition. Column types map to
+// GraphQL scalars: int to Int, float to Float, bool to Boolean, and every
+// other type to String. Empty cells are null.
+package gql
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/jiyeol-lee/csvstore"
+)
+
+// maxBodyBytes bounds the size of request bodies
+const maxBodyBytes = 1 << 20
+
+// invalidNameChars matches the characters GraphQL names cannot contain
+var invalidNameChars = regexp.MustCompile(`[^_0-9A-Za-z]`)
+
+// graphQLName turns a table or column name into a valid GraphQL name
+func graphQLName(name string) string {
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// typeName turns a table name into a GraphQL type name
+func typeName(tableName string) string {
+	name := graphQLName(tableName)
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// sortOrder is the enum of the order argument
+var sortOrder = graphql.NewEnum(graphql.EnumConfig{
+	Name: "SortOrder",
+	Values: graphql.EnumValueConfigMap{
+		"ASC":  &graphql.EnumValueConfig{Value: "asc"},
+		"DESC": &graphql.EnumValueConfig{Value: "desc"},
+	},
+})
+
+// NewSchema generates a GraphQL schema querying the tables of store, described
+// by their expected columns as for csvstore.CSVStore.CheckSchema
+func NewSchema(store *csvstore.CSVStore, tables map[string][]csvstore.ColumnDef) (graphql.Schema, error) {
+	if len(tables) == 0 {
+		return graphql.Schema{}, fmt.Errorf("no tables to expose")
+	}
+
+	fields := graphql.Fields{}
+	for _, tableName := range slices.Sorted(maps.Keys(tables)) {
+		name := graphQLName(tableName)
+		if _, ok := fields[name]; ok {
+			return graphql.Schema{}, fmt.Errorf("tables named %s in GraphQL clash", name)
+		}
+		field, err := tableField(store, tableName, tables[tableName])
+		if err != nil {
+			return graphql.Schema{}, err
+		}
+		fields[name] = field
+	}
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: fields}),
+	})
+	if err != nil {
+		return graphql.Schema{}, fmt.Errorf("failed to create schema: %w", err)
+	}
+	return schema, nil
+}
+
+// tableField builds the query field of a table
+func tableField(store *csvstore.CSVStore, tableName string, columns []csvstore.ColumnDef) (*graphql.Field, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s has no columns", tableName)
+	}
+	typ := typeName(tableName)
+
+	objectFields := graphql.Fields{}
+	enumValues := graphql.EnumValueConfigMap{}
+	for _, column := range columns {
+		name := graphQLName(column.Name)
+		if _, ok := objectFields[name]; ok {
+			return nil, fmt.Errorf("columns of table %s named %s in GraphQL clash", tableName, name)
+		}
+		objectFields[name] = columnField(column)
+		enumValues[name] = &graphql.EnumValueConfig{Value: column.Name}
+	}
+
+	columnEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name:   typ + "Column",
+		Values: enumValues,
+	})
+	filter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: typ + "Filter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"column": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(columnEnum)},
+			"op":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"value":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+	object := graphql.NewObject(graphql.ObjectConfig{
+		Name:   typ,
+		Fields: objectFields,
+	})
+
+	return &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(object))),
+		Args: graphql.FieldConfigArgument{
+			"filter": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(filter))},
+			"sort":   &graphql.ArgumentConfig{Type: columnEnum},
+			"order":  &graphql.ArgumentConfig{Type: sortOrder},
+			"limit":  &graphql.ArgumentConfig{Type: graphql.Int},
+			"offset": &graphql.ArgumentConfig{Type: graphql.Int},
+		},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			result, err := store.QueryWithOptions(tableName, queryOptions(p.Args))
+			if err != nil {
+				return nil, err
+			}
+			return result.Records, nil
+		},
+	}, nil
+}
+
+// queryOptions converts the arguments of a table field into query options
+func queryOptions(args map[string]any) csvstore.QueryOptions {
+	var opts csvstore.QueryOptions
+	if filters, ok := args["filter"].([]any); ok {
+		for _, filter := range filters {
+			filter := filter.(map[string]any)
+			condition := csvstore.QueryCondition{
+				Column:   filter["column"].(string),
+				Operator: filter["op"].(string),
+			}
+			if value, ok := filter["value"].(string); ok {
+				condition.Value = value
+			}
+			opts.Conditions = append(opts.Conditions, condition)
+		}
+	}
+	opts.SortField, _ = args["sort"].(string)
+	opts.SortBy, _ = args["order"].(string)
+	opts.Limit, _ = args["limit"].(int)
+	opts.Offset, _ = args["offset"].(int)
+	return opts
+}
+
+// columnField builds the field of a column, converting its values to the
+// GraphQL scalar of the column type
+func columnField(column csvstore.ColumnDef) *graphql.Field {
+	var typ graphql.Output = graphql.String
+	var parse func(string) (any, error)
+	switch column.Type {
+	case csvstore.TypeInt:
+		typ = graphql.Int
+		parse = func(value string) (any, error) { return strconv.Atoi(value) }
+	case csvstore.TypeFloat:
+		typ = graphql.Float
+		parse = func(value string) (any, error) { return strconv.ParseFloat(value, 64) }
+	case csvstore.TypeBool:
+		typ = graphql.Boolean
+		parse = func(value string) (any, error) { return strconv.ParseBool(value) }
+	}
+
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			value := p.Source.(csvstore.CSVRecord)[column.Name]
+			if value == "" {
+				return nil, nil
+			}
+			if parse == nil {
+				return value, nil
+			}
+			parsed, err := parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("value %q of column %s is not of type %s", value, column.Name, column.Type)
+			}
+			return parsed, nil
+		},
+	}
+}
+
+// request is the body of a GraphQL request
+type request struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// NewHandler returns an http.Handler serving the GraphQL schema generated for
+// the tables of store. It accepts POST requests with a JSON body holding query,
+// variables, and operationName, and GET requests with a query parameter.
+func NewHandler(store *csvstore.CSVStore, tables map[string][]csvstore.ColumnDef) (http.Handler, error) {
+	schema, err := NewSchema(store, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		switch r.Method {
+		case http.MethodGet:
+			req.Query = r.URL.Query().Get("query")
+			req.OperationName = r.URL.Query().Get("operationName")
+		case http.MethodPost:
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        r.Context(),
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}), nil
+}
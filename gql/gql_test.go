@@ -0,0 +1,183 @@
+package gql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+var testTables = map[string][]csvstore.ColumnDef{
+	"products": {
+		{Name: "id", Type: csvstore.TypeInt},
+		{Name: "name", Type: csvstore.TypeString},
+		{Name: "price", Type: csvstore.TypeFloat},
+		{Name: "in-stock", Type: csvstore.TypeBool},
+	},
+}
+
+type graphQLResponse struct {
+	Data struct {
+		Products []map[string]any `json:"products"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	store := csvstore.NewMemoryStore()
+	if err := store.CreateTable("products", []string{"id", "name", "price", "in-stock"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i, name := range []string{"pen", "desk", "lamp", "chair"} {
+		record := csvstore.CSVRecord{
+			"id":       fmt.Sprint(i + 1),
+			"name":     name,
+			"price":    fmt.Sprint(float64(i+1) * 10.5),
+			"in-stock": fmt.Sprint(i%2 == 0),
+		}
+		if name == "lamp" {
+			record["in-stock"] = ""
+		}
+		if _, err := store.Insert("products", record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	handler, err := NewHandler(store, testTables)
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(func() {
+		server.Close()
+		store.Close()
+	})
+	return server
+}
+
+func doQuery(t *testing.T, server *httptest.Server, query string) graphQLResponse {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{"query": query})
+	if err != nil {
+		t.Fatalf("Failed to encode request: %v", err)
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+	return decodeResponse(t, resp)
+}
+
+func decodeResponse(t *testing.T, resp *http.Response) graphQLResponse {
+	t.Helper()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		t.Fatalf("Expected status 200, got %d: %s", resp.StatusCode, data)
+	}
+	var result graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return result
+}
+
+func TestQueryTypes(t *testing.T) {
+	server := newTestServer(t)
+
+	result := doQuery(t, server, `{ products { id name price in_stock } }`)
+	if len(result.Errors) != 0 {
+		t.Fatalf("Failed to query: %v", result.Errors)
+	}
+	if len(result.Data.Products) != 4 {
+		t.Fatalf("Expected 4 products, got %d", len(result.Data.Products))
+	}
+
+	first := result.Data.Products[0]
+	if first["id"] != float64(1) || first["name"] != "pen" || first["price"] != 10.5 || first["in_stock"] != true {
+		t.Errorf("Unexpected first product: %v", first)
+	}
+	if lamp := result.Data.Products[2]; lamp["in_stock"] != nil {
+		t.Errorf("Expected empty cell to be null, got %v", lamp["in_stock"])
+	}
+}
+
+func TestQueryArguments(t *testing.T) {
+	server := newTestServer(t)
+
+	result := doQuery(t, server, `{
+		products(filter: [{column: price, op: ">", value: "15"}], sort: price, order: DESC, limit: 2, offset: 1) {
+			name
+		}
+	}`)
+	if len(result.Errors) != 0 {
+		t.Fatalf("Failed to query: %v", result.Errors)
+	}
+
+	var names []string
+	for _, product := range result.Data.Products {
+		names = append(names, product["name"].(string))
+	}
+	if strings.Join(names, ",") != "lamp,desk" {
+		t.Errorf("Expected lamp,desk, got %v", names)
+	}
+}
+
+func TestQueryGet(t *testing.T) {
+	server := newTestServer(t)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(server.URL + "?query=" + url.QueryEscape(`{ products(limit: 1) { name } }`))
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	result := decodeResponse(t, resp)
+	if len(result.Data.Products) != 1 || result.Data.Products[0]["name"] != "pen" {
+		t.Errorf("Unexpected products: %v", result.Data.Products)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	server := newTestServer(t)
+
+	result := doQuery(t, server, `{ products { unknown } }`)
+	if len(result.Errors) == 0 {
+		t.Error("Expected error for unknown field")
+	}
+
+	result = doQuery(t, server, `{ products(limit: -1) { name } }`)
+	if len(result.Errors) == 0 {
+		t.Error("Expected error for negative limit")
+	}
+}
+
+func TestNewSchemaErrors(t *testing.T) {
+	store := csvstore.NewMemoryStore()
+	defer store.Close()
+
+	if _, err := NewSchema(store, nil); err == nil {
+		t.Error("Expected error without tables")
+	}
+	clash := map[string][]csvstore.ColumnDef{
+		"t": {{Name: "a-b"}, {Name: "a_b"}},
+	}
+	if _, err := NewSchema(store, clash); err == nil {
+		t.Error("Expected error for clashing column names")
+	}
+}
@@ -0,0 +1,39 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnconditionalWrite is returned by Update and Delete called without
+// conditions on a store with WithWriteGuard
+var ErrUnconditionalWrite = errors.New("refusing to change every record without conditions")
+
+// allRowsOperator is the operator of the condition AllRows returns, which
+// matches every record
+const allRowsOperator = "all_rows"
+
+// WithWriteGuard makes Update, Delete, and DeleteCount fail with
+// ErrUnconditionalWrite when called with nil or empty conditions, so a
+// forgotten filter cannot change every record of a table. Pass AllRows() as
+// the conditions to change every record on purpose.
+func WithWriteGuard() Option {
+	return func(cs *CSVStore) {
+		cs.writeGuard = true
+	}
+}
+
+// AllRows returns conditions matching every record, to update or delete every
+// record of a table on a store with WithWriteGuard
+func AllRows() []QueryCondition {
+	return []QueryCondition{{Operator: allRowsOperator}}
+}
+
+// checkWriteGuard refuses updates and deletes without conditions on stores
+// with WithWriteGuard
+func (cs *CSVStore) checkWriteGuard(tableName string, conditions []QueryCondition) error {
+	if cs.writeGuard && len(conditions) == 0 {
+		return fmt.Errorf("table %s: %w; pass AllRows() to confirm", tableName, ErrUnconditionalWrite)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package csvstore
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWriteGuard(t *testing.T) {
+	store := NewMemoryStore(WithWriteGuard())
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name", "active"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if _, err := store.Insert("users", CSVRecord{"name": name, "active": "true"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	if _, err := store.Update("users", CSVRecord{"active": "false"}, nil); !errors.Is(err, ErrUnconditionalWrite) {
+		t.Errorf("Expected ErrUnconditionalWrite updating without conditions, got %v", err)
+	}
+	if _, err := store.Delete("users", []QueryCondition{}); !errors.Is(err, ErrUnconditionalWrite) {
+		t.Errorf("Expected ErrUnconditionalWrite deleting without conditions, got %v", err)
+	}
+	if _, err := store.DeleteCount("users", nil); !errors.Is(err, ErrUnconditionalWrite) {
+		t.Errorf("Expected ErrUnconditionalWrite deleting without conditions, got %v", err)
+	}
+	result, err := store.Query("users", []QueryCondition{{Column: "active", Operator: "=", Value: "true"}})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 3 {
+		t.Errorf("Expected the refused writes to change nothing, got %v", result.Records)
+	}
+
+	// Conditions and AllRows are accepted
+	conditions := []QueryCondition{{Column: "name", Operator: "=", Value: "bob"}}
+	if result, err := store.Update("users", CSVRecord{"active": "false"}, conditions); err != nil || result.Count != 1 {
+		t.Errorf("Expected 1 updated record, got %v, %v", result, err)
+	}
+	if result, err := store.Update("users", CSVRecord{"active": "false"}, AllRows()); err != nil || result.Count != 3 {
+		t.Errorf("Expected 3 updated records, got %v, %v", result, err)
+	}
+	if deleted, err := store.DeleteCount("users", AllRows()); err != nil || deleted != 3 {
+		t.Errorf("Expected 3 deleted records, got %d, %v", deleted, err)
+	}
+}
@@ -0,0 +1,105 @@
+package csvstore
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashAlgorithm selects how hashed columns are hashed
+type HashAlgorithm int
+
+const (
+	// HashSHA256 stores "sha256$<salt>$<digest>" with a random 16-byte salt per value
+	HashSHA256 HashAlgorithm = iota
+	// HashBcrypt stores a bcrypt hash with the default cost, suited for passwords
+	HashBcrypt
+)
+
+// sha256HashPrefix starts the values hashed with HashSHA256
+const sha256HashPrefix = "sha256$"
+
+// WithHashedColumn one-way hashes the values written to a column by Insert and
+// Update, after the before hooks ran, so plaintext secrets never reach the table
+// file. Empty values are stored as is. Use VerifyHash to check a value against
+// a stored hash.
+func WithHashedColumn(column string, algorithm HashAlgorithm) TableOption {
+	return func(c *tableConfig) {
+		if c.hashedColumns == nil {
+			c.hashedColumns = make(map[string]HashAlgorithm)
+		}
+		c.hashedColumns[column] = algorithm
+	}
+}
+
+// hashColumns replaces the values of hashed columns of a record with their
+// hashes, for the columns for which changed returns true
+func (c *tableConfig) hashColumns(record CSVRecord, changed func(column string) bool) error {
+	for column, algorithm := range c.hashedColumns {
+		value, exists := record[column]
+		if !exists || value == "" || !changed(column) {
+			continue
+		}
+		hashed, err := hashValue(value, algorithm)
+		if err != nil {
+			return fmt.Errorf("failed to hash column %s: %w", column, err)
+		}
+		record[column] = hashed
+	}
+	return nil
+}
+
+// hashValue hashes a value with algorithm
+func hashValue(value string, algorithm HashAlgorithm) (string, error) {
+	switch algorithm {
+	case HashSHA256:
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		return sha256HashPrefix + hex.EncodeToString(salt) + "$" + sha256Digest(salt, value), nil
+	case HashBcrypt:
+		hashed, err := bcrypt.GenerateFromPassword([]byte(value), bcrypt.DefaultCost)
+		return string(hashed), err
+	default:
+		return "", fmt.Errorf("unknown hash algorithm %d", algorithm)
+	}
+}
+
+// sha256Digest returns the hex encoded SHA-256 digest of salt followed by value
+func sha256Digest(salt []byte, value string) string {
+	digest := sha256.Sum256(append(append([]byte{}, salt...), value...))
+	return hex.EncodeToString(digest[:])
+}
+
+// VerifyHash reports whether value matches a hash stored in a hashed column
+func VerifyHash(hash string, value string) (bool, error) {
+	if rest, found := strings.CutPrefix(hash, sha256HashPrefix); found {
+		saltHex, digest, found := strings.Cut(rest, "$")
+		if !found {
+			return false, errors.New("malformed sha256 hash")
+		}
+		salt, err := hex.DecodeString(saltHex)
+		if err != nil {
+			return false, fmt.Errorf("malformed sha256 hash: %w", err)
+		}
+		expected := sha256Digest(salt, value)
+		return subtle.ConstantTimeCompare([]byte(expected), []byte(digest)) == 1, nil
+	}
+
+	if strings.HasPrefix(hash, "$2") {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(value))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	return false, errors.New("unrecognized hash format")
+}
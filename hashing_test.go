@@ -0,0 +1,72 @@
+package csvstore
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHashedColumns(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+	store.ConfigureTable("accounts",
+		WithHashedColumn("password", HashBcrypt),
+		WithHashedColumn("api_key", HashSHA256),
+	)
+
+	if err := store.CreateTable("accounts", []string{"name", "password", "api_key"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	inserted, err := store.Insert("accounts", CSVRecord{"name": "alice", "password": "s3cret", "api_key": "key-123"})
+	if err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if !strings.HasPrefix(inserted["password"], "$2") || !strings.HasPrefix(inserted["api_key"], "sha256$") {
+		t.Errorf("Expected hashed values, got %v", inserted)
+	}
+
+	data, err := os.ReadFile(store.GetTablePath("accounts"))
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	if strings.Contains(string(data), "s3cret") || strings.Contains(string(data), "key-123") {
+		t.Errorf("Expected no plaintext secrets in the table file")
+	}
+
+	for value, expected := range map[string]bool{"s3cret": true, "wrong": false} {
+		ok, err := VerifyHash(inserted["password"], value)
+		if err != nil {
+			t.Fatalf("Failed to verify hash: %v", err)
+		}
+		if ok != expected {
+			t.Errorf("Expected verification of %q to be %v", value, expected)
+		}
+	}
+	if ok, err := VerifyHash(inserted["api_key"], "key-123"); err != nil || !ok {
+		t.Errorf("Expected the API key to verify, got %v, %v", ok, err)
+	}
+
+	// Updating other columns leaves hashes untouched; updating a hashed column rehashes it
+	updated, err := store.Update("accounts", CSVRecord{"name": "alice2"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if updated.Records[0]["api_key"] != inserted["api_key"] {
+		t.Errorf("Expected the hash to be unchanged")
+	}
+	updated, err = store.Update("accounts", CSVRecord{"api_key": "key-456"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if ok, _ := VerifyHash(updated.Records[0]["api_key"], "key-456"); !ok {
+		t.Errorf("Expected the updated API key to verify, got %v", updated.Records[0])
+	}
+
+	if _, err := VerifyHash("plaintext", "plaintext"); err == nil {
+		t.Errorf("Expected an error for an unrecognized hash")
+	}
+}
@@ -0,0 +1,79 @@
+package csvstore
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// ValueCount is the number of records holding a value
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+// Histogram counts the values of a column over the records matching conditions,
+// most frequent first; values with the same count are ordered by value. topN
+// limits the result to the most frequent values when positive. Empty cells are
+// not counted. Values of masked columns are counted masked.
+func (cs *CSVStore) Histogram(
+	tableName string,
+	column string,
+	conditions []QueryCondition,
+	topN int,
+) ([]ValueCount, error) {
+	op := Operation{Name: OpHistogram, Table: tableName, Payload: conditions}
+	return runOperation(cs, op, func() ([]ValueCount, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		return cs.histogram(tableName, column, conditions, topN)
+	})
+}
+
+// histogram counts the values of a column over the records matching conditions.
+// The caller must hold cs.mu.
+func (cs *CSVStore) histogram(
+	tableName string,
+	column string,
+	conditions []QueryCondition,
+	topN int,
+) ([]ValueCount, error) {
+	resolve := cs.columnResolver(tableName)
+	column = resolve(column)
+	conditions = resolveConditions(conditions, resolve)
+	matcher := cs.newConditionMatcher(tableName)
+	mask := cs.recordMasker(tableName)
+
+	counts := make(map[string]int)
+	onHeaders := func(headers []string) error {
+		if !slices.Contains(headers, column) {
+			return fmt.Errorf("column %s does not exist in table %s", column, tableName)
+		}
+		return nil
+	}
+	err := cs.scanTable(tableName, conditions, onHeaders, func(record CSVRecord) error {
+		if value := mask(record)[column]; !matcher.isNull(value) {
+			counts[value]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	histogram := make([]ValueCount, 0, len(counts))
+	for value, count := range counts {
+		histogram = append(histogram, ValueCount{Value: value, Count: count})
+	}
+	slices.SortFunc(histogram, func(a, b ValueCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Value, b.Value)
+	})
+	if topN > 0 && len(histogram) > topN {
+		histogram = histogram[:topN]
+	}
+	return histogram, nil
+}
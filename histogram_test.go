@@ -0,0 +1,60 @@
+package csvstore
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("visits", []string{"id", "browser", "country"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	visits := []CSVRecord{
+		{"browser": "firefox", "country": "kr"},
+		{"browser": "chrome", "country": "kr"},
+		{"browser": "chrome", "country": "us"},
+		{"browser": "safari", "country": "kr"},
+		{"browser": "chrome", "country": "kr"},
+		{"browser": "firefox", "country": "us"},
+		{"browser": "", "country": "kr"},
+	}
+	for _, visit := range visits {
+		if _, err := store.Insert("visits", visit); err != nil {
+			t.Fatalf("Failed to insert visit: %v", err)
+		}
+	}
+
+	histogram, err := store.Histogram("visits", "browser", nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to compute histogram: %v", err)
+	}
+	expected := []ValueCount{{"chrome", 3}, {"firefox", 2}, {"safari", 1}}
+	if !slices.Equal(histogram, expected) {
+		t.Errorf("Expected %v, got %v", expected, histogram)
+	}
+
+	histogram, err = store.Histogram("visits", "browser", []QueryCondition{
+		{Column: "country", Operator: "=", Value: "kr"},
+	}, 2)
+	if err != nil {
+		t.Fatalf("Failed to compute histogram: %v", err)
+	}
+	expected = []ValueCount{{"chrome", 2}, {"firefox", 1}}
+	if !slices.Equal(histogram, expected) {
+		t.Errorf("Expected %v, got %v", expected, histogram)
+	}
+
+	if _, err := store.Histogram("visits", "missing", nil, 0); err == nil {
+		t.Errorf("Expected an error for a missing column")
+	}
+}
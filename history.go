@@ -0,0 +1,141 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// historySuffix is appended to a table name to form its history table name
+	historySuffix = "__history"
+
+	// historyOpColumn holds the operation that replaced a row version
+	historyOpColumn = "_op"
+	// historyChangedAtColumn holds the time a row version was replaced
+	historyChangedAtColumn = "_changed_at"
+
+	historyOpUpdate = "update"
+	historyOpDelete = "delete"
+)
+
+// HistoryTableName returns the name of the history table for a table
+func HistoryTableName(tableName string) string {
+	return tableName + historySuffix
+}
+
+// isHistoryTable reports whether a table is a history table
+func isHistoryTable(tableName string) bool {
+	return strings.HasSuffix(tableName, historySuffix)
+}
+
+// recordHistory appends the prior versions of records to the history table of tableName,
+// creating the history table when needed. It does nothing if history is disabled for the table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) recordHistory(
+	tableName string,
+	headers []string,
+	op string,
+	records []CSVRecord,
+) error {
+	if len(records) == 0 || isHistoryTable(tableName) || !cs.tableConfig(tableName).history {
+		return nil
+	}
+
+	historyTable := HistoryTableName(tableName)
+	historyHeaders, err := cs.getHeaders(historyTable)
+	if errors.Is(err, fs.ErrNotExist) {
+		historyHeaders = append([]string{historyOpColumn, historyChangedAtColumn}, headers...)
+		if err := cs.saveTable(historyTable, historyHeaders, nil); err != nil {
+			return fmt.Errorf("failed to create history table %s: %w", historyTable, err)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	changedAt := time.Now().Format(time.RFC3339Nano)
+	rows := make([][]string, 0, len(records))
+	for _, record := range records {
+		row := make([]string, len(historyHeaders))
+		for i, header := range historyHeaders {
+			switch header {
+			case historyOpColumn:
+				row[i] = op
+			case historyChangedAtColumn:
+				row[i] = changedAt
+			default:
+				row[i] = record[header]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if err := cs.appendRows(historyTable, rows); err != nil {
+		return fmt.Errorf("failed to write history for table %s: %w", tableName, err)
+	}
+
+	return nil
+}
+
+// History returns the prior versions of the record with the given id, oldest first.
+// Each version carries the operation that replaced it ("update" or "delete") in the _op
+// column and the time of that change in the _changed_at column.
+// History must be enabled for the table with WithHistory.
+func (cs *CSVStore) History(tableName string, id string) (*QueryResult, error) {
+	op := Operation{Name: OpHistory, Table: tableName, Payload: id}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		result, err := cs.history(tableName, id)
+		if err != nil {
+			return nil, err
+		}
+		return cs.maskResult(tableName, result), nil
+	})
+}
+
+// history returns the prior versions of the record with the given id.
+// The caller must hold cs.mu.
+func (cs *CSVStore) history(tableName string, id string) (*QueryResult, error) {
+	if !cs.tableConfig(tableName).history {
+		return nil, fmt.Errorf("history is not enabled for table %s", tableName)
+	}
+
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return nil, err
+	}
+	idColumn := cs.reservedColumns(tableName).ID
+	if !slices.Contains(headers, idColumn) {
+		return nil, fmt.Errorf("table %s has no %s column", tableName, idColumn)
+	}
+
+	historyTable := HistoryTableName(tableName)
+	if !cs.fileExists(cs.getTableFile(historyTable)) {
+		return &QueryResult{
+			Records: []CSVRecord{},
+			Count:   0,
+		}, nil
+	}
+
+	records, err := cs.loadTable(historyTable)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]CSVRecord, 0)
+	for _, record := range records {
+		if record[idColumn] == id {
+			versions = append(versions, record)
+		}
+	}
+
+	return &QueryResult{
+		Records: versions,
+		Count:   len(versions),
+	}, nil
+}
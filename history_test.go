@@ -0,0 +1,77 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir, WithTableDefaults(WithHistory()))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "users"
+	err = store.CreateTable(tableName, []string{"id", "name", "email"})
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	_, err = store.Insert(tableName, CSVRecord{"id": "1", "name": "John", "email": "john@example.com"})
+	if err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	// No history before any change
+	result, err := store.History(tableName, "1")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if result.Count != 0 {
+		t.Errorf("Expected 0 versions before any change, got %d", result.Count)
+	}
+
+	conditions := []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}
+	_, err = store.Update(tableName, CSVRecord{"email": "new@example.com"}, conditions)
+	if err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	_, err = store.Delete(tableName, conditions)
+	if err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+
+	if !store.CheckTableExists(HistoryTableName(tableName)) {
+		t.Fatalf("Expected history table %s to exist", HistoryTableName(tableName))
+	}
+
+	result, err = store.History(tableName, "1")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if result.Count != 2 {
+		t.Fatalf("Expected 2 versions, got %d", result.Count)
+	}
+
+	first, second := result.Records[0], result.Records[1]
+	if first["_op"] != "update" || first["email"] != "john@example.com" {
+		t.Errorf("Expected first version to be the pre-update row, got %v", first)
+	}
+	if second["_op"] != "delete" || second["email"] != "new@example.com" {
+		t.Errorf("Expected second version to be the pre-delete row, got %v", second)
+	}
+	if first["_changed_at"] == "" {
+		t.Error("Expected _changed_at to be set")
+	}
+
+	// History is not available when disabled
+	plainStore, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	if _, err := plainStore.History(tableName, "1"); err == nil {
+		t.Error("Expected error when history is not enabled")
+	}
+}
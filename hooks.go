@@ -0,0 +1,47 @@
+package csvstore
+
+import "fmt"
+
+// HookType identifies the point in a write operation at which a hook runs
+type HookType string
+
+// Lifecycle hook points
+const (
+	BeforeInsert HookType = "before_insert"
+	AfterInsert  HookType = "after_insert"
+	BeforeUpdate HookType = "before_update"
+	AfterUpdate  HookType = "after_update"
+	BeforeDelete HookType = "before_delete"
+	AfterDelete  HookType = "after_delete"
+)
+
+// Hook is called with a record during a write operation.
+//
+// Before hooks receive the prospective record: changes made by BeforeInsert and
+// BeforeUpdate hooks are written to the table, and returning an error vetoes the
+// whole operation before anything is written.
+// After hooks receive a copy of the written record; an error returned by an after
+// hook is returned to the caller, but the write has already happened.
+//
+// Hooks run while the store is locked and must not call back into the store.
+type Hook func(record CSVRecord) error
+
+// WithHook registers a lifecycle hook. Hooks of the same type run in registration order.
+func WithHook(hookType HookType, hook Hook) TableOption {
+	return func(c *tableConfig) {
+		if c.hooks == nil {
+			c.hooks = make(map[HookType][]Hook)
+		}
+		c.hooks[hookType] = append(c.hooks[hookType], hook)
+	}
+}
+
+// runHooks runs the hooks of a type against a record, stopping at the first error
+func (c *tableConfig) runHooks(tableName string, hookType HookType, record CSVRecord) error {
+	for _, hook := range c.hooks[hookType] {
+		if err := hook(record); err != nil {
+			return fmt.Errorf("%s hook failed for table %s: %w", hookType, tableName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "users"
+	err = store.CreateTable(tableName, []string{"id", "name", "email", "domain"})
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	errBlocked := errors.New("blocked")
+	deriveDomain := func(record CSVRecord) error {
+		record["email"] = strings.ToLower(record["email"])
+		if _, domain, ok := strings.Cut(record["email"], "@"); ok {
+			record["domain"] = domain
+		}
+		return nil
+	}
+	afterInserts := 0
+	store.ConfigureTable(tableName,
+		WithHook(BeforeInsert, deriveDomain),
+		WithHook(BeforeUpdate, deriveDomain),
+		WithHook(AfterInsert, func(record CSVRecord) error {
+			afterInserts++
+			return nil
+		}),
+		WithHook(BeforeDelete, func(record CSVRecord) error {
+			if record["name"] == "Admin" {
+				return errBlocked
+			}
+			return nil
+		}),
+	)
+
+	inserted, err := store.Insert(tableName, CSVRecord{"id": "1", "name": "John", "email": "John@Example.COM"})
+	if err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if inserted["email"] != "john@example.com" || inserted["domain"] != "example.com" {
+		t.Errorf("Expected BeforeInsert hook to normalize the record, got %v", inserted)
+	}
+	if afterInserts != 1 {
+		t.Errorf("Expected AfterInsert hook to run once, ran %d times", afterInserts)
+	}
+
+	updated, err := store.Update(
+		tableName,
+		CSVRecord{"email": "john@corp.io"},
+		[]QueryCondition{{Column: "id", Operator: "=", Value: "1"}},
+	)
+	if err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if updated.Records[0]["domain"] != "corp.io" {
+		t.Errorf("Expected BeforeUpdate hook to derive domain, got %v", updated.Records[0])
+	}
+
+	// A vetoing hook aborts the whole delete
+	if _, err := store.Insert(tableName, CSVRecord{"id": "2", "name": "Admin"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	_, err = store.Delete(tableName, nil)
+	if !errors.Is(err, errBlocked) {
+		t.Fatalf("Expected delete to be vetoed, got %v", err)
+	}
+	result, err := store.Query(tableName, nil)
+	if err != nil {
+		t.Fatalf("Failed to query table: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected vetoed delete to leave 2 records, got %d", result.Count)
+	}
+}
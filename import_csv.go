@@ -0,0 +1,151 @@
+package csvstore
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// CSVImportOptions controls ImportCSV
+type CSVImportOptions struct {
+	UnknownColumns UnknownColumnPolicy
+	// InferTypes declares the types InferSchema proposes from the imported rows
+	// on the table (see WithColumnTypes), for columns without a declared type,
+	// so later writes are checked against them
+	InferTypes bool
+}
+
+// ImportCSV inserts rows read from r into a table. The first row of r holds the
+// source column names. mapping renames source columns to table columns; source
+// columns missing from mapping keep their name, and columns mapped to "" are
+// dropped. Rows are inserted like with Insert, so ids and timestamps that the
+// source does not provide are filled in. Every row is checked before any is
+// written, so an import that fails, e.g. on a row rejected by a hook or
+// validator, leaves the table untouched. The inserted records are returned.
+func (cs *CSVStore) ImportCSV(
+	tableName string,
+	r io.Reader,
+	mapping map[string]string,
+	opts CSVImportOptions,
+) (*QueryResult, error) {
+	return runOperation(cs, Operation{Name: OpImportCSV, Table: tableName, Payload: opts},
+		func() (*QueryResult, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return cs.importCSV(tableName, r, mapping, opts)
+		})
+}
+
+// importCSV inserts rows read from CSV into a table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) importCSV(
+	tableName string,
+	r io.Reader,
+	mapping map[string]string,
+	opts CSVImportOptions,
+) (*QueryResult, error) {
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(skipBOM(r))
+	sourceHeaders, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &QueryResult{Records: []CSVRecord{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	// Resolve the table column of every source column; "" drops the column
+	columns := make([]string, len(sourceHeaders))
+	unknown := make([]string, 0)
+	for i, source := range sourceHeaders {
+		column := source
+		if target, mapped := mapping[source]; mapped {
+			column = target
+		}
+		if column == "" {
+			continue
+		}
+		if slices.Contains(columns, column) {
+			return nil, fmt.Errorf("more than one source column maps to column %s", column)
+		}
+		columns[i] = column
+		if !slices.Contains(headers, column) {
+			unknown = append(unknown, column)
+		}
+	}
+	var added []string
+	if len(unknown) > 0 {
+		switch opts.UnknownColumns {
+		case ErrorOnUnknownColumns:
+			return nil, fmt.Errorf("unknown columns for table %s: %s", tableName, strings.Join(unknown, ", "))
+		case AddUnknownColumns:
+			// Added along with the rows, once they are all read and checked
+			added = unknown
+		default:
+			for i, column := range columns {
+				if slices.Contains(unknown, column) {
+					columns[i] = ""
+				}
+			}
+		}
+	}
+
+	// Read everything before inserting, so malformed input leaves the table
+	// untouched; insertAll checks every record before writing any
+	rows := make([][]string, 0)
+	records := make([]CSVRecord, 0)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		record := make(CSVRecord)
+		for i, value := range row {
+			if columns[i] != "" {
+				record[columns[i]] = value
+			}
+		}
+		rows = append(rows, row)
+		records = append(records, record)
+	}
+
+	result, err := cs.insertAll(tableName, records, added)
+	if err != nil {
+		return nil, err
+	}
+	if opts.InferTypes {
+		cs.declareInferredTypes(tableName, columns, inferColumns(sourceHeaders, rows, cs.tableConfig(tableName).null))
+	}
+	return result, nil
+}
+
+// declareInferredTypes declares the inferred types of the source columns of
+// an import on the table columns they were imported into, skipping strings and
+// columns with a declared type.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) declareInferredTypes(tableName string, columns []string, inferred []InferredColumn) {
+	declared := cs.tableConfig(tableName).columnTypes
+	defs := make([]ColumnDef, 0)
+	for i, column := range inferred {
+		if _, exists := declared[columns[i]]; exists || columns[i] == "" || column.Type == TypeString {
+			continue
+		}
+		defs = append(defs, ColumnDef{Name: columns[i], Type: column.Type})
+	}
+	if len(defs) > 0 {
+		cs.tableOptions[tableName] = append(cs.tableOptions[tableName], WithColumnTypes(defs...))
+		cs.queryCache.clear()
+	}
+}
@@ -0,0 +1,118 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestImportCSV(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "contacts"
+	if err := store.CreateTable(tableName, []string{"id", "name", "email", "created_at"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	source := "Full Name,E-mail,Internal ID,Notes\n" +
+		"Alice,alice@example.com,A-1,vip\n" +
+		"Bob,bob@example.com,B-2,\n"
+	mapping := map[string]string{
+		"Full Name":   "name",
+		"E-mail":      "email",
+		"Internal ID": "",
+	}
+
+	_, err = store.ImportCSV(tableName, strings.NewReader(source), mapping, CSVImportOptions{
+		UnknownColumns: ErrorOnUnknownColumns,
+	})
+	if err == nil || !strings.Contains(err.Error(), "Notes") {
+		t.Errorf("Expected unknown column error for Notes, got %v", err)
+	}
+
+	result, err := store.ImportCSV(tableName, strings.NewReader(source), mapping, CSVImportOptions{})
+	if err != nil {
+		t.Fatalf("Failed to import CSV: %v", err)
+	}
+	if result.Count != 2 {
+		t.Fatalf("Expected 2 imported records, got %d", result.Count)
+	}
+	alice := result.Records[0]
+	if alice["name"] != "Alice" || alice["email"] != "alice@example.com" {
+		t.Errorf("Unexpected imported record: %v", alice)
+	}
+	if alice["id"] == "" || alice["created_at"] == "" {
+		t.Errorf("Expected reserved columns to be filled, got %v", alice)
+	}
+	if _, exists := alice["Notes"]; exists {
+		t.Errorf("Expected unknown column to be ignored, got %v", alice)
+	}
+
+	all, err := store.Query(tableName, nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if all.Count != 2 {
+		t.Errorf("Expected 2 records in table, got %d", all.Count)
+	}
+
+	_, err = store.ImportCSV(tableName, strings.NewReader(source), mapping, CSVImportOptions{
+		UnknownColumns: AddUnknownColumns,
+	})
+	if err != nil {
+		t.Fatalf("Failed to import CSV: %v", err)
+	}
+	headers, err := store.getHeaders(tableName)
+	if err != nil {
+		t.Fatalf("Failed to read headers: %v", err)
+	}
+	if headers[len(headers)-1] != "Notes" {
+		t.Errorf("Expected Notes column to be added, got %v", headers)
+	}
+}
+
+func TestImportCSVAtomic(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+	store.ConfigureTable("bookings", WithValidator(dateRangeValidator{}))
+	if err := store.CreateTable("bookings", []string{"id", "start_date", "end_date"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	// The second row fails validation after a new column was seen
+	input := "start_date,end_date,room\n2024-01-01,2024-01-03,a\n2024-02-05,2024-02-01,b\n"
+	_, err := store.ImportCSV("bookings", strings.NewReader(input), nil, CSVImportOptions{UnknownColumns: AddUnknownColumns})
+	if !errors.Is(err, errEndBeforeStart) {
+		t.Fatalf("Expected the validation error, got %v", err)
+	}
+	headers, err := store.getHeaders("bookings")
+	if err != nil {
+		t.Fatalf("Failed to get headers: %v", err)
+	}
+	if !slices.Equal(headers, []string{"id", "start_date", "end_date"}) {
+		t.Errorf("Expected the failed import not to add columns, got %v", headers)
+	}
+	result, err := store.Query("bookings", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 0 {
+		t.Errorf("Expected the failed import not to insert rows, got %v", result.Records)
+	}
+
+	input = "start_date,end_date,room\n2024-01-01,2024-01-03,a\n2024-02-01,2024-02-05,b\n"
+	result, err = store.ImportCSV("bookings", strings.NewReader(input), nil, CSVImportOptions{UnknownColumns: AddUnknownColumns})
+	if err != nil {
+		t.Fatalf("Failed to import CSV: %v", err)
+	}
+	if result.Count != 2 || result.Records[0]["id"] == result.Records[1]["id"] || result.Records[1]["room"] != "b" {
+		t.Errorf("Expected 2 records with distinct ids, got %v", result.Records)
+	}
+}
@@ -0,0 +1,276 @@
+package csvstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// UnknownColumnPolicy controls what imports do with source fields that
+// have no matching table column
+type UnknownColumnPolicy int
+
+const (
+	// IgnoreUnknownColumns drops fields without a matching column
+	IgnoreUnknownColumns UnknownColumnPolicy = iota
+	// ErrorOnUnknownColumns fails the import, before anything is inserted
+	ErrorOnUnknownColumns
+	// AddUnknownColumns appends missing columns to the table
+	AddUnknownColumns
+)
+
+// JSONImportOptions controls ImportJSON
+type JSONImportOptions struct {
+	UnknownColumns UnknownColumnPolicy
+}
+
+// ImportJSON inserts records read from r into a table. r holds either a JSON array
+// of objects or newline-delimited JSON objects. Object keys map to columns; numbers
+// and booleans are stored in their JSON form, null as an empty cell, and nested
+// objects or arrays as compact JSON. Records are inserted like with Insert, so ids
+// and timestamps are filled in. Every record is checked before any is written, so
+// an import that fails leaves the table untouched. The inserted records are
+// returned.
+func (cs *CSVStore) ImportJSON(
+	tableName string,
+	r io.Reader,
+	opts JSONImportOptions,
+) (*QueryResult, error) {
+	return runOperation(cs, Operation{Name: OpImportJSON, Table: tableName, Payload: opts},
+		func() (*QueryResult, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return cs.importJSON(tableName, r, opts)
+		})
+}
+
+// importJSON inserts records read from JSON into a table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) importJSON(
+	tableName string,
+	r io.Reader,
+	opts JSONImportOptions,
+) (*QueryResult, error) {
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, records, err := decodeJSONRecords(r)
+	if err != nil {
+		return nil, err
+	}
+
+	unknown := make([]string, 0)
+	for _, key := range keys {
+		if !slices.Contains(headers, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	var added []string
+	if len(unknown) > 0 {
+		switch opts.UnknownColumns {
+		case ErrorOnUnknownColumns:
+			return nil, fmt.Errorf("unknown columns for table %s: %s", tableName, strings.Join(unknown, ", "))
+		case AddUnknownColumns:
+			added = unknown
+		}
+	}
+
+	return cs.insertAll(tableName, records, added)
+}
+
+// insertAll inserts records into a table in one write, after appending columns
+// to its headers, and returns the inserted records. Every record is prepared and
+// checked before anything is written, so a failing record leaves the table
+// untouched.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) insertAll(tableName string, records []CSVRecord, columns []string) (*QueryResult, error) {
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return nil, err
+	}
+	newHeaders := slices.Clone(headers)
+	for _, column := range columns {
+		if !slices.Contains(newHeaders, column) {
+			newHeaders = append(newHeaders, column)
+		}
+	}
+
+	rows := make([][]string, 0, len(records))
+	inserted := make([]CSVRecord, 0, len(records))
+	usedIDs := make(map[string]bool)
+	for i, record := range records {
+		row, insertedRecord, err := cs.prepareInsert(tableName, newHeaders, record, usedIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert record %d: %w", i+1, err)
+		}
+		rows = append(rows, row)
+		inserted = append(inserted, insertedRecord)
+	}
+
+	if len(newHeaders) > len(headers) {
+		if err := cs.addColumns(tableName, newHeaders[len(headers):]); err != nil {
+			return nil, err
+		}
+	}
+	if len(rows) > 0 {
+		if err := cs.commitInserts(tableName, rows, inserted); err != nil {
+			return nil, err
+		}
+	}
+
+	return &QueryResult{
+		Records: inserted,
+		Count:   len(inserted),
+	}, nil
+}
+
+// addColumns appends columns to the headers of a table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) addColumns(tableName string, columns []string) error {
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return err
+	}
+	records, err := cs.loadTable(tableName)
+	if err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		if !slices.Contains(headers, column) {
+			headers = append(headers, column)
+		}
+	}
+
+	return cs.saveTable(tableName, headers, records)
+}
+
+// decodeJSONRecords decodes a JSON array of objects or newline-delimited JSON objects.
+// It returns the keys in order of first appearance along with the records.
+func decodeJSONRecords(r io.Reader) ([]string, []CSVRecord, error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	first, err := peekNonSpace(reader)
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read JSON: %w", err)
+	}
+
+	isArray := first == '['
+	if isArray {
+		if _, err := decoder.Token(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read JSON: %w", err)
+		}
+	}
+
+	keys := make([]string, 0)
+	records := make([]CSVRecord, 0)
+	for decoder.More() {
+		objectKeys, record, err := decodeJSONObject(decoder)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read JSON object %d: %w", len(records)+1, err)
+		}
+		for _, key := range objectKeys {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+		records = append(records, record)
+	}
+
+	if isArray {
+		if _, err := decoder.Token(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read JSON: %w", err)
+		}
+	}
+
+	return keys, records, nil
+}
+
+// peekNonSpace returns the first non-whitespace byte of r without consuming it
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+// decodeJSONObject decodes the next JSON object of a stream into a record,
+// returning its keys in order
+func decodeJSONObject(decoder *json.Decoder) ([]string, CSVRecord, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("expected an object, got %v", token)
+	}
+
+	keys := make([]string, 0)
+	record := make(CSVRecord)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+		value, err := jsonCellValue(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+
+		if _, seen := record[key]; !seen {
+			keys = append(keys, key)
+		}
+		record[key] = value
+	}
+
+	// Closing brace
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	return keys, record, nil
+}
+
+// jsonCellValue converts a JSON value to its cell representation
+func jsonCellValue(raw json.RawMessage) (string, error) {
+	trimmed := bytes.TrimSpace(raw)
+	switch {
+	case bytes.Equal(trimmed, []byte("null")):
+		return "", nil
+	case len(trimmed) > 0 && trimmed[0] == '"':
+		var value string
+		err := json.Unmarshal(trimmed, &value)
+		return value, err
+	case len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '['):
+		var compacted bytes.Buffer
+		err := json.Compact(&compacted, trimmed)
+		return compacted.String(), err
+	default:
+		// Numbers and booleans keep their JSON text
+		return string(trimmed), nil
+	}
+}
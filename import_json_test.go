@@ -0,0 +1,81 @@
+package csvstore
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestImportJSON(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "products"
+	if err := store.CreateTable(tableName, []string{"id", "name", "price"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	array := `[
+		{"name": "Laptop", "price": 999.99, "tags": ["a", "b"]},
+		{"name": "Book", "price": null, "active": true}
+	]`
+
+	// Unknown columns fail the import before anything is inserted
+	_, err = store.ImportJSON(tableName, strings.NewReader(array), JSONImportOptions{
+		UnknownColumns: ErrorOnUnknownColumns,
+	})
+	if err == nil || !strings.Contains(err.Error(), "tags, active") {
+		t.Errorf("Expected unknown column error listing tags and active, got %v", err)
+	}
+
+	result, err := store.ImportJSON(tableName, strings.NewReader(array), JSONImportOptions{
+		UnknownColumns: AddUnknownColumns,
+	})
+	if err != nil {
+		t.Fatalf("Failed to import JSON: %v", err)
+	}
+	if result.Count != 2 {
+		t.Fatalf("Expected 2 imported records, got %d", result.Count)
+	}
+	laptop := result.Records[0]
+	if laptop["price"] != "999.99" || laptop["tags"] != `["a","b"]` || laptop["id"] == "" {
+		t.Errorf("Unexpected imported record: %v", laptop)
+	}
+	if book := result.Records[1]; book["price"] != "" || book["active"] != "true" {
+		t.Errorf("Unexpected imported record: %v", book)
+	}
+
+	headers, err := store.getHeaders(tableName)
+	if err != nil {
+		t.Fatalf("Failed to read headers: %v", err)
+	}
+	if expected := []string{"id", "name", "price", "tags", "active"}; !slices.Equal(headers, expected) {
+		t.Errorf("Expected headers %v, got %v", expected, headers)
+	}
+
+	// NDJSON, ignoring unknown fields
+	ndjson := "{\"name\": \"Phone\", \"color\": \"black\"}\n{\"name\": \"Pen\"}\n"
+	result, err = store.ImportJSON(tableName, strings.NewReader(ndjson), JSONImportOptions{})
+	if err != nil {
+		t.Fatalf("Failed to import NDJSON: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected 2 imported records, got %d", result.Count)
+	}
+
+	all, err := store.Query(tableName, nil)
+	if err != nil {
+		t.Fatalf("Failed to query table: %v", err)
+	}
+	if all.Count != 4 {
+		t.Errorf("Expected 4 records in table, got %d", all.Count)
+	}
+	if _, exists := all.Records[2]["color"]; exists {
+		t.Error("Expected unknown color field to be ignored")
+	}
+}
@@ -0,0 +1,92 @@
+package csvstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultURLImportMaxBytes is the download size limit used by ImportFromURL
+// when URLImportOptions.MaxBytes is zero
+const DefaultURLImportMaxBytes = 32 << 20
+
+// URLImportOptions controls ImportFromURL
+type URLImportOptions struct {
+	// Client performs the request; http.DefaultClient is used when nil
+	Client *http.Client
+	// Timeout bounds the whole download; zero relies on ctx alone
+	Timeout time.Duration
+	// MaxBytes fails the import when the response body is larger
+	MaxBytes int64
+	// Mapping and CSV are passed to ImportCSV
+	Mapping map[string]string
+	CSV     CSVImportOptions
+}
+
+// ImportFromURL downloads a CSV document with a GET request and imports it into a
+// table like ImportCSV. The download finishes before the table is touched, so a
+// failed or oversized download leaves the table unchanged.
+func (cs *CSVStore) ImportFromURL(
+	ctx context.Context,
+	tableName string,
+	url string,
+	opts URLImportOptions,
+) (*QueryResult, error) {
+	if cs.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	body, err := downloadURL(ctx, url, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.ImportCSV(tableName, bytes.NewReader(body), opts.Mapping, opts.CSV)
+}
+
+// downloadURL fetches the body of url within the limits of opts
+func downloadURL(ctx context.Context, url string, opts URLImportOptions) ([]byte, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultURLImportMaxBytes
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("download of %s exceeds %d bytes", url, maxBytes)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("download of %s exceeds %d bytes", url, maxBytes)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,74 @@
+package csvstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportFromURL(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "rates"
+	if err := store.CreateTable(tableName, []string{"currency", "rate"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rates.csv":
+			w.Write([]byte("code,rate\nEUR,0.92\nJPY,151.3\n"))
+		case "/slow.csv":
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte("code,rate\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	result, err := store.ImportFromURL(ctx, tableName, server.URL+"/rates.csv", URLImportOptions{
+		Mapping: map[string]string{"code": "currency"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to import from URL: %v", err)
+	}
+	if result.Count != 2 || result.Records[0]["currency"] != "EUR" || result.Records[1]["rate"] != "151.3" {
+		t.Errorf("Unexpected imported records: %v", result.Records)
+	}
+
+	_, err = store.ImportFromURL(ctx, tableName, server.URL+"/rates.csv", URLImportOptions{MaxBytes: 10})
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("Expected size limit error, got %v", err)
+	}
+
+	_, err = store.ImportFromURL(ctx, tableName, server.URL+"/slow.csv", URLImportOptions{
+		Timeout: 20 * time.Millisecond,
+	})
+	if err == nil {
+		t.Errorf("Expected timeout error")
+	}
+
+	_, err = store.ImportFromURL(ctx, tableName, server.URL+"/missing.csv", URLImportOptions{})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+
+	all, err := store.Query(tableName, nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if all.Count != 2 {
+		t.Errorf("Expected failed imports to leave the table unchanged, got %d records", all.Count)
+	}
+}
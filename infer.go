@@ -0,0 +1,108 @@
+package csvstore
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// defaultInferSampleRows is the number of rows InferSchema samples by default
+const defaultInferSampleRows = 1000
+
+// inferredTypes lists the types InferSchema proposes, most specific first
+var inferredTypes = []ColumnType{TypeInt, TypeFloat, TypeBool, TypeTime}
+
+// InferredColumn is the column type InferSchema proposes for a column
+type InferredColumn struct {
+	ColumnDef
+	Nullable bool // Some sampled cells are null
+}
+
+// InferSchemaOptions configures InferSchema
+type InferSchemaOptions struct {
+	// SampleRows is the number of rows to sample after the header row. Zero
+	// samples the first 1000 rows, and a negative number every row.
+	SampleRows int
+	// Null is the null sentinel of the file, as with WithNull. Without one,
+	// empty cells are null.
+	Null string
+}
+
+// InferSchema samples the rows of CSV read from r, whose first row holds the
+// column names, and proposes a type for every column: the most specific of
+// int, float, bool, and time (RFC 3339) that every sampled value that is not
+// null has, or string. Columns holding only null values are strings. The
+// columns can be declared with WithColumnTypes or checked with CheckSchema.
+func InferSchema(r io.Reader, opts InferSchemaOptions) ([]InferredColumn, error) {
+	reader := csv.NewReader(skipBOM(r))
+	headers, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("failed to read CSV header: no rows")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	sampleRows := opts.SampleRows
+	if sampleRows == 0 {
+		sampleRows = defaultInferSampleRows
+	}
+	rows := make([][]string, 0)
+	for sampleRows < 0 || len(rows) < sampleRows {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		rows = append(rows, row)
+	}
+	return inferColumns(headers, rows, opts.Null), nil
+}
+
+// InferSchemaFile is InferSchema for the CSV file at path
+func InferSchemaFile(path string, opts InferSchemaOptions) ([]InferredColumn, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return InferSchema(file, opts)
+}
+
+// inferColumns proposes the types of columns from rows of values
+func inferColumns(headers []string, rows [][]string, null string) []InferredColumn {
+	columns := make([]InferredColumn, len(headers))
+	for i, header := range headers {
+		candidates := inferredTypes
+		nullable, empty := false, true
+		for _, row := range rows {
+			if i >= len(row) || row[i] == null {
+				nullable = true
+				continue
+			}
+			empty = false
+			remaining := make([]ColumnType, 0, len(candidates))
+			for _, candidate := range candidates {
+				if candidate.valid(row[i]) {
+					remaining = append(remaining, candidate)
+				}
+			}
+			candidates = remaining
+		}
+
+		columnType := TypeString
+		if !empty && len(candidates) > 0 {
+			columnType = candidates[0]
+		}
+		columns[i] = InferredColumn{
+			ColumnDef: ColumnDef{Name: header, Type: columnType},
+			Nullable:  nullable,
+		}
+	}
+	return columns
+}
@@ -0,0 +1,85 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	source := "id,price,active,joined,name,notes\n" +
+		"1,9.5,true,2024-01-02T03:04:05Z,pen,\n" +
+		"2,10,false,2024-02-03T04:05:06Z,desk,\n" +
+		"3,,true,,7,\n"
+	expected := []InferredColumn{
+		{ColumnDef: ColumnDef{Name: "id", Type: TypeInt}},
+		{ColumnDef: ColumnDef{Name: "price", Type: TypeFloat}, Nullable: true},
+		{ColumnDef: ColumnDef{Name: "active", Type: TypeBool}},
+		{ColumnDef: ColumnDef{Name: "joined", Type: TypeTime}, Nullable: true},
+		{ColumnDef: ColumnDef{Name: "name", Type: TypeString}},
+		{ColumnDef: ColumnDef{Name: "notes", Type: TypeString}, Nullable: true},
+	}
+	columns, err := InferSchema(strings.NewReader(source), InferSchemaOptions{})
+	if err != nil {
+		t.Fatalf("Failed to infer schema: %v", err)
+	}
+	if !slices.Equal(columns, expected) {
+		t.Errorf("Expected %v, got %v", expected, columns)
+	}
+
+	// Only sampled rows are considered
+	columns, err = InferSchema(strings.NewReader(source), InferSchemaOptions{SampleRows: 2})
+	if err != nil {
+		t.Fatalf("Failed to infer schema: %v", err)
+	}
+	if columns[4].Type != TypeString || columns[1].Nullable {
+		t.Errorf("Expected the first 2 rows to be sampled, got %v", columns)
+	}
+
+	path := filepath.Join(t.TempDir(), "items.csv")
+	if err := os.WriteFile(path, []byte("qty\nNULL\n3\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	columns, err = InferSchemaFile(path, InferSchemaOptions{Null: "NULL"})
+	if err != nil {
+		t.Fatalf("Failed to infer schema: %v", err)
+	}
+	if columns[0].Type != TypeInt || !columns[0].Nullable {
+		t.Errorf("Expected a nullable int column, got %v", columns)
+	}
+
+	if _, err := InferSchema(strings.NewReader(""), InferSchemaOptions{}); err == nil {
+		t.Error("Expected error inferring the schema of empty input")
+	}
+}
+
+func TestImportCSVInferTypes(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.CreateTable("items", []string{"id", "name", "qty"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	source := "Name,Quantity\npen,3\ndesk,\n"
+	mapping := map[string]string{"Name": "name", "Quantity": "qty"}
+	if _, err := store.ImportCSV("items", strings.NewReader(source), mapping, CSVImportOptions{InferTypes: true}); err != nil {
+		t.Fatalf("Failed to import CSV: %v", err)
+	}
+
+	if _, err := store.Insert("items", CSVRecord{"name": "lamp", "qty": "many"}); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch inserting a non-integer quantity, got %v", err)
+	}
+	if _, err := store.Insert("items", CSVRecord{"name": "12", "qty": "12"}); err != nil {
+		t.Errorf("Failed to insert record: %v", err)
+	}
+	conditions := []QueryCondition{{Column: "name", Operator: "=", Value: "pen"}}
+	if _, err := store.Update("items", CSVRecord{"qty": "x"}, conditions); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch updating a non-integer quantity, got %v", err)
+	}
+	if _, err := store.Update("items", CSVRecord{"qty": ""}, conditions); err != nil {
+		t.Errorf("Failed to update record: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package csvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+)
+
+// ChecksumsFileName is the name of the file holding the checksums of the table
+// files of a store using WithChecksums
+const ChecksumsFileName = "checksums.json"
+
+// WithChecksums keeps a SHA-256 checksum of every table file in checksums.json,
+// updated on every write, so Verify can detect files modified outside the store,
+// partial writes, or bit rot. Every write hashes the whole table file again.
+// When the store is opened without checksums.json, the current files are trusted
+// and checksummed.
+func WithChecksums() Option {
+	return func(cs *CSVStore) {
+		cs.checksums = make(map[string]string)
+	}
+}
+
+// IntegrityReport lists the tables whose files do not match their checksums
+type IntegrityReport struct {
+	Corrupted []string // Tables whose file differs from its checksum
+	Missing   []string // Tables with a checksum but no file
+	Untracked []string // Tables with a file but no checksum
+}
+
+// OK reports whether every table matches its checksum
+func (r *IntegrityReport) OK() bool {
+	return len(r.Corrupted) == 0 && len(r.Missing) == 0 && len(r.Untracked) == 0
+}
+
+// Verify compares the table files with their checksums. The store must use WithChecksums.
+func (cs *CSVStore) Verify() (*IntegrityReport, error) {
+	return runOperation(cs, Operation{Name: OpVerify}, func() (*IntegrityReport, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		return cs.verify()
+	})
+}
+
+// verify compares the table files with their checksums.
+// The caller must hold cs.mu.
+func (cs *CSVStore) verify() (*IntegrityReport, error) {
+	if cs.checksums == nil {
+		return nil, errors.New("verification requires checksums (WithChecksums)")
+	}
+
+	current, err := cs.tableChecksums()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &IntegrityReport{}
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		tableName, _ := tableNameFromFile(name)
+		expected, tracked := cs.checksums[name]
+		switch {
+		case !tracked:
+			report.Untracked = append(report.Untracked, tableName)
+		case expected != current[name]:
+			report.Corrupted = append(report.Corrupted, tableName)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(cs.checksums)) {
+		if _, exists := current[name]; !exists {
+			tableName, _ := tableNameFromFile(name)
+			report.Missing = append(report.Missing, tableName)
+		}
+	}
+	return report, nil
+}
+
+// tableChecksums returns the checksums of the table files of the store by file name.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tableChecksums() (map[string]string, error) {
+	entries, err := cs.fs.ReadDir(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	checksums := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if _, ok := tableNameFromFile(entry.Name()); !ok {
+			continue
+		}
+		checksum, err := fileChecksum(cs.fs, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum %s: %w", entry.Name(), err)
+		}
+		checksums[entry.Name()] = checksum
+	}
+	return checksums, nil
+}
+
+// loadChecksums reads checksums.json, or checksums the current table files when
+// it does not exist
+func (cs *CSVStore) loadChecksums() error {
+	data, err := cs.readFile(ChecksumsFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		checksums, err := cs.tableChecksums()
+		if err != nil {
+			return err
+		}
+		cs.checksums = checksums
+		return cs.saveChecksums()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checksums: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cs.checksums); err != nil {
+		return fmt.Errorf("failed to decode checksums: %w", err)
+	}
+	return nil
+}
+
+// saveChecksums writes checksums.json, replacing it atomically.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) saveChecksums() error {
+	data, err := json.MarshalIndent(cs.checksums, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checksums: %w", err)
+	}
+
+	tempName := ".checksums-" + randomSuffix()
+	if err := cs.writeFile(tempName, data); err != nil {
+		cs.fs.Remove(tempName)
+		return fmt.Errorf("failed to write checksums: %w", err)
+	}
+	if err := cs.fs.Rename(tempName, ChecksumsFileName); err != nil {
+		cs.fs.Remove(tempName)
+		return fmt.Errorf("failed to write checksums: %w", err)
+	}
+	return nil
+}
+
+// updateChecksum records the checksum of the current file of a table. A failed
+// update leaves the previous checksum, which Verify then reports.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) updateChecksum(tableName string) {
+	if cs.checksums == nil {
+		return
+	}
+
+	for name := range cs.checksums {
+		if fileTable, _ := tableNameFromFile(name); fileTable == tableName {
+			delete(cs.checksums, name)
+		}
+	}
+	name := cs.getTableFile(tableName)
+	if checksum, err := fileChecksum(cs.fs, name); err == nil {
+		cs.checksums[name] = checksum
+	}
+	cs.saveChecksums()
+}
@@ -0,0 +1,66 @@
+package csvstore
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir, WithChecksums())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	for _, table := range []string{"a", "b", "c"} {
+		if err := store.CreateTable(table, []string{"id", "value"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		if _, err := store.Insert(table, CSVRecord{"value": "1"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := store.Update("a", CSVRecord{"value": "2"}, nil); err != nil {
+		t.Fatalf("Failed to update records: %v", err)
+	}
+
+	report, err := store.Verify()
+	if err != nil {
+		t.Fatalf("Failed to verify store: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected a clean report, got %+v", report)
+	}
+	store.Close()
+
+	// Tamper with the files while the store is closed
+	if err := os.WriteFile(filepath.Join(testDir, "a.csv"), []byte("id,value\n1,999\n"), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+	if err := os.Remove(filepath.Join(testDir, "b.csv")); err != nil {
+		t.Fatalf("Failed to remove table file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(testDir, "d.csv"), []byte("id\n"), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+
+	store, err = NewCSVStore(testDir, WithChecksums())
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	report, err = store.Verify()
+	if err != nil {
+		t.Fatalf("Failed to verify store: %v", err)
+	}
+	if !slices.Equal(report.Corrupted, []string{"a"}) ||
+		!slices.Equal(report.Missing, []string{"b"}) ||
+		!slices.Equal(report.Untracked, []string{"d"}) {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
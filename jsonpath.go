@@ -0,0 +1,92 @@
+package csvstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// matchesJSONPath reports whether the JSON document in a cell has expected at
+// path. Strings compare by their contents, other values by their JSON encoding,
+// e.g. "42", "true", or `{"a":1}`. Cells that are not JSON never match.
+func matchesJSONPath(value string, path string, expected string) bool {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return false
+	}
+
+	found, err := evalJSONPath(document, path)
+	if err != nil {
+		return false
+	}
+	if s, ok := found.(string); ok {
+		return s == expected
+	}
+	encoded, err := json.Marshal(found)
+	if err != nil {
+		return false
+	}
+	return string(encoded) == expected
+}
+
+// evalJSONPath returns the value at path in a decoded JSON document. Paths start
+// with "$" and select object members with ".name" or `["name"]` and array
+// elements with "[index]", e.g. "$.items[0].sku".
+func evalJSONPath(document any, path string) (any, error) {
+	rest, found := strings.CutPrefix(path, "$")
+	if !found {
+		return nil, fmt.Errorf("JSON path %q must start with $", path)
+	}
+
+	current := document
+	for rest != "" {
+		key, index := "", -1
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key, rest = rest[1:end+1], rest[end+1:]
+			if key == "" {
+				return nil, fmt.Errorf("empty member name in JSON path %q", path)
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ in JSON path %q", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			if unquoted, err := strconv.Unquote(selector); err == nil {
+				key = unquoted
+			} else if n, err := strconv.Atoi(selector); err == nil && n >= 0 {
+				index = n
+			} else {
+				return nil, fmt.Errorf("invalid selector [%s] in JSON path %q", selector, path)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in JSON path %q", rest[0], path)
+		}
+
+		if index >= 0 {
+			array, ok := current.([]any)
+			if !ok || index >= len(array) {
+				return nil, fmt.Errorf("no element %d in JSON path %q", index, path)
+			}
+			current = array[index]
+			continue
+		}
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("no member %q in JSON path %q", key, path)
+		}
+		if current, ok = object[key]; !ok {
+			return nil, fmt.Errorf("no member %q in JSON path %q", key, path)
+		}
+	}
+	return current, nil
+}
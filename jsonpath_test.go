@@ -0,0 +1,55 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("accounts", []string{"id", "meta"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, record := range []CSVRecord{
+		{"id": "1", "meta": `{"plan": "pro", "seats": 5, "tags": ["a", "b"], "owner": {"name": "ann"}}`},
+		{"id": "2", "meta": `{"plan": "free", "seats": 1}`},
+		{"id": "3", "meta": `not json`},
+	} {
+		if _, err := store.Insert("accounts", record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		path     string
+		value    string
+		expected int
+	}{
+		{"$.plan", "pro", 1},
+		{"$.seats", "1", 1},
+		{"$.tags[1]", "b", 1},
+		{`$["owner"].name`, "ann", 1},
+		{"$.owner", `{"name":"ann"}`, 1},
+		{"$.missing", "", 0},
+		{"plan", "pro", 0},
+	}
+	for _, tt := range tests {
+		result, err := store.Query("accounts", []QueryCondition{
+			{Column: "meta", Operator: "json_path", Path: tt.path, Value: tt.value},
+		})
+		if err != nil {
+			t.Fatalf("Failed to query records: %v", err)
+		}
+		if result.Count != tt.expected {
+			t.Errorf("Expected %d records for %s = %s, got %d", tt.expected, tt.path, tt.value, result.Count)
+		}
+	}
+}
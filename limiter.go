@@ -0,0 +1,84 @@
+package csvstore
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// WriteLimiter bounds the number of write operations rewriting table files at
+// the same time. A store runs one write at a time, so share a limiter between
+// the stores of a process, e.g. tenants or shards, to keep a burst of updates
+// on many big tables from loading and rewriting them all at once.
+type WriteLimiter struct {
+	slots   chan struct{}
+	waiting atomic.Int64
+}
+
+// NewWriteLimiter returns a limiter allowing n concurrent rewrites. n below 1
+// is treated as 1.
+func NewWriteLimiter(n int) *WriteLimiter {
+	return &WriteLimiter{slots: make(chan struct{}, max(n, 1))}
+}
+
+// WithWriteLimiter makes the write operations of the store that rewrite table
+// files, that is every write operation but Insert and CreateTable, wait for a
+// slot of limiter before running. Writes queue in arrival order and wait
+// without holding the store lock; writes through WithContext stop waiting when
+// their context is done.
+func WithWriteLimiter(limiter *WriteLimiter) Option {
+	return func(cs *CSVStore) {
+		cs.writeLimiter = limiter
+	}
+}
+
+// Acquire waits for a slot, or returns the error of ctx when it is done first.
+// Every successful Acquire must be followed by a Release.
+func (l *WriteLimiter) Acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	l.waiting.Add(1)
+	defer l.waiting.Add(-1)
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for a write slot: %w", ctx.Err())
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (l *WriteLimiter) Release() {
+	<-l.slots
+}
+
+// Waiting returns the number of writes waiting for a slot
+func (l *WriteLimiter) Waiting() int {
+	return int(l.waiting.Load())
+}
+
+// limitsOperation reports whether an operation waits for the write limiter
+func limitsOperation(name string) bool {
+	return IsWriteOperation(name) && name != OpInsert && name != OpCreateTable
+}
+
+// acquireWriteSlot waits for a slot of the write limiter of the store, if
+// any, for operations that rewrite table files. It returns the function
+// releasing the slot.
+func (cs *CSVStore) acquireWriteSlot(op *Operation) (func(), error) {
+	if cs.writeLimiter == nil || !limitsOperation(op.Name) {
+		return func() {}, nil
+	}
+	ctx := op.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := cs.writeLimiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	return cs.writeLimiter.Release, nil
+}
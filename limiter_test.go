@@ -0,0 +1,71 @@
+package csvstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWriteLimiter(t *testing.T) {
+	limiter := NewWriteLimiter(1)
+	stores := make([]*CSVStore, 2)
+	for i := range stores {
+		stores[i] = NewMemoryStore(WithWriteLimiter(limiter))
+		defer stores[i].Close()
+		if err := stores[i].CreateTable("users", []string{"id", "name"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		if _, err := stores[i].Insert("users", CSVRecord{"name": "alice"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	// Take the only slot, as a rewrite of another store would
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatalf("Failed to acquire slot: %v", err)
+	}
+
+	// Inserts append and reads do not rewrite, so they do not wait
+	if _, err := stores[0].Insert("users", CSVRecord{"name": "bob"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := stores[1].Query("users", nil); err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	conditions := []QueryCondition{{Column: "name", Operator: "=", Value: "alice"}}
+	_, err := stores[0].WithContext(ctx).Update("users", CSVRecord{"name": "carol"}, conditions)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the update to time out waiting, got %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := stores[1].Delete("users", conditions)
+		done <- err
+	}()
+	for limiter.Waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Expected the delete to wait for the slot, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	limiter.Release()
+	if err := <-done; err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if limiter.Waiting() != 0 {
+		t.Errorf("Expected no waiting writes, got %d", limiter.Waiting())
+	}
+
+	// The slot is free again
+	if _, err := stores[0].Update("users", CSVRecord{"name": "carol"}, conditions); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+}
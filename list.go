@@ -0,0 +1,60 @@
+package csvstore
+
+import (
+	"slices"
+	"strings"
+)
+
+// DefaultListSeparator separates the items of list cells in columns not
+// configured with WithListColumn
+const DefaultListSeparator = "|"
+
+// WithListColumn stores lists in a column, with items separated by separator
+// within each cell, e.g. "red;green" with ";". The has_any, has_all, and has_none
+// operators compare the items of a cell with the items of the condition value,
+// split the same way.
+func WithListColumn(column string, separator string) TableOption {
+	return func(c *tableConfig) {
+		if c.listSeparators == nil {
+			c.listSeparators = make(map[string]string)
+		}
+		c.listSeparators[column] = separator
+	}
+}
+
+// splitList returns the items of a list cell, trimmed of whitespace and without
+// empty items
+func splitList(value string, separator string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// matchesList evaluates a has_any, has_all, or has_none condition on a list cell
+func (m *conditionMatcher) matchesList(value string, condition QueryCondition) bool {
+	separator := DefaultListSeparator
+	if configured, ok := m.listSeparators[condition.Column]; ok {
+		separator = configured
+	}
+	items := splitList(value, separator)
+	wanted := splitList(condition.Value, separator)
+
+	switch condition.Operator {
+	case "has_any":
+		return slices.ContainsFunc(wanted, func(item string) bool {
+			return slices.Contains(items, item)
+		})
+	case "has_all":
+		return !slices.ContainsFunc(wanted, func(item string) bool {
+			return !slices.Contains(items, item)
+		})
+	default: // has_none
+		return !slices.ContainsFunc(wanted, func(item string) bool {
+			return slices.Contains(items, item)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestListColumns(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("posts", WithListColumn("tags", ";"))
+	if err := store.CreateTable("posts", []string{"id", "tags", "authors"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, record := range []CSVRecord{
+		{"id": "1", "tags": "go; csv", "authors": "ann|bob"},
+		{"id": "2", "tags": "go;rust", "authors": "bob"},
+		{"id": "3", "tags": "", "authors": "cy"},
+	} {
+		if _, err := store.Insert("posts", record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		condition QueryCondition
+		expected  int
+	}{
+		{QueryCondition{Column: "tags", Operator: "has_any", Value: "csv;rust"}, 2},
+		{QueryCondition{Column: "tags", Operator: "has_all", Value: "go;csv"}, 1},
+		{QueryCondition{Column: "tags", Operator: "has_none", Value: "go"}, 1},
+		{QueryCondition{Column: "tags", Operator: "has_any", Value: "g"}, 0},
+		{QueryCondition{Column: "authors", Operator: "has_any", Value: "bob"}, 2},
+	}
+	for _, tt := range tests {
+		result, err := store.Query("posts", []QueryCondition{tt.condition})
+		if err != nil {
+			t.Fatalf("Failed to query records: %v", err)
+		}
+		if result.Count != tt.expected {
+			t.Errorf("Expected %d records for %v, got %d", tt.expected, tt.condition, result.Count)
+		}
+	}
+}
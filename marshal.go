@@ -0,0 +1,128 @@
+package csvstore
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	valuerType        = reflect.TypeFor[Valuer]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Valuer is implemented by field types that format their values as cells in
+// RecordFromStruct and InsertStruct
+type Valuer interface {
+	CSVValue() (string, error)
+}
+
+// RecordFromStruct converts a struct, or a pointer to one, to a record of a
+// table, the reverse of ScanRecords. Fields are matched with columns by their
+// csv tag or by name like in ScanRecords; a tag with the omitempty option, e.g.
+// `csv:"id,omitempty"`, leaves zero values out, so Insert generates them. Fields
+// implementing Valuer or encoding.TextMarshaler format their values
+// themselves, in that order of preference. Values of columns with a declared
+// type are formatted by its converter (see RegisterType) when it has a Format
+// function. Other values are formatted from their kind: strings, integers,
+// floats, and booleans, and time.Durations. Nil pointers are null.
+func (cs *CSVStore) RecordFromStruct(tableName string, src any) (CSVRecord, error) {
+	value := reflect.ValueOf(src)
+	if value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("src must be a struct or a pointer to one, got %T", src)
+	}
+	if !value.CanAddr() {
+		// Copy the struct, so methods of pointers to its fields can be called
+		addressable := reflect.New(value.Type()).Elem()
+		addressable.Set(value)
+		value = addressable
+	}
+
+	cs.mu.RLock()
+	config := cs.tableConfig(tableName)
+	headers, err := cs.getHeaders(tableName)
+	cs.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	record := make(CSVRecord)
+	structType := value.Type()
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		column, omitEmpty, ok := fieldColumn(field, headers)
+		if !ok {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+		cell, err := config.formatField(fieldValue, column)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format field %s as column %s: %w", field.Name, column, err)
+		}
+		record[column] = cell
+	}
+	return record, nil
+}
+
+// InsertStruct converts a struct to a record with RecordFromStruct and inserts
+// it like Insert
+func (cs *CSVStore) InsertStruct(tableName string, src any) (CSVRecord, error) {
+	record, err := cs.RecordFromStruct(tableName, src)
+	if err != nil {
+		return nil, err
+	}
+	return cs.Insert(tableName, record)
+}
+
+// formatField formats the value of a struct field as a cell of a column
+func (c *tableConfig) formatField(field reflect.Value, column string) (string, error) {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return c.null, nil
+		}
+		if !field.Type().Implements(valuerType) && !field.Type().Implements(textMarshalerType) {
+			return c.formatField(field.Elem(), column)
+		}
+	}
+
+	switch {
+	case field.Type().Implements(valuerType):
+		return field.Interface().(Valuer).CSVValue()
+	case field.CanAddr() && field.Addr().Type().Implements(valuerType):
+		return field.Addr().Interface().(Valuer).CSVValue()
+	case field.Type().Implements(textMarshalerType):
+		text, err := field.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	case field.CanAddr() && field.Addr().Type().Implements(textMarshalerType):
+		text, err := field.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	if converter, ok := LookupType(c.columnTypes[column]); ok && converter.Format != nil {
+		return converter.Format(field.Interface())
+	}
+	if field.Type() == durationType {
+		return field.Interface().(fmt.Stringer).String(), nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, field.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported field type %s", field.Type())
+	}
+}
@@ -0,0 +1,99 @@
+package csvstore
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// level is marshaled as text
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte(strings.Repeat("*", int(l))), nil
+}
+
+func (l *level) UnmarshalText(text []byte) error {
+	if strings.Trim(string(text), "*") != "" {
+		return fmt.Errorf("invalid level %q", text)
+	}
+	*l = level(len(text))
+	return nil
+}
+
+// point is converted by the Valuer and Scanner interfaces
+type point struct {
+	X, Y int
+}
+
+func (p point) CSVValue() (string, error) {
+	return fmt.Sprintf("%d;%d", p.X, p.Y), nil
+}
+
+func (p *point) ScanCSV(value string) error {
+	_, err := fmt.Sscanf(value, "%d;%d", &p.X, &p.Y)
+	return err
+}
+
+func TestStructRoundTrip(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.CreateTable("places", []string{"id", "name", "level", "location", "visited", "stay"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	type place struct {
+		ID       int64 `csv:"id,omitempty"`
+		Name     string
+		Level    level
+		Location *point
+		Visited  time.Time
+		Stay     time.Duration
+	}
+	visited := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	inserted, err := store.InsertStruct("places", place{
+		Name:     "harbor",
+		Level:    3,
+		Location: &point{X: 4, Y: -2},
+		Visited:  visited,
+		Stay:     2 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert struct: %v", err)
+	}
+	if inserted["id"] == "" || inserted["id"] == "0" {
+		t.Errorf("Expected a generated id, got %q", inserted["id"])
+	}
+	if inserted["level"] != "***" || inserted["location"] != "4;-2" || inserted["stay"] != "2h0m0s" {
+		t.Errorf("Expected marshaled cells, got %v", inserted)
+	}
+	if _, err := store.InsertStruct("places", &place{Name: "nowhere"}); err != nil {
+		t.Fatalf("Failed to insert struct: %v", err)
+	}
+
+	result, err := store.Query("places", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	var places []place
+	if err := store.ScanRecords("places", result.Records, &places); err != nil {
+		t.Fatalf("Failed to scan records: %v", err)
+	}
+	first := places[0]
+	if first.Name != "harbor" || first.Level != 3 || first.Location == nil || *first.Location != (point{4, -2}) ||
+		!first.Visited.Equal(visited) || first.Stay != 2*time.Hour {
+		t.Errorf("Expected the struct to round-trip, got %+v", first)
+	}
+	if places[1].Location != nil {
+		t.Errorf("Expected a nil location, got %v", places[1].Location)
+	}
+
+	if err := store.ScanRecords("places", []CSVRecord{{"level": "high"}}, &places); err == nil {
+		t.Error("Expected error scanning an invalid level")
+	}
+	if _, err := store.RecordFromStruct("places", 42); err == nil {
+		t.Error("Expected error converting a value that is not a struct")
+	}
+}
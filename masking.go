@@ -0,0 +1,129 @@
+package csvstore
+
+import "maps"
+
+// MaskFunc returns the masked form of a cell value
+type MaskFunc func(value string) string
+
+// MaskKeepLast masks all but the last n characters of a value, e.g. "****1234".
+// Values of n characters or fewer are masked entirely.
+func MaskKeepLast(n int) MaskFunc {
+	return func(value string) string {
+		runes := []rune(value)
+		if len(runes) <= n {
+			return "****"
+		}
+		return "****" + string(runes[len(runes)-n:])
+	}
+}
+
+// Redact replaces every value with replacement
+func Redact(replacement string) MaskFunc {
+	return func(string) string {
+		return replacement
+	}
+}
+
+// WithMask masks a column in the records returned by Query, Select,
+// QuerySortedRange, History, and the exports of the table. Empty cells stay
+// empty. Stored data is unchanged; use Unmasked to read the actual values.
+func WithMask(column string, mask MaskFunc) TableOption {
+	return func(c *tableConfig) {
+		if c.masks == nil {
+			c.masks = make(map[string]MaskFunc)
+		}
+		c.masks[column] = mask
+	}
+}
+
+// recordMasker returns a function masking records of a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) recordMasker(tableName string) func(CSVRecord) CSVRecord {
+	masks := cs.tableConfig(tableName).masks
+	return func(record CSVRecord) CSVRecord {
+		if len(masks) == 0 {
+			return record
+		}
+		masked := maps.Clone(record)
+		for column, mask := range masks {
+			if value, exists := masked[column]; exists && value != "" {
+				masked[column] = mask(value)
+			}
+		}
+		return masked
+	}
+}
+
+// maskResult masks the records of a query result of a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) maskResult(tableName string, result *QueryResult) *QueryResult {
+	mask := cs.recordMasker(tableName)
+	for i, record := range result.Records {
+		result.Records[i] = mask(record)
+	}
+	return result
+}
+
+// UnmaskedView reads tables without applying column masks (see WithMask).
+// Operations run through it carry Operation.Unmasked, so middleware can
+// restrict who reads unmasked data.
+type UnmaskedView struct {
+	cs *CSVStore
+}
+
+// Unmasked returns a view of the store that reads the actual values of masked columns
+func (cs *CSVStore) Unmasked() *UnmaskedView {
+	return &UnmaskedView{cs: cs}
+}
+
+// Query executes a query on the CSV table without masking
+func (v *UnmaskedView) Query(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	op := Operation{Name: OpQuery, Table: tableName, Payload: conditions, Unmasked: true}
+	return runOperation(v.cs, op, func() (*QueryResult, error) {
+		v.cs.mu.RLock()
+		defer v.cs.mu.RUnlock()
+
+		return v.cs.query(tableName, conditions)
+	})
+}
+
+// Select retrieves specific columns from query results without masking
+func (v *UnmaskedView) Select(
+	tableName string,
+	columns []string,
+	conditions []QueryCondition,
+) (*QueryResult, error) {
+	op := Operation{
+		Name:     OpSelect,
+		Table:    tableName,
+		Payload:  SelectPayload{Columns: columns, Conditions: conditions},
+		Unmasked: true,
+	}
+	return runOperation(v.cs, op, func() (*QueryResult, error) {
+		v.cs.mu.RLock()
+		defer v.cs.mu.RUnlock()
+
+		return v.cs.selectColumns(tableName, columns, conditions)
+	})
+}
+
+// QuerySortedRange retrieves a limited number of sorted records without masking
+func (v *UnmaskedView) QuerySortedRange(
+	tableName string,
+	sortField string,
+	sortBy string,
+	limit int,
+) (*QueryResult, error) {
+	op := Operation{
+		Name:     OpQuerySortedRange,
+		Table:    tableName,
+		Payload:  SortedRangePayload{SortField: sortField, SortBy: sortBy, Limit: limit},
+		Unmasked: true,
+	}
+	return runOperation(v.cs, op, func() (*QueryResult, error) {
+		v.cs.mu.RLock()
+		defer v.cs.mu.RUnlock()
+
+		return v.cs.querySortedRange(tableName, sortField, sortBy, limit)
+	})
+}
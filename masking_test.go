@@ -0,0 +1,77 @@
+package csvstore
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMasking(t *testing.T) {
+	testDir := getTestDir()
+	var unmaskedOps int
+	store, err := NewCSVStore(testDir, WithMiddleware(func(next Handler) Handler {
+		return func(op *Operation) (any, error) {
+			if op.Unmasked {
+				unmaskedOps++
+			}
+			return next(op)
+		}
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+	store.ConfigureTable("cards",
+		WithMask("number", MaskKeepLast(4)),
+		WithMask("cvv", Redact("[REDACTED]")),
+	)
+
+	if err := store.CreateTable("cards", []string{"holder", "number", "cvv"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("cards", CSVRecord{"holder": "Alice", "number": "4111111111111234", "cvv": "123"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Insert("cards", CSVRecord{"holder": "Bob", "number": "", "cvv": "456"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	result, err := store.Query("cards", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	alice := result.Records[0]
+	if alice["number"] != "****1234" || alice["cvv"] != "[REDACTED]" || alice["holder"] != "Alice" {
+		t.Errorf("Unexpected masked record: %v", alice)
+	}
+	if result.Records[1]["number"] != "" {
+		t.Errorf("Expected empty cells to stay empty, got %q", result.Records[1]["number"])
+	}
+
+	selected, err := store.Select("cards", []string{"number"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to select records: %v", err)
+	}
+	if selected.Records[0]["number"] != "****1234" {
+		t.Errorf("Expected masked column in Select, got %v", selected.Records[0])
+	}
+
+	var exported strings.Builder
+	if err := store.QueryToCSV("cards", nil, &exported); err != nil {
+		t.Fatalf("Failed to export CSV: %v", err)
+	}
+	if strings.Contains(exported.String(), "4111111111111234") {
+		t.Errorf("Expected export to be masked, got %s", exported.String())
+	}
+
+	raw, err := store.Unmasked().Query("cards", nil)
+	if err != nil {
+		t.Fatalf("Failed to query unmasked records: %v", err)
+	}
+	if raw.Records[0]["number"] != "4111111111111234" || raw.Records[0]["cvv"] != "123" {
+		t.Errorf("Expected actual values, got %v", raw.Records[0])
+	}
+	if unmaskedOps != 1 {
+		t.Errorf("Expected the operation to be marked unmasked")
+	}
+}
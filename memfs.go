@@ -0,0 +1,300 @@
+package csvstore
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memFS is an FS keeping files in memory
+type memFS struct {
+	mu    sync.Mutex
+	files map[string]*memFile
+	dirs  map[string]bool
+}
+
+// memFile is the contents of a file of a memFS
+type memFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemFS returns an empty in-memory FS, e.g. for tests
+func NewMemFS() FS {
+	return &memFS{
+		files: make(map[string]*memFile),
+		dirs:  map[string]bool{".": true},
+	}
+}
+
+// clean validates and normalizes a name
+func (m *memFS) clean(op string, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return name, nil
+}
+
+// parentExists reports whether the directory of name exists.
+// The caller must hold m.mu.
+func (m *memFS) parentExists(name string) bool {
+	return m.dirs[path.Dir(name)]
+}
+
+func (m *memFS) Open(name string) (io.ReadCloser, error) {
+	name, err := m.clean("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, exists := m.files[name]
+	if !exists {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	// Writers only append past the length read here or replace the slice,
+	// so the reader sees a stable snapshot
+	return io.NopCloser(bytes.NewReader(file.data[:len(file.data):len(file.data)])), nil
+}
+
+func (m *memFS) Create(name string) (io.WriteCloser, error) {
+	return m.openWriter("create", name, true)
+}
+
+func (m *memFS) Append(name string) (io.WriteCloser, error) {
+	return m.openWriter("append", name, false)
+}
+
+// openWriter opens a file for writing, truncating it if requested
+func (m *memFS) openWriter(op string, name string, truncate bool) (io.WriteCloser, error) {
+	name, err := m.clean(op, name)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.dirs[name] {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrExist}
+	}
+	if !m.parentExists(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	file, exists := m.files[name]
+	if !exists || truncate {
+		file = &memFile{}
+		m.files[name] = file
+	}
+	file.modTime = time.Now()
+
+	return &memWriter{fs: m, file: file}, nil
+}
+
+// memWriter writes to a file of a memFS
+type memWriter struct {
+	fs   *memFS
+	file *memFile
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	w.fs.mu.Lock()
+	defer w.fs.mu.Unlock()
+
+	w.file.data = append(w.file.data, p...)
+	w.file.modTime = time.Now()
+	return len(p), nil
+}
+
+func (w *memWriter) Close() error {
+	return nil
+}
+
+func (m *memFS) Rename(oldName, newName string) error {
+	oldName, err := m.clean("rename", oldName)
+	if err != nil {
+		return err
+	}
+	newName, err = m.clean("rename", newName)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.parentExists(newName) {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrNotExist}
+	}
+	if file, exists := m.files[oldName]; exists {
+		if m.dirs[newName] {
+			return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrExist}
+		}
+		delete(m.files, oldName)
+		m.files[newName] = file
+		return nil
+	}
+	if !m.dirs[oldName] || oldName == "." {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: fs.ErrNotExist}
+	}
+	if m.dirs[newName] || m.files[newName] != nil {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrExist}
+	}
+
+	prefix := oldName + "/"
+	for name, file := range m.files {
+		if rest, found := strings.CutPrefix(name, prefix); found {
+			delete(m.files, name)
+			m.files[newName+"/"+rest] = file
+		}
+	}
+	for name := range m.dirs {
+		if name == oldName {
+			delete(m.dirs, name)
+			m.dirs[newName] = true
+		} else if rest, found := strings.CutPrefix(name, prefix); found {
+			delete(m.dirs, name)
+			m.dirs[newName+"/"+rest] = true
+		}
+	}
+	return nil
+}
+
+func (m *memFS) Remove(name string) error {
+	name, err := m.clean("remove", name)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.files[name]; exists {
+		delete(m.files, name)
+		return nil
+	}
+	if !m.dirs[name] || name == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	prefix := name + "/"
+	for fileName := range m.files {
+		if strings.HasPrefix(fileName, prefix) {
+			delete(m.files, fileName)
+		}
+	}
+	for dirName := range m.dirs {
+		if dirName == name || strings.HasPrefix(dirName, prefix) {
+			delete(m.dirs, dirName)
+		}
+	}
+	return nil
+}
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	name, err := m.clean("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stat(name)
+}
+
+// stat describes a file or directory.
+// The caller must hold m.mu.
+func (m *memFS) stat(name string) (fs.FileInfo, error) {
+	if file, exists := m.files[name]; exists {
+		return &memFileInfo{name: path.Base(name), size: int64(len(file.data)), modTime: file.modTime}, nil
+	}
+	if m.dirs[name] {
+		return &memFileInfo{name: path.Base(name), dir: true}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	name, err := m.clean("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.dirs[name] {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	names := make([]string, 0)
+	for fileName := range m.files {
+		if path.Dir(fileName) == name {
+			names = append(names, fileName)
+		}
+	}
+	for dirName := range m.dirs {
+		if dirName != "." && path.Dir(dirName) == name {
+			names = append(names, dirName)
+		}
+	}
+	slices.Sort(names)
+
+	entries := make([]fs.DirEntry, 0, len(names))
+	for _, entryName := range names {
+		info, err := m.stat(entryName)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	return entries, nil
+}
+
+func (m *memFS) Mkdir(name string) error {
+	name, err := m.clean("mkdir", name)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.dirs[name] || m.files[name] != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	if !m.parentExists(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrNotExist}
+	}
+	m.dirs[name] = true
+	return nil
+}
+
+// memFileInfo describes a file or directory of a memFS
+type memFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i *memFileInfo) Name() string       { return i.name }
+func (i *memFileInfo) Size() int64        { return i.size }
+func (i *memFileInfo) ModTime() time.Time { return i.modTime }
+func (i *memFileInfo) IsDir() bool        { return i.dir }
+func (i *memFileInfo) Sys() any           { return nil }
+
+func (i *memFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
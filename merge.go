@@ -0,0 +1,203 @@
+package csvstore
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// MergeStrategy selects how MergeTables resolves rows present in both tables
+type MergeStrategy int
+
+const (
+	// MergeSrcWins overwrites the destination row with the source row
+	MergeSrcWins MergeStrategy = iota
+	// MergeDstWins keeps the destination row
+	MergeDstWins
+	// MergeNewestWins keeps the most recently updated row, by the updated_at
+	// column, or the created_at column for rows never updated; the destination
+	// row wins ties
+	MergeNewestWins
+	// MergeFields merges field by field, taking the source value of every
+	// column the source row does not leave null
+	MergeFields
+)
+
+// MergeReport describes the result of MergeTables
+type MergeReport struct {
+	Inserted  []CSVRecord // Source rows added to the destination table
+	Updated   []CSVRecord // Destination rows after the merge
+	Unchanged int         // Destination rows matched by a source row but left unchanged
+}
+
+// MergeTables merges the rows of table src into table dst. Rows are matched by
+// the values of keyColumns; source rows without a match are inserted, and rows
+// present in both tables are resolved according to strategy. Columns are matched
+// by name and only the columns of dst are merged. Destination ids and creation
+// timestamps are kept; source ids are copied only when the id column is a key
+// column. Later source rows with the same key merge over earlier ones. Changes go
+// through the hooks, history, and change log of dst, and updated rows get a new
+// updated_at timestamp.
+func (cs *CSVStore) MergeTables(
+	dst string,
+	src string,
+	keyColumns []string,
+	strategy MergeStrategy,
+) (*MergeReport, error) {
+	op := Operation{Name: OpMergeTables, Table: dst, Payload: src}
+	return runOperation(cs, op, func() (*MergeReport, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.mergeTables(dst, src, keyColumns, strategy)
+	})
+}
+
+// mergeTables merges the rows of table src into table dst.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) mergeTables(
+	dst string,
+	src string,
+	keyColumns []string,
+	strategy MergeStrategy,
+) (*MergeReport, error) {
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("merging table %s into %s requires key columns", src, dst)
+	}
+	headers, err := cs.getHeaders(dst)
+	if err != nil {
+		return nil, err
+	}
+	srcRecords, err := cs.loadTable(src)
+	if err != nil {
+		return nil, err
+	}
+	dstRecords, err := cs.loadTable(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	resolveDst := cs.columnResolver(dst)
+	resolveSrc := cs.columnResolver(src)
+	keyColumns = slices.Clone(keyColumns)
+	for i, column := range keyColumns {
+		keyColumns[i] = resolveDst(column)
+		if !slices.Contains(headers, keyColumns[i]) {
+			return nil, fmt.Errorf("column %s does not exist in table %s", keyColumns[i], dst)
+		}
+	}
+
+	// Bring source rows to the columns of dst, matching columns by name
+	dstReserved := cs.reservedColumns(dst)
+	srcNull := cs.tableConfig(src).null
+	columns := make(map[string]string) // dst column by src column
+	for _, column := range headers {
+		if column == dstReserved.ID && !slices.Contains(keyColumns, column) {
+			continue
+		}
+		columns[resolveSrc(column)] = column
+	}
+	key := func(record CSVRecord) string {
+		values := make([]string, len(keyColumns))
+		for i, column := range keyColumns {
+			values[i] = record[column]
+		}
+		return strings.Join(values, "\x00")
+	}
+
+	merge := func(current, incoming CSVRecord) CSVRecord {
+		merged := maps.Clone(current)
+		switch strategy {
+		case MergeSrcWins:
+			maps.Copy(merged, incoming)
+		case MergeNewestWins:
+			if dstReserved.lastModified(incoming).After(dstReserved.lastModified(current)) {
+				maps.Copy(merged, incoming)
+			}
+		case MergeFields:
+			for column, value := range incoming {
+				if value != srcNull {
+					merged[column] = value
+				}
+			}
+		}
+		return merged
+	}
+
+	dstIndex := make(map[string]int)
+	for i, record := range dstRecords {
+		if _, seen := dstIndex[key(record)]; !seen {
+			dstIndex[key(record)] = i
+		}
+	}
+	merged := make(map[int]CSVRecord)
+	var newKeys []string
+	newRecords := make(map[string]CSVRecord)
+	for _, srcRecord := range srcRecords {
+		incoming := make(CSVRecord)
+		for srcColumn, dstColumn := range columns {
+			if value, exists := srcRecord[srcColumn]; exists {
+				incoming[dstColumn] = value
+			}
+		}
+		k := key(incoming)
+
+		if i, exists := dstIndex[k]; exists {
+			current, pending := merged[i]
+			if !pending {
+				current = dstRecords[i]
+			}
+			merged[i] = merge(current, incoming)
+			continue
+		}
+		if current, pending := newRecords[k]; pending {
+			newRecords[k] = merge(current, incoming)
+			continue
+		}
+		newKeys = append(newKeys, k)
+		newRecords[k] = incoming
+	}
+
+	report := &MergeReport{Inserted: []CSVRecord{}, Updated: []CSVRecord{}}
+	updates := make(map[int]CSVRecord)
+	for i, record := range merged {
+		changes := make(CSVRecord)
+		for column, value := range record {
+			if column == dstReserved.CreatedAt || column == dstReserved.UpdatedAt {
+				continue
+			}
+			if dstRecords[i][column] != value {
+				changes[column] = value
+			}
+		}
+		if len(changes) == 0 {
+			report.Unchanged++
+			continue
+		}
+		updates[i] = changes
+	}
+
+	if len(updates) > 0 {
+		// Records are loaded again in the same order while the lock is held
+		i := -1
+		updated, err := cs.updateMatching(dst, func(CSVRecord) CSVRecord {
+			i++
+			return updates[i]
+		})
+		if err != nil {
+			return nil, err
+		}
+		report.Updated = updated.Records
+	}
+
+	for _, k := range newKeys {
+		inserted, err := cs.insert(dst, newRecords[k])
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert merged record: %w", err)
+		}
+		report.Inserted = append(report.Inserted, inserted)
+	}
+
+	return report, nil
+}
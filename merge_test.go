@@ -0,0 +1,101 @@
+package csvstore
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestMergeTables(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	headers := []string{"id", "email", "name", "phone", "updated_at"}
+	dstRows := []CSVRecord{
+		{"id": "1", "email": "a@example.com", "name": "Alice", "phone": "", "updated_at": "2024-01-05T00:00:00Z"},
+		{"id": "2", "email": "b@example.com", "name": "Bob", "phone": "555-0002", "updated_at": "2024-01-01T00:00:00Z"},
+		{"id": "3", "email": "c@example.com", "name": "Carol", "phone": "555-0003", "updated_at": "2024-01-01T00:00:00Z"},
+	}
+	srcRows := []CSVRecord{
+		{"id": "91", "email": "a@example.com", "name": "Alice Smith", "phone": "555-0001", "updated_at": "2024-01-02T00:00:00Z"},
+		{"id": "92", "email": "b@example.com", "name": "Robert", "phone": "", "updated_at": "2024-01-03T00:00:00Z"},
+		{"id": "93", "email": "c@example.com", "name": "Carol", "phone": "555-0003", "updated_at": "2024-01-01T00:00:00Z"},
+		{"id": "94", "email": "d@example.com", "name": "Dan", "phone": "555-0004", "updated_at": "2024-01-01T00:00:00Z"},
+	}
+
+	expected := map[MergeStrategy]map[string]CSVRecord{
+		MergeSrcWins: {
+			"a@example.com": {"name": "Alice Smith", "phone": "555-0001"},
+			"b@example.com": {"name": "Robert", "phone": ""},
+		},
+		MergeDstWins: {
+			"a@example.com": {"name": "Alice", "phone": ""},
+			"b@example.com": {"name": "Bob", "phone": "555-0002"},
+		},
+		MergeNewestWins: {
+			"a@example.com": {"name": "Alice", "phone": ""},
+			"b@example.com": {"name": "Robert", "phone": ""},
+		},
+		MergeFields: {
+			"a@example.com": {"name": "Alice Smith", "phone": "555-0001"},
+			"b@example.com": {"name": "Robert", "phone": "555-0002"},
+		},
+	}
+	for strategy, rows := range expected {
+		dst := fmt.Sprintf("dst%d", strategy)
+		src := fmt.Sprintf("src%d", strategy)
+		for _, table := range []string{dst, src} {
+			if err := store.CreateTable(table, headers); err != nil {
+				t.Fatalf("Failed to create table: %v", err)
+			}
+		}
+		for _, row := range dstRows {
+			if _, err := store.Insert(dst, row); err != nil {
+				t.Fatalf("Failed to insert row: %v", err)
+			}
+		}
+		for _, row := range srcRows {
+			if _, err := store.Insert(src, row); err != nil {
+				t.Fatalf("Failed to insert row: %v", err)
+			}
+		}
+
+		report, err := store.MergeTables(dst, src, []string{"email"}, strategy)
+		if err != nil {
+			t.Fatalf("Failed to merge tables: %v", err)
+		}
+		if len(report.Inserted) != 1 || report.Inserted[0]["name"] != "Dan" || report.Inserted[0]["id"] == "94" {
+			t.Errorf("Strategy %d: expected Dan to be inserted with a new id, got %v", strategy, report.Inserted)
+		}
+
+		result, err := store.Query(dst, nil)
+		if err != nil {
+			t.Fatalf("Failed to query table: %v", err)
+		}
+		if result.Count != 4 {
+			t.Fatalf("Strategy %d: expected 4 rows, got %v", strategy, result.Records)
+		}
+		for _, record := range result.Records {
+			want, ok := rows[record["email"]]
+			if !ok {
+				continue
+			}
+			if record["name"] != want["name"] || record["phone"] != want["phone"] {
+				t.Errorf("Strategy %d: expected %v for %s, got %v", strategy, want, record["email"], record)
+			}
+			if record["id"] != "1" && record["id"] != "2" {
+				t.Errorf("Strategy %d: expected the destination id to be kept, got %v", strategy, record)
+			}
+		}
+	}
+
+	if _, err := store.MergeTables("dst0", "src0", []string{"missing"}, MergeSrcWins); err == nil {
+		t.Errorf("Expected an error for a missing key column")
+	}
+}
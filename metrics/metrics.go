@@ -0,0 +1,141 @@
+// Package metrics exports the statistics of a csvstore to Prometheus.
+//
+//	store, err := csvstore.NewCSVStore(dir, csvstore.WithStats())
+//	...
+//	prometheus.MustRegister(metrics.NewCollector(store, metrics.CollectorOptions{}))
+//
+// The cache hit rate is rate(csvstore_cache_hits_total[5m]) divided by the sum
+// of the hit and miss rates.
+package metrics
+
+import (
+	"github.com/jiyeol-lee/csvstore"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace prefixes the metric names when CollectorOptions.Namespace is empty
+const DefaultNamespace = "csvstore"
+
+// CollectorOptions configures NewCollector
+type CollectorOptions struct {
+	// Namespace prefixes the metric names; DefaultNamespace when empty
+	Namespace string
+	// ConstLabels are added to every metric, e.g. to tell several stores apart
+	ConstLabels prometheus.Labels
+	// CompactionTables lists the tables whose csvstore.CompactionStats are
+	// exported. Every scrape reads these tables and their tombstone tables.
+	CompactionTables []string
+}
+
+// collector exposes the statistics of a store
+type collector struct {
+	store *csvstore.CSVStore
+
+	operations       *prometheus.Desc
+	operationErrors  *prometheus.Desc
+	operationLatency *prometheus.Desc
+	rowsScanned      *prometheus.Desc
+	bytesWritten     *prometheus.Desc
+	cacheHits        *prometheus.Desc
+	cacheMisses      *prometheus.Desc
+	lockAcquisitions *prometheus.Desc
+	lockWait         *prometheus.Desc
+
+	compactionTables []string
+	liveRows         *prometheus.Desc
+	tombstones       *prometheus.Desc
+	deadRows         *prometheus.Desc
+	reclaimableBytes *prometheus.Desc
+	lastCompaction   *prometheus.Desc
+}
+
+// NewCollector returns a prometheus.Collector exposing the statistics of a
+// store opened with csvstore.WithStats. Statistics are read on every scrape.
+func NewCollector(store *csvstore.CSVStore, opts CollectorOptions) prometheus.Collector {
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, opts.ConstLabels)
+	}
+
+	return &collector{
+		store:            store,
+		operations:       desc("operations_total", "Number of store operations.", "operation", "table"),
+		operationErrors:  desc("operation_errors_total", "Number of store operations that failed.", "operation", "table"),
+		operationLatency: desc("operation_duration_seconds", "Duration of store operations.", "operation", "table"),
+		rowsScanned:      desc("rows_scanned_total", "Number of rows read from table files.", "table"),
+		bytesWritten:     desc("bytes_written_total", "Number of bytes written to table files.", "table"),
+		cacheHits:        desc("cache_hits_total", "Number of cache lookups served from the cache."),
+		cacheMisses:      desc("cache_misses_total", "Number of cache lookups not served from the cache."),
+		lockAcquisitions: desc("lock_acquisitions_total", "Number of acquisitions of the store lock."),
+		lockWait:         desc("lock_wait_seconds_total", "Time spent waiting for the store lock."),
+
+		compactionTables: opts.CompactionTables,
+		liveRows:         desc("live_rows", "Number of rows of the table.", "table"),
+		tombstones:       desc("tombstones", "Number of tombstones kept by compaction.", "table"),
+		deadRows:         desc("dead_rows", "Number of superseded tombstones removed by compaction.", "table"),
+		reclaimableBytes: desc("reclaimable_bytes", "Size of the rows removed by compaction, before compression.", "table"),
+		lastCompaction:   desc("last_compaction_timestamp_seconds", "Time of the last compaction, or 0.", "table"),
+	}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.operations
+	ch <- c.operationErrors
+	ch <- c.operationLatency
+	ch <- c.rowsScanned
+	ch <- c.bytesWritten
+	ch <- c.cacheHits
+	ch <- c.cacheMisses
+	ch <- c.lockAcquisitions
+	ch <- c.lockWait
+	if len(c.compactionTables) > 0 {
+		ch <- c.liveRows
+		ch <- c.tombstones
+		ch <- c.deadRows
+		ch <- c.reclaimableBytes
+		ch <- c.lastCompaction
+	}
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.store.Stats()
+
+	for _, op := range stats.Operations {
+		ch <- prometheus.MustNewConstMetric(c.operations, prometheus.CounterValue, float64(op.Count), op.Operation, op.Table)
+		ch <- prometheus.MustNewConstMetric(c.operationErrors, prometheus.CounterValue, float64(op.Errors), op.Operation, op.Table)
+
+		buckets := make(map[float64]uint64, len(op.BucketCounts))
+		for i, count := range op.BucketCounts {
+			buckets[csvstore.StatsLatencyBuckets[i].Seconds()] = count
+		}
+		ch <- prometheus.MustNewConstHistogram(c.operationLatency, op.Count, op.Duration.Seconds(), buckets, op.Operation, op.Table)
+	}
+	for _, table := range stats.Tables {
+		ch <- prometheus.MustNewConstMetric(c.rowsScanned, prometheus.CounterValue, float64(table.RowsScanned), table.Table)
+		ch <- prometheus.MustNewConstMetric(c.bytesWritten, prometheus.CounterValue, float64(table.BytesWritten), table.Table)
+	}
+	ch <- prometheus.MustNewConstMetric(c.cacheHits, prometheus.CounterValue, float64(stats.CacheHits))
+	ch <- prometheus.MustNewConstMetric(c.cacheMisses, prometheus.CounterValue, float64(stats.CacheMisses))
+	ch <- prometheus.MustNewConstMetric(c.lockAcquisitions, prometheus.CounterValue, float64(stats.LockAcquisitions))
+	ch <- prometheus.MustNewConstMetric(c.lockWait, prometheus.CounterValue, stats.LockWait.Seconds())
+
+	for _, table := range c.compactionTables {
+		compaction, err := c.store.CompactionStats(table)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(c.liveRows, err)
+			continue
+		}
+		var lastCompaction float64
+		if !compaction.LastCompaction.IsZero() {
+			lastCompaction = float64(compaction.LastCompaction.UnixNano()) / 1e9
+		}
+		ch <- prometheus.MustNewConstMetric(c.liveRows, prometheus.GaugeValue, float64(compaction.LiveRows), table)
+		ch <- prometheus.MustNewConstMetric(c.tombstones, prometheus.GaugeValue, float64(compaction.Tombstones), table)
+		ch <- prometheus.MustNewConstMetric(c.deadRows, prometheus.GaugeValue, float64(compaction.DeadRows), table)
+		ch <- prometheus.MustNewConstMetric(c.reclaimableBytes, prometheus.GaugeValue, float64(compaction.ReclaimableBytes), table)
+		ch <- prometheus.MustNewConstMetric(c.lastCompaction, prometheus.GaugeValue, lastCompaction, table)
+	}
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/jiyeol-lee/csvstore"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCollector(t *testing.T) {
+	store := csvstore.NewMemoryStore(csvstore.WithStats())
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := store.Insert("users", csvstore.CSVRecord{"name": name}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := store.Query("users", nil); err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+
+	registry := prometheus.NewRegistry()
+	collector := NewCollector(store, CollectorOptions{ConstLabels: prometheus.Labels{"store": "test"}})
+	if err := registry.Register(collector); err != nil {
+		t.Fatalf("Failed to register collector: %v", err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	metrics := make(map[string][]*dto.Metric)
+	for _, family := range families {
+		metrics[family.GetName()] = family.GetMetric()
+	}
+
+	var inserts float64
+	for _, metric := range metrics["csvstore_operations_total"] {
+		if labelValue(metric, "operation") == csvstore.OpInsert && labelValue(metric, "table") == "users" {
+			inserts = metric.GetCounter().GetValue()
+		}
+		if labelValue(metric, "store") != "test" {
+			t.Errorf("Expected const label store=test, got %v", metric.GetLabel())
+		}
+	}
+	if inserts != 2 {
+		t.Errorf("Expected 2 inserts, got %v", inserts)
+	}
+
+	var queries uint64
+	for _, metric := range metrics["csvstore_operation_duration_seconds"] {
+		if labelValue(metric, "operation") == csvstore.OpQuery {
+			queries = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	if queries != 1 {
+		t.Errorf("Expected 1 query in the latency histogram, got %d", queries)
+	}
+
+	rows := metrics["csvstore_rows_scanned_total"]
+	if len(rows) != 1 || rows[0].GetCounter().GetValue() < 2 {
+		t.Errorf("Expected rows scanned for users, got %v", rows)
+	}
+	for _, name := range []string{"csvstore_bytes_written_total", "csvstore_cache_hits_total", "csvstore_lock_wait_seconds_total"} {
+		if _, ok := metrics[name]; !ok {
+			t.Errorf("Expected metric %s", name)
+		}
+	}
+}
+
+func TestCollectorCompactionStats(t *testing.T) {
+	store := csvstore.NewMemoryStore(csvstore.WithStats())
+	defer store.Close()
+	store.ConfigureTable("users", csvstore.WithTombstones())
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := store.Insert("users", csvstore.CSVRecord{"name": name}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	conditions := []csvstore.QueryCondition{{Column: "name", Operator: "=", Value: "alice"}}
+	if _, err := store.Delete("users", conditions); err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(NewCollector(store, CollectorOptions{CompactionTables: []string{"users"}})); err != nil {
+		t.Fatalf("Failed to register collector: %v", err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	gauges := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if labelValue(metric, "table") == "users" && metric.GetGauge() != nil {
+				gauges[family.GetName()] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	if gauges["csvstore_live_rows"] != 1 || gauges["csvstore_tombstones"] != 1 || gauges["csvstore_dead_rows"] != 0 {
+		t.Errorf("Unexpected compaction metrics: %v", gauges)
+	}
+	if _, ok := gauges["csvstore_last_compaction_timestamp_seconds"]; !ok {
+		t.Error("Expected the last compaction time metric")
+	}
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+package csvstore
+
+import (
+	"context"
+	"time"
+)
+
+// Operation names passed to middleware
+const (
+	OpCheckTableExists   = "CheckTableExists"
+	OpCreateTable        = "CreateTable"
+	OpQuery              = "Query"
+	OpQuerySortedRange   = "QuerySortedRange"
+	OpSelect             = "Select"
+	OpInsert             = "Insert"
+	OpUpdate             = "Update"
+	OpDelete             = "Delete"
+	OpListTables         = "ListTables"
+	OpHistory            = "History"
+	OpExpireNow          = "ExpireNow"
+	OpBackup             = "Backup"
+	OpRestoreBackup      = "RestoreBackup"
+	OpRestoreToTime      = "RestoreToTime"
+	OpExportSQL          = "ExportSQL"
+	OpExportJSON         = "ExportJSON"
+	OpImportJSON         = "ImportJSON"
+	OpExportXLSX         = "ExportXLSX"
+	OpImportCSV          = "ImportCSV"
+	OpQueryToCSV         = "QueryToCSV"
+	OpQueryToJSON        = "QueryToJSON"
+	OpErase              = "Erase"
+	OpFlush              = "Flush"
+	OpMigrate            = "Migrate"
+	OpRollback           = "Rollback"
+	OpCheckSchema        = "CheckSchema"
+	OpRepairSchema       = "RepairSchema" // CheckSchema with Repair set
+	OpVerify             = "Verify"
+	OpRepair             = "Repair"
+	OpQueryTolerant      = "QueryTolerant"
+	OpCheckReferences    = "CheckReferences"
+	OpRefresh            = "Refresh"
+	OpHistogram          = "Histogram"
+	OpSample             = "Sample"
+	OpQueryTopNPerGroup  = "QueryTopNPerGroup"
+	OpQueryRunning       = "QueryRunning"
+	OpDedupe             = "Dedupe"
+	OpMergeTables        = "MergeTables"
+	OpDiff               = "Diff"
+	OpSync               = "Sync"
+	OpReplicate          = "Replicate"
+	OpQueryWithOptions   = "QueryWithOptions"
+	OpCompact            = "Compact"
+	OpHeaders            = "Headers"
+	OpListTenants        = "ListTenants"
+	OpDeleteTenant       = "DeleteTenant"
+	OpArchive            = "Archive"
+	OpArchiveNow         = "ArchiveNow"
+	OpApplyRetention     = "ApplyRetention"
+	OpCompactionStats    = "CompactionStats"
+	OpNormalize          = "Normalize"
+	OpUpdateRowChecksums = "UpdateRowChecksums"
+	OpSnapshot           = "Snapshot"
+	OpListSnapshots      = "ListSnapshots"
+	OpDeleteSnapshot     = "DeleteSnapshot"
+	OpRestoreSnapshot    = "RestoreSnapshot"
+	OpExportStore        = "ExportStore"
+	OpImportStore        = "ImportStore"
+	OpSeed               = "Seed"
+	OpColumnStats        = "ColumnStats"
+	OpDeleteCount        = "DeleteCount"
+)
+
+// Operation describes a store operation passing through the middleware chain
+type Operation struct {
+	Name  string // One of the Op* constants
+	Table string // Empty for store-wide operations such as ListTables
+	// Payload holds the operation arguments, e.g. []QueryCondition for Query and Delete,
+	// CSVRecord for Insert, UpdatePayload for Update. It is informational: changing it
+	// does not change the arguments the operation runs with.
+	Payload any
+	// Unmasked is set for reads through UnmaskedView, which skip column masks
+	Unmasked bool
+	// Context is the context of operations run through WithContext, nil otherwise
+	Context context.Context
+}
+
+// SelectPayload is the Operation payload of Select
+type SelectPayload struct {
+	Columns    []string
+	Conditions []QueryCondition
+}
+
+// SortedRangePayload is the Operation payload of QuerySortedRange
+type SortedRangePayload struct {
+	SortField string
+	SortBy    string
+	Limit     int
+}
+
+// UpdatePayload is the Operation payload of Update
+type UpdatePayload struct {
+	Updates    CSVRecord
+	Conditions []QueryCondition
+}
+
+// Handler executes an operation and returns its result
+type Handler func(op *Operation) (any, error)
+
+// Middleware wraps a Handler to add behavior around store operations, such as
+// logging, metrics, authorization, or retries. A middleware may return early
+// without calling next, call next several times, or replace its result.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware wraps every store operation in the given middleware.
+// The first middleware is the outermost one.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(cs *CSVStore) {
+		cs.middleware = append(cs.middleware, middleware...)
+	}
+}
+
+// runOperation runs fn through the middleware chain of the store
+func runOperation[T any](cs *CSVStore, op Operation, fn func() (T, error)) (T, error) {
+	handler := Handler(func(*Operation) (any, error) {
+		if cs.closed.Load() {
+			return nil, ErrClosed
+		}
+		if cs.readOnly && IsWriteOperation(op.Name) {
+			return nil, ErrReadOnly
+		}
+		if err := cs.authorize(&op); err != nil {
+			return nil, err
+		}
+		release, err := cs.acquireWriteSlot(&op)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return fn()
+	})
+	for i := len(cs.middleware) - 1; i >= 0; i-- {
+		handler = cs.middleware[i](handler)
+	}
+
+	span := cs.startSpan(&op)
+	scanned := cs.slowLog.scanned()
+	start := time.Now()
+	result, err := handler(&op)
+	duration := time.Since(start)
+	cs.stats.recordOperation(&op, duration, err)
+	endSpan(span, result, duration, err)
+	cs.slowLog.observe(&op, start, duration, scanned, err)
+	typed, _ := result.(T)
+	return typed, err
+}
@@ -0,0 +1,63 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	errDenied := errors.New("denied")
+	var calls []string
+	logging := func(next Handler) Handler {
+		return func(op *Operation) (any, error) {
+			calls = append(calls, op.Name+":"+op.Table)
+			return next(op)
+		}
+	}
+	readOnlySecrets := func(next Handler) Handler {
+		return func(op *Operation) (any, error) {
+			if op.Table == "secrets" && op.Name == OpDelete {
+				return nil, errDenied
+			}
+			return next(op)
+		}
+	}
+
+	store, err := NewCSVStore(testDir, WithMiddleware(logging, readOnlySecrets))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+
+	if err := store.CreateTable("secrets", []string{"id", "value"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("secrets", CSVRecord{"id": "1", "value": "s3cr3t"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	result, err := store.Select("secrets", []string{"value"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to select: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Expected 1 record through middleware, got %d", result.Count)
+	}
+
+	if _, err := store.Delete("secrets", nil); !errors.Is(err, errDenied) {
+		t.Errorf("Expected middleware to deny delete, got %v", err)
+	}
+
+	expectedCalls := []string{
+		"CreateTable:secrets",
+		"Insert:secrets",
+		"Select:secrets",
+		"Delete:secrets",
+	}
+	if !slices.Equal(calls, expectedCalls) {
+		t.Errorf("Expected calls %v, got %v", expectedCalls, calls)
+	}
+}
@@ -0,0 +1,713 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MigrationsTableName is the table recording the applied migrations
+const MigrationsTableName = "_migrations"
+
+// Migration is a numbered schema change. Migrations run in order of Version, and
+// each runs once; the applied versions are recorded in the _migrations table.
+// Each migration is applied atomically: the files its steps write, including
+// partitions, segments, and the tables changed by triggers and cascading
+// deletes, are staged apart from the store and moved into place only once every
+// step succeeded. Its change events are emitted, and its quota usage counted,
+// only then.
+type Migration struct {
+	Version     int
+	Description string
+	// Up lists the steps of the migration, declarative changes such as AddColumn
+	// or Go functions, run in order
+	Up []SchemaChange
+	// Down lists the steps reverting the migration, run by Rollback
+	Down []SchemaChange
+}
+
+// SchemaChange is a step of a migration
+type SchemaChange func(tx *MigrationTx) error
+
+// MigrationTx gives the steps of a migration access to the store. Its methods
+// must not be used after the step returns.
+type MigrationTx struct {
+	cs *CSVStore
+}
+
+// WithMigrations registers migrations and runs the pending ones when the store
+// is created
+func WithMigrations(migrations ...Migration) Option {
+	return func(cs *CSVStore) {
+		cs.migrations = append(cs.migrations, migrations...)
+	}
+}
+
+// Migrate runs the registered migrations that have not been applied yet, in
+// order of version, and returns the versions it applied. A failed migration
+// stops the run; the migrations before it stay applied.
+func (cs *CSVStore) Migrate() ([]int, error) {
+	return runOperation(cs, Operation{Name: OpMigrate, Table: MigrationsTableName},
+		func() ([]int, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return cs.migrate()
+		})
+}
+
+// migrate runs the pending migrations.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) migrate() ([]int, error) {
+	migrations, err := sortMigrations(cs.migrations)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := cs.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0)
+	for _, migration := range migrations {
+		if _, done := applied[migration.Version]; done {
+			continue
+		}
+		err := cs.runMigration(migration.Up, func(tx *MigrationTx) error {
+			return tx.recordMigration(migration)
+		})
+		if err != nil {
+			return versions, fmt.Errorf("failed to apply migration %d: %w", migration.Version, err)
+		}
+		versions = append(versions, migration.Version)
+	}
+	return versions, nil
+}
+
+// Rollback reverts the last n applied migrations, newest first, by running their
+// Down steps, and returns the versions it reverted. Each migration is reverted
+// atomically; a failure stops the rollback.
+func (cs *CSVStore) Rollback(n int) ([]int, error) {
+	return runOperation(cs, Operation{Name: OpRollback, Table: MigrationsTableName, Payload: n},
+		func() ([]int, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return cs.rollback(n)
+		})
+}
+
+// rollback reverts the last n applied migrations.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) rollback(n int) ([]int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("rollback count (%d) cannot be negative", n)
+	}
+	migrations, err := sortMigrations(cs.migrations)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := cs.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	appliedVersions := slices.Sorted(maps.Keys(applied))
+	slices.Reverse(appliedVersions)
+	versions := make([]int, 0)
+	for _, version := range appliedVersions[:min(n, len(appliedVersions))] {
+		i, found := slices.BinarySearchFunc(migrations, version, func(m Migration, v int) int {
+			return m.Version - v
+		})
+		if !found {
+			return versions, fmt.Errorf("migration %d is applied but not registered", version)
+		}
+		migration := migrations[i]
+		if migration.Down == nil && len(migration.Up) > 0 {
+			return versions, fmt.Errorf("migration %d has no down steps", version)
+		}
+
+		err := cs.runMigration(migration.Down, func(tx *MigrationTx) error {
+			return tx.unrecordMigration(version)
+		})
+		if err != nil {
+			return versions, fmt.Errorf("failed to roll back migration %d: %w", version, err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// sortMigrations returns migrations sorted by version, checking that versions
+// are positive and unique
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	sorted := slices.Clone(migrations)
+	slices.SortFunc(sorted, func(a, b Migration) int { return a.Version - b.Version })
+	for i, migration := range sorted {
+		if migration.Version <= 0 {
+			return nil, fmt.Errorf("migration version %d must be positive", migration.Version)
+		}
+		if i > 0 && sorted[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migration.Version)
+		}
+	}
+	return sorted, nil
+}
+
+// appliedMigrations returns the records of the applied migrations by version.
+// The caller must hold cs.mu.
+func (cs *CSVStore) appliedMigrations() (map[int]CSVRecord, error) {
+	applied := make(map[int]CSVRecord)
+	if !cs.tableExists(MigrationsTableName) {
+		return applied, nil
+	}
+
+	records, err := cs.loadTable(MigrationsTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		version, err := strconv.Atoi(record["version"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", record["version"], err)
+		}
+		applied[version] = record
+	}
+	return applied, nil
+}
+
+// recordMigration marks a migration as applied
+func (tx *MigrationTx) recordMigration(migration Migration) error {
+	if !tx.cs.tableExists(MigrationsTableName) {
+		err := tx.CreateTable(MigrationsTableName, []string{"version", "description", "applied_at"})
+		if err != nil {
+			return err
+		}
+	}
+	_, err := tx.Insert(MigrationsTableName, CSVRecord{
+		"version":     strconv.Itoa(migration.Version),
+		"description": migration.Description,
+		"applied_at":  time.Now().Format(time.RFC3339Nano),
+	})
+	return err
+}
+
+// unrecordMigration marks a migration as not applied
+func (tx *MigrationTx) unrecordMigration(version int) error {
+	_, err := tx.Delete(MigrationsTableName, []QueryCondition{
+		{Column: "version", Operator: "=", Value: strconv.Itoa(version)},
+	})
+	return err
+}
+
+// runMigration runs the steps of a migration followed by finish on a staging
+// FS, and moves the files they wrote into the store when all of them succeed.
+// The change events of the steps are emitted, and their quota usage kept, only
+// then.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) runMigration(changes []SchemaChange, finish SchemaChange) error {
+	staging, err := cs.makeTempDir(".migrate-")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer cs.fs.Remove(staging)
+
+	stage := newStagingFS(cs.fs, staging)
+	usage := cs.usage
+	var events []ChangeEvent
+	err = cs.runStaged(stage, &events, append(slices.Clone(changes), finish))
+	if err == nil {
+		if commitErr := stage.commit(); commitErr != nil {
+			err = fmt.Errorf("failed to commit migration: %w", commitErr)
+		}
+	}
+	if err != nil {
+		cs.usage = usage
+	}
+
+	// The state of the changed tables was taken from the staged files
+	for _, name := range stage.names() {
+		if tableName, ok := tableNameFromFile(name); ok && isPlainFileName(name) {
+			cs.trackWrite(tableName)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+	if err := cs.emitEvents(events); err != nil {
+		return fmt.Errorf("migration was applied but its changes were not recorded: %w", err)
+	}
+	return nil
+}
+
+// runStaged runs the steps of a migration with the store writing to stage,
+// collecting change events in events and tracking quota usage apart.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) runStaged(stage *stagingFS, events *[]ChangeEvent, changes []SchemaChange) error {
+	cs.fs = stage
+	cs.usage = maps.Clone(cs.usage)
+	cs.stagedEvents = events
+	defer func() {
+		cs.fs = stage.base
+		cs.stagedEvents = nil
+	}()
+
+	tx := &MigrationTx{cs: cs}
+	for _, change := range changes {
+		if err := change(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateTable creates a table
+func (tx *MigrationTx) CreateTable(tableName string, headers []string) error {
+	return tx.cs.createTable(tableName, headers)
+}
+
+// DropTable removes a table with its partitions and segments
+func (tx *MigrationTx) DropTable(tableName string) error {
+	if !tx.cs.tableExists(tableName) {
+		return fmt.Errorf("table %s does not exist", tableName)
+	}
+	partitions, err := tx.cs.tablePartitions(tableName)
+	if err != nil {
+		return err
+	}
+	for _, partition := range partitions {
+		if err := tx.cs.fs.Remove(partition.file); err != nil {
+			return fmt.Errorf("failed to remove partition %s of table %s: %w", partition.key, tableName, err)
+		}
+		tx.cs.trackWrite(partitionTable(tableName, partition.key))
+	}
+	segments, err := tx.cs.tableSegments(tableName)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if err := tx.cs.fs.Remove(segment.file); err != nil {
+			return fmt.Errorf("failed to remove segment %d of table %s: %w", segment.n, tableName, err)
+		}
+		tx.cs.trackWrite(segmentTable(tableName, segment.n))
+	}
+
+	if err := tx.cs.fs.Remove(tx.cs.getTableFile(tableName)); err != nil {
+		return err
+	}
+	tx.cs.trackWrite(tableName)
+	return nil
+}
+
+// RenameTable renames a table, rewriting it in the format configured for the new name
+func (tx *MigrationTx) RenameTable(oldName, newName string) error {
+	if tx.cs.tableExists(newName) {
+		return fmt.Errorf("table %s already exists", newName)
+	}
+	headers, records, err := tx.load(oldName)
+	if err != nil {
+		return err
+	}
+	if err := tx.cs.saveTable(newName, headers, records); err != nil {
+		return err
+	}
+	return tx.DropTable(oldName)
+}
+
+// AddColumn appends a column to a table, filled with value in existing rows
+func (tx *MigrationTx) AddColumn(tableName, column, value string) error {
+	headers, records, err := tx.load(tableName)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(headers, column) {
+		return fmt.Errorf("column %s already exists in table %s", column, tableName)
+	}
+	for _, record := range records {
+		record[column] = value
+	}
+	return tx.cs.saveTable(tableName, append(headers, column), records)
+}
+
+// DropColumn removes a column from a table
+func (tx *MigrationTx) DropColumn(tableName, column string) error {
+	headers, records, err := tx.load(tableName)
+	if err != nil {
+		return err
+	}
+	i := slices.Index(headers, column)
+	if i < 0 {
+		return fmt.Errorf("column %s does not exist in table %s", column, tableName)
+	}
+	return tx.cs.saveTable(tableName, slices.Delete(headers, i, i+1), records)
+}
+
+// RenameColumn renames a column of a table
+func (tx *MigrationTx) RenameColumn(tableName, oldName, newName string) error {
+	headers, records, err := tx.load(tableName)
+	if err != nil {
+		return err
+	}
+	i := slices.Index(headers, oldName)
+	if i < 0 {
+		return fmt.Errorf("column %s does not exist in table %s", oldName, tableName)
+	}
+	if slices.Contains(headers, newName) {
+		return fmt.Errorf("column %s already exists in table %s", newName, tableName)
+	}
+	headers[i] = newName
+	for _, record := range records {
+		record[newName] = record[oldName]
+	}
+	return tx.cs.saveTable(tableName, headers, records)
+}
+
+// Query returns the records of a table matching conditions
+func (tx *MigrationTx) Query(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	return tx.cs.query(tableName, conditions)
+}
+
+// Insert adds a record to a table
+func (tx *MigrationTx) Insert(tableName string, record CSVRecord) (CSVRecord, error) {
+	return tx.cs.insert(tableName, maps.Clone(record))
+}
+
+// Update updates the records of a table matching conditions
+func (tx *MigrationTx) Update(tableName string, updates CSVRecord, conditions []QueryCondition) (*QueryResult, error) {
+	return tx.cs.update(tableName, updates, conditions)
+}
+
+// Delete removes the records of a table matching conditions
+func (tx *MigrationTx) Delete(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	return tx.cs.delete(tableName, conditions)
+}
+
+// load returns the headers and records of a table about to be changed
+func (tx *MigrationTx) load(tableName string) ([]string, []CSVRecord, error) {
+	if !tx.cs.tableExists(tableName) {
+		return nil, nil, fmt.Errorf("table %s does not exist", tableName)
+	}
+	headers, err := tx.cs.getHeaders(tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, err := tx.cs.loadTable(tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return headers, records, nil
+}
+
+// CreateTableChange is a SchemaChange creating a table
+func CreateTableChange(tableName string, headers []string) SchemaChange {
+	return func(tx *MigrationTx) error {
+		return tx.CreateTable(tableName, headers)
+	}
+}
+
+// DropTableChange is a SchemaChange removing a table
+func DropTableChange(tableName string) SchemaChange {
+	return func(tx *MigrationTx) error {
+		return tx.DropTable(tableName)
+	}
+}
+
+// RenameTableChange is a SchemaChange renaming a table
+func RenameTableChange(oldName, newName string) SchemaChange {
+	return func(tx *MigrationTx) error {
+		return tx.RenameTable(oldName, newName)
+	}
+}
+
+// AddColumn is a SchemaChange appending a column filled with value
+func AddColumn(tableName, column, value string) SchemaChange {
+	return func(tx *MigrationTx) error {
+		return tx.AddColumn(tableName, column, value)
+	}
+}
+
+// DropColumn is a SchemaChange removing a column
+func DropColumn(tableName, column string) SchemaChange {
+	return func(tx *MigrationTx) error {
+		return tx.DropColumn(tableName, column)
+	}
+}
+
+// RenameColumn is a SchemaChange renaming a column
+func RenameColumn(tableName, oldName, newName string) SchemaChange {
+	return func(tx *MigrationTx) error {
+		return tx.RenameColumn(tableName, oldName, newName)
+	}
+}
+
+// stagingFS is an FS keeping the changes of a migration apart from the files of
+// the store until they are committed: written files go to a staging directory
+// and removed files are only hidden, while unchanged files are read from base
+type stagingFS struct {
+	base FS
+	dir  string
+	// staged holds the names written under dir, true for directories
+	staged map[string]bool
+	// removed holds the names of base files removed by the migration
+	removed map[string]bool
+}
+
+// newStagingFS returns a staging FS over base keeping changes under dir, an
+// empty directory of base
+func newStagingFS(base FS, dir string) *stagingFS {
+	return &stagingFS{base: base, dir: dir, staged: make(map[string]bool), removed: make(map[string]bool)}
+}
+
+// path returns the path in base of the staged version of name
+func (s *stagingFS) path(name string) string {
+	return path.Join(s.dir, name)
+}
+
+// inBase reports whether name may refer to a file or directory of base: one
+// the migration neither replaced by a staged file nor removed
+func (s *stagingFS) inBase(name string) bool {
+	dir, staged := s.staged[name]
+	return (!staged || dir) && !s.removed[name] && name != s.dir
+}
+
+func (s *stagingFS) Open(name string) (io.ReadCloser, error) {
+	if dir, staged := s.staged[name]; staged && !dir {
+		return s.base.Open(s.path(name))
+	}
+	if !s.inBase(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return s.base.Open(name)
+}
+
+func (s *stagingFS) Create(name string) (io.WriteCloser, error) {
+	if err := s.stageParent(name); err != nil {
+		return nil, err
+	}
+	file, err := s.base.Create(s.path(name))
+	if err != nil {
+		return nil, err
+	}
+	s.stage(name, false)
+	return file, nil
+}
+
+func (s *stagingFS) Append(name string) (io.WriteCloser, error) {
+	if _, staged := s.staged[name]; !staged {
+		if err := s.copyFromBase(name, name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if err := s.stageParent(name); err != nil {
+		return nil, err
+	}
+	file, err := s.base.Append(s.path(name))
+	if err != nil {
+		return nil, err
+	}
+	s.stage(name, false)
+	return file, nil
+}
+
+func (s *stagingFS) Rename(oldName, newName string) error {
+	if dir, staged := s.staged[oldName]; staged {
+		if dir {
+			return &fs.PathError{Op: "rename", Path: oldName, Err: errors.ErrUnsupported}
+		}
+		if err := s.stageParent(newName); err != nil {
+			return err
+		}
+		if err := s.base.Rename(s.path(oldName), s.path(newName)); err != nil {
+			return err
+		}
+		delete(s.staged, oldName)
+	} else {
+		if err := s.copyFromBase(oldName, newName); err != nil {
+			return err
+		}
+		s.removed[oldName] = true
+	}
+	s.stage(newName, false)
+	return nil
+}
+
+func (s *stagingFS) Remove(name string) error {
+	dir, staged := s.staged[name]
+	if staged {
+		if err := s.base.Remove(s.path(name)); err != nil {
+			return err
+		}
+		for other := range s.staged {
+			if other == name || strings.HasPrefix(other, name+"/") {
+				delete(s.staged, other)
+			}
+		}
+	}
+	if s.removed[name] || name == s.dir {
+		if staged {
+			return nil
+		}
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	info, err := s.base.Stat(name)
+	if err != nil {
+		if staged && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if info.IsDir() && !(staged && dir) {
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.ErrUnsupported}
+	}
+	s.removed[name] = true
+	return nil
+}
+
+func (s *stagingFS) Stat(name string) (fs.FileInfo, error) {
+	if _, staged := s.staged[name]; staged {
+		return s.base.Stat(s.path(name))
+	}
+	if !s.inBase(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return s.base.Stat(name)
+}
+
+func (s *stagingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	found := false
+	if s.inBase(name) {
+		base, err := s.base.ReadDir(name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		found = err == nil
+		for _, entry := range base {
+			child := path.Join(name, entry.Name())
+			if _, staged := s.staged[child]; !staged && s.inBase(child) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if _, staged := s.staged[name]; staged || name == "." {
+		staged, err := s.base.ReadDir(s.path(name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		found = found || err == nil
+		entries = append(entries, staged...)
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+func (s *stagingFS) Mkdir(name string) error {
+	if _, err := s.Stat(name); err == nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	if err := s.stageParent(name); err != nil {
+		return err
+	}
+	if err := s.base.Mkdir(s.path(name)); err != nil {
+		return err
+	}
+	s.stage(name, true)
+	return nil
+}
+
+// stage records name as written under the staging directory
+func (s *stagingFS) stage(name string, dir bool) {
+	s.staged[name] = dir
+	delete(s.removed, name)
+}
+
+// stageParent creates the staging directories holding the staged version of name
+func (s *stagingFS) stageParent(name string) error {
+	dir := path.Dir(name)
+	if dir == "." {
+		return nil
+	}
+	if _, staged := s.staged[dir]; staged {
+		return nil
+	}
+	if err := s.stageParent(dir); err != nil {
+		return err
+	}
+	if err := s.base.Mkdir(s.path(dir)); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	s.staged[dir] = true
+	return nil
+}
+
+// copyFromBase stages a copy of the base file name as target
+func (s *stagingFS) copyFromBase(name, target string) error {
+	if !s.inBase(name) {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	src, err := s.base.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := s.stageParent(target); err != nil {
+		return err
+	}
+	dst, err := s.base.Create(s.path(target))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// names returns the names changed by the migration, sorted
+func (s *stagingFS) names() []string {
+	names := slices.Collect(maps.Keys(s.staged))
+	names = append(names, slices.Collect(maps.Keys(s.removed))...)
+	slices.Sort(names)
+	return names
+}
+
+// commit moves the staged files into base and removes the removed ones.
+// Directories come before the files they contain.
+func (s *stagingFS) commit() error {
+	for _, name := range slices.Sorted(maps.Keys(s.staged)) {
+		if s.staged[name] {
+			if err := s.base.Mkdir(name); err != nil && !errors.Is(err, fs.ErrExist) {
+				return err
+			}
+			continue
+		}
+		if err := s.base.Rename(s.path(name), name); err != nil {
+			return err
+		}
+	}
+	for name := range s.removed {
+		if err := s.base.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
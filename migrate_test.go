@@ -0,0 +1,289 @@
+package csvstore
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	migrations := []Migration{
+		{Version: 2, Description: "add email", Up: []SchemaChange{
+			AddColumn("users", "email", ""),
+			RenameColumn("users", "name", "full_name"),
+		}},
+		{Version: 1, Description: "create users", Up: []SchemaChange{
+			CreateTableChange("users", []string{"id", "name"}),
+			func(tx *MigrationTx) error {
+				_, err := tx.Insert("users", CSVRecord{"name": "ann"})
+				return err
+			},
+		}},
+	}
+
+	store, err := NewCSVStore(testDir, WithMigrations(migrations...))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	headers, err := store.getHeaders("users")
+	if err != nil {
+		t.Fatalf("Failed to get headers: %v", err)
+	}
+	if !slices.Equal(headers, []string{"id", "full_name", "email"}) {
+		t.Errorf("Unexpected headers: %v", headers)
+	}
+	result, err := store.Query("users", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["full_name"] != "ann" {
+		t.Errorf("Unexpected records: %v", result.Records)
+	}
+	store.Close()
+
+	// Applied migrations do not run again; new ones do
+	failing := Migration{Version: 4, Up: []SchemaChange{func(*MigrationTx) error {
+		return errors.New("boom")
+	}}}
+	more := append(migrations, Migration{Version: 3, Up: []SchemaChange{DropColumn("users", "email")}})
+	if _, err := NewCSVStore(testDir, WithMigrations(append(more, failing)...)); err == nil {
+		t.Fatalf("Expected the failing migration to fail store creation")
+	}
+
+	store, err = NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	applied, err := store.Query(MigrationsTableName, nil)
+	if err != nil {
+		t.Fatalf("Failed to query migrations: %v", err)
+	}
+	if applied.Count != 3 {
+		t.Errorf("Expected 3 applied migrations, got %v", applied.Records)
+	}
+
+	store.migrations = more
+	versions, err := store.Migrate()
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if len(versions) != 0 {
+		t.Errorf("Expected no pending migrations, got %v", versions)
+	}
+}
+
+func TestMigrationRollback(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	migrations := []Migration{
+		{
+			Version: 1,
+			Up:      []SchemaChange{CreateTableChange("users", []string{"id", "name"})},
+			Down:    []SchemaChange{DropTableChange("users")},
+		},
+		{
+			Version: 2,
+			Up:      []SchemaChange{AddColumn("users", "email", "")},
+			Down:    []SchemaChange{DropColumn("users", "email")},
+		},
+	}
+	store, err := NewCSVStore(testDir, WithMigrations(migrations...))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.Insert("users", CSVRecord{"name": "ann", "email": "a@example.com"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	// A failing migration leaves no trace
+	store.migrations = append(store.migrations, Migration{Version: 3, Up: []SchemaChange{
+		RenameColumn("users", "name", "full_name"),
+		CreateTableChange("teams", []string{"id"}),
+		func(*MigrationTx) error { return errors.New("boom") },
+	}})
+	if _, err := store.Migrate(); err == nil {
+		t.Fatalf("Expected migration 3 to fail")
+	}
+	headers, err := store.getHeaders("users")
+	if err != nil {
+		t.Fatalf("Failed to get headers: %v", err)
+	}
+	if !slices.Equal(headers, []string{"id", "name", "email"}) {
+		t.Errorf("Expected the failed migration to be undone, got headers %v", headers)
+	}
+	if store.CheckTableExists("teams") {
+		t.Errorf("Expected the table created by the failed migration to be removed")
+	}
+	store.migrations = migrations
+
+	versions, err := store.Rollback(1)
+	if err != nil {
+		t.Fatalf("Failed to roll back: %v", err)
+	}
+	if !slices.Equal(versions, []int{2}) {
+		t.Errorf("Expected to roll back migration 2, got %v", versions)
+	}
+	headers, err = store.getHeaders("users")
+	if err != nil {
+		t.Fatalf("Failed to get headers: %v", err)
+	}
+	if !slices.Equal(headers, []string{"id", "name"}) {
+		t.Errorf("Unexpected headers after rollback: %v", headers)
+	}
+
+	if _, err := store.Rollback(5); err != nil {
+		t.Fatalf("Failed to roll back: %v", err)
+	}
+	if store.CheckTableExists("users") {
+		t.Errorf("Expected users table to be dropped")
+	}
+	applied, err := store.Query(MigrationsTableName, nil)
+	if err != nil {
+		t.Fatalf("Failed to query migrations: %v", err)
+	}
+	if applied.Count != 0 {
+		t.Errorf("Expected no applied migrations, got %v", applied.Records)
+	}
+
+	versions, err = store.Migrate()
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if !slices.Equal(versions, []int{1, 2}) {
+		t.Errorf("Expected to reapply migrations 1 and 2, got %v", versions)
+	}
+}
+
+func TestMigrationStaging(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("orders",
+		WithPartitioning("region", PartitionByValue),
+		WithForeignKey(ForeignKey{Column: "user_id", References: "users", OnDelete: OnDeleteCascade}),
+	)
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := store.CreateTable("orders", []string{"id", "user_id", "region"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	inserts := []struct {
+		table  string
+		record CSVRecord
+	}{
+		{"users", CSVRecord{"id": "u1", "name": "ann"}},
+		{"users", CSVRecord{"id": "u2", "name": "bob"}},
+		{"orders", CSVRecord{"id": "o1", "user_id": "u1", "region": "eu"}},
+		{"orders", CSVRecord{"id": "o2", "user_id": "u1", "region": "us"}},
+		{"orders", CSVRecord{"id": "o3", "user_id": "u2", "region": "us"}},
+	}
+	for _, insert := range inserts {
+		if _, err := store.Insert(insert.table, insert.record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	files := func() map[string]string {
+		t.Helper()
+		entries, err := os.ReadDir(testDir)
+		if err != nil {
+			t.Fatalf("Failed to list store: %v", err)
+		}
+		contents := make(map[string]string)
+		for _, entry := range entries {
+			data, _ := os.ReadFile(filepath.Join(testDir, entry.Name()))
+			contents[entry.Name()] = string(data)
+		}
+		return contents
+	}
+	before := files()
+	events, cancel := store.Subscribe("")
+	defer cancel()
+
+	// A step failing after a delete cascading to partitions leaves every file as it was
+	var staged int
+	store.migrations = []Migration{{Version: 1, Up: []SchemaChange{
+		func(tx *MigrationTx) error {
+			_, err := tx.Delete("users", []QueryCondition{{Column: "id", Operator: "=", Value: "u1"}})
+			return err
+		},
+		func(tx *MigrationTx) error {
+			orders, err := tx.Query("orders", nil)
+			if err != nil {
+				return err
+			}
+			staged = orders.Count
+			return errors.New("boom")
+		},
+	}}}
+	if _, err := store.Migrate(); err == nil {
+		t.Fatalf("Expected the migration to fail")
+	}
+	if staged != 1 {
+		t.Errorf("Expected the later step to see the cascaded delete, got %d orders", staged)
+	}
+	if after := files(); !maps.Equal(before, after) {
+		t.Errorf("Expected the failed migration to leave the files unchanged, got %v", slices.Sorted(maps.Keys(after)))
+	}
+	orders, err := store.Query("orders", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if orders.Count != 3 {
+		t.Errorf("Expected the cascaded delete to be undone, got %v", orders.Records)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no change events from the failed migration, got %d", len(events))
+	}
+
+	// The same changes are moved into place once every step succeeded
+	store.migrations[0].Up = store.migrations[0].Up[:1]
+	if _, err := store.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	orders, err = store.Query("orders", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if orders.Count != 1 || orders.Records[0]["id"] != "o3" {
+		t.Errorf("Expected the cascaded delete to be applied, got %v", orders.Records)
+	}
+	// The deleted user, its two orders, and the migrations table and record
+	if len(events) != 5 {
+		t.Errorf("Expected the change events of the migration once committed, got %d", len(events))
+	}
+
+	// Dropping a partitioned table removes its partitions
+	store.migrations = append(store.migrations, Migration{Version: 2, Up: []SchemaChange{DropTableChange("orders")}})
+	if _, err := store.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	for name := range files() {
+		if strings.HasPrefix(name, "orders") {
+			t.Errorf("Expected %s to be removed with its table", name)
+		}
+	}
+	for name := range files() {
+		if strings.HasPrefix(name, ".migrate-") {
+			t.Errorf("Expected the staging directory %s to be removed", name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package csvstore
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Normalize rewrites a table in canonical form, so that table files checked
+// into git produce clean diffs. Rows are written in the quoting and line
+// endings of WithWriterOptions, blank lines are dropped, and the columns are
+// ordered with the id column first and the created_at and updated_at columns
+// last, the others keeping their order. Leading comments are kept, and the
+// values and order of the rows are unchanged.
+func (cs *CSVStore) Normalize(tableName string) error {
+	_, err := runOperation(cs, Operation{Name: OpNormalize, Table: tableName},
+		func() (any, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return nil, cs.normalize(tableName)
+		})
+	return err
+}
+
+// normalize rewrites a table in canonical form.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) normalize(tableName string) error {
+	if !cs.tableExists(tableName) {
+		return fmt.Errorf("table %s does not exist", tableName)
+	}
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return err
+	}
+	records, err := cs.loadTable(tableName)
+	if err != nil {
+		return err
+	}
+	return cs.saveTable(tableName, canonicalColumns(headers, cs.reservedColumns(tableName)), records)
+}
+
+// canonicalColumns returns headers with the id column first and the timestamp
+// columns last
+func canonicalColumns(headers []string, reserved ReservedColumns) []string {
+	columns := make([]string, 0, len(headers))
+	if slices.Contains(headers, reserved.ID) {
+		columns = append(columns, reserved.ID)
+	}
+	for _, header := range headers {
+		if header != reserved.ID && header != reserved.CreatedAt && header != reserved.UpdatedAt {
+			columns = append(columns, header)
+		}
+	}
+	for _, column := range []string{reserved.CreatedAt, reserved.UpdatedAt} {
+		if slices.Contains(headers, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,45 @@
+package csvstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	messy := "created_at,name,id,note\r\n" +
+		"2024-01-01T00:00:00Z,\"alice\",1,\"plain\"\n" +
+		"\n" +
+		"2024-01-02T00:00:00Z,bob,2,\"has, comma\"\r\n"
+	path := filepath.Join(testDir, "users.csv")
+	if err := os.WriteFile(path, []byte(messy), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+
+	if err := store.Normalize("users"); err != nil {
+		t.Fatalf("Failed to normalize: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	expected := "id,name,note,created_at\n" +
+		"1,alice,plain,2024-01-01T00:00:00Z\n" +
+		"2,bob,\"has, comma\",2024-01-02T00:00:00Z\n"
+	if string(data) != expected {
+		t.Errorf("Expected canonical table file:\n%s\ngot:\n%s", expected, data)
+	}
+
+	if err := store.Normalize("missing"); err == nil {
+		t.Error("Expected error normalizing a missing table")
+	}
+}
@@ -0,0 +1,21 @@
+package csvstore
+
+// WithNull marks the cells of a table equal to sentinel, e.g. `\N` or "NULL", as
+// null, so an unknown value can be told apart from an empty one. Insert stores
+// the sentinel in the columns a record leaves out. Null cells match only the
+// is_null operator: they are neither equal nor unequal to any value, and they
+// sort like missing fields. Without a sentinel, is_null matches empty cells.
+func WithNull(sentinel string) TableOption {
+	return func(c *tableConfig) {
+		c.null = sentinel
+	}
+}
+
+// NullValue returns the null sentinel of a table set with WithNull, or "" when
+// the table has none
+func (cs *CSVStore) NullValue(tableName string) string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	return cs.tableConfig(tableName).null
+}
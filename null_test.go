@@ -0,0 +1,59 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNull(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir, WithTableDefaults(WithNull(`\N`)))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("people", []string{"id", "name", "age"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, record := range []CSVRecord{
+		{"id": "1", "name": "a", "age": "30"},
+		{"id": "2", "name": ""},
+		{"id": "3", "name": "c", "age": "20"},
+	} {
+		if _, err := store.Insert("people", record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		condition QueryCondition
+		expected  int
+	}{
+		{QueryCondition{Column: "age", Operator: "is_null"}, 1},
+		{QueryCondition{Column: "age", Operator: "is_not_null"}, 2},
+		{QueryCondition{Column: "name", Operator: "is_null"}, 0},
+		{QueryCondition{Column: "name", Operator: "=", Value: ""}, 1},
+		{QueryCondition{Column: "age", Operator: "!=", Value: "30"}, 1},
+		{QueryCondition{Column: "age", Operator: "<", Value: "100"}, 2},
+	}
+	for _, tt := range tests {
+		result, err := store.Query("people", []QueryCondition{tt.condition})
+		if err != nil {
+			t.Fatalf("Failed to query records: %v", err)
+		}
+		if result.Count != tt.expected {
+			t.Errorf("Expected %d records for %v, got %d", tt.expected, tt.condition, result.Count)
+		}
+	}
+
+	sorted, err := store.QuerySortedRange("people", "age", "desc", 3)
+	if err != nil {
+		t.Fatalf("Failed to query sorted records: %v", err)
+	}
+	if sorted.Records[0]["id"] != "1" || sorted.Records[2]["id"] != "2" {
+		t.Errorf("Expected the null age to sort last in descending order, got %v", sorted.Records)
+	}
+}
@@ -0,0 +1,374 @@
+package csvstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPreconditionFailed is returned by ObjectStorage.Put when a write condition
+// does not hold, i.e. another writer changed the object in the meantime
+var ErrPreconditionFailed = errors.New("object was modified by another writer")
+
+// ObjectInfo describes an object of an ObjectStorage
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ETag    string // Changes with every write of the object
+	ModTime time.Time
+}
+
+// PutCondition makes an ObjectStorage write conditional
+type PutCondition struct {
+	// IfMatch writes only if the current ETag of the object equals it
+	IfMatch string
+	// IfNoneMatch writes only if the object does not exist
+	IfNoneMatch bool
+}
+
+// ObjectStorage is a bucket of an object store such as S3 or GCS. Adapters map
+// PutCondition to the conditional write support of the service: If-Match and
+// If-None-Match headers on S3, or generation preconditions on GCS. Missing
+// objects are reported with errors wrapping fs.ErrNotExist.
+type ObjectStorage interface {
+	Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	Put(ctx context.Context, key string, r io.Reader, cond PutCondition) (ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose keys start with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ObjectFSOptions controls NewObjectFS
+type ObjectFSOptions struct {
+	// Prefix is prepended to the names of store files to form object keys,
+	// e.g. "stores/billing/"
+	Prefix string
+	// Timeout bounds every storage request; zero means no timeout
+	Timeout time.Duration
+}
+
+// objectFS is an FS keeping store files as objects of an ObjectStorage
+type objectFS struct {
+	storage ObjectStorage
+	opts    ObjectFSOptions
+
+	mu      sync.Mutex
+	cache   map[string]cachedObject
+	dirs    map[string]bool
+	onCache func(hit bool) // Counts cache lookups, when set
+}
+
+// cachedObject is the local copy of an object
+type cachedObject struct {
+	etag string
+	data []byte
+}
+
+// NewObjectFS returns an FS storing files in an object storage bucket, so several
+// services can share a store without a shared disk (pass it to WithFS).
+//
+// Objects are cached locally and only downloaded again when their ETag changes.
+// Every write is conditional on the object being unchanged since this FS last
+// read or wrote it, so a store that lost a race with another writer fails with
+// ErrPreconditionFailed instead of overwriting its changes. Directories are kept
+// locally and only used for temporary files.
+func NewObjectFS(storage ObjectStorage, opts ObjectFSOptions) FS {
+	return &objectFS{
+		storage: storage,
+		opts:    opts,
+		cache:   make(map[string]cachedObject),
+		dirs:    map[string]bool{".": true},
+	}
+}
+
+// context returns the context of a storage request
+func (o *objectFS) context() (context.Context, context.CancelFunc) {
+	if o.opts.Timeout > 0 {
+		return context.WithTimeout(context.Background(), o.opts.Timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// key returns the object key of a file name
+func (o *objectFS) key(name string) string {
+	return o.opts.Prefix + name
+}
+
+func (o *objectFS) observeCache(observe func(hit bool)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.onCache = observe
+}
+
+// fetch returns the contents of an object, from the cache when it is current
+func (o *objectFS) fetch(name string) (cachedObject, error) {
+	ctx, cancel := o.context()
+	defer cancel()
+
+	info, err := o.storage.Stat(ctx, o.key(name))
+	if err != nil {
+		return cachedObject{}, err
+	}
+	o.mu.Lock()
+	cached, exists := o.cache[name]
+	onCache := o.onCache
+	o.mu.Unlock()
+	hit := exists && cached.etag == info.ETag
+	if onCache != nil {
+		onCache(hit)
+	}
+	if hit {
+		return cached, nil
+	}
+
+	body, info, err := o.storage.Get(ctx, o.key(name))
+	if err != nil {
+		return cachedObject{}, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return cachedObject{}, err
+	}
+
+	cached = cachedObject{etag: info.ETag, data: data}
+	o.mu.Lock()
+	o.cache[name] = cached
+	o.mu.Unlock()
+	return cached, nil
+}
+
+// put writes an object, conditional on the version this FS knows about
+func (o *objectFS) put(name string, data []byte) error {
+	ctx, cancel := o.context()
+	defer cancel()
+
+	o.mu.Lock()
+	cached, exists := o.cache[name]
+	o.mu.Unlock()
+	cond := PutCondition{IfMatch: cached.etag}
+	if !exists {
+		if _, err := o.storage.Stat(ctx, o.key(name)); err == nil {
+			// Never read by this FS; refuse to overwrite blindly
+			return &fs.PathError{Op: "write", Path: name, Err: ErrPreconditionFailed}
+		}
+		cond = PutCondition{IfNoneMatch: true}
+	}
+
+	info, err := o.storage.Put(ctx, o.key(name), bytes.NewReader(data), cond)
+	if err != nil {
+		o.mu.Lock()
+		delete(o.cache, name)
+		o.mu.Unlock()
+		return &fs.PathError{Op: "write", Path: name, Err: err}
+	}
+
+	o.mu.Lock()
+	o.cache[name] = cachedObject{etag: info.ETag, data: data}
+	o.mu.Unlock()
+	return nil
+}
+
+func (o *objectFS) Open(name string) (io.ReadCloser, error) {
+	cached, err := o.fetch(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(cached.data)), nil
+}
+
+func (o *objectFS) Create(name string) (io.WriteCloser, error) {
+	if _, err := o.fetch(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return &objectWriter{fs: o, name: name}, nil
+}
+
+func (o *objectFS) Append(name string) (io.WriteCloser, error) {
+	// Objects cannot be appended to; the whole object is written on close
+	writer := &objectWriter{fs: o, name: name}
+	cached, err := o.fetch(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	writer.buf.Write(cached.data)
+	return writer, nil
+}
+
+// objectWriter buffers the contents of an object until it is closed
+type objectWriter struct {
+	fs     *objectFS
+	name   string
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (w *objectWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *objectWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.fs.put(w.name, w.buf.Bytes())
+}
+
+func (o *objectFS) Rename(oldName, newName string) error {
+	names, err := o.filesUnder(oldName)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: fs.ErrNotExist}
+	}
+
+	for _, name := range names {
+		target := newName + strings.TrimPrefix(name, oldName)
+		cached, err := o.fetch(name)
+		if err != nil {
+			return err
+		}
+		if _, err := o.fetch(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := o.put(target, cached.data); err != nil {
+			return err
+		}
+		if err := o.Remove(name); err != nil {
+			return err
+		}
+	}
+
+	o.mu.Lock()
+	if o.dirs[oldName] {
+		delete(o.dirs, oldName)
+		o.dirs[newName] = true
+	}
+	o.mu.Unlock()
+	return nil
+}
+
+// filesUnder returns the file name itself if it names a file, or the files
+// inside it if it names a directory
+func (o *objectFS) filesUnder(name string) ([]string, error) {
+	ctx, cancel := o.context()
+	defer cancel()
+
+	if _, err := o.storage.Stat(ctx, o.key(name)); err == nil {
+		return []string{name}, nil
+	}
+	objects, err := o.storage.List(ctx, o.key(name+"/"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(objects))
+	for _, object := range objects {
+		names = append(names, strings.TrimPrefix(object.Key, o.opts.Prefix))
+	}
+	return names, nil
+}
+
+func (o *objectFS) Remove(name string) error {
+	names, err := o.filesUnder(name)
+	if err != nil {
+		return err
+	}
+	o.mu.Lock()
+	isDir := o.dirs[name]
+	delete(o.dirs, name)
+	o.mu.Unlock()
+	if len(names) == 0 && !isDir {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	ctx, cancel := o.context()
+	defer cancel()
+	for _, file := range names {
+		if err := o.storage.Delete(ctx, o.key(file)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		o.mu.Lock()
+		delete(o.cache, file)
+		o.mu.Unlock()
+	}
+	return nil
+}
+
+func (o *objectFS) Stat(name string) (fs.FileInfo, error) {
+	o.mu.Lock()
+	isDir := o.dirs[name]
+	o.mu.Unlock()
+	if isDir {
+		return &memFileInfo{name: path.Base(name), dir: true}, nil
+	}
+
+	ctx, cancel := o.context()
+	defer cancel()
+	info, err := o.storage.Stat(ctx, o.key(name))
+	if err != nil {
+		return nil, err
+	}
+	return &memFileInfo{name: path.Base(name), size: info.Size, modTime: info.ModTime}, nil
+}
+
+func (o *objectFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	prefix := ""
+	if name != "." {
+		prefix = name + "/"
+	}
+
+	ctx, cancel := o.context()
+	defer cancel()
+	objects, err := o.storage.List(ctx, o.key(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]fs.DirEntry, 0, len(objects))
+	seenDirs := make(map[string]bool)
+	for _, object := range objects {
+		rest := strings.TrimPrefix(object.Key, o.key(prefix))
+		if dir, _, nested := strings.Cut(rest, "/"); nested {
+			seenDirs[dir] = true
+			continue
+		}
+		info := &memFileInfo{name: rest, size: object.Size, modTime: object.ModTime}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	o.mu.Lock()
+	for dir := range o.dirs {
+		if dir != "." && path.Dir(dir) == name {
+			seenDirs[path.Base(dir)] = true
+		}
+	}
+	o.mu.Unlock()
+	for dir := range seenDirs {
+		entries = append(entries, fs.FileInfoToDirEntry(&memFileInfo{name: dir, dir: true}))
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+func (o *objectFS) Mkdir(name string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.dirs[name] {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	o.dirs[name] = true
+	return nil
+}
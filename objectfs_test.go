@@ -0,0 +1,278 @@
+package csvstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeObjectStorage is an in-memory ObjectStorage counting downloads
+type fakeObjectStorage struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	etags     map[string]string
+	version   int
+	downloads int
+}
+
+func newFakeObjectStorage() *fakeObjectStorage {
+	return &fakeObjectStorage{objects: make(map[string][]byte), etags: make(map[string]string)}
+}
+
+func (s *fakeObjectStorage) info(key string) ObjectInfo {
+	return ObjectInfo{Key: key, Size: int64(len(s.objects[key])), ETag: s.etags[key], ModTime: time.Now()}
+}
+
+func (s *fakeObjectStorage) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, exists := s.objects[key]
+	if !exists {
+		return nil, ObjectInfo{}, fs.ErrNotExist
+	}
+	s.downloads++
+	return io.NopCloser(bytes.NewReader(data)), s.info(key), nil
+}
+
+func (s *fakeObjectStorage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.objects[key]; !exists {
+		return ObjectInfo{}, fs.ErrNotExist
+	}
+	return s.info(key), nil
+}
+
+func (s *fakeObjectStorage) Put(ctx context.Context, key string, r io.Reader, cond PutCondition) (ObjectInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, exists := s.objects[key]
+	if (cond.IfNoneMatch && exists) || (cond.IfMatch != "" && cond.IfMatch != s.etags[key]) {
+		return ObjectInfo{}, ErrPreconditionFailed
+	}
+	s.version++
+	s.objects[key] = data
+	s.etags[key] = strconv.Itoa(s.version)
+	return s.info(key), nil
+}
+
+func (s *fakeObjectStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+	delete(s.etags, key)
+	return nil
+}
+
+func (s *fakeObjectStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := make([]ObjectInfo, 0)
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, s.info(key))
+		}
+	}
+	slices.SortFunc(infos, func(a, b ObjectInfo) int { return strings.Compare(a.Key, b.Key) })
+	return infos, nil
+}
+
+func TestObjectFS(t *testing.T) {
+	storage := newFakeObjectStorage()
+	opts := ObjectFSOptions{Prefix: "stores/app/"}
+
+	storeA, err := NewCSVStore("app", WithFS(NewObjectFS(storage, opts)))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	storeB, err := NewCSVStore("app", WithFS(NewObjectFS(storage, opts)))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+
+	if err := storeA.CreateTable("users", []string{"name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := storeA.Insert("users", CSVRecord{"name": "Alice"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := storeB.Insert("users", CSVRecord{"name": "Bob"}); err != nil {
+		t.Fatalf("Failed to insert record from a second store: %v", err)
+	}
+	if _, exists := storage.objects["stores/app/users.csv"]; !exists {
+		t.Errorf("Expected the table to be stored under the prefix")
+	}
+
+	result, err := storeA.Query("users", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected both stores' records, got %v", result.Records)
+	}
+
+	// Unchanged objects are served from the local cache
+	downloads := storage.downloads
+	if _, err := storeA.Query("users", nil); err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if storage.downloads != downloads {
+		t.Errorf("Expected a cached read, got %d downloads", storage.downloads-downloads)
+	}
+
+	// A write racing with another writer fails instead of losing its changes
+	fsA := NewObjectFS(storage, opts)
+	fsB := NewObjectFS(storage, opts)
+	writer, err := fsA.Create("users.csv")
+	if err != nil {
+		t.Fatalf("Failed to open object for writing: %v", err)
+	}
+	writerB, err := fsB.Append("users.csv")
+	if err != nil {
+		t.Fatalf("Failed to open object for writing: %v", err)
+	}
+	writerB.Write([]byte("Carol\n"))
+	if err := writerB.Close(); err != nil {
+		t.Fatalf("Failed to write object: %v", err)
+	}
+	writer.Write([]byte("name\n"))
+	if err := writer.Close(); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Expected ErrPreconditionFailed, got %v", err)
+	}
+}
+
+func TestObjectFSOperations(t *testing.T) {
+	storage := newFakeObjectStorage()
+	fsys := NewObjectFS(storage, ObjectFSOptions{Prefix: "p/"})
+
+	writeFile := func(name, data string) {
+		t.Helper()
+		w, err := fsys.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		w.Write([]byte(data))
+		if err := w.Close(); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	readFile := func(name string) string {
+		t.Helper()
+		r, err := fsys.Open(name)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", name, err)
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	// Append rewrites the whole object with the new data after the old
+	writeFile("log.csv", "a\n")
+	w, err := fsys.Append("log.csv")
+	if err != nil {
+		t.Fatalf("Failed to open for appending: %v", err)
+	}
+	w.Write([]byte("b\n"))
+	if got := string(storage.objects["p/log.csv"]); got != "a\n" {
+		t.Errorf("Expected appends to be buffered until close, got %q", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	if got := readFile("log.csv"); got != "a\nb\n" {
+		t.Errorf("Expected the appended object, got %q", got)
+	}
+	w, err = fsys.Append("new.csv")
+	if err != nil {
+		t.Fatalf("Failed to open a missing object for appending: %v", err)
+	}
+	w.Write([]byte("c\n"))
+	if err := w.Close(); err != nil || readFile("new.csv") != "c\n" {
+		t.Errorf("Expected appending to create the object, got %v", err)
+	}
+
+	// Rename moves files and the files of directories
+	if err := fsys.Rename("log.csv", "old.csv"); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	if _, err := fsys.Stat("log.csv"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the renamed object to be gone, got %v", err)
+	}
+	if got := readFile("old.csv"); got != "a\nb\n" {
+		t.Errorf("Expected the renamed object, got %q", got)
+	}
+	if err := fsys.Mkdir("stage"); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeFile("stage/one.csv", "1\n")
+	writeFile("stage/two.csv", "2\n")
+	if err := fsys.Rename("stage", "done"); err != nil {
+		t.Fatalf("Failed to rename directory: %v", err)
+	}
+	if got := readFile("done/two.csv"); got != "2\n" {
+		t.Errorf("Expected the files of the renamed directory, got %q", got)
+	}
+	if err := fsys.Rename("missing.csv", "other.csv"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist renaming a missing object, got %v", err)
+	}
+
+	// Listing shows files and directories of a level, sorted by name
+	entries, err := fsys.ReadDir(".")
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	if want := []string{"done/", "new.csv", "old.csv"}; !slices.Equal(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+	entries, err = fsys.ReadDir("done")
+	if err != nil || len(entries) != 2 || entries[0].Name() != "one.csv" {
+		t.Errorf("Expected the files of the directory, got %v, %v", entries, err)
+	}
+
+	// Remove deletes objects and directories, and reports missing ones
+	if err := fsys.Remove("old.csv"); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if _, exists := storage.objects["p/old.csv"]; exists {
+		t.Errorf("Expected the removed object to be deleted from the storage")
+	}
+	if err := fsys.Remove("done"); err != nil {
+		t.Fatalf("Failed to remove directory: %v", err)
+	}
+	if _, err := fsys.Open("done/one.csv"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the files of the removed directory to be gone, got %v", err)
+	}
+	if err := fsys.Remove("old.csv"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist removing a missing object, got %v", err)
+	}
+}
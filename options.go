@@ -0,0 +1,102 @@
+package csvstore
+
+import (
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/language"
+)
+
+// Option configures a CSVStore
+type Option func(*CSVStore)
+
+// TableOption configures the behavior of a table
+type TableOption func(*tableConfig)
+
+// tableConfig holds the effective configuration of a table
+type tableConfig struct {
+	history       bool
+	hooks         map[HookType][]Hook
+	triggers      []Trigger
+	ttl           time.Duration
+	ttlColumn     string
+	delimiter     rune
+	compression   Compression
+	keyProvider   KeyProvider
+	masks         map[string]MaskFunc
+	hashedColumns map[string]HashAlgorithm
+	reserved      ReservedColumns
+	noTimestamps  bool
+
+	caseInsensitiveColumns bool
+	trimSpace              bool
+	collapseSpace          bool
+	readerOptions          ReaderOptions
+	comment                rune
+	writerOptions          WriterOptions
+	bom                    bool
+	encoding               encoding.Encoding
+	null                   string
+	listSeparators         map[string]string
+	collation              *language.Tag
+	strictNumeric          bool
+	foreignKeys            []ForeignKey
+	tombstones             bool
+	quota                  Quota
+	partitioning           *partitioning
+	segmentSize            int64
+	rowChecksums           bool
+	columnTypes            map[string]ColumnType
+	validators             []Validator
+}
+
+// WithTableDefaults applies table options to every table in the store.
+// Options set with ConfigureTable take precedence over these defaults.
+func WithTableDefaults(opts ...TableOption) Option {
+	return func(cs *CSVStore) {
+		cs.tableDefaults = append(cs.tableDefaults, opts...)
+	}
+}
+
+// WithHistory keeps prior versions of rows changed by Update and Delete
+// in a companion history table (see History)
+func WithHistory() TableOption {
+	return func(c *tableConfig) {
+		c.history = true
+	}
+}
+
+// WithDelimiter sets the field delimiter of table files, e.g. '\t' for TSV or ';'
+// for semicolon-separated files. Use it with WithTableDefaults to change the
+// delimiter of the whole store. The default is a comma.
+func WithDelimiter(delimiter rune) TableOption {
+	return func(c *tableConfig) {
+		c.delimiter = delimiter
+	}
+}
+
+// ConfigureTable applies table options to a single table, on top of the store defaults
+func (cs *CSVStore) ConfigureTable(tableName string, opts ...TableOption) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.tableOptions[tableName] = append(cs.tableOptions[tableName], opts...)
+	cs.queryCache.clear()
+}
+
+// tableConfig returns the effective configuration of a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tableConfig(tableName string) *tableConfig {
+	config := &tableConfig{}
+	for _, opt := range cs.tableDefaults {
+		opt(config)
+	}
+	for _, opt := range cs.tableOptions[tableName] {
+		opt(config)
+	}
+	if config.partitioning != nil && config.partitioning.column == "" {
+		// Rotated tables are partitioned by creation time
+		config.partitioning.column = config.reservedColumns().CreatedAt
+	}
+	return config
+}
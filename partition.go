@@ -0,0 +1,370 @@
+package csvstore
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PartitionScheme selects how WithPartitioning maps the values of the
+// partition column to partitions
+type PartitionScheme int
+
+const (
+	// PartitionByValue puts every distinct value in its own partition. Values
+	// that are not valid file names, or longer than 64 bytes, stay in the main
+	// table file.
+	PartitionByValue PartitionScheme = iota
+	// PartitionByMonth partitions dates and timestamps such as RFC 3339 values
+	// by their YYYY-MM prefix
+	PartitionByMonth
+	// PartitionByDay partitions dates and timestamps by their YYYY-MM-DD prefix
+	PartitionByDay
+)
+
+// partitionSeparator separates the table name from the partition key in the
+// names of partition files, as in events__2024-01.csv
+const partitionSeparator = "__"
+
+var (
+	valueKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+	monthKeyPattern = regexp.MustCompile(`^\d{4}-\d{2}$`)
+	dayKeyPattern   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// partitioning is the partitioning of a table
+type partitioning struct {
+	column string
+	scheme PartitionScheme
+	// retention is the number of periods of partitions kept by rotation, or 0
+	retention int
+}
+
+// WithPartitioning splits the rows of a table into one file per partition of
+// the values of column, e.g. events__2024-01.csv and events__2024-02.csv with
+// PartitionByMonth, to keep large time series manageable. Rows without a
+// partition, such as rows with an empty value, stay in the main table file,
+// which also holds the headers.
+//
+// Reads span all partitions. Queries with "=" conditions on column, and with
+// ordering conditions for PartitionByMonth and PartitionByDay, only read the
+// partitions that can match. Inserts append to the partition of the row;
+// updates and deletes rewrite every partition. Partition files appear in
+// backups but not in ListTables.
+func WithPartitioning(column string, scheme PartitionScheme) TableOption {
+	return func(c *tableConfig) {
+		c.partitioning = &partitioning{column: column, scheme: scheme}
+	}
+}
+
+// key returns the partition of a value, or "" for the main table file
+func (p *partitioning) key(value string) string {
+	switch p.scheme {
+	case PartitionByMonth:
+		if len(value) >= 7 && monthKeyPattern.MatchString(value[:7]) {
+			return value[:7]
+		}
+	case PartitionByDay:
+		if len(value) >= 10 && dayKeyPattern.MatchString(value[:10]) {
+			return value[:10]
+		}
+	default:
+		if p.validKey(value) {
+			return value
+		}
+	}
+	return ""
+}
+
+// validKey reports whether key names a partition of the scheme
+func (p *partitioning) validKey(key string) bool {
+	switch p.scheme {
+	case PartitionByMonth:
+		return monthKeyPattern.MatchString(key)
+	case PartitionByDay:
+		return dayKeyPattern.MatchString(key)
+	default:
+		// Keep clear of the names of companion tables
+		return valueKeyPattern.MatchString(key) &&
+			partitionSeparator+key != historySuffix &&
+			partitionSeparator+key != tombstoneSuffix
+	}
+}
+
+// prefixKeys reports whether partition keys are fixed-length prefixes of the
+// values, so that ranges of values map to ranges of keys
+func (p *partitioning) prefixKeys() bool {
+	return p.scheme == PartitionByMonth || p.scheme == PartitionByDay
+}
+
+// partitionTable returns the name of the table file of a partition, without
+// its extensions
+func partitionTable(tableName, key string) string {
+	return tableName + partitionSeparator + key
+}
+
+// tablePartition is a partition file of a table
+type tablePartition struct {
+	key  string
+	file string
+}
+
+// tablePartitions returns the partition files of a partitioned table, ordered
+// by key, or nil for other tables.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tablePartitions(tableName string) ([]tablePartition, error) {
+	p := cs.tableConfig(tableName).partitioning
+	if p == nil {
+		return nil, nil
+	}
+	entries, err := cs.fs.ReadDir(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var partitions []tablePartition
+	prefix := tableName + partitionSeparator
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		fileTable, ok := tableNameFromFile(entry.Name())
+		if !ok {
+			continue
+		}
+		key, found := strings.CutPrefix(fileTable, prefix)
+		if found && p.validKey(key) {
+			partitions = append(partitions, tablePartition{key: key, file: entry.Name()})
+		}
+	}
+	slices.SortFunc(partitions, func(a, b tablePartition) int { return strings.Compare(a.key, b.key) })
+	return partitions, nil
+}
+
+// isPartitionTable reports whether a table name read from a file name is a
+// partition of a partitioned table rather than a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) isPartitionTable(name string) bool {
+	for i := strings.Index(name, partitionSeparator); i > 0; {
+		base, key := name[:i], name[i+len(partitionSeparator):]
+		if p := cs.tableConfig(base).partitioning; p != nil && p.validKey(key) {
+			return true
+		}
+		next := strings.Index(name[i+1:], partitionSeparator)
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	return false
+}
+
+// partitionFiles returns the partition files of a table, skipping the
+// partitions that cannot hold records matching conditions.
+// The caller must hold cs.mu.
+func (cs *CSVStore) partitionFiles(tableName string, conditions []QueryCondition) ([]string, error) {
+	partitions, err := cs.tablePartitions(tableName)
+	if err != nil || partitions == nil {
+		return nil, err
+	}
+
+	config := cs.tableConfig(tableName)
+	p := config.partitioning
+	// Collations and strict numeric mode change how values are ordered
+	ordered := p.prefixKeys() && config.collation == nil && !config.strictNumeric
+	files := make([]string, 0, len(partitions))
+	for _, partition := range partitions {
+		if partitionMayMatch(p, ordered, partition.key, conditions) {
+			files = append(files, partition.file)
+		}
+	}
+	return files, nil
+}
+
+// partitionMayMatch reports whether the partition key may hold records
+// matching conditions. ordered is set when ordering conditions can be checked
+// against keys.
+func partitionMayMatch(p *partitioning, ordered bool, key string, conditions []QueryCondition) bool {
+	for _, condition := range conditions {
+		if condition.Column != p.column {
+			continue
+		}
+		switch condition.Operator {
+		case "=", "==":
+			if p.key(condition.Value) != key {
+				return false
+			}
+		case ">", ">=":
+			// Truncating values to the key length keeps their order
+			if ordered && key < truncate(condition.Value, len(key)) {
+				return false
+			}
+		case "<", "<=":
+			if ordered && key > truncate(condition.Value, len(key)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// truncate returns the first n bytes of s
+func truncate(s string, n int) string {
+	return s[:min(len(s), n)]
+}
+
+// splitPartitions groups the records of a partitioned table by partition. It
+// returns the records of the main table file, and the others by key.
+// The caller must hold cs.mu.
+func (cs *CSVStore) splitPartitions(tableName string, records []CSVRecord) ([]CSVRecord, map[string][]CSVRecord) {
+	p := cs.tableConfig(tableName).partitioning
+	main := make([]CSVRecord, 0)
+	partitions := make(map[string][]CSVRecord)
+	for _, record := range records {
+		if key := p.key(record[p.column]); key != "" {
+			partitions[key] = append(partitions[key], record)
+		} else {
+			main = append(main, record)
+		}
+	}
+	return main, partitions
+}
+
+// savePartitions rewrites the partition files of a table with records by
+// partition key, removing the partitions left without records.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) savePartitions(
+	tableName string,
+	headers []string,
+	records map[string][]CSVRecord,
+) error {
+	existing, err := cs.tablePartitions(tableName)
+	if err != nil {
+		return err
+	}
+	for _, partition := range existing {
+		if _, ok := records[partition.key]; ok {
+			continue
+		}
+		if err := cs.fs.Remove(partition.file); err != nil {
+			return fmt.Errorf("failed to remove partition %s of table %s: %w", partition.key, tableName, err)
+		}
+		cs.trackWrite(partitionTable(tableName, partition.key))
+	}
+
+	for key, partitionRecords := range records {
+		rows := make([][]string, 0, len(partitionRecords)+1)
+		rows = append(rows, headers)
+		for _, record := range partitionRecords {
+			rows = append(rows, recordRow(headers, record))
+		}
+		if err := cs.writePartition(tableName, key, rows, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendPartitions appends rows of a partitioned table to the files of their
+// partitions, and returns the rows of the main table file.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) appendPartitions(tableName string, rows [][]string) ([][]string, error) {
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return nil, err
+	}
+	p := cs.tableConfig(tableName).partitioning
+	column := slices.Index(headers, p.column)
+	if column < 0 {
+		return rows, nil
+	}
+
+	var main [][]string
+	partitions := make(map[string][][]string)
+	var keys []string
+	for _, row := range rows {
+		var key string
+		if column < len(row) {
+			key = p.key(row[column])
+		}
+		if key == "" {
+			main = append(main, row)
+			continue
+		}
+		if _, ok := partitions[key]; !ok {
+			keys = append(keys, key)
+		}
+		partitions[key] = append(partitions[key], row)
+	}
+
+	existing, err := cs.tablePartitions(tableName)
+	if err != nil {
+		return nil, err
+	}
+	rotated := false
+	for _, key := range keys {
+		if err := cs.writePartition(tableName, key, partitions[key], true); err != nil {
+			return nil, err
+		}
+		rotated = rotated || !slices.ContainsFunc(existing, func(partition tablePartition) bool {
+			return partition.key == key
+		})
+	}
+	if rotated {
+		if _, err := cs.applyRetention(tableName, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return main, nil
+}
+
+// writePartition writes rows to the file of a partition, appending them when
+// append is set and the file exists, and replacing its contents otherwise.
+// New partition files get the headers of the table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) writePartition(tableName, key string, rows [][]string, append bool) error {
+	name := partitionTable(tableName, key)
+	defer cs.trackWrite(name)
+
+	config := cs.tableConfig(tableName)
+	file := tableFileName(name, tableFormat{compression: config.compression, encrypted: config.keyProvider != nil})
+	partitions, err := cs.tablePartitions(tableName)
+	if err != nil {
+		return err
+	}
+	exists := false
+	for _, partition := range partitions {
+		if partition.key == key {
+			file, exists = partition.file, true
+		}
+	}
+
+	var tf *tableFile
+	if append && exists {
+		tf, err = cs.appendTableFileNamed(tableName, file)
+	} else {
+		if append {
+			headers, err := cs.getHeaders(tableName)
+			if err != nil {
+				return err
+			}
+			rows = slices.Insert(rows, 0, headers)
+		}
+		tf, err = cs.createTableFileNamed(tableName, file)
+	}
+	if err != nil {
+		return err
+	}
+	defer tf.Close()
+
+	writer := cs.newTableWriter(tableName, tf)
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	if err := tf.Close(); err != nil {
+		return fmt.Errorf("failed to write table file: %w", err)
+	}
+	return nil
+}
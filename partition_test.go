@@ -0,0 +1,137 @@
+package csvstore
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestPartitioning(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("events", WithPartitioning("at", PartitionByMonth))
+	if err := store.CreateTable("events", []string{"id", "at", "kind"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, event := range []CSVRecord{
+		{"at": "2024-01-15T10:00:00Z", "kind": "login"},
+		{"at": "2024-01-20T10:00:00Z", "kind": "logout"},
+		{"at": "2024-02-03T10:00:00Z", "kind": "login"},
+		{"at": "", "kind": "unknown"},
+	} {
+		if _, err := store.Insert("events", event); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	for _, name := range []string{"events__2024-01.csv", "events__2024-02.csv"} {
+		if _, err := os.Stat(testDir + "/" + name); err != nil {
+			t.Errorf("Expected partition file %s: %v", name, err)
+		}
+	}
+	tables, err := store.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if !slices.Equal(tables, []string{"events"}) {
+		t.Errorf("Expected partitions to be hidden, got %v", tables)
+	}
+
+	all, err := store.Query("events", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if all.Count != 4 {
+		t.Errorf("Expected 4 events across partitions, got %d", all.Count)
+	}
+
+	january := []QueryCondition{
+		{Column: "at", Operator: ">=", Value: "2024-01-01"},
+		{Column: "at", Operator: "<=", Value: "2024-01-31T23:59:59Z"},
+	}
+	result, err := store.Query("events", january)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected 2 events in January, got %v", result.Records)
+	}
+
+	// Only the main file and the January partition are read
+	files, err := store.partitionFiles("events", january)
+	if err != nil {
+		t.Fatalf("Failed to list partitions: %v", err)
+	}
+	if !slices.Equal(files, []string{"events__2024-01.csv"}) {
+		t.Errorf("Expected the January partition only, got %v", files)
+	}
+	files, err = store.partitionFiles("events", []QueryCondition{{Column: "at", Operator: "=", Value: "2024-02-03T10:00:00Z"}})
+	if err != nil {
+		t.Fatalf("Failed to list partitions: %v", err)
+	}
+	if !slices.Equal(files, []string{"events__2024-02.csv"}) {
+		t.Errorf("Expected the February partition only, got %v", files)
+	}
+}
+
+func TestPartitioningRewrites(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	store.ConfigureTable("orders", WithPartitioning("region", PartitionByValue))
+	if err := store.CreateTable("orders", []string{"id", "region", "status"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, region := range []string{"eu", "us", "eu", "not a key"} {
+		if _, err := store.Insert("orders", CSVRecord{"region": region, "status": "open"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	// Moving a row to another partition rewrites both
+	updated, err := store.Update("orders",
+		CSVRecord{"region": "apac"},
+		[]QueryCondition{{Column: "region", Operator: "=", Value: "us"}})
+	if err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	if updated.Count != 1 {
+		t.Errorf("Expected 1 updated record, got %d", updated.Count)
+	}
+	partitions, err := store.tablePartitions("orders")
+	if err != nil {
+		t.Fatalf("Failed to list partitions: %v", err)
+	}
+	var keys []string
+	for _, partition := range partitions {
+		keys = append(keys, partition.key)
+	}
+	if !slices.Equal(keys, []string{"apac", "eu"}) {
+		t.Errorf("Expected the emptied partition to be removed, got %v", keys)
+	}
+
+	if _, err := store.Delete("orders", []QueryCondition{{Column: "region", Operator: "=", Value: "eu"}}); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	remaining, err := store.Query("orders", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if remaining.Count != 2 {
+		t.Errorf("Expected 2 remaining orders, got %v", remaining.Records)
+	}
+	result, err := store.Query("orders", []QueryCondition{{Column: "region", Operator: "=", Value: "not a key"}})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Expected the unpartitioned order in the main file, got %v", result.Records)
+	}
+}
@@ -0,0 +1,175 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+	"time"
+)
+
+// RestoreToTime reconstructs the store as it was at target. backups holds a full
+// backup followed by any incremental backups taken on top of it, all taken at or
+// before target; they are restored in order, then the change log is replayed from
+// the last backup up to target. Tables that did not exist in the last backup are
+// removed unless the replay creates them again.
+//
+// The store must use WithChangeLog, and its change log must cover the period
+// between the last backup and target. The change log itself is kept whole, so the
+// store can be restored to a later moment again.
+func (cs *CSVStore) RestoreToTime(target time.Time, backups ...io.Reader) error {
+	_, err := runOperation(cs, Operation{Name: OpRestoreToTime, Payload: target},
+		func() (any, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return nil, cs.restoreToTime(target, backups)
+		})
+	return err
+}
+
+// restoreToTime reconstructs the store as it was at target.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) restoreToTime(target time.Time, backups []io.Reader) error {
+	if !cs.changeLog {
+		return errors.New("point-in-time restore requires the change log (WithChangeLog)")
+	}
+	if len(backups) == 0 {
+		return errors.New("point-in-time restore requires at least one backup")
+	}
+
+	// Stage and check every backup before touching the store
+	type stagedBackup struct {
+		dir      string
+		manifest *BackupManifest
+		files    []string
+	}
+	staged := make([]stagedBackup, 0, len(backups))
+	defer func() {
+		for _, backup := range staged {
+			cs.fs.Remove(backup.dir)
+		}
+	}()
+	for i, r := range backups {
+		dir, err := cs.makeTempDir(".restore-")
+		if err != nil {
+			return fmt.Errorf("failed to create staging directory: %w", err)
+		}
+		staged = append(staged, stagedBackup{dir: dir})
+
+		manifest, files, err := cs.extractBackup(r, dir)
+		if err != nil {
+			return fmt.Errorf("backup %d: %w", i, err)
+		}
+		switch {
+		case i == 0 && manifest.Incremental:
+			return errors.New("the first backup must be a full backup")
+		case i > 0 && !manifest.Incremental:
+			return fmt.Errorf("backup %d must be an incremental backup", i)
+		case manifest.CreatedAt.After(target):
+			return fmt.Errorf("backup %d was taken after %s", i, target.Format(time.RFC3339))
+		case i > 0 && manifest.ChangeSequence < staged[i-1].manifest.ChangeSequence:
+			return fmt.Errorf("backup %d is older than the backup before it", i)
+		}
+		staged[i].manifest = manifest
+		staged[i].files = files
+	}
+
+	// Keep the current change log aside; the backups carry older copies of it
+	changeLog, err := cs.readFile(ChangeLogFileName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read change log: %w", err)
+	}
+
+	for _, backup := range staged {
+		err := cs.applyStagedBackup(backup.dir, backup.manifest, backup.files, RestoreOverwrite)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Drop files created after the last backup
+	last := staged[len(staged)-1].manifest
+	names, err := cs.storeFiles()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, exists := last.Checksums[name]; !exists && name != ChangeLogFileName {
+			if err := cs.removeStoreFile(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := cs.writeFile(ChangeLogFileName, changeLog); err != nil {
+		return fmt.Errorf("failed to write change log: %w", err)
+	}
+
+	return cs.readChangeLog(func(event ChangeEvent) error {
+		if event.Sequence <= last.ChangeSequence || event.Timestamp.After(target) {
+			return nil
+		}
+		if err := cs.applyChange(event); err != nil {
+			return fmt.Errorf("failed to replay change %d: %w", event.Sequence, err)
+		}
+		return nil
+	})
+}
+
+// applyChange applies a change event to the tables of the store without running
+// hooks, triggers, or history, and without emitting new events. Records of updates
+// and deletes are matched by their id column, or by their full contents for tables without an
+// id column.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) applyChange(event ChangeEvent) error {
+	if event.Operation == ChangeCreateTable {
+		if cs.fileExists(cs.getTableFile(event.Table)) {
+			return nil
+		}
+		return cs.saveTable(event.Table, event.Headers, nil)
+	}
+
+	headers, err := cs.getHeaders(event.Table)
+	if err != nil {
+		return err
+	}
+
+	if event.Operation == ChangeInsert {
+		row := make([]string, len(headers))
+		for i, header := range headers {
+			row[i] = event.Record[header]
+		}
+		return cs.appendRows(event.Table, [][]string{row})
+	}
+
+	records, err := cs.loadTable(event.Table)
+	if err != nil {
+		return err
+	}
+
+	idColumn := cs.reservedColumns(event.Table).ID
+	matches := func(record CSVRecord) bool {
+		if slices.Contains(headers, idColumn) {
+			return record[idColumn] == event.Record[idColumn]
+		}
+		return maps.Equal(record, event.Record)
+	}
+
+	switch event.Operation {
+	case ChangeUpdate:
+		for i, record := range records {
+			if matches(record) {
+				records[i] = maps.Clone(event.Record)
+			}
+		}
+	case ChangeDelete:
+		records = slices.DeleteFunc(records, matches)
+	default:
+		return fmt.Errorf("unknown change operation %q", event.Operation)
+	}
+
+	return cs.saveTable(event.Table, headers, records)
+}
@@ -0,0 +1,87 @@
+package csvstore
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRestoreToTime(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir, WithChangeLog())
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "users"
+	if err := store.CreateTable(tableName, []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert(tableName, CSVRecord{"id": "1", "name": "John"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	var backup bytes.Buffer
+	if err := store.Backup(&backup); err != nil {
+		t.Fatalf("Failed to back up store: %v", err)
+	}
+
+	// Changes after the backup, up to the moment we want to go back to
+	time.Sleep(5 * time.Millisecond)
+	if _, err := store.Insert(tableName, CSVRecord{"id": "2", "name": "Jane"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Update(
+		tableName,
+		CSVRecord{"name": "Johnny"},
+		[]QueryCondition{{Column: "id", Operator: "=", Value: "1"}},
+	); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if err := store.CreateTable("orders", []string{"id", "user_id"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	target := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	// The bad bulk delete, and a table created afterwards
+	if _, err := store.Delete(tableName, nil); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if err := store.CreateTable("scratch", []string{"id"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	if err := store.RestoreToTime(target, &backup); err != nil {
+		t.Fatalf("Failed to restore to time: %v", err)
+	}
+
+	result, err := store.QuerySortedRange(tableName, "id", "asc", 10)
+	if err != nil {
+		t.Fatalf("Failed to query table: %v", err)
+	}
+	if result.Count != 2 {
+		t.Fatalf("Expected 2 records after restore, got %d", result.Count)
+	}
+	if result.Records[0]["name"] != "Johnny" || result.Records[1]["name"] != "Jane" {
+		t.Errorf("Unexpected records after restore: %v", result.Records)
+	}
+	if !store.CheckTableExists("orders") {
+		t.Error("Expected orders table created before the target time to exist")
+	}
+	if store.CheckTableExists("scratch") {
+		t.Error("Expected scratch table created after the target time to be removed")
+	}
+
+	// A backup taken after the target cannot be used
+	var late bytes.Buffer
+	if err := store.Backup(&late); err != nil {
+		t.Fatalf("Failed to back up store: %v", err)
+	}
+	if err := store.RestoreToTime(target, &late); err == nil {
+		t.Error("Expected error when restoring from a backup taken after the target time")
+	}
+}
@@ -0,0 +1,278 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// projectionFunc is a function of Select expressions, called with the values
+// of its arguments and the null value of the table
+type projectionFunc struct {
+	minArgs int
+	maxArgs int // -1 for any number
+	call    func(args []string, isNull func(string) bool) (string, error)
+}
+
+// projectionFuncs lists the functions of Select expressions by name
+var projectionFuncs = map[string]projectionFunc{
+	"concat": {minArgs: 1, maxArgs: -1, call: func(args []string, isNull func(string) bool) (string, error) {
+		var b strings.Builder
+		for _, arg := range args {
+			if !isNull(arg) {
+				b.WriteString(arg)
+			}
+		}
+		return b.String(), nil
+	}},
+	"substr": {minArgs: 2, maxArgs: 3, call: func(args []string, isNull func(string) bool) (string, error) {
+		if isNull(args[0]) {
+			return args[0], nil
+		}
+		runes := []rune(args[0])
+		start, err := strconv.Atoi(args[1])
+		if err != nil {
+			return "", fmt.Errorf("substr start %q is not an integer", args[1])
+		}
+		start = min(max(start, 1), len(runes)+1) - 1
+		end := len(runes)
+		if len(args) == 3 {
+			length, err := strconv.Atoi(args[2])
+			if err != nil || length < 0 {
+				return "", fmt.Errorf("substr length %q is not a non-negative integer", args[2])
+			}
+			end = min(start+length, end)
+		}
+		return string(runes[start:end]), nil
+	}},
+	"upper": {minArgs: 1, maxArgs: 1, call: func(args []string, isNull func(string) bool) (string, error) {
+		if isNull(args[0]) {
+			return args[0], nil
+		}
+		return strings.ToUpper(args[0]), nil
+	}},
+	"lower": {minArgs: 1, maxArgs: 1, call: func(args []string, isNull func(string) bool) (string, error) {
+		if isNull(args[0]) {
+			return args[0], nil
+		}
+		return strings.ToLower(args[0]), nil
+	}},
+	"coalesce": {minArgs: 1, maxArgs: -1, call: func(args []string, isNull func(string) bool) (string, error) {
+		for _, arg := range args {
+			if !isNull(arg) {
+				return arg, nil
+			}
+		}
+		return args[len(args)-1], nil
+	}},
+	"format_time": {minArgs: 2, maxArgs: 2, call: func(args []string, isNull func(string) bool) (string, error) {
+		if isNull(args[0]) {
+			return args[0], nil
+		}
+		for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+			if t, err := time.Parse(layout, args[0]); err == nil {
+				return t.Format(args[1]), nil
+			}
+		}
+		return "", fmt.Errorf("format_time cannot parse %q as a timestamp", args[0])
+	}},
+}
+
+// projectionExpr is an expression of a Select column
+type projectionExpr interface {
+	eval(record CSVRecord, isNull func(string) bool) (string, error)
+}
+
+// columnRef is the value of a column
+type columnRef string
+
+func (c columnRef) eval(record CSVRecord, _ func(string) bool) (string, error) {
+	return record[string(c)], nil
+}
+
+// literal is a constant value
+type literal string
+
+func (l literal) eval(CSVRecord, func(string) bool) (string, error) {
+	return string(l), nil
+}
+
+// funcCall is a call of a projection function
+type funcCall struct {
+	fn   projectionFunc
+	args []projectionExpr
+}
+
+func (f *funcCall) eval(record CSVRecord, isNull func(string) bool) (string, error) {
+	args := make([]string, len(f.args))
+	for i, arg := range f.args {
+		value, err := arg.eval(record, isNull)
+		if err != nil {
+			return "", err
+		}
+		args[i] = value
+	}
+	return f.fn.call(args, isNull)
+}
+
+// computedColumn is a Select column computed by an expression
+type computedColumn struct {
+	name string // Column of the result: the alias, or the expression as written
+	expr projectionExpr
+}
+
+// parseComputedColumn parses a Select column of the form
+// function(argument, ...) [AS alias]. Arguments are column names, "quoted
+// column names", 'quoted strings' with a doubled quote escaping a quote,
+// numbers, or function calls. ok is false when column is not a function call.
+func parseComputedColumn(column string, resolve func(string) string) (computed *computedColumn, ok bool, err error) {
+	tokens, err := tokenizeProjection(column)
+	if err != nil || len(tokens) < 2 || tokens[1].text != "(" || tokens[1].quoted {
+		return nil, false, nil
+	}
+
+	p := &projectionParser{tokens: tokens, resolve: resolve}
+	expr, err := p.expr()
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid column expression %q: %w", column, err)
+	}
+	computed = &computedColumn{name: strings.TrimSpace(column), expr: expr}
+	if t, ok := p.next(); ok {
+		alias, hasAlias := p.next()
+		symbol := !alias.quoted && strings.Contains("(),", alias.text)
+		if t.quoted || !strings.EqualFold(t.text, "AS") || !hasAlias || alias.string || symbol {
+			return nil, true, fmt.Errorf("invalid column expression %q: expected AS alias", column)
+		}
+		computed.name = alias.text
+	}
+	if t, ok := p.next(); ok {
+		return nil, true, fmt.Errorf("invalid column expression %q: unexpected %q", column, t.text)
+	}
+	return computed, true, nil
+}
+
+// projectionToken is a lexical token of a Select expression
+type projectionToken struct {
+	text   string
+	quoted bool
+	string bool // 'quoted string', as opposed to a "quoted name"
+}
+
+// tokenizeProjection splits a Select expression into tokens
+func tokenizeProjection(input string) ([]projectionToken, error) {
+	var tokens []projectionToken
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			var text strings.Builder
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == r {
+					if j+1 < len(runes) && runes[j+1] == r {
+						text.WriteRune(r)
+						j++
+						continue
+					}
+					break
+				}
+				text.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, errors.New("unterminated quote")
+			}
+			tokens = append(tokens, projectionToken{text: text.String(), quoted: true, string: r == '\''})
+			i = j + 1
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, projectionToken{text: string(r)})
+			i++
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(`(),'"`, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, projectionToken{text: string(runes[i:j])})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// projectionParser parses the tokens of a Select expression
+type projectionParser struct {
+	tokens  []projectionToken
+	resolve func(string) string
+}
+
+// next consumes the next token
+func (p *projectionParser) next() (projectionToken, bool) {
+	if len(p.tokens) == 0 {
+		return projectionToken{}, false
+	}
+	t := p.tokens[0]
+	p.tokens = p.tokens[1:]
+	return t, true
+}
+
+// accept consumes the next token if it is the unquoted symbol s
+func (p *projectionParser) accept(s string) bool {
+	if len(p.tokens) > 0 && !p.tokens[0].quoted && p.tokens[0].text == s {
+		p.tokens = p.tokens[1:]
+		return true
+	}
+	return false
+}
+
+// expr consumes a column, literal, or function call
+func (p *projectionParser) expr() (projectionExpr, error) {
+	t, ok := p.next()
+	switch {
+	case !ok:
+		return nil, errors.New("expected an argument")
+	case t.string:
+		return literal(t.text), nil
+	case t.quoted:
+		return columnRef(p.resolve(t.text)), nil
+	case t.text == "(" || t.text == ")" || t.text == ",":
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+
+	if !p.accept("(") {
+		if _, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return literal(t.text), nil
+		}
+		return columnRef(p.resolve(t.text)), nil
+	}
+
+	name := strings.ToLower(t.text)
+	fn, ok := projectionFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", t.text)
+	}
+	call := &funcCall{fn: fn}
+	if !p.accept(")") {
+		for {
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if p.accept(")") {
+				break
+			}
+			if !p.accept(",") {
+				return nil, fmt.Errorf("expected ',' or ')' in arguments of %s", name)
+			}
+		}
+	}
+	if len(call.args) < fn.minArgs || fn.maxArgs >= 0 && len(call.args) > fn.maxArgs {
+		return nil, fmt.Errorf("wrong number of arguments for %s: %d", name, len(call.args))
+	}
+	return call, nil
+}
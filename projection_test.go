@@ -0,0 +1,78 @@
+package csvstore
+
+import (
+	"testing"
+)
+
+func TestSelectFunctions(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+	store.ConfigureTable("users", WithMask("email", Redact("hidden")))
+
+	if err := store.CreateTable("users", []string{"id", "first", "last", "nick", "email", "joined"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	record := CSVRecord{
+		"id": "1", "first": "Ada", "last": "Lovelace", "nick": "",
+		"email": "ada@example.com", "joined": "2024-03-05T10:30:00Z",
+	}
+	if _, err := store.Insert("users", record); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	columns := []string{
+		"first",
+		"concat(first, ' ', last) AS full_name",
+		"upper(substr(last, 1, 4)) as code",
+		"lower(first)",
+		"coalesce(nick, first, 'anonymous') AS display",
+		"format_time(joined, '2006-01-02') AS joined_on",
+		"upper(email) AS contact",
+		"missing",
+	}
+	result, err := store.Select("users", columns, nil)
+	if err != nil {
+		t.Fatalf("Failed to select: %v", err)
+	}
+	want := CSVRecord{
+		"first":        "Ada",
+		"full_name":    "Ada Lovelace",
+		"code":         "LOVE",
+		"lower(first)": "ada",
+		"display":      "Ada",
+		"joined_on":    "2024-03-05",
+		"contact":      "HIDDEN",
+	}
+	got := result.Records[0]
+	if len(got) != len(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	for column, value := range want {
+		if got[column] != value {
+			t.Errorf("Expected %s to be %q, got %q", column, value, got[column])
+		}
+	}
+
+	// Query options project the same expressions
+	options, err := store.QueryWithOptions("users", QueryOptions{Columns: []string{"upper(first) AS name"}})
+	if err != nil {
+		t.Fatalf("Failed to query with options: %v", err)
+	}
+	if options.Records[0]["name"] != "ADA" {
+		t.Errorf("Expected the computed column, got %v", options.Records[0])
+	}
+
+	for _, column := range []string{
+		"reverse(first)",
+		"upper(first, last)",
+		"substr(first)",
+		"concat(first",
+		"upper(first) AS",
+		"format_time(first, '2006')",
+		"substr(first, 'x')",
+	} {
+		if _, err := store.Select("users", []string{column}, nil); err == nil {
+			t.Errorf("Expected error selecting %q", column)
+		}
+	}
+}
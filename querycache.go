@@ -0,0 +1,166 @@
+package csvstore
+
+import (
+	"container/list"
+	"encoding/json"
+	"maps"
+	"strings"
+	"sync"
+)
+
+// queryCache holds the results of recent queries, least recently used first
+type queryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // Of *queryCacheEntry, most recently used at the front
+}
+
+// queryCacheEntry is a cached query result
+type queryCacheEntry struct {
+	key    string
+	table  string
+	state  fileState // State of the table files when the result was computed
+	result *QueryResult
+}
+
+// queryCacheKey identifies a query by its table, conditions, and projection
+type queryCacheKey struct {
+	Table      string
+	Columns    []string
+	Conditions []QueryCondition
+}
+
+// WithQueryCache caches the results of up to maxEntries distinct Query and
+// Select calls, keyed by table, conditions, and columns, so repeated queries
+// skip scanning the table files. Writes through the store invalidate the
+// results of the tables they change, and results of tables whose files changed
+// on disk otherwise are recomputed. Changing the options of a table with
+// ConfigureTable empties the cache. Lookups count as cache hits and misses in
+// Stats.
+func WithQueryCache(maxEntries int) Option {
+	return func(cs *CSVStore) {
+		if maxEntries <= 0 {
+			return
+		}
+		cs.queryCache = &queryCache{
+			maxEntries: maxEntries,
+			entries:    make(map[string]*list.Element),
+			order:      list.New(),
+		}
+	}
+}
+
+// cachedQuery returns the result of a query on a table from the query cache,
+// or computes and caches it.
+// The caller must hold cs.mu.
+func (cs *CSVStore) cachedQuery(
+	tableName string,
+	columns []string,
+	conditions []QueryCondition,
+	compute func() (*QueryResult, error),
+) (*QueryResult, error) {
+	if cs.queryCache == nil {
+		return compute()
+	}
+
+	data, err := json.Marshal(queryCacheKey{Table: tableName, Columns: columns, Conditions: conditions})
+	if err != nil {
+		return compute()
+	}
+	key := string(data)
+	state := cs.tableState(tableName)
+
+	result, hit := cs.queryCache.get(key, state)
+	if cs.stats != nil {
+		cs.stats.addCacheLookup(hit)
+	}
+	if hit {
+		return result, nil
+	}
+
+	result, err = compute()
+	if err != nil {
+		return nil, err
+	}
+	cs.queryCache.put(&queryCacheEntry{key: key, table: tableName, state: state, result: cloneResult(result)})
+	return result, nil
+}
+
+// get returns a copy of the cached result for key, if it was computed from the
+// table files in state
+func (c *queryCache) get(key string, state fileState) (*QueryResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*queryCacheEntry)
+	if entry.state.exists != state.exists || entry.state.size != state.size ||
+		!entry.state.modTime.Equal(state.modTime) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return cloneResult(entry.result), true
+}
+
+// put adds an entry to the cache, evicting the least recently used entry when
+// the cache is full
+func (c *queryCache) put(entry *queryCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[entry.key]; ok {
+		c.order.Remove(element)
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*queryCacheEntry).key)
+	}
+}
+
+// invalidate drops the cached results of a table. Writes to the partitions and
+// segments of a table, named <table>__<suffix>, invalidate the table as well.
+func (c *queryCache) invalidate(tableName string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	base, _, _ := strings.Cut(tableName, "__")
+	for key, element := range c.entries {
+		table := element.Value.(*queryCacheEntry).table
+		if table == tableName || table == base {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// clear drops every cached result
+func (c *queryCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	c.order.Init()
+}
+
+// cloneResult returns a copy of a result whose records can be modified
+func cloneResult(result *QueryResult) *QueryResult {
+	records := make([]CSVRecord, len(result.Records))
+	for i, record := range result.Records {
+		records[i] = maps.Clone(record)
+	}
+	return &QueryResult{Records: records, Count: result.Count}
+}
@@ -0,0 +1,90 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestQueryCache(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir, WithQueryCache(2), WithStats())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name", "age"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("users", CSVRecord{"id": "1", "name": "alice", "age": "30"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	conditions := []QueryCondition{{Column: "age", Operator: ">", Value: "18"}}
+	for range 3 {
+		result, err := store.Query("users", conditions)
+		if err != nil {
+			t.Fatalf("Failed to query: %v", err)
+		}
+		if result.Count != 1 {
+			t.Fatalf("Expected 1 record, got %d", result.Count)
+		}
+		// Callers can modify results without affecting the cache
+		result.Records[0]["name"] = "changed"
+	}
+	stats := store.Stats()
+	if stats.CacheHits != 2 || stats.CacheMisses != 1 {
+		t.Errorf("Expected 2 hits and 1 miss, got %d and %d", stats.CacheHits, stats.CacheMisses)
+	}
+
+	// Projections are cached separately
+	selected, err := store.Select("users", []string{"name"}, conditions)
+	if err != nil {
+		t.Fatalf("Failed to select: %v", err)
+	}
+	if len(selected.Records[0]) != 1 || selected.Records[0]["name"] != "alice" {
+		t.Errorf("Expected the projected record, got %v", selected.Records)
+	}
+
+	// Writes invalidate the results of the table
+	if _, err := store.Insert("users", CSVRecord{"id": "2", "name": "bob", "age": "40"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	result, err := store.Query("users", conditions)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected the inserted record after the write, got %v", result.Records)
+	}
+
+	// External modifications are detected from the file state
+	later := time.Now().Add(time.Minute)
+	data := "id,name,age\n1,alice,30\n"
+	if err := os.WriteFile(store.GetTablePath("users"), []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+	if err := os.Chtimes(store.GetTablePath("users"), later, later); err != nil {
+		t.Fatalf("Failed to touch table file: %v", err)
+	}
+	result, err = store.Query("users", conditions)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Expected the externally modified table, got %v", result.Records)
+	}
+
+	// The least recently used results are evicted
+	for _, age := range []string{"20", "50", "60"} {
+		if _, err := store.Query("users", []QueryCondition{{Column: "age", Operator: ">", Value: age}}); err != nil {
+			t.Fatalf("Failed to query: %v", err)
+		}
+	}
+	if entries := len(store.queryCache.entries); entries != 2 {
+		t.Errorf("Expected the cache to hold 2 entries, got %d", entries)
+	}
+}
@@ -0,0 +1,137 @@
+package csvstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// QueryOptions combines filtering, sorting, paging, and projection for QueryWithOptions
+type QueryOptions struct {
+	Conditions []QueryCondition
+	// SortField orders the records by a column; records keep their table order when empty
+	SortField string
+	// SortBy is "asc" or "desc"; "asc" when empty
+	SortBy string
+	// Limit is the maximum number of records returned; zero returns all of them
+	Limit int
+	// Offset skips that many records, after sorting
+	Offset int
+	// Columns lists the columns returned; all of them when empty
+	Columns []string
+	// Distinct drops records whose returned columns repeat those of an earlier
+	// record, before paging, e.g. to list the unique values of some columns
+	Distinct bool
+}
+
+// QueryWithOptions executes a query on the CSV table, then sorts, pages, and
+// projects the matching records according to opts. With a Limit or Offset, the
+// result describes the page in its pagination fields.
+func (cs *CSVStore) QueryWithOptions(tableName string, opts QueryOptions) (*QueryResult, error) {
+	op := Operation{Name: OpQueryWithOptions, Table: tableName, Payload: opts}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		return cs.queryWithOptions(tableName, opts)
+	})
+}
+
+// queryWithOptions executes a query with sorting, paging, and projection, and
+// masks the records.
+// The caller must hold cs.mu.
+func (cs *CSVStore) queryWithOptions(tableName string, opts QueryOptions) (*QueryResult, error) {
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("limit (%d) cannot be negative", opts.Limit)
+	}
+	if opts.Offset < 0 {
+		return nil, fmt.Errorf("offset (%d) cannot be negative", opts.Offset)
+	}
+	sortBy := opts.SortBy
+	if sortBy == "" {
+		sortBy = "asc"
+	}
+	if sortBy != "asc" && sortBy != "desc" {
+		return nil, fmt.Errorf("sortBy must be either 'asc' or 'desc', got '%s'", sortBy)
+	}
+
+	result, err := cs.query(tableName, opts.Conditions)
+	if err != nil {
+		return nil, err
+	}
+	records := result.Records
+
+	if opts.SortField != "" {
+		sortField := cs.columnResolver(tableName)(opts.SortField)
+		headers, err := cs.getHeaders(tableName)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(headers, sortField) {
+			return nil, fmt.Errorf("sortField '%s' does not exist in table '%s'", sortField, tableName)
+		}
+		slices.SortStableFunc(records, cs.recordComparer(tableName, sortField, sortBy))
+	}
+
+	// Mask before projecting, so computed columns see masked values
+	masked := cs.maskResult(tableName, &QueryResult{Records: records})
+	projected, err := cs.projectColumns(tableName, masked, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	records = projected.Records
+	if opts.Distinct {
+		records = distinctRecords(records)
+	}
+
+	total := len(records)
+	records = records[min(opts.Offset, len(records)):]
+	hasMore := false
+	if opts.Limit > 0 && len(records) > opts.Limit {
+		records = records[:opts.Limit]
+		hasMore = true
+	}
+
+	result = &QueryResult{
+		Records: records,
+		Count:   len(records),
+	}
+	if opts.Limit > 0 || opts.Offset > 0 {
+		result.Paginated = true
+		result.TotalCount = total
+		result.HasMore = hasMore
+		result.Limit = opts.Limit
+		result.Offset = opts.Offset
+	}
+	return result, nil
+}
+
+// SelectDistinct retrieves the unique combinations of values of columns among
+// the records matching conditions, in table order
+func (cs *CSVStore) SelectDistinct(
+	tableName string,
+	columns []string,
+	conditions []QueryCondition,
+) (*QueryResult, error) {
+	return cs.QueryWithOptions(tableName, QueryOptions{
+		Conditions: conditions,
+		Columns:    columns,
+		Distinct:   true,
+	})
+}
+
+// distinctRecords returns the records that do not repeat an earlier record
+func distinctRecords(records []CSVRecord) []CSVRecord {
+	seen := make(map[string]bool, len(records))
+	distinct := make([]CSVRecord, 0, len(records))
+	for _, record := range records {
+		// Maps encode with sorted keys, so equal records encode alike
+		key, _ := json.Marshal(record)
+		if seen[string(key)] {
+			continue
+		}
+		seen[string(key)] = true
+		distinct = append(distinct, record)
+	}
+	return distinct
+}
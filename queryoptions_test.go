@@ -0,0 +1,119 @@
+package csvstore
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestQueryWithOptions(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("products", []string{"id", "name", "price"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, product := range []CSVRecord{
+		{"name": "pen", "price": "3"},
+		{"name": "desk", "price": "250"},
+		{"name": "lamp", "price": "40"},
+		{"name": "chair", "price": "180"},
+	} {
+		if _, err := store.Insert("products", product); err != nil {
+			t.Fatalf("Failed to insert product: %v", err)
+		}
+	}
+
+	result, err := store.QueryWithOptions("products", QueryOptions{
+		Conditions: []QueryCondition{{Column: "price", Operator: ">", Value: "10"}},
+		SortField:  "price",
+		SortBy:     "desc",
+		Offset:     1,
+		Limit:      1,
+		Columns:    []string{"name"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["name"] != "chair" || len(result.Records[0]) != 1 {
+		t.Errorf("Expected only the name of chair, got %v", result.Records)
+	}
+	if !result.Paginated || result.TotalCount != 3 || !result.HasMore || result.Limit != 1 || result.Offset != 1 {
+		t.Errorf("Expected page 2 of 3 matching records, got %+v", result)
+	}
+
+	result, err = store.QueryWithOptions("products", QueryOptions{Offset: 10})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 0 || result.TotalCount != 4 || result.HasMore {
+		t.Errorf("Expected no records past the end, got %+v", result)
+	}
+
+	result, err = store.QueryWithOptions("products", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 4 || result.Paginated {
+		t.Errorf("Expected all records without pagination, got %+v", result)
+	}
+
+	if _, err := store.QueryWithOptions("products", QueryOptions{SortField: "missing"}); err == nil {
+		t.Errorf("Expected an error for a missing sort field")
+	}
+	if _, err := store.QueryWithOptions("products", QueryOptions{Limit: -1}); err == nil {
+		t.Errorf("Expected an error for a negative limit")
+	}
+}
+
+func TestSelectDistinct(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.CreateTable("products", []string{"id", "name", "category", "supplier"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, product := range []CSVRecord{
+		{"name": "pen", "category": "office", "supplier": "acme"},
+		{"name": "desk", "category": "office", "supplier": "globex"},
+		{"name": "pencil", "category": "office", "supplier": "acme"},
+		{"name": "lamp", "category": "home", "supplier": "acme"},
+		{"name": "chair", "category": "office", "supplier": "globex"},
+	} {
+		if _, err := store.Insert("products", product); err != nil {
+			t.Fatalf("Failed to insert product: %v", err)
+		}
+	}
+
+	result, err := store.SelectDistinct("products", []string{"category", "supplier"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to select distinct: %v", err)
+	}
+	expected := []CSVRecord{
+		{"category": "office", "supplier": "acme"},
+		{"category": "office", "supplier": "globex"},
+		{"category": "home", "supplier": "acme"},
+	}
+	if !reflect.DeepEqual(result.Records, expected) {
+		t.Errorf("Expected the unique pairs %v, got %v", expected, result.Records)
+	}
+
+	// Paging applies to the distinct records
+	result, err = store.QueryWithOptions("products", QueryOptions{
+		Columns:  []string{"supplier"},
+		Distinct: true,
+		Limit:    1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.TotalCount != 2 || !result.HasMore {
+		t.Errorf("Expected the first of 2 suppliers, got %+v", result)
+	}
+}
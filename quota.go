@@ -0,0 +1,196 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrQuotaExceeded is returned by writes that would take a table or the store
+// over its quota
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Quota limits the size of a table or of the whole store. Zero fields are
+// unlimited.
+type Quota struct {
+	// MaxBytes limits the size of the table files. The size of the rows being
+	// written is measured before compression and encryption, so the check is
+	// conservative for compressed or encrypted tables.
+	MaxBytes int64
+	// MaxRows limits the number of rows, headers excluded
+	MaxRows int
+}
+
+// WithQuota limits the total size of the tables of the store, companion tables
+// such as history and tombstones included. Writes that would exceed it fail
+// with ErrQuotaExceeded; writes that shrink the store, such as Delete, are
+// always allowed.
+func WithQuota(quota Quota) Option {
+	return func(cs *CSVStore) {
+		cs.quota = quota
+	}
+}
+
+// WithTableQuota limits the size of a table. Writes that would exceed it fail
+// with ErrQuotaExceeded; writes that shrink the table are always allowed.
+func WithTableQuota(quota Quota) TableOption {
+	return func(c *tableConfig) {
+		c.quota = quota
+	}
+}
+
+// enabled reports whether the quota limits anything
+func (q Quota) enabled() bool {
+	return q.MaxBytes > 0 || q.MaxRows > 0
+}
+
+// tableUsage is the size of a table file and its number of rows
+type tableUsage struct {
+	state fileState
+	rows  int
+}
+
+// quotasEnabled reports whether writes to a table are checked against a quota.
+// The caller must hold cs.mu.
+func (cs *CSVStore) quotasEnabled(tableName string) bool {
+	return cs.quota.enabled() || cs.tableConfig(tableName).quota.enabled()
+}
+
+// tableUsage returns the usage of a table, counting its rows only when the
+// file changed since they were last counted.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) tableUsage(tableName string) (tableUsage, error) {
+	state := cs.tableState(tableName)
+	if !state.exists {
+		return tableUsage{}, nil
+	}
+	if usage, ok := cs.usage[tableName]; ok && usage.state == state {
+		return usage, nil
+	}
+
+	usage := tableUsage{state: state}
+	err := cs.scanTable(tableName, nil, nil, func(CSVRecord) error {
+		usage.rows++
+		return nil
+	})
+	if err != nil {
+		return tableUsage{}, err
+	}
+	cs.usage[tableName] = usage
+	return usage, nil
+}
+
+// checkQuota returns ErrQuotaExceeded when writing rows to a table would take
+// it or the store over their quota. The rows are appended to the table, or
+// replace its contents, header row included, when replace is set. It returns
+// the number of rows of the table after the write.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) checkQuota(tableName string, rows [][]string, replace bool) (int, error) {
+	current, err := cs.tableUsage(tableName)
+	if err != nil {
+		return 0, err
+	}
+	size, err := cs.encodedSize(tableName, rows)
+	if err != nil {
+		return 0, err
+	}
+	next := tableUsage{state: fileState{size: size}, rows: len(rows)}
+	if replace {
+		// The header row is rewritten too
+		next.rows--
+	} else {
+		next.state.size += current.state.size
+		next.rows += current.rows
+	}
+
+	if err := checkUsage("table "+tableName, cs.tableConfig(tableName).quota, current, next); err != nil {
+		return 0, err
+	}
+	if !cs.quota.enabled() {
+		return next.rows, nil
+	}
+
+	tables, err := cs.listTables()
+	if err != nil {
+		return 0, err
+	}
+	var total tableUsage
+	for _, name := range tables {
+		usage, err := cs.tableUsage(name)
+		if err != nil {
+			return 0, err
+		}
+		total.state.size += usage.state.size
+		total.rows += usage.rows
+	}
+	nextTotal := tableUsage{
+		state: fileState{size: total.state.size - current.state.size + next.state.size},
+		rows:  total.rows - current.rows + next.rows,
+	}
+	if err := checkUsage("store", cs.quota, total, nextTotal); err != nil {
+		return 0, err
+	}
+	return next.rows, nil
+}
+
+// recordUsage remembers the number of rows of a table just written, so the
+// next quota check need not count them.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) recordUsage(tableName string, rows int) {
+	cs.usage[tableName] = tableUsage{state: cs.tableState(tableName), rows: rows}
+}
+
+// tableState returns the state of the files of a table, such as its
+// partitions and segments: their total size and latest modification time.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tableState(tableName string) fileState {
+	state := statFile(cs.fs, cs.getTableFile(tableName))
+	if !state.exists {
+		return state
+	}
+	// Files that cannot be listed are left out
+	leading, trailing, _ := cs.tableFiles(tableName, nil)
+	for _, name := range slices.Concat(leading, trailing) {
+		fileState := statFile(cs.fs, name)
+		state.size += fileState.size
+		if fileState.modTime.After(state.modTime) {
+			state.modTime = fileState.modTime
+		}
+	}
+	return state
+}
+
+// checkUsage returns ErrQuotaExceeded when a write growing usage from current
+// to next exceeds quota
+func checkUsage(subject string, quota Quota, current, next tableUsage) error {
+	if quota.MaxBytes > 0 && next.state.size > quota.MaxBytes && next.state.size > current.state.size {
+		return fmt.Errorf("%s would take %d bytes, over its quota of %d: %w",
+			subject, next.state.size, quota.MaxBytes, ErrQuotaExceeded)
+	}
+	if quota.MaxRows > 0 && next.rows > quota.MaxRows && next.rows > current.rows {
+		return fmt.Errorf("%s would hold %d rows, over its quota of %d: %w",
+			subject, next.rows, quota.MaxRows, ErrQuotaExceeded)
+	}
+	return nil
+}
+
+// encodedSize returns the number of bytes rows take in a table file, before
+// compression and encryption
+func (cs *CSVStore) encodedSize(tableName string, rows [][]string) (int64, error) {
+	counter := &byteCounter{}
+	writer := cs.newTableWriter(tableName, counter)
+	if err := writer.WriteAll(rows); err != nil {
+		return 0, fmt.Errorf("failed to encode records: %w", err)
+	}
+	return counter.n, nil
+}
+
+// byteCounter counts the bytes written to it
+type byteCounter struct {
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
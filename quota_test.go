@@ -0,0 +1,111 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTableQuota(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("events", WithTableQuota(Quota{MaxRows: 2}))
+	if err := store.CreateTable("events", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		if _, err := store.Insert("events", CSVRecord{"name": name}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	_, err = store.Insert("events", CSVRecord{"name": "c"})
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "table events would hold 3 rows") {
+		t.Errorf("Expected the table in the error, got %v", err)
+	}
+
+	// Other tables are not limited
+	for range 3 {
+		if _, err := store.Insert("users", CSVRecord{"name": "alice"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	// Updates that keep the size and deletes are allowed at the quota
+	conditions := []QueryCondition{{Column: "name", Operator: "=", Value: "a"}}
+	if _, err := store.Update("events", CSVRecord{"name": "z"}, conditions); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	if _, err := store.Delete("events", []QueryCondition{{Column: "name", Operator: "=", Value: "z"}}); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, err := store.Insert("events", CSVRecord{"name": "c"}); err != nil {
+		t.Fatalf("Failed to insert after delete: %v", err)
+	}
+
+	// Rows written by other programs are counted
+	path := store.getTableFile("events")
+	data, err := os.ReadFile(testDir + "/" + path)
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if err := os.WriteFile(testDir+"/"+path, []byte(strings.Join(lines[:2], "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+	if _, err := store.Insert("events", CSVRecord{"name": "d"}); err != nil {
+		t.Fatalf("Failed to insert after external change: %v", err)
+	}
+}
+
+func TestStoreQuota(t *testing.T) {
+	store := NewMemoryStore(WithQuota(Quota{MaxBytes: 200}))
+	defer store.Close()
+
+	for _, table := range []string{"users", "groups"} {
+		if err := store.CreateTable(table, []string{"id", "name"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+
+	var err error
+	inserted := 0
+	for err == nil {
+		table := []string{"users", "groups"}[inserted%2]
+		if _, err = store.Insert(table, CSVRecord{"name": strings.Repeat("x", 20)}); err == nil {
+			inserted++
+		}
+	}
+	if !errors.Is(err, ErrQuotaExceeded) || !strings.Contains(err.Error(), "store would take") {
+		t.Fatalf("Expected the store quota to be exceeded, got %v", err)
+	}
+	if inserted == 0 {
+		t.Fatal("Expected inserts below the quota to succeed")
+	}
+
+	usersSize, _ := store.fs.Stat(store.getTableFile("users"))
+	groupsSize, _ := store.fs.Stat(store.getTableFile("groups"))
+	if total := usersSize.Size() + groupsSize.Size(); total > 200 {
+		t.Errorf("Expected the store to stay within 200 bytes, got %d", total)
+	}
+
+	// Updates growing the store past the quota fail
+	_, err = store.Update("users", CSVRecord{"name": strings.Repeat("y", 100)}, nil)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded updating, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package csvstore
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// ErrReadOnly is returned by operations that would modify a read-only store
+var ErrReadOnly = errors.New("store is read-only")
+
+// writeOperations lists the operations that modify the store
+var writeOperations = map[string]bool{
+	OpCreateTable:        true,
+	OpInsert:             true,
+	OpUpdate:             true,
+	OpDelete:             true,
+	OpExpireNow:          true,
+	OpRestoreBackup:      true,
+	OpRestoreToTime:      true,
+	OpImportJSON:         true,
+	OpImportCSV:          true,
+	OpErase:              true,
+	OpFlush:              true,
+	OpMigrate:            true,
+	OpRollback:           true,
+	OpRepairSchema:       true,
+	OpRepair:             true,
+	OpRefresh:            true,
+	OpDedupe:             true,
+	OpMergeTables:        true,
+	OpSync:               true,
+	OpCompact:            true,
+	OpDeleteTenant:       true,
+	OpArchive:            true,
+	OpArchiveNow:         true,
+	OpApplyRetention:     true,
+	OpNormalize:          true,
+	OpUpdateRowChecksums: true,
+	OpSnapshot:           true,
+	OpDeleteSnapshot:     true,
+	OpRestoreSnapshot:    true,
+	OpImportStore:        true,
+	OpSeed:               true,
+	OpDeleteCount:        true,
+}
+
+// IsWriteOperation reports whether the named operation modifies the store
+func IsWriteOperation(name string) bool {
+	return writeOperations[name]
+}
+
+// NewCSVStoreFromFS creates a read-only store over the table files of fsys, e.g.
+// reference tables embedded in the binary with embed.FS (use fs.Sub to select a
+// directory). Queries work as usual; operations that would modify the store
+// return ErrReadOnly.
+func NewCSVStoreFromFS(fsys fs.FS, opts ...Option) (*CSVStore, error) {
+	opts = append(opts, WithFS(readOnlyFS{fsys: fsys}), func(cs *CSVStore) {
+		cs.readOnly = true
+	})
+	return NewCSVStore(".", opts...)
+}
+
+// readOnlyFS adapts an fs.FS to FS, refusing every modification
+type readOnlyFS struct {
+	fsys fs.FS
+}
+
+func (r readOnlyFS) Open(name string) (io.ReadCloser, error) {
+	return r.fsys.Open(name)
+}
+
+func (r readOnlyFS) Create(name string) (io.WriteCloser, error) {
+	return nil, &fs.PathError{Op: "create", Path: name, Err: ErrReadOnly}
+}
+
+func (r readOnlyFS) Append(name string) (io.WriteCloser, error) {
+	return nil, &fs.PathError{Op: "append", Path: name, Err: ErrReadOnly}
+}
+
+func (r readOnlyFS) Rename(oldName, newName string) error {
+	return &fs.PathError{Op: "rename", Path: oldName, Err: ErrReadOnly}
+}
+
+func (r readOnlyFS) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: ErrReadOnly}
+}
+
+func (r readOnlyFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(r.fsys, name)
+}
+
+func (r readOnlyFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(r.fsys, name)
+}
+
+func (r readOnlyFS) Mkdir(name string) error {
+	return &fs.PathError{Op: "mkdir", Path: name, Err: ErrReadOnly}
+}
+
+// Reader is the read-only part of the table API, implemented by CSVStore and
+// ReadOnlyView
+type Reader interface {
+	CheckTableExists(tableName string) bool
+	ListTables() ([]string, error)
+	Headers(tableName string) ([]string, error)
+	Query(tableName string, conditions []QueryCondition) (*QueryResult, error)
+	QuerySortedRange(tableName string, sortField string, sortBy string, limit int) (*QueryResult, error)
+	QueryWithOptions(tableName string, opts QueryOptions) (*QueryResult, error)
+	Select(tableName string, columns []string, conditions []QueryCondition) (*QueryResult, error)
+}
+
+var (
+	_ Reader = (*CSVStore)(nil)
+	_ Reader = (*ReadOnlyView)(nil)
+)
+
+// ReadOnlyView is a handle of a store that can only read tables, to hand to
+// components such as reports that must not modify data. Reads go through the
+// store as usual, with its middleware and column masks.
+type ReadOnlyView struct {
+	cs *CSVStore
+}
+
+// ReadOnlyView returns a read-only handle of the store
+func (cs *CSVStore) ReadOnlyView() *ReadOnlyView {
+	return &ReadOnlyView{cs: cs}
+}
+
+// CheckTableExists checks if a table exists
+func (v *ReadOnlyView) CheckTableExists(tableName string) bool {
+	return v.cs.CheckTableExists(tableName)
+}
+
+// ListTables returns all available tables
+func (v *ReadOnlyView) ListTables() ([]string, error) {
+	return v.cs.ListTables()
+}
+
+// Headers returns the column names of a table, in file order
+func (v *ReadOnlyView) Headers(tableName string) ([]string, error) {
+	return v.cs.Headers(tableName)
+}
+
+// Query executes a query on the CSV table
+func (v *ReadOnlyView) Query(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	return v.cs.Query(tableName, conditions)
+}
+
+// QuerySortedRange retrieves a limited number of sorted records
+func (v *ReadOnlyView) QuerySortedRange(
+	tableName string,
+	sortField string,
+	sortBy string,
+	limit int,
+) (*QueryResult, error) {
+	return v.cs.QuerySortedRange(tableName, sortField, sortBy, limit)
+}
+
+// QueryWithOptions executes a query on the CSV table, then sorts, pages, and
+// projects the matching records according to opts
+func (v *ReadOnlyView) QueryWithOptions(tableName string, opts QueryOptions) (*QueryResult, error) {
+	return v.cs.QueryWithOptions(tableName, opts)
+}
+
+// Select retrieves specific columns from query results
+func (v *ReadOnlyView) Select(
+	tableName string,
+	columns []string,
+	conditions []QueryCondition,
+) (*QueryResult, error) {
+	return v.cs.Select(tableName, columns, conditions)
+}
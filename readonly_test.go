@@ -0,0 +1,77 @@
+package csvstore
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewCSVStoreFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"countries.csv": {Data: []byte("code,name\nDE,Germany\nFR,France\n")},
+		"README.md":     {Data: []byte("lookup tables")},
+	}
+	store, err := NewCSVStoreFromFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+
+	tables, err := store.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if len(tables) != 1 || tables[0] != "countries" {
+		t.Errorf("Expected the countries table, got %v", tables)
+	}
+
+	result, err := store.Query("countries", []QueryCondition{{Column: "code", Operator: "=", Value: "FR"}})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["name"] != "France" {
+		t.Errorf("Unexpected records: %v", result.Records)
+	}
+
+	if _, err := store.Insert("countries", CSVRecord{"code": "IT", "name": "Italy"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly on insert, got %v", err)
+	}
+	if err := store.CreateTable("cities", []string{"name"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly on create, got %v", err)
+	}
+}
+
+func TestReadOnlyView(t *testing.T) {
+	store := NewMemoryStore(WithTableDefaults(WithMask("email", func(string) string { return "***" })))
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name", "email"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("users", CSVRecord{"name": "alice", "email": "alice@example.com"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	var view Reader = store.ReadOnlyView()
+	result, err := view.Query("users", []QueryCondition{{Column: "name", Operator: "=", Value: "alice"}})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["email"] != "***" {
+		t.Errorf("Expected the masked record, got %v", result.Records)
+	}
+	tables, err := view.ListTables()
+	if err != nil || len(tables) != 1 {
+		t.Errorf("Expected 1 table, got %v (%v)", tables, err)
+	}
+	if _, err := view.Select("users", []string{"name"}, nil); err != nil {
+		t.Errorf("Failed to select: %v", err)
+	}
+
+	viewType := reflect.TypeOf(store.ReadOnlyView())
+	for _, method := range []string{"Insert", "Update", "Delete", "CreateTable", "Close"} {
+		if _, ok := viewType.MethodByName(method); ok {
+			t.Errorf("Expected ReadOnlyView to have no %s method", method)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package csvstore
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// RepairPolicy selects what Repair does with malformed rows
+type RepairPolicy int
+
+const (
+	// RepairFix pads short rows, truncates long rows, reparses rows with broken
+	// quoting leniently, and gives rows with a duplicate id a new id
+	RepairFix RepairPolicy = iota
+	// RepairDrop drops every malformed row
+	RepairDrop
+	// RepairReportOnly reports malformed rows without writing a repaired copy
+	RepairReportOnly
+)
+
+// Kinds of problems found by Repair
+const (
+	RepairFieldCount  = "field_count"
+	RepairQuoting     = "quoting"
+	RepairDuplicateID = "duplicate_id"
+)
+
+// RepairIssue describes a malformed row and what Repair did with it
+type RepairIssue struct {
+	Line   int    // Line of the table file where the row starts, from 1
+	Kind   string // One of the Repair* problem kinds
+	Action string // "padded", "truncated", "reparsed", "reassigned", "dropped", or "reported"
+	Detail string
+}
+
+// RepairReport describes the result of Repair
+type RepairReport struct {
+	Table         string
+	RepairedTable string // Empty for RepairReportOnly
+	Rows          int    // Rows written to the repaired copy
+	Issues        []RepairIssue
+}
+
+// RepairedTableName returns the name of the table Repair writes the repaired copy of a table to
+func RepairedTableName(tableName string) string {
+	return tableName + "__repaired"
+}
+
+// Repair scans a table for rows with the wrong number of fields, broken quoting,
+// or duplicate ids, and writes the rows, fixed or dropped according to policy,
+// to a repaired copy named by RepairedTableName. The table itself is not changed.
+func (cs *CSVStore) Repair(tableName string, policy RepairPolicy) (*RepairReport, error) {
+	op := Operation{Name: OpRepair, Table: tableName, Payload: policy}
+	return runOperation(cs, op, func() (*RepairReport, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.repair(tableName, policy)
+	})
+}
+
+// repair writes a repaired copy of a table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) repair(tableName string, policy RepairPolicy) (*RepairReport, error) {
+	file, err := cs.openTableFile(tableName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table file: %w", err)
+	}
+
+	report := &RepairReport{Table: tableName}
+	addIssue := func(line int, kind, fixAction, detail string) bool {
+		action := fixAction
+		switch policy {
+		case RepairDrop:
+			action = "dropped"
+		case RepairReportOnly:
+			action = "reported"
+		}
+		report.Issues = append(report.Issues, RepairIssue{Line: line, Kind: kind, Action: action, Detail: detail})
+		return action != "dropped"
+	}
+
+	var headers []string
+	records := make([]CSVRecord, 0)
+	idColumn := cs.reservedColumns(tableName).ID
+	seenIDs := make(map[string]bool)
+	line := 1 // Line of the start of data
+	for len(data) > 0 {
+		reader := cs.newTableReader(tableName, bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowLine, consumed int
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			// Skip only the first line of a record that cannot be parsed, and
+			// parse that line again leniently
+			rowLine = line + parseErr.StartLine - 1
+			start := lineOffset(data, parseErr.StartLine-1)
+			consumed = lineOffset(data, parseErr.StartLine)
+			lenient := cs.newTableReader(tableName, bytes.NewReader(data[start:consumed]))
+			lenient.LazyQuotes = true
+			lenient.FieldsPerRecord = -1
+			fixAction := "reparsed"
+			if row, err = lenient.Read(); err != nil {
+				fixAction = "dropped"
+			}
+			if !addIssue(rowLine, RepairQuoting, fixAction, parseErr.Err.Error()) || err != nil {
+				err = errDropRow
+			}
+		case err != nil:
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		default:
+			fieldLine, _ := reader.FieldPos(0)
+			rowLine = line + fieldLine - 1
+			consumed = int(reader.InputOffset())
+		}
+		line += bytes.Count(data[:consumed], []byte("\n"))
+		data = data[consumed:]
+		if err != nil {
+			continue
+		}
+
+		if headers == nil {
+			headers = append([]string(nil), row...)
+			continue
+		}
+
+		if len(row) != len(headers) {
+			action := "padded"
+			if len(row) > len(headers) {
+				action = "truncated"
+			}
+			detail := fmt.Sprintf("%d fields, expected %d", len(row), len(headers))
+			if !addIssue(rowLine, RepairFieldCount, action, detail) {
+				continue
+			}
+		}
+		record := make(CSVRecord, len(headers))
+		for i, header := range headers {
+			if i < len(row) {
+				record[header] = row[i]
+			} else {
+				record[header] = ""
+			}
+		}
+
+		if id, hasID := record[idColumn]; hasID && id != "" {
+			if seenIDs[id] {
+				if !addIssue(rowLine, RepairDuplicateID, "reassigned", "duplicate id "+id) {
+					continue
+				}
+				if policy == RepairFix {
+					id = uniqueID(seenIDs)
+					record[idColumn] = id
+				}
+			}
+			seenIDs[id] = true
+		}
+		records = append(records, record)
+	}
+
+	if headers == nil {
+		return nil, fmt.Errorf("table %s has no header row", tableName)
+	}
+	if policy == RepairReportOnly {
+		return report, nil
+	}
+
+	report.RepairedTable = RepairedTableName(tableName)
+	report.Rows = len(records)
+	if err := cs.saveTable(report.RepairedTable, headers, records); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// errDropRow marks a row dropped by Repair
+var errDropRow = errors.New("row dropped")
+
+// lineOffset returns the offset of the start of line n+1 of data, counting from
+// 0, or the length of data when it has fewer lines
+func lineOffset(data []byte, n int) int {
+	offset := 0
+	for range n {
+		i := bytes.IndexByte(data[offset:], '\n')
+		if i < 0 {
+			return len(data)
+		}
+		offset += i + 1
+	}
+	return offset
+}
+
+// uniqueID returns a timestamp id, like the ids Insert generates, not in used
+func uniqueID(used map[string]bool) string {
+	for {
+		id := strconv.Itoa(int(time.Now().UnixNano()))
+		if !used[id] {
+			return id
+		}
+	}
+}
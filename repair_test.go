@@ -0,0 +1,76 @@
+package csvstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	contents := "id,name,note\n" +
+		"1,a,ok\n" +
+		"2,short\n" +
+		"3,long,row,extra\n" +
+		"4,bad \"quote\",x\n" +
+		"1,dup,again\n" +
+		"5,\"multi\nline\",fine\n" +
+		"6,f,ok\n"
+	if err := os.WriteFile(filepath.Join(testDir, "messy.csv"), []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+
+	report, err := store.Repair("messy", RepairFix)
+	if err != nil {
+		t.Fatalf("Failed to repair table: %v", err)
+	}
+	expected := []RepairIssue{
+		{Line: 3, Kind: RepairFieldCount, Action: "padded"},
+		{Line: 4, Kind: RepairFieldCount, Action: "truncated"},
+		{Line: 5, Kind: RepairQuoting, Action: "reparsed"},
+		{Line: 6, Kind: RepairDuplicateID, Action: "reassigned"},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %+v", len(expected), report.Issues)
+	}
+	for i, issue := range report.Issues {
+		if issue.Line != expected[i].Line || issue.Kind != expected[i].Kind || issue.Action != expected[i].Action {
+			t.Errorf("Expected issue %+v, got %+v", expected[i], issue)
+		}
+	}
+	if report.Rows != 7 {
+		t.Errorf("Expected 7 repaired rows, got %d", report.Rows)
+	}
+
+	repaired, err := store.Query(RepairedTableName("messy"), nil)
+	if err != nil {
+		t.Fatalf("Failed to query repaired table: %v", err)
+	}
+	if repaired.Records[3]["name"] != `bad "quote"` || repaired.Records[5]["name"] != "multi\nline" {
+		t.Errorf("Unexpected repaired records: %v", repaired.Records)
+	}
+	if repaired.Records[4]["id"] == "1" {
+		t.Errorf("Expected the duplicate id to be reassigned")
+	}
+
+	report, err = store.Repair("messy", RepairDrop)
+	if err != nil {
+		t.Fatalf("Failed to repair table: %v", err)
+	}
+	if report.Rows != 3 {
+		t.Errorf("Expected 3 rows after dropping, got %d", report.Rows)
+	}
+
+	_, err = store.Repair("other", RepairReportOnly)
+	if err == nil {
+		t.Errorf("Expected an error for a missing table")
+	}
+}
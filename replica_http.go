@@ -0,0 +1,108 @@
+package csvstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPReplica is a replica forwarding changes to a remote replica served with ReplicaHandler
+type HTTPReplica struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPReplica returns a replica forwarding changes to the ReplicaHandler at url.
+// http.DefaultClient is used when client is nil.
+func NewHTTPReplica(url string, client *http.Client) *HTTPReplica {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPReplica{url: url, client: client}
+}
+
+// Position asks the remote replica for the sequence number of the last change it applied
+func (r *HTTPReplica) Position() (uint64, error) {
+	resp, err := r.client.Get(r.url)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach replica %s: %w", r.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("replica %s: %s", r.url, resp.Status)
+	}
+	var position replicaPosition
+	if err := json.NewDecoder(resp.Body).Decode(&position); err != nil {
+		return 0, fmt.Errorf("failed to parse position of replica %s: %w", r.url, err)
+	}
+	return position.Position, nil
+}
+
+// Apply sends changes to the remote replica as NDJSON, one ChangeEvent per line
+func (r *HTTPReplica) Apply(events []ChangeEvent) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode change %d: %w", event.Sequence, err)
+		}
+	}
+
+	resp, err := r.client.Post(r.url, "application/x-ndjson", &body)
+	if err != nil {
+		return fmt.Errorf("failed to reach replica %s: %w", r.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("replica %s: %s: %s", r.url, resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// ReplicaHandler serves a replica to primaries using NewHTTPReplica. GET requests
+// return the position of the replica as JSON; POST requests apply the changes of
+// their NDJSON body.
+func ReplicaHandler(replica Replica) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			position, err := replica.Position()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(replicaPosition{Position: position})
+		case http.MethodPost:
+			var events []ChangeEvent
+			scanner := bufio.NewScanner(req.Body)
+			scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+			for scanner.Scan() {
+				var event ChangeEvent
+				if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+					http.Error(w, fmt.Sprintf("failed to parse change: %v", err), http.StatusBadRequest)
+					return
+				}
+				events = append(events, event)
+			}
+			if err := scanner.Err(); err != nil {
+				http.Error(w, fmt.Sprintf("failed to read changes: %v", err), http.StatusBadRequest)
+				return
+			}
+			if err := replica.Apply(events); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
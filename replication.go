@@ -0,0 +1,255 @@
+package csvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// ReplicaPositionFileName is the name of the file of a replica directory holding
+// the sequence number of the last change applied to it
+const ReplicaPositionFileName = "replica.json"
+
+// Replica receives the changes of a primary store, in order
+type Replica interface {
+	// Position returns the sequence number of the last change the replica applied
+	Position() (uint64, error)
+	// Apply applies changes in order of sequence number and records the sequence
+	// number of the last one as the new position
+	Apply(events []ChangeEvent) error
+}
+
+// WithReplication makes the store a primary streaming its change log to replicas,
+// e.g. read replicas for heavy dashboards. Changes are shipped in the background
+// shortly after every write, at least once per interval, and when the store is
+// closed; Replicate ships them right away. A replica that falls behind or was
+// unreachable catches up from its position. Errors of background shipping are
+// passed to onError when it is not nil.
+//
+// The store must use WithChangeLog, and its change log must cover every change
+// since the replicas were created or copied from the primary.
+func WithReplication(interval time.Duration, onError func(error), replicas ...Replica) Option {
+	return func(cs *CSVStore) {
+		cs.replication = &replicationConfig{
+			interval: interval,
+			onError:  onError,
+			replicas: replicas,
+			wake:     make(chan struct{}, 1),
+		}
+	}
+}
+
+// replicationConfig holds the settings of WithReplication
+type replicationConfig struct {
+	interval time.Duration
+	onError  func(error)
+	replicas []Replica
+	// wake is signaled when changes are written to the change log
+	wake chan struct{}
+}
+
+// startReplication starts shipping the change log to the replicas in the background
+func (cs *CSVStore) startReplication() error {
+	if !cs.changeLog {
+		return errors.New("replication requires the change log (WithChangeLog)")
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		var tick <-chan time.Time
+		if cs.replication.interval > 0 {
+			ticker := time.NewTicker(cs.replication.interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for {
+			select {
+			case <-stop:
+				return
+			case <-cs.replication.wake:
+			case <-tick:
+			}
+			if err := cs.replicate(); err != nil && cs.replication.onError != nil {
+				cs.replication.onError(err)
+			}
+		}
+	}()
+
+	cs.addCloser(func() error {
+		close(stop)
+		<-done
+		return cs.replicate()
+	})
+	return nil
+}
+
+// wakeReplication signals the replication goroutine that changes were written
+func (cs *CSVStore) wakeReplication() {
+	if cs.replication == nil {
+		return
+	}
+	select {
+	case cs.replication.wake <- struct{}{}:
+	default:
+		// Already signaled
+	}
+}
+
+// Replicate ships the changes the replicas have not applied yet and waits until
+// they are applied. It does nothing for stores without WithReplication.
+func (cs *CSVStore) Replicate() error {
+	_, err := runOperation(cs, Operation{Name: OpReplicate}, func() (any, error) {
+		return nil, cs.replicate()
+	})
+	return err
+}
+
+// replicate ships the changes each replica has not applied yet
+func (cs *CSVStore) replicate() error {
+	if cs.replication == nil {
+		return nil
+	}
+
+	var errs []error
+	for i, replica := range cs.replication.replicas {
+		if err := cs.replicateTo(replica); err != nil {
+			errs = append(errs, fmt.Errorf("replica %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// replicateTo ships the changes a replica has not applied yet
+func (cs *CSVStore) replicateTo(replica Replica) error {
+	position, err := replica.Position()
+	if err != nil {
+		return fmt.Errorf("failed to read replica position: %w", err)
+	}
+
+	cs.mu.RLock()
+	var events []ChangeEvent
+	err = cs.readChangeLog(func(event ChangeEvent) error {
+		if event.Sequence > position {
+			events = append(events, event)
+		}
+		return nil
+	})
+	cs.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+	return replica.Apply(events)
+}
+
+// DirReplica is a replica keeping a copy of the primary's tables in a directory
+type DirReplica struct {
+	cs *CSVStore
+}
+
+// NewDirReplica opens the replica directory at basePath, creating it if needed.
+// opts must configure the tables like the primary, e.g. their delimiters and
+// compression.
+func NewDirReplica(basePath string, opts ...Option) (*DirReplica, error) {
+	opts = append(opts, func(cs *CSVStore) {
+		cs.readOnly = true
+	})
+	cs, err := NewCSVStore(basePath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &DirReplica{cs: cs}, nil
+}
+
+// Store returns the read-only store over the tables of the replica
+func (r *DirReplica) Store() *CSVStore {
+	return r.cs
+}
+
+// Close closes the store of the replica
+func (r *DirReplica) Close() error {
+	return r.cs.Close()
+}
+
+// replicaPosition is the contents of the position file of a replica directory
+type replicaPosition struct {
+	Position uint64 `json:"position"`
+}
+
+// Position returns the sequence number of the last change applied to the replica
+func (r *DirReplica) Position() (uint64, error) {
+	r.cs.mu.RLock()
+	defer r.cs.mu.RUnlock()
+
+	return r.cs.replicaPosition()
+}
+
+// replicaPosition reads the position file of a replica directory.
+// The caller must hold cs.mu.
+func (cs *CSVStore) replicaPosition() (uint64, error) {
+	data, err := cs.readFile(ReplicaPositionFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read replica position: %w", err)
+	}
+
+	var position replicaPosition
+	if err := json.Unmarshal(data, &position); err != nil {
+		return 0, fmt.Errorf("failed to parse replica position: %w", err)
+	}
+	return position.Position, nil
+}
+
+// Apply applies changes of the primary to the tables of the replica. Changes at
+// or before the current position are skipped, so shipping a change twice is harmless.
+func (r *DirReplica) Apply(events []ChangeEvent) error {
+	if r.cs.closed.Load() {
+		return ErrClosed
+	}
+
+	r.cs.mu.Lock()
+	defer r.cs.mu.Unlock()
+
+	position, err := r.cs.replicaPosition()
+	if err != nil {
+		return err
+	}
+	applied := position
+	for _, event := range events {
+		if event.Sequence <= applied {
+			continue
+		}
+		if err := r.cs.applyChange(event); err != nil {
+			err = fmt.Errorf("failed to apply change %d: %w", event.Sequence, err)
+			return errors.Join(err, r.cs.writeReplicaPosition(applied))
+		}
+		applied = event.Sequence
+	}
+	if applied == position {
+		return nil
+	}
+	return r.cs.writeReplicaPosition(applied)
+}
+
+// writeReplicaPosition writes the position file of a replica directory.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) writeReplicaPosition(position uint64) error {
+	data, err := json.Marshal(replicaPosition{Position: position})
+	if err != nil {
+		return fmt.Errorf("failed to encode replica position: %w", err)
+	}
+	if err := cs.writeFile(ReplicaPositionFileName, data); err != nil {
+		return fmt.Errorf("failed to write replica position: %w", err)
+	}
+	return nil
+}
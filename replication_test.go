@@ -0,0 +1,100 @@
+package csvstore
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReplication(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+	replicaDir := getTestDir() + "-replica"
+	defer os.RemoveAll(replicaDir)
+	remoteDir := getTestDir() + "-remote"
+	defer os.RemoveAll(remoteDir)
+
+	replica, err := NewDirReplica(replicaDir)
+	if err != nil {
+		t.Fatalf("Failed to open replica: %v", err)
+	}
+	defer replica.Close()
+	remote, err := NewDirReplica(remoteDir)
+	if err != nil {
+		t.Fatalf("Failed to open replica: %v", err)
+	}
+	defer remote.Close()
+	server := httptest.NewServer(ReplicaHandler(remote))
+	defer server.Close()
+
+	if _, err := NewCSVStore(testDir, WithReplication(0, nil, replica)); err == nil {
+		t.Fatalf("Expected replication without the change log to fail")
+	}
+
+	primary, err := NewCSVStore(testDir, WithChangeLog(),
+		WithReplication(time.Hour, nil, replica, NewHTTPReplica(server.URL, nil)))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer primary.Close()
+
+	if err := primary.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, user := range []CSVRecord{{"id": "1", "name": "alice"}, {"id": "2", "name": "bob"}} {
+		if _, err := primary.Insert("users", user); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := primary.Update("users", CSVRecord{"name": "robert"}, []QueryCondition{{Column: "id", Operator: "=", Value: "2"}}); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if _, err := primary.Delete("users", []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}); err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+	if err := primary.Replicate(); err != nil {
+		t.Fatalf("Failed to replicate: %v", err)
+	}
+
+	for name, r := range map[string]*DirReplica{"local": replica, "remote": remote} {
+		result, err := r.Store().Query("users", nil)
+		if err != nil {
+			t.Fatalf("Failed to query %s replica: %v", name, err)
+		}
+		if result.Count != 1 || result.Records[0]["name"] != "robert" {
+			t.Errorf("Expected the %s replica to hold robert, got %v", name, result.Records)
+		}
+		position, err := r.Position()
+		if err != nil {
+			t.Fatalf("Failed to read position: %v", err)
+		}
+		if position != 5 {
+			t.Errorf("Expected the %s replica at position 5, got %d", name, position)
+		}
+	}
+
+	if _, err := replica.Store().Insert("users", CSVRecord{"id": "3"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly writing to a replica, got %v", err)
+	}
+
+	// Writes are shipped in the background
+	if _, err := primary.Insert("users", CSVRecord{"id": "3", "name": "carol"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		result, err := replica.Store().Query("users", nil)
+		if err != nil {
+			t.Fatalf("Failed to query replica: %v", err)
+		}
+		if result.Count == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the insert to reach the replica, got %v", result.Records)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
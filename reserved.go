@@ -0,0 +1,127 @@
+package csvstore
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Default names of the columns the store fills in automatically
+const (
+	DefaultIDColumn        = "id"
+	DefaultCreatedAtColumn = "created_at"
+	DefaultUpdatedAtColumn = "updated_at"
+)
+
+// ReservedColumns names the columns the store fills in automatically: the
+// generated id, the creation timestamp, and the last update timestamp. Empty
+// fields keep the default names.
+type ReservedColumns struct {
+	ID        string
+	CreatedAt string
+	UpdatedAt string
+}
+
+// WithReservedColumns renames the columns the store fills in automatically, so
+// the automation works with existing files whose headers cannot change, e.g.
+// ReservedColumns{ID: "uuid", CreatedAt: "createdAt"}
+func WithReservedColumns(columns ReservedColumns) TableOption {
+	return func(c *tableConfig) {
+		if columns.ID != "" {
+			c.reserved.ID = columns.ID
+		}
+		if columns.CreatedAt != "" {
+			c.reserved.CreatedAt = columns.CreatedAt
+		}
+		if columns.UpdatedAt != "" {
+			c.reserved.UpdatedAt = columns.UpdatedAt
+		}
+	}
+}
+
+// WithoutTimestamps turns off the automatic creation and update timestamps, for
+// tables whose timestamp columns carry values supplied by callers. Insert and
+// Update then store the timestamp columns as given, like any other column.
+func WithoutTimestamps() TableOption {
+	return func(c *tableConfig) {
+		c.noTimestamps = true
+	}
+}
+
+// CreateTableOption configures the creation of a table
+type CreateTableOption func(*createTableConfig)
+
+// createTableConfig holds the options of a CreateTable call
+type createTableConfig struct {
+	injectReserved bool
+}
+
+// WithReservedColumnsInjected makes CreateTable prepend the id column and append
+// the timestamp columns to the headers when they are missing, so the store
+// generates ids and timestamps for the table. Timestamp columns are left out for
+// tables configured with WithoutTimestamps.
+func WithReservedColumnsInjected() CreateTableOption {
+	return func(c *createTableConfig) {
+		c.injectReserved = true
+	}
+}
+
+// injectReservedColumns adds the missing reserved columns of a table to headers.
+// The caller must hold cs.mu.
+func (cs *CSVStore) injectReservedColumns(tableName string, headers []string) []string {
+	config := cs.tableConfig(tableName)
+	reserved := config.reservedColumns()
+
+	injected := make([]string, 0, len(headers)+3)
+	if !slices.Contains(headers, reserved.ID) {
+		injected = append(injected, reserved.ID)
+	}
+	injected = append(injected, headers...)
+	if !config.noTimestamps {
+		for _, column := range []string{reserved.CreatedAt, reserved.UpdatedAt} {
+			if !slices.Contains(headers, column) {
+				injected = append(injected, column)
+			}
+		}
+	}
+	return injected
+}
+
+// reservedColumns returns the names of the automatic columns of the table
+func (c *tableConfig) reservedColumns() ReservedColumns {
+	columns := c.reserved
+	if columns.ID == "" {
+		columns.ID = DefaultIDColumn
+	}
+	if columns.CreatedAt == "" {
+		columns.CreatedAt = DefaultCreatedAtColumn
+	}
+	if columns.UpdatedAt == "" {
+		columns.UpdatedAt = DefaultUpdatedAtColumn
+	}
+	return columns
+}
+
+// reservedColumns returns the names of the automatic columns of a table. History
+// and tombstone tables use the names of the table they belong to.
+// The caller must hold cs.mu.
+func (cs *CSVStore) reservedColumns(tableName string) ReservedColumns {
+	if isHistoryTable(tableName) {
+		tableName = strings.TrimSuffix(tableName, historySuffix)
+	}
+	if isTombstoneTable(tableName) {
+		tableName = strings.TrimSuffix(tableName, tombstoneSuffix)
+	}
+	return cs.tableConfig(tableName).reservedColumns()
+}
+
+// lastModified returns when a record was last updated, or created if it was never
+// updated; the zero time when the record holds no valid timestamp
+func (r ReservedColumns) lastModified(record CSVRecord) time.Time {
+	value := record[r.UpdatedAt]
+	if value == "" {
+		value = record[r.CreatedAt]
+	}
+	t, _ := time.Parse(time.RFC3339Nano, value)
+	return t
+}
@@ -0,0 +1,147 @@
+package csvstore
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestReservedColumns(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("events", WithHistory(), WithReservedColumns(ReservedColumns{
+		ID:        "uuid",
+		CreatedAt: "createdAt",
+		UpdatedAt: "updatedAt",
+	}))
+	if err := store.CreateTable("events", []string{"uuid", "name", "createdAt", "updatedAt"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	inserted, err := store.Insert("events", CSVRecord{"name": "signup"})
+	if err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if inserted["uuid"] == "" || inserted["createdAt"] == "" || inserted["updatedAt"] == "" {
+		t.Errorf("Expected renamed reserved columns to be filled in, got %v", inserted)
+	}
+
+	updated, err := store.Update("events", CSVRecord{"name": "login"}, []QueryCondition{
+		{Column: "uuid", Operator: "=", Value: inserted["uuid"]},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update records: %v", err)
+	}
+	if updated.Count != 1 || updated.Records[0]["updatedAt"] == inserted["updatedAt"] {
+		t.Errorf("Expected updatedAt to change, got %v", updated.Records)
+	}
+
+	history, err := store.History("events", inserted["uuid"])
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if history.Count != 1 || history.Records[0]["name"] != "signup" {
+		t.Errorf("Unexpected history: %v", history.Records)
+	}
+
+	// Erasure keeps the renamed reserved columns
+	report, err := store.Erase("name", "login", EraseOptions{Mode: EraseRedact})
+	if err != nil {
+		t.Fatalf("Failed to erase records: %v", err)
+	}
+	if report.Total != 1 {
+		t.Fatalf("Expected 1 erased record, got %d", report.Total)
+	}
+	result, err := store.Query("events", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Records[0]["uuid"] != inserted["uuid"] || result.Records[0]["name"] != DefaultRedaction {
+		t.Errorf("Unexpected erased record: %v", result.Records[0])
+	}
+}
+
+func TestWithoutTimestamps(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("imported", WithoutTimestamps())
+	headers := []string{"id", "name", "created_at", "updated_at"}
+	if err := store.CreateTable("imported", headers); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	inserted, err := store.Insert("imported", CSVRecord{"name": "a", "updated_at": "2020-01-01T00:00:00Z"})
+	if err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if inserted["id"] == "" {
+		t.Errorf("Expected id to be generated")
+	}
+	if inserted["created_at"] != "" || inserted["updated_at"] != "2020-01-01T00:00:00Z" {
+		t.Errorf("Expected timestamps to be stored as given, got %v", inserted)
+	}
+
+	updated, err := store.Update("imported", CSVRecord{"name": "b"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to update records: %v", err)
+	}
+	if updated.Records[0]["updated_at"] != "2020-01-01T00:00:00Z" {
+		t.Errorf("Expected updated_at to be kept, got %q", updated.Records[0]["updated_at"])
+	}
+}
+
+func TestCreateTableInjectsReservedColumns(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("renamed", WithReservedColumns(ReservedColumns{ID: "uuid"}))
+	tests := []struct {
+		table    string
+		headers  []string
+		expected []string
+	}{
+		{"plain", []string{"name"}, []string{"id", "name", "created_at", "updated_at"}},
+		{"partial", []string{"name", "created_at"}, []string{"id", "name", "created_at", "updated_at"}},
+		{"renamed", []string{"name"}, []string{"uuid", "name", "created_at", "updated_at"}},
+	}
+	for _, tt := range tests {
+		if err := store.CreateTable(tt.table, tt.headers, WithReservedColumnsInjected()); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		headers, err := store.getHeaders(tt.table)
+		if err != nil {
+			t.Fatalf("Failed to get headers: %v", err)
+		}
+		if !slices.Equal(headers, tt.expected) {
+			t.Errorf("Expected headers %v for %s, got %v", tt.expected, tt.table, headers)
+		}
+	}
+
+	inserted, err := store.Insert("plain", CSVRecord{"name": "a"})
+	if err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if inserted["id"] == "" || inserted["created_at"] == "" {
+		t.Errorf("Expected id and timestamps to be generated, got %v", inserted)
+	}
+}
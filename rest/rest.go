@@ -0,0 +1,311 @@
+// Package rest exposes a csvstore over HTTP with JSON bodies, so tools written in
+// other languages can use a store without linking the library.
+//
+// Routes:
+//
+//	GET    /tables                   list tables
+//	GET    /tables/{table}/records   query records
+//	POST   /tables/{table}/records   insert a record
+//	PATCH  /tables/{table}/records   update the records matching the filters
+//	DELETE /tables/{table}/records   delete the records matching the filters
+//
+// Records are filtered with repeated filter=column:operator:value parameters,
+// e.g. filter=age:>=:18, using the operators of csvstore.QueryCondition. GET also
+// takes sort=column, order=asc|desc, limit, offset, and columns=a,b; responses
+// to queries with limit or offset describe the page in pagination. PATCH and
+// DELETE refuse to change every record of a table unless all=true is passed.
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+// maxBodyBytes bounds the size of request bodies
+const maxBodyBytes = 1 << 20
+
+// recordsResponse is the body of responses carrying records
+type recordsResponse struct {
+	Records []csvstore.CSVRecord `json:"records"`
+	Count   int                  `json:"count"`
+	// Pagination is set for queries with limit or offset
+	Pagination *paginationResponse `json:"pagination,omitempty"`
+}
+
+// paginationResponse describes the page of records of a query
+type paginationResponse struct {
+	TotalCount int  `json:"total_count"`
+	HasMore    bool `json:"has_more"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+}
+
+// errorResponse is the body of error responses
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves the routes of a store
+type handler struct {
+	store *csvstore.CSVStore
+}
+
+// NewHandler returns an http.Handler exposing store. Mount it with
+// http.StripPrefix to serve it below a path prefix.
+func NewHandler(store *csvstore.CSVStore) http.Handler {
+	h := &handler{store: store}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tables", h.listTables)
+	mux.HandleFunc("GET /tables/{table}/records", h.query)
+	mux.HandleFunc("POST /tables/{table}/records", h.insert)
+	mux.HandleFunc("PATCH /tables/{table}/records", h.update)
+	mux.HandleFunc("DELETE /tables/{table}/records", h.delete)
+	return mux
+}
+
+func (h *handler) listTables(w http.ResponseWriter, r *http.Request) {
+	tables, err := h.store.ListTables()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tables)
+}
+
+func (h *handler) query(w http.ResponseWriter, r *http.Request) {
+	table, ok := h.table(w, r)
+	if !ok {
+		return
+	}
+	opts, err := queryOptions(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.store.QueryWithOptions(table, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response := recordsResponse{Records: result.Records, Count: result.Count}
+	if result.Paginated {
+		response.Pagination = &paginationResponse{
+			TotalCount: result.TotalCount,
+			HasMore:    result.HasMore,
+			Limit:      result.Limit,
+			Offset:     result.Offset,
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *handler) insert(w http.ResponseWriter, r *http.Request) {
+	table, ok := h.table(w, r)
+	if !ok {
+		return
+	}
+	record, ok := readRecord(w, r, h.store.NullValue(table))
+	if !ok {
+		return
+	}
+
+	inserted, err := h.store.Insert(table, record)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, inserted)
+}
+
+func (h *handler) update(w http.ResponseWriter, r *http.Request) {
+	table, ok := h.table(w, r)
+	if !ok {
+		return
+	}
+	conditions, ok := writeConditions(w, r)
+	if !ok {
+		return
+	}
+	updates, ok := readRecord(w, r, h.store.NullValue(table))
+	if !ok {
+		return
+	}
+
+	result, err := h.store.Update(table, updates, conditions)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, recordsResponse{Records: result.Records, Count: result.Count})
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request) {
+	table, ok := h.table(w, r)
+	if !ok {
+		return
+	}
+	conditions, ok := writeConditions(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.store.Delete(table, conditions)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, recordsResponse{Records: result.Records, Count: result.Count})
+}
+
+// table returns the table of a request, writing a 404 response when it does not exist
+func (h *handler) table(w http.ResponseWriter, r *http.Request) (string, bool) {
+	table := r.PathValue("table")
+	if !h.store.CheckTableExists(table) {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("table %s does not exist", table)})
+		return "", false
+	}
+	return table, true
+}
+
+// queryOptions parses the query parameters of a GET request
+func queryOptions(params url.Values) (csvstore.QueryOptions, error) {
+	conditions, err := parseFilters(params["filter"])
+	if err != nil {
+		return csvstore.QueryOptions{}, err
+	}
+	opts := csvstore.QueryOptions{
+		Conditions: conditions,
+		SortField:  params.Get("sort"),
+		SortBy:     params.Get("order"),
+	}
+	if columns := params.Get("columns"); columns != "" {
+		opts.Columns = strings.Split(columns, ",")
+	}
+	for name, target := range map[string]*int{"limit": &opts.Limit, "offset": &opts.Offset} {
+		value := params.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return csvstore.QueryOptions{}, fmt.Errorf("invalid %s %q", name, value)
+		}
+		*target = n
+	}
+	return opts, nil
+}
+
+// parseFilters parses filter parameters of the form column:operator:value
+func parseFilters(filters []string) ([]csvstore.QueryCondition, error) {
+	conditions := make([]csvstore.QueryCondition, 0, len(filters))
+	for _, filter := range filters {
+		parts := strings.SplitN(filter, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid filter %q, expected column:operator:value", filter)
+		}
+		condition := csvstore.QueryCondition{Column: parts[0], Operator: parts[1]}
+		if len(parts) == 3 {
+			condition.Value = parts[2]
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// writeConditions parses the filters of a PATCH or DELETE request, refusing
+// requests without filters unless all=true is passed
+func writeConditions(w http.ResponseWriter, r *http.Request) ([]csvstore.QueryCondition, bool) {
+	params := r.URL.Query()
+	conditions, err := parseFilters(params["filter"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return nil, false
+	}
+	if len(conditions) == 0 {
+		if params.Get("all") != "true" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{
+				Error: "refusing to change every record without filters; pass all=true to confirm",
+			})
+			return nil, false
+		}
+		// Confirm to stores with csvstore.WithWriteGuard as well
+		conditions = csvstore.AllRows()
+	}
+	return conditions, true
+}
+
+// readRecord decodes the JSON object body of a request into a record. Numbers,
+// booleans, and nulls are accepted as values and stored as text; null is stored
+// as the null sentinel of the table, or as an empty value without one.
+func readRecord(w http.ResponseWriter, r *http.Request, null string) (csvstore.CSVRecord, bool) {
+	var body map[string]any
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid record: %v", err)})
+		return nil, false
+	}
+
+	record := make(csvstore.CSVRecord, len(body))
+	for column, value := range body {
+		switch value := value.(type) {
+		case string:
+			record[column] = value
+		case json.Number:
+			record[column] = value.String()
+		case bool:
+			record[column] = strconv.FormatBool(value)
+		case nil:
+			record[column] = null
+		default:
+			writeJSON(w, http.StatusBadRequest, errorResponse{
+				Error: fmt.Sprintf("invalid record: value of column %s must be a string, number, boolean, or null", column),
+			})
+			return nil, false
+		}
+	}
+	return record, true
+}
+
+// writeError writes the response for an error of the store: 400 for requests
+// the store rejects, and 500 for failures reading or writing its files
+func writeError(w http.ResponseWriter, err error) {
+	var pathErr *fs.PathError
+	var parseErr *csv.ParseError
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		status = http.StatusNotFound
+	case errors.Is(err, csvstore.ErrReadOnly), errors.Is(err, csvstore.ErrAccessDenied):
+		status = http.StatusForbidden
+	case errors.Is(err, csvstore.ErrQuotaExceeded):
+		status = http.StatusInsufficientStorage
+	case errors.Is(err, csvstore.ErrPreconditionFailed):
+		status = http.StatusConflict
+	case errors.Is(err, csvstore.ErrClosed):
+		status = http.StatusServiceUnavailable
+	case errors.As(err, &pathErr), errors.As(err, &parseErr),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, csvstore.ErrRowChecksum):
+		status = http.StatusInternalServerError
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,197 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiyeol-lee/csvstore"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	store := csvstore.NewMemoryStore(csvstore.WithWriteGuard())
+	if err := store.CreateTable("products", []string{"id", "name", "price"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i, name := range []string{"pen", "desk", "lamp", "chair"} {
+		record := csvstore.CSVRecord{"id": fmt.Sprint(i + 1), "name": name, "price": fmt.Sprint((i + 1) * 10)}
+		if _, err := store.Insert("products", record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(NewHandler(store))
+	t.Cleanup(func() {
+		server.Close()
+		store.Close()
+	})
+	return server
+}
+
+func doRequest(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func decodeRecords(t *testing.T, body string) recordsResponse {
+	t.Helper()
+
+	var response recordsResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", body, err)
+	}
+	return response
+}
+
+func TestListTables(t *testing.T) {
+	server := newTestServer(t)
+
+	status, body := doRequest(t, http.MethodGet, server.URL+"/tables", "")
+	if status != http.StatusOK || strings.TrimSpace(body) != `["products"]` {
+		t.Errorf("Expected the table list, got %d %s", status, body)
+	}
+}
+
+func TestQueryRecords(t *testing.T) {
+	server := newTestServer(t)
+
+	status, body := doRequest(t, http.MethodGet,
+		server.URL+"/tables/products/records?filter=price:>:10&sort=price&order=desc&limit=2&offset=1&columns=name", "")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d %s", status, body)
+	}
+	response := decodeRecords(t, body)
+	if response.Count != 2 || response.Records[0]["name"] != "lamp" || response.Records[1]["name"] != "desk" {
+		t.Errorf("Expected lamp and desk, got %v", response.Records)
+	}
+	if _, ok := response.Records[0]["price"]; ok {
+		t.Errorf("Expected only the name column, got %v", response.Records[0])
+	}
+	pagination := response.Pagination
+	if pagination == nil || pagination.TotalCount != 3 || pagination.HasMore || pagination.Limit != 2 || pagination.Offset != 1 {
+		t.Errorf("Expected the last page of 3 records, got %+v", pagination)
+	}
+
+	status, _ = doRequest(t, http.MethodGet, server.URL+"/tables/missing/records", "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing table, got %d", status)
+	}
+	status, _ = doRequest(t, http.MethodGet, server.URL+"/tables/products/records?filter=price", "")
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid filter, got %d", status)
+	}
+	status, _ = doRequest(t, http.MethodGet, server.URL+"/tables/products/records?limit=x", "")
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid limit, got %d", status)
+	}
+}
+
+func TestWriteRecords(t *testing.T) {
+	server := newTestServer(t)
+	records := server.URL + "/tables/products/records"
+
+	status, body := doRequest(t, http.MethodPost, records, `{"id": 5, "name": "shelf", "price": 90}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d %s", status, body)
+	}
+	var inserted csvstore.CSVRecord
+	if err := json.Unmarshal([]byte(body), &inserted); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if inserted["id"] != "5" || inserted["price"] != "90" {
+		t.Errorf("Expected the inserted record, got %v", inserted)
+	}
+
+	status, body = doRequest(t, http.MethodPatch, records+"?filter=name:=:shelf", `{"price": "95"}`)
+	if status != http.StatusOK || decodeRecords(t, body).Count != 1 {
+		t.Errorf("Expected 1 updated record, got %d %s", status, body)
+	}
+
+	status, _ = doRequest(t, http.MethodDelete, records, "")
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unfiltered delete, got %d", status)
+	}
+	status, body = doRequest(t, http.MethodDelete, records+"?filter=price:>=:40", "")
+	if status != http.StatusOK || decodeRecords(t, body).Count != 2 {
+		t.Errorf("Expected 2 deleted records, got %d %s", status, body)
+	}
+	status, body = doRequest(t, http.MethodDelete, records+"?all=true", "")
+	if status != http.StatusOK || decodeRecords(t, body).Count != 3 {
+		t.Errorf("Expected 3 deleted records, got %d %s", status, body)
+	}
+
+	status, _ = doRequest(t, http.MethodPost, records, `{"name": ["a"]}`)
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid record, got %d", status)
+	}
+}
+
+func TestWriteErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{errors.New("column price must be numeric"), http.StatusBadRequest},
+		{fmt.Errorf("failed to open table file: %w", fs.ErrNotExist), http.StatusNotFound},
+		{fmt.Errorf("failed to insert: %w", csvstore.ErrAccessDenied), http.StatusForbidden},
+		{fmt.Errorf("failed to insert: %w", csvstore.ErrQuotaExceeded), http.StatusInsufficientStorage},
+		{&fs.PathError{Op: "write", Path: "products.csv", Err: errors.New("disk full")}, http.StatusInternalServerError},
+		{&csv.ParseError{StartLine: 3, Line: 3, Err: csv.ErrQuote}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		writeError(recorder, tt.err)
+		if recorder.Code != tt.status {
+			t.Errorf("Expected status %d for %v, got %d", tt.status, tt.err, recorder.Code)
+		}
+	}
+}
+
+func TestNullValues(t *testing.T) {
+	store := csvstore.NewMemoryStore()
+	store.ConfigureTable("notes", csvstore.WithNull(`\N`))
+	if err := store.CreateTable("notes", []string{"id", "text"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	server := httptest.NewServer(NewHandler(store))
+	defer server.Close()
+	defer store.Close()
+
+	status, body := doRequest(t, http.MethodPost, server.URL+"/tables/notes/records", `{"id": 1, "text": null}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d %s", status, body)
+	}
+	result, err := store.Query("notes", []csvstore.QueryCondition{{Column: "text", Operator: "is_null"}})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Expected null to be stored as the null sentinel, got %v", result.Records)
+	}
+}
@@ -0,0 +1,75 @@
+package csvstore
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithRotation partitions an insert-heavy table by its creation timestamp
+// column, so that inserts go to a new partition file every day or month
+// according to period, PartitionByDay or PartitionByMonth (see WithPartitioning).
+// When retention is positive, only the partitions of the current period and
+// the retention-1 periods before it are kept: older partitions are dropped
+// when inserts open a new partition, by ApplyRetention, and by the sweeper of
+// StartExpirySweeper. Dropped partitions are removed as whole files, without
+// hooks, history, or change events.
+func WithRotation(period PartitionScheme, retention int) TableOption {
+	return func(c *tableConfig) {
+		c.partitioning = &partitioning{scheme: period, retention: retention}
+	}
+}
+
+// ApplyRetention drops the partitions of a table older than the retention of
+// WithRotation and returns their keys, e.g. "2024-01"
+func (cs *CSVStore) ApplyRetention(tableName string) ([]string, error) {
+	return runOperation(cs, Operation{Name: OpApplyRetention, Table: tableName},
+		func() ([]string, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			p := cs.tableConfig(tableName).partitioning
+			if p == nil || p.retention <= 0 {
+				return nil, fmt.Errorf("no retention configured for table %s", tableName)
+			}
+			return cs.applyRetention(tableName, time.Now())
+		})
+}
+
+// applyRetention drops the partitions of a table older than its retention at
+// now, if any.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) applyRetention(tableName string, now time.Time) ([]string, error) {
+	p := cs.tableConfig(tableName).partitioning
+	if p == nil || p.retention <= 0 || !p.prefixKeys() {
+		return nil, nil
+	}
+	partitions, err := cs.tablePartitions(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	oldest := p.periodKey(now, p.retention-1)
+	var dropped []string
+	for _, partition := range partitions {
+		if partition.key >= oldest {
+			// Partitions are ordered by key
+			break
+		}
+		if err := cs.fs.Remove(partition.file); err != nil {
+			return dropped, fmt.Errorf("failed to remove partition %s of table %s: %w", partition.key, tableName, err)
+		}
+		cs.trackWrite(partitionTable(tableName, partition.key))
+		dropped = append(dropped, partition.key)
+	}
+	return dropped, nil
+}
+
+// periodKey returns the partition key of the period n periods before the one
+// holding t
+func (p *partitioning) periodKey(t time.Time, n int) string {
+	if p.scheme == PartitionByMonth {
+		year, month, _ := t.Date()
+		return time.Date(year, month-time.Month(n), 1, 0, 0, 0, 0, t.Location()).Format("2006-01")
+	}
+	return t.AddDate(0, 0, -n).Format(time.DateOnly)
+}
@@ -0,0 +1,79 @@
+package csvstore
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRotation(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	store.ConfigureTable("logs", WithRotation(PartitionByDay, 2))
+	if err := store.CreateTable("logs", []string{"id", "message", "created_at"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	now := time.Now()
+	for _, age := range []int{5, 3} {
+		createdAt := now.AddDate(0, 0, -age).Format(time.RFC3339Nano)
+		if _, err := store.Insert("logs", CSVRecord{"message": "old", "created_at": createdAt}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := store.Insert("logs", CSVRecord{"message": "yesterday", "created_at": now.AddDate(0, 0, -1).Format(time.RFC3339Nano)}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	// Opening yesterday's partition dropped the older ones
+	result, err := store.Query("logs", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["message"] != "yesterday" {
+		t.Errorf("Expected only yesterday's log to be kept, got %v", result.Records)
+	}
+
+	// Inserts rotate into today's partition
+	if _, err := store.Insert("logs", CSVRecord{"message": "today"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	partitions, err := store.tablePartitions("logs")
+	if err != nil {
+		t.Fatalf("Failed to list partitions: %v", err)
+	}
+	var keys []string
+	for _, partition := range partitions {
+		keys = append(keys, partition.key)
+	}
+	expected := []string{now.AddDate(0, 0, -1).Format(time.DateOnly), now.Format(time.DateOnly)}
+	if !slices.Equal(keys, expected) {
+		t.Errorf("Expected partitions %v, got %v", expected, keys)
+	}
+
+	dropped, err := store.ApplyRetention("logs")
+	if err != nil {
+		t.Fatalf("Failed to apply retention: %v", err)
+	}
+	if len(dropped) != 0 {
+		t.Errorf("Expected no partition to be dropped, got %v", dropped)
+	}
+	if _, err := store.ApplyRetention("missing"); err == nil {
+		t.Error("Expected error applying retention to a table without one")
+	}
+}
+
+func TestPeriodKey(t *testing.T) {
+	at := time.Date(2024, time.March, 31, 12, 0, 0, 0, time.UTC)
+	month := &partitioning{scheme: PartitionByMonth}
+	if key := month.periodKey(at, 1); key != "2024-02" {
+		t.Errorf("Expected 2024-02, got %s", key)
+	}
+	if key := month.periodKey(at, 3); key != "2023-12" {
+		t.Errorf("Expected 2023-12, got %s", key)
+	}
+	day := &partitioning{scheme: PartitionByDay}
+	if key := day.periodKey(at, 31); key != "2024-02-29" {
+		t.Errorf("Expected 2024-02-29, got %s", key)
+	}
+}
@@ -0,0 +1,199 @@
+package csvstore
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// RowChecksumColumn holds the checksum of every row of tables configured with
+// WithRowChecksums
+const RowChecksumColumn = "_checksum"
+
+// ErrRowChecksum is wrapped by the errors of reads finding rows that do not
+// match their checksums
+var ErrRowChecksum = errors.New("row does not match its checksum")
+
+// RowChecksumError lists the rows of a table that do not match their checksums,
+// e.g. after an edit of the table file outside the store. Use errors.As to
+// inspect it.
+type RowChecksumError struct {
+	Table   string
+	Records []CSVRecord
+}
+
+func (e *RowChecksumError) Error() string {
+	return fmt.Sprintf("%d rows of table %s do not match their checksums, the first one holding %v",
+		len(e.Records), e.Table, e.Records[0])
+}
+
+// Unwrap returns ErrRowChecksum
+func (e *RowChecksumError) Unwrap() error {
+	return ErrRowChecksum
+}
+
+// WithRowChecksums maintains a checksum of every row of a table in its
+// RowChecksumColumn, which the table must have, e.g. from CreateTable or
+// AddColumn. Writes set the checksum of the rows they write. Reads verify the
+// rows they scan and fail with a RowChecksumError listing the rows edited or
+// corrupted outside the store, including rows without a checksum, instead of
+// returning them. UpdateRowChecksums accepts the current rows, e.g. after an
+// intentional edit or when enabling checksums on an existing table.
+func WithRowChecksums() TableOption {
+	return func(c *tableConfig) {
+		c.rowChecksums = true
+	}
+}
+
+// UpdateRowChecksums sets the checksum of every row of a table configured with
+// WithRowChecksums to match its current values, and returns the number of rows
+// whose checksum changed
+func (cs *CSVStore) UpdateRowChecksums(tableName string) (int, error) {
+	return runOperation(cs, Operation{Name: OpUpdateRowChecksums, Table: tableName},
+		func() (int, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return cs.updateRowChecksums(tableName)
+		})
+}
+
+// updateRowChecksums rewrites the checksums of the rows of a table.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) updateRowChecksums(tableName string) (int, error) {
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return 0, err
+	}
+	checksum := cs.rowChecksummer(tableName, headers)
+	if checksum == nil {
+		return 0, fmt.Errorf("no row checksums configured for table %s", tableName)
+	}
+
+	records, err := cs.loadTableUnverified(tableName)
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	for _, record := range records {
+		if record[RowChecksumColumn] != checksum(record) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	if err := cs.saveTable(tableName, headers, records); err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// loadTableUnverified loads all records of a table without verifying their
+// checksums.
+// The caller must hold cs.mu.
+func (cs *CSVStore) loadTableUnverified(tableName string) ([]CSVRecord, error) {
+	scanner, err := cs.openTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.close()
+	scanner.unverified = true
+
+	records := make([]CSVRecord, 0)
+	err = cs.scanRecords(tableName, scanner, nil, nil, func(record CSVRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// rowChecksummer returns a function computing the checksum of a row of a table
+// with headers, or nil when the table has no row checksums.
+// The caller must hold cs.mu.
+func (cs *CSVStore) rowChecksummer(tableName string, headers []string) func(record CSVRecord) string {
+	config := cs.tableConfig(tableName)
+	if !config.rowChecksums || !slices.Contains(headers, RowChecksumColumn) {
+		return nil
+	}
+	columns := slices.Sorted(slices.Values(headers))
+	// Hash values as reads return them
+	normalize := config.cellNormalizer()
+	return func(record CSVRecord) string {
+		return rowChecksum(columns, record, normalize)
+	}
+}
+
+// setRowChecksums sets the checksum column of records about to be written to
+// a table with headers, if the table has row checksums.
+// The caller must hold cs.mu.
+func (cs *CSVStore) setRowChecksums(tableName string, headers []string, records []CSVRecord) {
+	checksum := cs.rowChecksummer(tableName, headers)
+	if checksum == nil {
+		return
+	}
+	for _, record := range records {
+		record[RowChecksumColumn] = checksum(record)
+	}
+}
+
+// setRowChecksumCells sets the checksum cell of rows about to be appended to a
+// table, if the table has row checksums.
+// The caller must hold cs.mu.
+func (cs *CSVStore) setRowChecksumCells(tableName string, rows [][]string) error {
+	if !cs.tableConfig(tableName).rowChecksums {
+		return nil
+	}
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return err
+	}
+	checksum := cs.rowChecksummer(tableName, headers)
+	if checksum == nil {
+		return nil
+	}
+	column := slices.Index(headers, RowChecksumColumn)
+	for _, row := range rows {
+		record := make(CSVRecord, len(headers))
+		for i, header := range headers {
+			if i < len(row) {
+				record[header] = row[i]
+			}
+		}
+		if column < len(row) {
+			row[column] = checksum(record)
+		}
+	}
+	return nil
+}
+
+// rowChecksum returns the checksum of the values of a record in columns, in
+// sorted order, other than RowChecksumColumn, so it does not depend on the
+// order of the columns in the table. Values are normalized first when
+// normalize is not nil.
+func rowChecksum(columns []string, record CSVRecord, normalize func(string) string) string {
+	hash := sha256.New()
+	var length [8]byte
+	for _, column := range columns {
+		if column == RowChecksumColumn {
+			continue
+		}
+		value := record[column]
+		if normalize != nil {
+			value = normalize(value)
+		}
+		for _, s := range []string{column, value} {
+			binary.BigEndian.PutUint64(length[:], uint64(len(s)))
+			hash.Write(length[:])
+			hash.Write([]byte(s))
+		}
+	}
+	// 64 bits detect accidental changes; the checksum is not a signature
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
@@ -0,0 +1,81 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRowChecksums(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("accounts", WithRowChecksums())
+	if err := store.CreateTable("accounts", []string{"id", "owner", "balance", RowChecksumColumn}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	inserted, err := store.Insert("accounts", CSVRecord{"id": "1", "owner": "alice", "balance": "100"})
+	if err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if inserted[RowChecksumColumn] == "" {
+		t.Error("Expected the inserted record to hold its checksum")
+	}
+	if _, err := store.Insert("accounts", CSVRecord{"id": "2", "owner": "bob", "balance": "50"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	conditions := []QueryCondition{{Column: "id", Operator: "=", Value: "2"}}
+	if _, err := store.Update("accounts", CSVRecord{"balance": "75"}, conditions); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	result, err := store.Query("accounts", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 2 {
+		t.Fatalf("Expected 2 verified records, got %v", result.Records)
+	}
+
+	// Edit a balance behind the store's back
+	path := filepath.Join(testDir, "accounts.csv")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), ",100,", ",900,", 1)), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+
+	_, err = store.Query("accounts", nil)
+	var checksumErr *RowChecksumError
+	if !errors.As(err, &checksumErr) || !errors.Is(err, ErrRowChecksum) {
+		t.Fatalf("Expected a row checksum error, got %v", err)
+	}
+	if len(checksumErr.Records) != 1 || checksumErr.Records[0]["balance"] != "900" {
+		t.Errorf("Expected the edited row to be reported, got %v", checksumErr.Records)
+	}
+
+	// Accepting the edit makes the row readable again
+	changed, err := store.UpdateRowChecksums("accounts")
+	if err != nil {
+		t.Fatalf("Failed to update row checksums: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("Expected 1 changed checksum, got %d", changed)
+	}
+	result, err = store.Query("accounts", []QueryCondition{{Column: "id", Operator: "=", Value: "1"}})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["balance"] != "900" {
+		t.Errorf("Expected the accepted edit, got %v", result.Records)
+	}
+}
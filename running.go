@@ -0,0 +1,148 @@
+package csvstore
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Running aggregate functions
+const (
+	RunningSum   = "sum"
+	RunningAvg   = "avg"
+	RunningCount = "count"
+	RunningMin   = "min"
+	RunningMax   = "max"
+)
+
+// RunningAggregate computes a running aggregate of a numeric column into an
+// extra column of each record
+type RunningAggregate struct {
+	Function string // One of the Running* functions
+	Column   string // Aggregated column
+	As       string // Name of the computed column
+}
+
+// QueryRunning returns the records matching conditions ordered by sortField,
+// "asc" or "desc" according to sortBy, with running aggregates computed over the
+// records up to and including each one, e.g. cumulative revenue by date.
+// Null cells do not count towards aggregates; other cells that are not numbers
+// fail with ErrNotNumeric. Averages, minimums, and maximums of no values
+// yet are null.
+func (cs *CSVStore) QueryRunning(
+	tableName string,
+	sortField string,
+	sortBy string,
+	conditions []QueryCondition,
+	aggregates []RunningAggregate,
+) (*QueryResult, error) {
+	op := Operation{Name: OpQueryRunning, Table: tableName, Payload: conditions}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		result, err := cs.queryRunning(tableName, sortField, sortBy, conditions, aggregates)
+		if err != nil {
+			return nil, err
+		}
+		return cs.maskResult(tableName, result), nil
+	})
+}
+
+// queryRunning returns sorted records with running aggregates.
+// The caller must hold cs.mu.
+func (cs *CSVStore) queryRunning(
+	tableName string,
+	sortField string,
+	sortBy string,
+	conditions []QueryCondition,
+	aggregates []RunningAggregate,
+) (*QueryResult, error) {
+	if sortBy != "asc" && sortBy != "desc" {
+		return nil, fmt.Errorf("sortBy must be either 'asc' or 'desc', got '%s'", sortBy)
+	}
+	resolve := cs.columnResolver(tableName)
+	sortField = resolve(sortField)
+	conditions = resolveConditions(conditions, resolve)
+	aggregates = slices.Clone(aggregates)
+	for i, aggregate := range aggregates {
+		switch aggregate.Function {
+		case RunningSum, RunningAvg, RunningCount, RunningMin, RunningMax:
+		default:
+			return nil, fmt.Errorf("unknown running aggregate function %q", aggregate.Function)
+		}
+		if aggregate.As == "" {
+			return nil, fmt.Errorf("running %s of column %s needs a column name", aggregate.Function, aggregate.Column)
+		}
+		aggregates[i].Column = resolve(aggregate.Column)
+	}
+
+	onHeaders := func(headers []string) error {
+		columns := []string{sortField}
+		for _, aggregate := range aggregates {
+			columns = append(columns, aggregate.Column)
+		}
+		for _, column := range columns {
+			if !slices.Contains(headers, column) {
+				return fmt.Errorf("column %s does not exist in table %s", column, tableName)
+			}
+		}
+		return nil
+	}
+	records := make([]CSVRecord, 0)
+	err := cs.scanTable(tableName, conditions, onHeaders, func(record CSVRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(records, cs.recordComparer(tableName, sortField, sortBy))
+
+	matcher := cs.newConditionMatcher(tableName)
+	for _, aggregate := range aggregates {
+		var sum, low, high float64
+		count := 0
+		for _, record := range records {
+			value := record[aggregate.Column]
+			if !matcher.isNull(value) {
+				number, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return nil, fmt.Errorf("column %s value %q: %w", aggregate.Column, value, ErrNotNumeric)
+				}
+				if count == 0 || number < low {
+					low = number
+				}
+				if count == 0 || number > high {
+					high = number
+				}
+				sum += number
+				count++
+			}
+
+			var result float64
+			switch aggregate.Function {
+			case RunningSum:
+				result = sum
+			case RunningAvg:
+				result = sum / float64(count)
+			case RunningCount:
+				result = float64(count)
+			case RunningMin:
+				result = low
+			case RunningMax:
+				result = high
+			}
+			if count == 0 && aggregate.Function != RunningCount && aggregate.Function != RunningSum {
+				record[aggregate.As] = matcher.null
+			} else {
+				record[aggregate.As] = strconv.FormatFloat(result, 'f', -1, 64)
+			}
+		}
+	}
+
+	return &QueryResult{
+		Records: records,
+		Count:   len(records),
+	}, nil
+}
@@ -0,0 +1,81 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestQueryRunning(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("sales", []string{"id", "date", "revenue"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	sales := []CSVRecord{
+		{"date": "2024-01-03", "revenue": "30"},
+		{"date": "2024-01-01", "revenue": ""},
+		{"date": "2024-01-02", "revenue": "10"},
+		{"date": "2024-01-04", "revenue": "20"},
+	}
+	for _, sale := range sales {
+		if _, err := store.Insert("sales", sale); err != nil {
+			t.Fatalf("Failed to insert sale: %v", err)
+		}
+	}
+
+	result, err := store.QueryRunning("sales", "date", "asc", nil, []RunningAggregate{
+		{Function: RunningSum, Column: "revenue", As: "cumulative"},
+		{Function: RunningAvg, Column: "revenue", As: "average"},
+		{Function: RunningMax, Column: "revenue", As: "best"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to query running aggregates: %v", err)
+	}
+	expected := []struct{ date, cumulative, average, best string }{
+		{"2024-01-01", "0", "", ""},
+		{"2024-01-02", "10", "10", "10"},
+		{"2024-01-03", "40", "20", "30"},
+		{"2024-01-04", "60", "20", "30"},
+	}
+	if result.Count != len(expected) {
+		t.Fatalf("Expected %d records, got %v", len(expected), result.Records)
+	}
+	for i, e := range expected {
+		record := result.Records[i]
+		if record["date"] != e.date || record["cumulative"] != e.cumulative ||
+			record["average"] != e.average || record["best"] != e.best {
+			t.Errorf("Expected %+v at position %d, got %v", e, i, record)
+		}
+	}
+
+	result, err = store.QueryRunning("sales", "date", "desc", []QueryCondition{
+		{Column: "revenue", Operator: "is_not_null"},
+	}, []RunningAggregate{{Function: RunningCount, Column: "revenue", As: "n"}})
+	if err != nil {
+		t.Fatalf("Failed to query running aggregates: %v", err)
+	}
+	if result.Count != 3 || result.Records[0]["date"] != "2024-01-04" || result.Records[2]["n"] != "3" {
+		t.Errorf("Unexpected running counts: %v", result.Records)
+	}
+
+	_, err = store.QueryRunning("sales", "revenue", "asc", nil, []RunningAggregate{
+		{Function: RunningSum, Column: "date", As: "total"},
+	})
+	if !errors.Is(err, ErrNotNumeric) {
+		t.Errorf("Expected ErrNotNumeric, got %v", err)
+	}
+	_, err = store.QueryRunning("sales", "date", "asc", nil, []RunningAggregate{
+		{Function: "median", Column: "revenue", As: "m"},
+	})
+	if err == nil {
+		t.Errorf("Expected an error for an unknown function")
+	}
+}
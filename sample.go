@@ -0,0 +1,52 @@
+package csvstore
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Sample returns n records chosen uniformly at random among the records matching
+// conditions, or all of them when fewer match. The table is streamed, so only
+// the sample is held in memory.
+func (cs *CSVStore) Sample(tableName string, n int, conditions []QueryCondition) (*QueryResult, error) {
+	op := Operation{Name: OpSample, Table: tableName, Payload: conditions}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		result, err := cs.sample(tableName, n, conditions)
+		if err != nil {
+			return nil, err
+		}
+		return cs.maskResult(tableName, result), nil
+	})
+}
+
+// sample picks n random records matching conditions with reservoir sampling.
+// The caller must hold cs.mu.
+func (cs *CSVStore) sample(tableName string, n int, conditions []QueryCondition) (*QueryResult, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("n (%d) cannot be negative", n)
+	}
+	conditions = resolveConditions(conditions, cs.columnResolver(tableName))
+
+	reservoir := make([]CSVRecord, 0, n)
+	seen := 0
+	err := cs.scanTable(tableName, conditions, nil, func(record CSVRecord) error {
+		seen++
+		if len(reservoir) < n {
+			reservoir = append(reservoir, record)
+		} else if i := rand.IntN(seen); i < n {
+			reservoir[i] = record
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryResult{
+		Records: reservoir,
+		Count:   len(reservoir),
+	}, nil
+}
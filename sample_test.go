@@ -0,0 +1,78 @@
+package csvstore
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("numbers", []string{"id", "n", "parity"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i := range 100 {
+		parity := "even"
+		if i%2 == 1 {
+			parity = "odd"
+		}
+		if _, err := store.Insert("numbers", CSVRecord{"n": strconv.Itoa(i), "parity": parity}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	conditions := []QueryCondition{{Column: "parity", Operator: "=", Value: "odd"}}
+	hits := make(map[string]int)
+	for range 200 {
+		result, err := store.Sample("numbers", 5, conditions)
+		if err != nil {
+			t.Fatalf("Failed to sample: %v", err)
+		}
+		if result.Count != 5 {
+			t.Fatalf("Expected 5 records, got %d", result.Count)
+		}
+		distinct := make(map[string]bool)
+		for _, record := range result.Records {
+			if record["parity"] != "odd" {
+				t.Fatalf("Expected only matching records, got %v", record)
+			}
+			distinct[record["n"]] = true
+			hits[record["n"]]++
+		}
+		if len(distinct) != 5 {
+			t.Fatalf("Expected distinct records, got %v", result.Records)
+		}
+	}
+	// 1000 picks over 50 records; every record is all but certain to come up
+	if len(hits) != 50 {
+		t.Errorf("Expected every matching record to be sampled, got %d", len(hits))
+	}
+
+	result, err := store.Sample("numbers", 500, conditions)
+	if err != nil {
+		t.Fatalf("Failed to sample: %v", err)
+	}
+	if result.Count != 50 {
+		t.Errorf("Expected all 50 matching records, got %d", result.Count)
+	}
+
+	result, err = store.Sample("numbers", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to sample: %v", err)
+	}
+	if result.Count != 0 {
+		t.Errorf("Expected no records, got %d", result.Count)
+	}
+
+	if _, err := store.Sample("numbers", -1, nil); err == nil {
+		t.Error("Expected error for negative n")
+	}
+}
@@ -0,0 +1,280 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// savedQuery is a query registered by SaveQuery
+type savedQuery struct {
+	table      string
+	conditions []QueryCondition
+	// params holds the name of the placeholder giving the value of every
+	// condition, or "" for conditions with a literal value
+	params []string
+}
+
+// SaveQuery registers a named query on a table, to be run by RunQuery with
+// values for its placeholders, so applications keep their query definitions in
+// one place. where holds conditions joined by AND, e.g.
+// "category = :cat AND price > :min". A condition is column operator value,
+// with the operators of QueryCondition written as =, ==, !=, <>, <, <=, >, >=,
+// or the names of the others, case-insensitively; or column IS [NOT] NULL.
+// Values are :placeholders, bare words, or 'quoted strings' with a doubled quote
+// escaping a quote; "double quotes" delimit column names. Saving a query under
+// an existing name replaces it.
+func (cs *CSVStore) SaveQuery(name, tableName, where string) error {
+	query, err := parseSavedQuery(where)
+	if err != nil {
+		return fmt.Errorf("failed to parse query %s: %w", name, err)
+	}
+	query.table = tableName
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.savedQueries == nil {
+		cs.savedQueries = make(map[string]*savedQuery)
+	}
+	cs.savedQueries[name] = query
+	return nil
+}
+
+// RunQuery runs a query registered by SaveQuery like Query, with params giving
+// the values of its placeholders by name, without the colon. Every placeholder
+// needs a value, and every value a placeholder.
+func (cs *CSVStore) RunQuery(name string, params map[string]string) (*QueryResult, error) {
+	cs.mu.RLock()
+	query, ok := cs.savedQueries[name]
+	cs.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("query %s is not saved", name)
+	}
+
+	conditions, err := query.bind(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query %s: %w", name, err)
+	}
+	return cs.Query(query.table, conditions)
+}
+
+// bind returns the conditions of a saved query with the values of params
+func (q *savedQuery) bind(params map[string]string) ([]QueryCondition, error) {
+	conditions := slices.Clone(q.conditions)
+	for i, param := range q.params {
+		if param == "" {
+			continue
+		}
+		value, ok := params[param]
+		if !ok {
+			return nil, fmt.Errorf("missing value for :%s", param)
+		}
+		conditions[i].Value = value
+	}
+	for param := range params {
+		if !slices.Contains(q.params, param) {
+			return nil, fmt.Errorf("unknown parameter %s", param)
+		}
+	}
+	return conditions, nil
+}
+
+// conditionOperators maps the word operators of conditions to store operators
+var conditionOperators = map[string]string{
+	"CONTAINS":    "contains",
+	"STARTS_WITH": "starts_with",
+	"ENDS_WITH":   "ends_with",
+	"HAS_ANY":     "has_any",
+	"HAS_ALL":     "has_all",
+	"HAS_NONE":    "has_none",
+}
+
+// queryToken is a lexical token of conditions
+type queryToken struct {
+	text   string
+	pos    int  // Index of the first rune of the token in the input
+	quote  rune // '\'' or '"' for quoted tokens
+	symbol bool // Comparison operator
+	punct  bool // One of ,;() which ends a bare word
+}
+
+// is reports whether an unquoted token is the keyword s, ignoring case
+func (t queryToken) is(s string) bool {
+	return t.quote == 0 && !t.punct && strings.EqualFold(t.text, s)
+}
+
+// parseSavedQuery parses conditions joined by AND
+func parseSavedQuery(where string) (*savedQuery, error) {
+	if strings.TrimSpace(where) == "" {
+		return nil, errors.New("expected conditions")
+	}
+	conditions, params, rest, err := parseConditions(where)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("expected AND, got %q", rest)
+	}
+	return &savedQuery{conditions: conditions, params: params}, nil
+}
+
+// ParseConditions parses conditions joined by AND, in the syntax of SaveQuery,
+// and returns the input left after the last condition, so that conditions can be
+// embedded in a larger syntax such as a WHERE clause. Placeholders are kept as
+// literal values.
+func ParseConditions(input string) (conditions []QueryCondition, rest string, err error) {
+	conditions, _, rest, err = parseConditions(input)
+	return conditions, rest, err
+}
+
+// parseConditions parses conditions joined by AND, returning with them the
+// placeholder of each, or "" for literal values, and the input left after the
+// last condition
+func parseConditions(input string) ([]QueryCondition, []string, string, error) {
+	runes := []rune(input)
+	tokens, err := tokenizeQuery(runes)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	var conditions []QueryCondition
+	var params []string
+	next := func(expected string) (queryToken, error) {
+		if len(tokens) == 0 {
+			return queryToken{}, fmt.Errorf("expected %s", expected)
+		}
+		t := tokens[0]
+		tokens = tokens[1:]
+		return t, nil
+	}
+	for {
+		column, err := next("a column")
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if column.symbol || column.punct || column.quote == '\'' {
+			return nil, nil, "", fmt.Errorf("expected a column, got %q", column.text)
+		}
+		condition := QueryCondition{Column: column.text}
+		param := ""
+
+		operator, err := next("an operator")
+		if err != nil {
+			return nil, nil, "", err
+		}
+		word := ""
+		if operator.quote == 0 && !operator.punct {
+			word = conditionOperators[strings.ToUpper(operator.text)]
+		}
+		switch {
+		case operator.is("IS"):
+			condition.Operator = "is_null"
+			t, err := next("NULL")
+			if err == nil && t.is("NOT") {
+				condition.Operator = "is_not_null"
+				t, err = next("NULL")
+			}
+			if err != nil {
+				return nil, nil, "", err
+			}
+			if !t.is("NULL") {
+				return nil, nil, "", fmt.Errorf("expected NULL, got %q", t.text)
+			}
+		case operator.symbol || word != "":
+			switch {
+			case word != "":
+				condition.Operator = word
+			case operator.text == "<>":
+				condition.Operator = "!="
+			default:
+				condition.Operator = operator.text
+			}
+			value, err := next("a value")
+			if err != nil {
+				return nil, nil, "", err
+			}
+			if value.symbol || value.punct || value.quote == '"' {
+				return nil, nil, "", fmt.Errorf("expected a value, got %q", value.text)
+			}
+			condition.Value = value.text
+			if name, ok := strings.CutPrefix(value.text, ":"); ok && value.quote == 0 {
+				if name == "" {
+					return nil, nil, "", errors.New("expected a placeholder name after ':'")
+				}
+				param = name
+			}
+		default:
+			return nil, nil, "", fmt.Errorf("unknown operator %q", operator.text)
+		}
+		conditions = append(conditions, condition)
+		params = append(params, param)
+
+		if len(tokens) == 0 {
+			return conditions, params, "", nil
+		}
+		switch t := tokens[0]; {
+		case t.is("AND"):
+			tokens = tokens[1:]
+		case t.is("OR"):
+			return nil, nil, "", errors.New("OR is not supported; conditions are joined by AND")
+		default:
+			return conditions, params, string(runes[t.pos:]), nil
+		}
+	}
+}
+
+// tokenizeQuery splits conditions into tokens
+func tokenizeQuery(runes []rune) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			var text strings.Builder
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == r {
+					if j+1 < len(runes) && runes[j+1] == r {
+						text.WriteRune(r)
+						j++
+						continue
+					}
+					break
+				}
+				text.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("unterminated quote starting at %q", string(runes[i:]))
+			}
+			tokens = append(tokens, queryToken{text: text.String(), pos: i, quote: r})
+			i = j + 1
+		case strings.ContainsRune("<>!=", r):
+			j := i + 1
+			if j < len(runes) && strings.ContainsRune("<>=", runes[j]) {
+				j++
+			}
+			operator := string(runes[i:j])
+			if !slices.Contains([]string{"=", "==", "!=", "<>", "<", "<=", ">", ">="}, operator) {
+				return nil, fmt.Errorf("unknown operator %q", operator)
+			}
+			tokens = append(tokens, queryToken{text: operator, pos: i, symbol: true})
+			i = j
+		case strings.ContainsRune(",;()", r):
+			tokens = append(tokens, queryToken{text: string(r), pos: i, punct: true})
+			i++
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(`<>!='",;()`, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, queryToken{text: string(runes[i:j]), pos: i})
+			i = j
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,90 @@
+package csvstore
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSavedQueries(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.CreateTable("products", []string{"id", "name", "category", "price"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	products := []CSVRecord{
+		{"id": "1", "name": "pen", "category": "office", "price": "2"},
+		{"id": "2", "name": "desk", "category": "office", "price": "250"},
+		{"id": "3", "name": "lamp", "category": "home", "price": "40"},
+		{"id": "4", "name": "chair", "category": "office", "price": ""},
+	}
+	for _, product := range products {
+		if _, err := store.Insert("products", product); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	if err := store.SaveQuery("expensive_in_cat", "products", "category = :cat AND price > :min"); err != nil {
+		t.Fatalf("Failed to save query: %v", err)
+	}
+	result, err := store.RunQuery("expensive_in_cat", map[string]string{"cat": "office", "min": "10"})
+	if err != nil {
+		t.Fatalf("Failed to run query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["name"] != "desk" {
+		t.Errorf("Expected the desk, got %v", result.Records)
+	}
+
+	if err := store.SaveQuery("named", "products", `"name" starts_with 'l''' OR price IS NOT NULL`); err == nil {
+		t.Error("Expected error saving a query with OR")
+	}
+	if err := store.SaveQuery("unpriced", "products", "category<>'home' and price is null"); err != nil {
+		t.Fatalf("Failed to save query: %v", err)
+	}
+	result, err = store.RunQuery("unpriced", nil)
+	if err != nil {
+		t.Fatalf("Failed to run query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["name"] != "chair" {
+		t.Errorf("Expected the chair, got %v", result.Records)
+	}
+
+	if _, err := store.RunQuery("expensive_in_cat", map[string]string{"cat": "office"}); err == nil {
+		t.Error("Expected error running a query with a missing parameter")
+	}
+	if _, err := store.RunQuery("expensive_in_cat", map[string]string{"cat": "office", "min": "1", "max": "9"}); err == nil {
+		t.Error("Expected error running a query with an unknown parameter")
+	}
+	if _, err := store.RunQuery("missing", nil); err == nil {
+		t.Error("Expected error running a query that is not saved")
+	}
+	for _, where := range []string{"", "price >", "price ~ 3", "price = 3 price = 4", "price = :"} {
+		if err := store.SaveQuery("bad", "products", where); err == nil {
+			t.Errorf("Expected error saving query %q", where)
+		}
+	}
+}
+
+func TestParseConditions(t *testing.T) {
+	conditions, rest, err := ParseConditions(`price >= :min and "unit price" <> 'it''s' AND note IS NOT NULL ORDER BY price;`)
+	if err != nil {
+		t.Fatalf("Failed to parse conditions: %v", err)
+	}
+	want := []QueryCondition{
+		{Column: "price", Operator: ">=", Value: ":min"},
+		{Column: "unit price", Operator: "!=", Value: "it's"},
+		{Column: "note", Operator: "is_not_null"},
+	}
+	if !slices.Equal(conditions, want) {
+		t.Errorf("Expected %v, got %v", want, conditions)
+	}
+	if rest != "ORDER BY price;" {
+		t.Errorf("Expected the input after the conditions, got %q", rest)
+	}
+
+	for _, input := range []string{"", "a = 1 OR b = 2", "a = (1)", "a ~ 1", "'a' = 1"} {
+		if _, _, err := ParseConditions(input); err == nil {
+			t.Errorf("Expected error parsing %q", input)
+		}
+	}
+}
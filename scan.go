@@ -0,0 +1,190 @@
+package csvstore
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	scannerType         = reflect.TypeFor[Scanner]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Scanner is implemented by field types that parse their values from cells in
+// ScanRecords
+type Scanner interface {
+	ScanCSV(value string) error
+}
+
+// ScanRecords stores records of a table in dest, a pointer to a slice of
+// structs or of pointers to structs, e.g. the records of a QueryResult. Fields
+// are matched with columns by their csv tag, e.g. `csv:"created_at"`, or else by
+// name ignoring case; fields tagged "-" and unexported fields are skipped.
+// Fields whose pointers implement Scanner or encoding.TextUnmarshaler parse
+// their cells themselves, in that order of preference. Cells of a column with a
+// declared type (see WithColumnTypes) are parsed by its converter when it
+// returns a value assignable to the field. Other cells are converted to the
+// type of the field: strings, integers, floats, booleans, time.Time (RFC 3339),
+// and time.Duration are supported, or pointers to them. Null cells leave
+// fields at their zero value.
+func (cs *CSVStore) ScanRecords(tableName string, records []CSVRecord, dest any) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Pointer || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest must be a pointer to a slice, got %T", dest)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if elemType.Kind() == reflect.Pointer {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a pointer to a slice of structs, got %T", dest)
+	}
+
+	cs.mu.RLock()
+	config := cs.tableConfig(tableName)
+	cs.mu.RUnlock()
+
+	values := reflect.MakeSlice(slice.Type(), len(records), len(records))
+	for i, record := range records {
+		elem := values.Index(i)
+		if elemType.Kind() == reflect.Pointer {
+			elem.Set(reflect.New(structType))
+			elem = elem.Elem()
+		}
+		if err := config.scanRecord(record, elem); err != nil {
+			return fmt.Errorf("failed to scan record %d: %w", i+1, err)
+		}
+	}
+	slice.Set(values)
+	return nil
+}
+
+// scanRecord stores the cells of a record in the fields of a struct
+func (c *tableConfig) scanRecord(record CSVRecord, structValue reflect.Value) error {
+	columns := slices.Collect(maps.Keys(record))
+	structType := structValue.Type()
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		column, _, ok := fieldColumn(field, columns)
+		if !ok {
+			continue
+		}
+		value, exists := record[column]
+		if !exists || value == c.null {
+			continue
+		}
+		if err := c.setField(structValue.Field(i), column, value); err != nil {
+			return fmt.Errorf("failed to scan column %s into field %s: %w", column, field.Name, err)
+		}
+	}
+	return nil
+}
+
+// fieldColumn returns the column of a struct field, given by its csv tag or
+// matched by name among columns, and whether the tag has the omitempty option
+func fieldColumn(field reflect.StructField, columns []string) (column string, omitEmpty bool, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag, options, _ := strings.Cut(field.Tag.Get("csv"), ",")
+	omitEmpty = slices.Contains(strings.Split(options, ","), "omitempty")
+	if tag == "-" {
+		return "", false, false
+	}
+	if tag != "" {
+		return tag, omitEmpty, true
+	}
+	if slices.Contains(columns, field.Name) {
+		return field.Name, omitEmpty, true
+	}
+	for _, column := range columns {
+		if strings.EqualFold(column, field.Name) {
+			return column, omitEmpty, true
+		}
+	}
+	return "", false, false
+}
+
+// setField stores a cell of a column in a struct field
+func (c *tableConfig) setField(field reflect.Value, column, value string) error {
+	if field.Kind() == reflect.Pointer {
+		target := reflect.New(field.Type().Elem())
+		if err := c.setField(target.Elem(), column, value); err != nil {
+			return err
+		}
+		field.Set(target)
+		return nil
+	}
+
+	if field.Addr().Type().Implements(scannerType) {
+		return field.Addr().Interface().(Scanner).ScanCSV(value)
+	}
+	if field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	if converter, ok := LookupType(c.columnTypes[column]); ok {
+		parsed, err := converter.Parse(value)
+		if err != nil {
+			return err
+		}
+		if parsedValue := reflect.ValueOf(parsed); parsedValue.Type().AssignableTo(field.Type()) {
+			field.Set(parsedValue)
+			return nil
+		}
+	}
+	return setFieldFromString(field, value)
+}
+
+// setFieldFromString converts a cell to the type of a struct field. Times are
+// parsed by their UnmarshalText method.
+func setFieldFromString(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
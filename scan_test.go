@@ -0,0 +1,73 @@
+package csvstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScanRecords(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+	store.ConfigureTable("tasks", WithColumnTypes(
+		ColumnDef{Name: "budget", Type: money},
+		ColumnDef{Name: "done", Type: TypeBool},
+	))
+
+	records := []CSVRecord{
+		{
+			"id": "1", "title": "write", "done": "true", "budget": "USD 5.00",
+			"estimate": "90m", "due": "2024-05-01T09:00:00Z", "priority": "2", "notes": "",
+		},
+		{
+			"id": "2", "title": "review", "done": "false", "budget": "",
+			"estimate": "", "due": "", "priority": "", "notes": "urgent",
+		},
+	}
+
+	type task struct {
+		ID       int64
+		Title    string
+		Done     bool
+		Budget   int64 `csv:"budget"`
+		Estimate time.Duration
+		Due      *time.Time
+		Priority uint8
+		Notes    string `csv:"-"`
+		internal string
+	}
+	var tasks []task
+	if err := store.ScanRecords("tasks", records, &tasks); err != nil {
+		t.Fatalf("Failed to scan records: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(tasks))
+	}
+	due := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	first := tasks[0]
+	if first.ID != 1 || first.Title != "write" || !first.Done || first.Budget != 500 ||
+		first.Estimate != 90*time.Minute || first.Due == nil || !first.Due.Equal(due) || first.Priority != 2 {
+		t.Errorf("Unexpected first task: %+v", first)
+	}
+	if second := tasks[1]; second.Due != nil || second.Budget != 0 || second.Notes != "" {
+		t.Errorf("Expected null cells and skipped fields to stay zero, got %+v", second)
+	}
+
+	var pointers []*task
+	if err := store.ScanRecords("tasks", records[:1], &pointers); err != nil {
+		t.Fatalf("Failed to scan records: %v", err)
+	}
+	if len(pointers) != 1 || pointers[0].Title != "write" {
+		t.Errorf("Expected the scanned task, got %v", pointers)
+	}
+
+	if err := store.ScanRecords("tasks", []CSVRecord{{"priority": "300"}}, &tasks); err == nil {
+		t.Error("Expected error scanning an out of range priority")
+	}
+	if err := store.ScanRecords("tasks", records, tasks); err == nil {
+		t.Error("Expected error scanning into a slice that is not a pointer")
+	}
+	var titles []string
+	if err := store.ScanRecords("tasks", records, &titles); err == nil {
+		t.Error("Expected error scanning into a slice of strings")
+	}
+}
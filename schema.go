@@ -0,0 +1,213 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ColumnType is the expected type of the values of a column
+type ColumnType string
+
+// Built-in column types understood by CheckSchema and WithColumnTypes. More
+// types can be added with RegisterType.
+const (
+	TypeString ColumnType = "string"
+	TypeInt    ColumnType = "int"
+	TypeFloat  ColumnType = "float"
+	TypeBool   ColumnType = "bool"
+	TypeTime   ColumnType = "time" // RFC 3339
+)
+
+// valid reports whether a value has the type, by parsing it with the converter
+// of the type (see RegisterType). Values of unknown types are valid.
+func (t ColumnType) valid(value string) bool {
+	converter, ok := LookupType(t)
+	if !ok {
+		return true
+	}
+	_, err := converter.Parse(value)
+	return err == nil
+}
+
+// ErrTypeMismatch is returned when a write stores a value that does not have
+// the declared type of its column (see WithColumnTypes)
+var ErrTypeMismatch = errors.New("value does not have the column type")
+
+// WithColumnTypes declares the types of columns of a table. Insert and Update
+// fail with ErrTypeMismatch on values that do not have the type of their
+// column; null cells (see WithNull) have every type. Columns with an empty Type
+// accept any value. Queries sorted by a typed column order its values by the
+// converter of the type (see RegisterType).
+func WithColumnTypes(columns ...ColumnDef) TableOption {
+	return func(c *tableConfig) {
+		if c.columnTypes == nil {
+			c.columnTypes = make(map[string]ColumnType)
+		}
+		for _, column := range columns {
+			c.columnTypes[column.Name] = column.Type
+		}
+	}
+}
+
+// checkTypes checks the values of a record against the declared column types,
+// for the columns for which changed returns true
+func (c *tableConfig) checkTypes(record CSVRecord, changed func(column string) bool) error {
+	for column, columnType := range c.columnTypes {
+		value, exists := record[column]
+		if !exists || value == c.null || !changed(column) {
+			continue
+		}
+		if !columnType.valid(value) {
+			return fmt.Errorf("column %s value %q is not %s: %w", column, value, columnType, ErrTypeMismatch)
+		}
+	}
+	return nil
+}
+
+// ColumnDef describes a column an application expects. An empty Type accepts any value.
+type ColumnDef struct {
+	Name string
+	Type ColumnType
+}
+
+// CheckSchemaOptions configures CheckSchema
+type CheckSchemaOptions struct {
+	// Repair creates missing tables and appends missing columns, left empty in
+	// existing rows. Other differences are only reported.
+	Repair bool
+}
+
+// TypeMismatch describes a column holding values of an unexpected type
+type TypeMismatch struct {
+	Column  string
+	Type    ColumnType
+	Value   string // First offending value
+	Records int    // Number of offending records
+}
+
+// SchemaDrift describes how a table differs from the expected schema
+type SchemaDrift struct {
+	Table          string
+	MissingTable   bool
+	MissingColumns []string
+	ExtraColumns   []string
+	TypeMismatches []TypeMismatch
+	// Repaired is set when the missing table or columns were added
+	Repaired bool
+}
+
+// CheckSchema compares the tables of the store with the columns an application
+// expects, and returns a drift for every table that differs, ordered by table
+// name. Empty and null cells match every type. With Repair set, the operation
+// is named OpRepairSchema and counts as a write.
+func (cs *CSVStore) CheckSchema(expected map[string][]ColumnDef, opts CheckSchemaOptions) ([]SchemaDrift, error) {
+	op := Operation{Name: OpCheckSchema, Payload: expected}
+	if opts.Repair {
+		op.Name = OpRepairSchema
+	}
+	return runOperation(cs, op, func() ([]SchemaDrift, error) {
+		if opts.Repair {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+		} else {
+			cs.mu.RLock()
+			defer cs.mu.RUnlock()
+		}
+
+		drifts := make([]SchemaDrift, 0)
+		for _, tableName := range slices.Sorted(maps.Keys(expected)) {
+			drift, err := cs.checkTableSchema(tableName, expected[tableName], opts)
+			if err != nil {
+				return nil, err
+			}
+			if drift != nil {
+				drifts = append(drifts, *drift)
+			}
+		}
+		return drifts, nil
+	})
+}
+
+// checkTableSchema compares a table with its expected columns and returns nil
+// when it matches.
+// The caller must hold cs.mu, for writing when opts.Repair is set.
+func (cs *CSVStore) checkTableSchema(
+	tableName string,
+	columns []ColumnDef,
+	opts CheckSchemaOptions,
+) (*SchemaDrift, error) {
+	drift := &SchemaDrift{Table: tableName}
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+
+	if !cs.tableExists(tableName) {
+		drift.MissingTable = true
+		if opts.Repair {
+			if err := cs.createTable(tableName, names); err != nil {
+				return nil, err
+			}
+			drift.Repaired = true
+		}
+		return drift, nil
+	}
+
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if !slices.Contains(headers, name) {
+			drift.MissingColumns = append(drift.MissingColumns, name)
+		}
+	}
+	for _, header := range headers {
+		if !slices.Contains(names, header) {
+			drift.ExtraColumns = append(drift.ExtraColumns, header)
+		}
+	}
+
+	mismatches := make(map[string]*TypeMismatch)
+	matcher := cs.newConditionMatcher(tableName)
+	err = cs.scanTable(tableName, nil, nil, func(record CSVRecord) error {
+		for _, column := range columns {
+			value, exists := record[column.Name]
+			if !exists || matcher.isNull(value) || column.Type.valid(value) {
+				continue
+			}
+			if mismatch, found := mismatches[column.Name]; found {
+				mismatch.Records++
+				continue
+			}
+			mismatches[column.Name] = &TypeMismatch{
+				Column:  column.Name,
+				Type:    column.Type,
+				Value:   value,
+				Records: 1,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range columns {
+		if mismatch, found := mismatches[column.Name]; found {
+			drift.TypeMismatches = append(drift.TypeMismatches, *mismatch)
+		}
+	}
+
+	if len(drift.MissingColumns) == 0 && len(drift.ExtraColumns) == 0 && len(drift.TypeMismatches) == 0 {
+		return nil, nil
+	}
+	if opts.Repair && len(drift.MissingColumns) > 0 {
+		if err := cs.addColumns(tableName, drift.MissingColumns); err != nil {
+			return nil, fmt.Errorf("failed to add columns to table %s: %w", tableName, err)
+		}
+		drift.Repaired = true
+	}
+	return drift, nil
+}
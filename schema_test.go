@@ -0,0 +1,95 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheckSchema(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("orders", []string{"id", "total", "legacy"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, total := range []string{"10.5", "n/a", "", "oops"} {
+		if _, err := store.Insert("orders", CSVRecord{"id": "1", "total": total}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if err := store.CreateTable("users", []string{"id"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	expected := map[string][]ColumnDef{
+		"orders": {{Name: "id", Type: TypeInt}, {Name: "total", Type: TypeFloat}, {Name: "status"}},
+		"users":  {{Name: "id", Type: TypeInt}},
+		"audit":  {{Name: "id"}, {Name: "event"}},
+	}
+	drifts, err := store.CheckSchema(expected, CheckSchemaOptions{})
+	if err != nil {
+		t.Fatalf("Failed to check schema: %v", err)
+	}
+	if len(drifts) != 2 {
+		t.Fatalf("Expected 2 drifts, got %+v", drifts)
+	}
+	if !drifts[0].MissingTable || drifts[0].Table != "audit" || drifts[0].Repaired {
+		t.Errorf("Unexpected audit drift: %+v", drifts[0])
+	}
+	orders := drifts[1]
+	if !slices.Equal(orders.MissingColumns, []string{"status"}) || !slices.Equal(orders.ExtraColumns, []string{"legacy"}) {
+		t.Errorf("Unexpected column drift: %+v", orders)
+	}
+	if len(orders.TypeMismatches) != 1 || orders.TypeMismatches[0].Value != "n/a" || orders.TypeMismatches[0].Records != 2 {
+		t.Errorf("Unexpected type mismatches: %+v", orders.TypeMismatches)
+	}
+
+	drifts, err = store.CheckSchema(expected, CheckSchemaOptions{Repair: true})
+	if err != nil {
+		t.Fatalf("Failed to repair schema: %v", err)
+	}
+	if !drifts[0].Repaired || !drifts[1].Repaired {
+		t.Errorf("Expected drifts to be repaired: %+v", drifts)
+	}
+	if !store.CheckTableExists("audit") {
+		t.Errorf("Expected audit table to be created")
+	}
+	headers, err := store.getHeaders("orders")
+	if err != nil {
+		t.Fatalf("Failed to get headers: %v", err)
+	}
+	if !slices.Equal(headers, []string{"id", "total", "legacy", "status"}) {
+		t.Errorf("Unexpected headers after repair: %v", headers)
+	}
+}
+
+func TestCheckSchemaReadOnly(t *testing.T) {
+	store, err := NewCSVStoreFromFS(fstest.MapFS{
+		"orders.csv": {Data: []byte("id,total\n1,10.5\n")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	expected := map[string][]ColumnDef{"orders": {{Name: "id"}, {Name: "total"}, {Name: "status"}}}
+	drifts, err := store.CheckSchema(expected, CheckSchemaOptions{})
+	if err != nil {
+		t.Fatalf("Failed to check schema: %v", err)
+	}
+	if len(drifts) != 1 || !slices.Equal(drifts[0].MissingColumns, []string{"status"}) {
+		t.Errorf("Expected the missing status column, got %+v", drifts)
+	}
+	if _, err := store.CheckSchema(expected, CheckSchemaOptions{Repair: true}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly repairing a read-only store, got %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package csvstore
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SeedReport describes the result of Seed
+type SeedReport struct {
+	Tables    []string // Tables seeded, in order
+	Inserted  int      // Fixture records added
+	Updated   int      // Existing records changed to match their fixture
+	Unchanged int      // Existing records already matching their fixture
+}
+
+// fixtureDecoders decode the records of fixture files by extension, returning
+// the keys in order of first appearance along with the records
+var fixtureDecoders = map[string]func(r io.Reader) ([]string, []CSVRecord, error){
+	".yaml": decodeYAMLRecords,
+	".yml":  decodeYAMLRecords,
+	".json": decodeJSONRecords,
+	".csv":  decodeCSVRecords,
+}
+
+// Seed loads fixture files from the top directory of fsys into tables, e.g. to
+// initialize a test or demo store in one call. Every file <table>.yaml,
+// <table>.yml, <table>.json, or <table>.csv holds the records of a table: a
+// YAML or JSON list of objects, newline-delimited JSON objects, or CSV with a
+// header row. Files are loaded in order of their names, and other files are
+// ignored. Missing tables are created with the columns of their fixtures.
+// Records are upserted by id, so seeding again leaves the store unchanged:
+// records with a new id are inserted, and existing records are updated where
+// they differ from their fixture. Every record must have an id, and the
+// columns of the fixtures must exist in the tables. Changes go through the
+// hooks, history, and change log of the tables.
+func (cs *CSVStore) Seed(fsys fs.FS) (*SeedReport, error) {
+	return runOperation(cs, Operation{Name: OpSeed}, func() (*SeedReport, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.seed(fsys)
+	})
+}
+
+// SeedDir loads the fixture files of a directory like Seed
+func (cs *CSVStore) SeedDir(dir string) (*SeedReport, error) {
+	return cs.Seed(os.DirFS(dir))
+}
+
+// seed loads the fixture files of fsys into tables.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) seed(fsys fs.FS) (*SeedReport, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	report := &SeedReport{Tables: make([]string, 0)}
+	for _, entry := range entries {
+		extension := path.Ext(entry.Name())
+		decode, ok := fixtureDecoders[extension]
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		tableName := strings.TrimSuffix(entry.Name(), extension)
+
+		file, err := fsys.Open(entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", entry.Name(), err)
+		}
+		keys, records, err := decode(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", entry.Name(), err)
+		}
+
+		if err := cs.seedTable(tableName, keys, records, report); err != nil {
+			return nil, fmt.Errorf("failed to seed table %s: %w", tableName, err)
+		}
+		report.Tables = append(report.Tables, tableName)
+	}
+	return report, nil
+}
+
+// seedTable upserts the fixture records of a table by id, creating the table
+// when it does not exist.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) seedTable(tableName string, keys []string, records []CSVRecord, report *SeedReport) error {
+	if len(records) == 0 {
+		return nil
+	}
+	id := cs.reservedColumns(tableName).ID
+	for i, record := range records {
+		if record[id] == "" {
+			return fmt.Errorf("fixture record %d has no %s", i+1, id)
+		}
+	}
+
+	if !cs.tableExists(tableName) {
+		if err := cs.createTable(tableName, keys); err != nil {
+			return err
+		}
+	}
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if !slices.Contains(headers, key) {
+			return fmt.Errorf("unknown column %s", key)
+		}
+	}
+
+	existing, err := cs.loadTable(tableName)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]CSVRecord, len(existing))
+	for _, record := range existing {
+		byID[record[id]] = record
+	}
+
+	for _, record := range records {
+		current, exists := byID[record[id]]
+		if !exists {
+			inserted, err := cs.insert(tableName, record)
+			if err != nil {
+				return err
+			}
+			byID[record[id]] = inserted
+			report.Inserted++
+			continue
+		}
+
+		changed := false
+		for column, value := range record {
+			if current[column] != value {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			report.Unchanged++
+			continue
+		}
+		conditions := []QueryCondition{{Column: id, Operator: "=", Value: record[id]}}
+		result, err := cs.update(tableName, record, conditions)
+		if err != nil {
+			return err
+		}
+		if result.Count > 0 {
+			byID[record[id]] = result.Records[0]
+		}
+		report.Updated++
+	}
+	return nil
+}
+
+// decodeYAMLRecords decodes a YAML list of mappings. It returns the keys in
+// order of first appearance along with the records. Scalars are stored as
+// written, null as an empty cell, and nested values as compact JSON.
+func decodeYAMLRecords(r io.Reader) ([]string, []CSVRecord, error) {
+	var document yaml.Node
+	err := yaml.NewDecoder(r).Decode(&document)
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode YAML: %w", err)
+	}
+
+	list := &document
+	if list.Kind == yaml.DocumentNode && len(list.Content) > 0 {
+		list = list.Content[0]
+	}
+	if list.Kind != yaml.SequenceNode {
+		return nil, nil, errors.New("failed to decode YAML: expected a list of records")
+	}
+
+	keys := make([]string, 0)
+	records := make([]CSVRecord, 0, len(list.Content))
+	for i, item := range list.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, nil, fmt.Errorf("failed to decode YAML: record %d is not a mapping", i+1)
+		}
+		record := make(CSVRecord, len(item.Content)/2)
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			key := item.Content[j].Value
+			value, err := yamlCell(item.Content[j+1])
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to decode YAML: record %d: %w", i+1, err)
+			}
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+			record[key] = value
+		}
+		records = append(records, record)
+	}
+	return keys, records, nil
+}
+
+// yamlCell converts a YAML value to a cell
+func yamlCell(node *yaml.Node) (string, error) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			return "", nil
+		}
+		return node.Value, nil
+	}
+
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeCSVRecords decodes CSV with a header row. It returns the header along
+// with the records.
+func decodeCSVRecords(r io.Reader) ([]string, []CSVRecord, error) {
+	reader := csv.NewReader(skipBOM(r))
+	headers, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	records := make([]CSVRecord, 0)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		record := make(CSVRecord, len(headers))
+		for i, header := range headers {
+			if i < len(row) {
+				record[header] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return headers, records, nil
+}
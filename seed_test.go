@@ -0,0 +1,119 @@
+package csvstore
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSeed(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.CreateTable("orders", []string{"id", "user_id", "total", "created_at"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	fixtures := fstest.MapFS{
+		"users.yaml": {Data: []byte("- id: 1\n  name: alice\n  tags: [admin, ops]\n" +
+			"- id: 2\n  name: bob\n  tags: ~\n")},
+		"orders.json":  {Data: []byte(`[{"id": "10", "user_id": "1", "total": 9.5}]`)},
+		"products.csv": {Data: []byte("id,name\np1,pen\np2,paper\n")},
+		"README.md":    {Data: []byte("not a fixture")},
+	}
+
+	report, err := store.Seed(fixtures)
+	if err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	if !slices.Equal(report.Tables, []string{"orders", "products", "users"}) {
+		t.Errorf("Expected seeded tables in name order, got %v", report.Tables)
+	}
+	if report.Inserted != 5 || report.Updated != 0 || report.Unchanged != 0 {
+		t.Errorf("Expected 5 inserted records, got %+v", report)
+	}
+
+	headers, err := store.getHeaders("users")
+	if err != nil {
+		t.Fatalf("Failed to get headers: %v", err)
+	}
+	if !slices.Equal(headers, []string{"id", "name", "tags"}) {
+		t.Errorf("Expected the table created with the fixture columns, got %v", headers)
+	}
+	result, err := store.Query("users", []QueryCondition{{Column: "id", Operator: "=", Value: "1"}})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["tags"] != `["admin","ops"]` {
+		t.Errorf("Expected nested values as JSON, got %v", result.Records)
+	}
+	result, err = store.Query("orders", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["total"] != "9.5" || result.Records[0]["created_at"] == "" {
+		t.Errorf("Expected the order inserted into the existing table, got %v", result.Records)
+	}
+
+	// Seeding again changes nothing
+	report, err = store.Seed(fixtures)
+	if err != nil {
+		t.Fatalf("Failed to seed again: %v", err)
+	}
+	if report.Inserted != 0 || report.Updated != 0 || report.Unchanged != 5 {
+		t.Errorf("Expected seeding to be idempotent, got %+v", report)
+	}
+
+	// Changed fixtures update the existing records
+	fixtures["products.csv"] = &fstest.MapFile{Data: []byte("id,name\np1,pencil\np3,ink\n")}
+	report, err = store.Seed(fixtures)
+	if err != nil {
+		t.Fatalf("Failed to seed again: %v", err)
+	}
+	if report.Inserted != 1 || report.Updated != 1 || report.Unchanged != 3 {
+		t.Errorf("Expected 1 inserted and 1 updated record, got %+v", report)
+	}
+	result, err = store.Query("products", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 3 || result.Records[0]["name"] != "pencil" {
+		t.Errorf("Expected the updated products, got %v", result.Records)
+	}
+
+	// Fixture records need an id
+	bad := fstest.MapFS{"users.yaml": {Data: []byte("- name: carol\n")}}
+	if _, err := store.Seed(bad); err == nil {
+		t.Error("Expected error seeding a record without id")
+	}
+}
+
+func TestSeedDir(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	fixturesDir := filepath.Join(testDir, "fixtures")
+	if err := os.MkdirAll(fixturesDir, 0755); err != nil {
+		t.Fatalf("Failed to create fixtures directory: %v", err)
+	}
+	data := []byte("{\"id\": \"1\", \"name\": \"alice\"}\n{\"id\": \"2\", \"name\": \"bob\"}\n")
+	if err := os.WriteFile(filepath.Join(fixturesDir, "users.json"), data, 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	report, err := store.SeedDir(fixturesDir)
+	if err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	if report.Inserted != 2 {
+		t.Errorf("Expected 2 inserted records, got %+v", report)
+	}
+}
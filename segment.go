@@ -0,0 +1,227 @@
+package csvstore
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// segmentPrefix starts the suffix of the names of segment files, as in
+// logs__seg000001.csv
+const segmentPrefix = partitionSeparator + "seg"
+
+var segmentPattern = regexp.MustCompile(`^\d{6,}$`)
+
+// WithSegmentSize rolls a table into numbered segments, logs-style, once its
+// file reaches maxBytes: the rows written so far move to the next segment
+// file, e.g. logs__seg000001.csv, and the table file starts over with the
+// headers. Updates and deletes rewrite the table into segments of at most
+// maxBytes before compression. Reads span all segments in order. Segment
+// files appear in backups but not in ListTables. Segments are not used for
+// tables configured with WithPartitioning or WithRotation.
+func WithSegmentSize(maxBytes int64) TableOption {
+	return func(c *tableConfig) {
+		c.segmentSize = maxBytes
+	}
+}
+
+// segmented reports whether the table is rolled into segments
+func (c *tableConfig) segmented() bool {
+	return c.segmentSize > 0 && c.partitioning == nil
+}
+
+// segmentTable returns the name of the table file of segment n, without its
+// extensions
+func segmentTable(tableName string, n int) string {
+	return fmt.Sprintf("%s%s%06d", tableName, segmentPrefix, n)
+}
+
+// tableSegment is a segment file of a table
+type tableSegment struct {
+	n    int
+	file string
+}
+
+// tableSegments returns the segment files of a segmented table, oldest first,
+// or nil for other tables.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tableSegments(tableName string) ([]tableSegment, error) {
+	if !cs.tableConfig(tableName).segmented() {
+		return nil, nil
+	}
+	entries, err := cs.fs.ReadDir(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var segments []tableSegment
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		fileTable, ok := tableNameFromFile(entry.Name())
+		if !ok {
+			continue
+		}
+		suffix, found := strings.CutPrefix(fileTable, tableName+segmentPrefix)
+		if !found || !segmentPattern.MatchString(suffix) {
+			continue
+		}
+		if n, err := strconv.Atoi(suffix); err == nil {
+			segments = append(segments, tableSegment{n: n, file: entry.Name()})
+		}
+	}
+	slices.SortFunc(segments, func(a, b tableSegment) int { return a.n - b.n })
+	return segments, nil
+}
+
+// isSegmentTable reports whether a table name read from a file name is a
+// segment of a segmented table rather than a table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) isSegmentTable(name string) bool {
+	i := strings.LastIndex(name, segmentPrefix)
+	if i <= 0 || !segmentPattern.MatchString(name[i+len(segmentPrefix):]) {
+		return false
+	}
+	return cs.tableConfig(name[:i]).segmented()
+}
+
+// rotateSegment moves the rows of a segmented table to a new segment when its
+// file reached the segment size, leaving the table file with its leading
+// comments and headers.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) rotateSegment(tableName string) error {
+	config := cs.tableConfig(tableName)
+	file := cs.getTableFile(tableName)
+	state := statFile(cs.fs, file)
+	if !state.exists || state.size < config.segmentSize {
+		return nil
+	}
+	// Headers alone may reach the segment size
+	empty, err := cs.tableFileEmpty(tableName)
+	if err != nil || empty {
+		return err
+	}
+
+	headers, err := cs.getHeaders(tableName)
+	if err != nil {
+		return err
+	}
+	comments, err := cs.leadingComments(tableName)
+	if err != nil {
+		return err
+	}
+	segments, err := cs.tableSegments(tableName)
+	if err != nil {
+		return err
+	}
+	n := 1
+	if len(segments) > 0 {
+		n = segments[len(segments)-1].n + 1
+	}
+
+	_, format, _ := parseTableFileName(file)
+	name := segmentTable(tableName, n)
+	if err := cs.fs.Rename(file, tableFileName(name, format)); err != nil {
+		return fmt.Errorf("failed to rotate table %s: %w", tableName, err)
+	}
+	cs.trackWrite(name)
+	return cs.writeTableFile(tableName, file, comments, [][]string{headers})
+}
+
+// tableFileEmpty reports whether the table file holds no rows besides its
+// headers.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tableFileEmpty(tableName string) (bool, error) {
+	file, err := cs.openTableFile(tableName)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	reader := cs.newTableReader(tableName, file)
+	for range 2 {
+		if _, err := reader.Read(); err != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// saveSegments rewrites the segments of a table with records, in segments of
+// at most the segment size, and returns the records left for the table file.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) saveSegments(tableName string, headers []string, records []CSVRecord) ([]CSVRecord, error) {
+	config := cs.tableConfig(tableName)
+	headerSize, err := cs.encodedSize(tableName, [][]string{headers})
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks [][]CSVRecord
+	start, size := 0, headerSize
+	for i, record := range records {
+		rowSize, err := cs.encodedSize(tableName, [][]string{recordRow(headers, record)})
+		if err != nil {
+			return nil, err
+		}
+		if i > start && size+rowSize > config.segmentSize {
+			chunks = append(chunks, records[start:i])
+			start, size = i, headerSize
+		}
+		size += rowSize
+	}
+
+	existing, err := cs.tableSegments(tableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, segment := range existing {
+		if err := cs.fs.Remove(segment.file); err != nil {
+			return nil, fmt.Errorf("failed to remove segment %d of table %s: %w", segment.n, tableName, err)
+		}
+		cs.trackWrite(segmentTable(tableName, segment.n))
+	}
+
+	format := tableFormat{compression: config.compression, encrypted: config.keyProvider != nil}
+	for i, chunk := range chunks {
+		rows := make([][]string, 0, len(chunk)+1)
+		rows = append(rows, headers)
+		for _, record := range chunk {
+			rows = append(rows, recordRow(headers, record))
+		}
+		name := segmentTable(tableName, i+1)
+		if err := cs.writeTableFile(tableName, tableFileName(name, format), nil, rows); err != nil {
+			return nil, err
+		}
+		cs.trackWrite(name)
+	}
+	return records[start:], nil
+}
+
+// writeTableFile replaces the contents of the file name holding rows of a
+// table with comments and rows.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) writeTableFile(tableName, name string, comments []string, rows [][]string) error {
+	file, err := cs.createTableFileNamed(tableName, name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := cs.newTableWriter(tableName, file)
+	for _, comment := range comments {
+		if err := writer.WriteComment(comment); err != nil {
+			return fmt.Errorf("failed to write comment: %w", err)
+		}
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write table file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package csvstore
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestSegments(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("logs", WithSegmentSize(100))
+	if err := store.CreateTable("logs", []string{"id", "message"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i := range 20 {
+		record := CSVRecord{"id": fmt.Sprint(i), "message": fmt.Sprintf("message number %d", i)}
+		if _, err := store.Insert("logs", record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	segments, err := store.tableSegments("logs")
+	if err != nil {
+		t.Fatalf("Failed to list segments: %v", err)
+	}
+	if len(segments) < 2 || segments[0].file != "logs__seg000001.csv" {
+		t.Fatalf("Expected the table to be rolled into segments, got %v", segments)
+	}
+	for _, segment := range segments {
+		info, err := os.Stat(testDir + "/" + segment.file)
+		if err != nil {
+			t.Fatalf("Failed to stat segment: %v", err)
+		}
+		if info.Size() > 100+30 {
+			t.Errorf("Expected segment %s to stay near the segment size, got %d bytes", segment.file, info.Size())
+		}
+	}
+	tables, err := store.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if !slices.Equal(tables, []string{"logs"}) {
+		t.Errorf("Expected segments to be hidden, got %v", tables)
+	}
+
+	// Reads span the segments in insertion order
+	result, err := store.Query("logs", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 20 {
+		t.Fatalf("Expected 20 records across segments, got %d", result.Count)
+	}
+	for i, record := range result.Records {
+		if record["id"] != fmt.Sprint(i) {
+			t.Errorf("Expected record %d in order, got %v", i, record)
+		}
+	}
+
+	// Updates and deletes rewrite the table into segments
+	if _, err := store.Update("logs", CSVRecord{"message": "redacted message"}, nil); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	segments, err = store.tableSegments("logs")
+	if err != nil {
+		t.Fatalf("Failed to list segments: %v", err)
+	}
+	if len(segments) < 2 {
+		t.Errorf("Expected the updated table to be rewritten into segments, got %v", segments)
+	}
+	result, err = store.Query("logs", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 20 || result.Records[19]["id"] != "19" {
+		t.Errorf("Expected the updated records in order, got %v", result.Records)
+	}
+
+	if _, err := store.Delete("logs", []QueryCondition{{Column: "id", Operator: "<", Value: "18"}}); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	segments, err = store.tableSegments("logs")
+	if err != nil {
+		t.Fatalf("Failed to list segments: %v", err)
+	}
+	if len(segments) != 0 {
+		t.Errorf("Expected the remaining rows to fit in the table file, got %v", segments)
+	}
+	result, err = store.Query("logs", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 2 || result.Records[0]["id"] != "18" {
+		t.Errorf("Expected the last 2 records, got %v", result.Records)
+	}
+}
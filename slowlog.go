@@ -0,0 +1,115 @@
+package csvstore
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SlowOperation describes an operation that ran longer than the slow-log threshold
+type SlowOperation struct {
+	Time      time.Time // When the operation started
+	Operation string
+	Table     string // Empty for store-wide operations
+	Duration  time.Duration
+	// RowsScanned is the number of rows read from table files while the
+	// operation ran. Reads running concurrently are counted too, so it is an
+	// upper bound when the store serves parallel queries.
+	RowsScanned uint64
+	Conditions  []QueryCondition
+	Err         error
+}
+
+// slowLogConfig records the operations slower than a threshold
+type slowLogConfig struct {
+	threshold   time.Duration
+	record      func(SlowOperation)
+	rowsScanned atomic.Uint64
+}
+
+// WithSlowLog calls record with every operation that takes longer than
+// threshold, middleware included, to find the queries worth an index or a
+// smaller table. record runs synchronously after the operation, outside the
+// store lock. See SlowLogWriter to append the slow operations to a file.
+func WithSlowLog(threshold time.Duration, record func(SlowOperation)) Option {
+	return func(cs *CSVStore) {
+		cs.slowLog = &slowLogConfig{threshold: threshold, record: record}
+	}
+}
+
+// SlowLogWriter returns a WithSlowLog callback writing every slow operation to
+// w as a line of JSON. Write errors are ignored.
+func SlowLogWriter(w io.Writer) func(SlowOperation) {
+	var mu sync.Mutex
+	return func(op SlowOperation) {
+		entry := struct {
+			Time        time.Time        `json:"time"`
+			Operation   string           `json:"operation"`
+			Table       string           `json:"table,omitempty"`
+			DurationMS  float64          `json:"duration_ms"`
+			RowsScanned uint64           `json:"rows_scanned"`
+			Conditions  []QueryCondition `json:"conditions,omitempty"`
+			Error       string           `json:"error,omitempty"`
+		}{
+			Time:        op.Time,
+			Operation:   op.Operation,
+			Table:       op.Table,
+			DurationMS:  float64(op.Duration) / float64(time.Millisecond),
+			RowsScanned: op.RowsScanned,
+			Conditions:  op.Conditions,
+		}
+		if op.Err != nil {
+			entry.Error = op.Err.Error()
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write(append(line, '\n'))
+	}
+}
+
+// scanned returns the number of rows scanned so far, or 0 when the slow log is
+// disabled
+func (l *slowLogConfig) scanned() uint64 {
+	if l == nil {
+		return 0
+	}
+	return l.rowsScanned.Load()
+}
+
+// addRowsScanned counts rows read from a table file
+func (l *slowLogConfig) addRowsScanned(rows int) {
+	if l == nil || rows == 0 {
+		return
+	}
+	l.rowsScanned.Add(uint64(rows))
+}
+
+// observe records an operation when it was slow. scannedBefore is the value
+// of scanned when the operation started.
+func (l *slowLogConfig) observe(
+	op *Operation,
+	start time.Time,
+	duration time.Duration,
+	scannedBefore uint64,
+	err error,
+) {
+	if l == nil || duration <= l.threshold {
+		return
+	}
+	l.record(SlowOperation{
+		Time:        start,
+		Operation:   op.Name,
+		Table:       op.Table,
+		Duration:    duration,
+		RowsScanned: l.rowsScanned.Load() - scannedBefore,
+		Conditions:  payloadConditions(op.Payload),
+		Err:         err,
+	})
+}
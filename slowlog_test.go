@@ -0,0 +1,92 @@
+package csvstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowLog(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	var slow []SlowOperation
+	sleepOnQuery := func(next Handler) Handler {
+		return func(op *Operation) (any, error) {
+			if op.Name == OpQuery {
+				time.Sleep(20 * time.Millisecond)
+			}
+			return next(op)
+		}
+	}
+	store, err := NewCSVStore(
+		testDir,
+		WithMiddleware(sleepOnQuery),
+		WithSlowLog(10*time.Millisecond, func(op SlowOperation) { slow = append(slow, op) }),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if _, err := store.Insert("users", CSVRecord{"name": name}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	conditions := []QueryCondition{{Column: "name", Operator: "=", Value: "bob"}}
+	if _, err := store.Query("users", conditions); err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if _, err := store.Query("missing", nil); err == nil {
+		t.Fatal("Expected error querying a missing table")
+	}
+
+	if len(slow) != 2 {
+		t.Fatalf("Expected only the 2 queries to be slow, got %+v", slow)
+	}
+	query := slow[0]
+	if query.Operation != OpQuery || query.Table != "users" || query.Duration < 20*time.Millisecond {
+		t.Errorf("Unexpected slow query: %+v", query)
+	}
+	if query.RowsScanned != 3 || len(query.Conditions) != 1 || query.Err != nil {
+		t.Errorf("Expected 3 rows scanned for 1 condition, got %+v", query)
+	}
+	if slow[1].Table != "missing" || slow[1].Err == nil {
+		t.Errorf("Expected the failed query to be logged with its error, got %+v", slow[1])
+	}
+}
+
+func TestSlowLogWriter(t *testing.T) {
+	var buf bytes.Buffer
+	store := NewMemoryStore(WithSlowLog(0, SlowLogWriter(&buf)))
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Query("users", []QueryCondition{{Column: "name", Operator: "=", Value: "bob"}}); err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %q", buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("Failed to parse log line: %v", err)
+	}
+	if entry["operation"] != OpQuery || entry["table"] != "users" || entry["conditions"] == nil {
+		t.Errorf("Unexpected log line: %s", lines[1])
+	}
+	if _, ok := entry["duration_ms"].(float64); !ok {
+		t.Errorf("Expected a duration in the log line: %s", lines[1])
+	}
+}
@@ -0,0 +1,237 @@
+package csvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// SnapshotsDir is the directory of the store holding the snapshots taken by Snapshot
+const SnapshotsDir = ".snapshots"
+
+// snapshotManifestName is the name of the file of a snapshot describing it
+const snapshotManifestName = "snapshot.json"
+
+// snapshotNamePattern matches valid snapshot names, which are used as directory names
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// SnapshotInfo describes a snapshot taken by Snapshot
+type SnapshotInfo struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []string  `json:"files"` // Names of the table files in the snapshot
+}
+
+// Snapshot saves a consistent copy of the files of every table under
+// .snapshots/<name>, e.g. as a save point before a risky batch job, to be put
+// back with RestoreSnapshot. Files are copied rather than hard-linked, since
+// the store appends to and rewrites table files in place.
+func (cs *CSVStore) Snapshot(name string) (*SnapshotInfo, error) {
+	return runOperation(cs, Operation{Name: OpSnapshot, Payload: name}, func() (*SnapshotInfo, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return cs.snapshot(name)
+	})
+}
+
+// snapshot copies the table files to a new snapshot.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) snapshot(name string) (*SnapshotInfo, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid snapshot name %q", name)
+	}
+	dir := path.Join(SnapshotsDir, name)
+	if cs.fileExists(dir) {
+		return nil, fmt.Errorf("snapshot %s already exists", name)
+	}
+	if !cs.fileExists(SnapshotsDir) {
+		if err := cs.fs.Mkdir(SnapshotsDir); err != nil {
+			return nil, fmt.Errorf("failed to create snapshots directory: %w", err)
+		}
+	}
+
+	// Copy to a staging directory, so a failed snapshot leaves nothing behind
+	staging, err := cs.makeTempDir(path.Join(SnapshotsDir, ".staging-"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer cs.fs.Remove(staging)
+
+	files, err := cs.tableFileNames()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err := cs.copyFile(file, path.Join(staging, file)); err != nil {
+			return nil, fmt.Errorf("failed to copy %s to snapshot %s: %w", file, name, err)
+		}
+	}
+
+	info := &SnapshotInfo{Name: name, CreatedAt: time.Now(), Files: files}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot manifest: %w", err)
+	}
+	if err := cs.writeFile(path.Join(staging, snapshotManifestName), data); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+	if err := cs.fs.Rename(staging, dir); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot %s: %w", name, err)
+	}
+	return info, nil
+}
+
+// ListSnapshots returns the snapshots taken by Snapshot, oldest first
+func (cs *CSVStore) ListSnapshots() ([]SnapshotInfo, error) {
+	return runOperation(cs, Operation{Name: OpListSnapshots}, func() ([]SnapshotInfo, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		entries, err := cs.fs.ReadDir(SnapshotsDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return []SnapshotInfo{}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshots directory: %w", err)
+		}
+
+		snapshots := make([]SnapshotInfo, 0, len(entries))
+		for _, entry := range entries {
+			if !entry.IsDir() || !snapshotNamePattern.MatchString(entry.Name()) {
+				continue
+			}
+			info, err := cs.snapshotInfo(entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			snapshots = append(snapshots, *info)
+		}
+		slices.SortStableFunc(snapshots, func(a, b SnapshotInfo) int { return a.CreatedAt.Compare(b.CreatedAt) })
+		return snapshots, nil
+	})
+}
+
+// snapshotInfo reads the manifest of a snapshot.
+// The caller must hold cs.mu.
+func (cs *CSVStore) snapshotInfo(name string) (*SnapshotInfo, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid snapshot name %q", name)
+	}
+	data, err := cs.readFile(path.Join(SnapshotsDir, name, snapshotManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("snapshot %s does not exist", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", name, err)
+	}
+	var info SnapshotInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", name, err)
+	}
+	info.Name = name
+	return &info, nil
+}
+
+// DeleteSnapshot removes a snapshot taken by Snapshot
+func (cs *CSVStore) DeleteSnapshot(name string) error {
+	_, err := runOperation(cs, Operation{Name: OpDeleteSnapshot, Payload: name}, func() (any, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		if _, err := cs.snapshotInfo(name); err != nil {
+			return nil, err
+		}
+		if err := cs.fs.Remove(path.Join(SnapshotsDir, name)); err != nil {
+			return nil, fmt.Errorf("failed to delete snapshot %s: %w", name, err)
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// RestoreSnapshot puts the tables of the store back as they were when a
+// snapshot was taken: tables are replaced by their copies in the snapshot, and
+// tables created since are removed. The snapshot is kept. Restoring runs no
+// hooks or triggers and records no changes in the change log.
+func (cs *CSVStore) RestoreSnapshot(name string) error {
+	_, err := runOperation(cs, Operation{Name: OpRestoreSnapshot, Payload: name}, func() (any, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		return nil, cs.restoreSnapshot(name)
+	})
+	return err
+}
+
+// restoreSnapshot replaces the table files with those of a snapshot.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) restoreSnapshot(name string) error {
+	info, err := cs.snapshotInfo(name)
+	if err != nil {
+		return err
+	}
+	current, err := cs.tableFileNames()
+	if err != nil {
+		return err
+	}
+	for _, file := range current {
+		if !slices.Contains(info.Files, file) {
+			if err := cs.removeStoreFile(file); err != nil {
+				return err
+			}
+		}
+	}
+	for _, file := range info.Files {
+		if !isPlainFileName(file) {
+			return fmt.Errorf("invalid file name %q in snapshot %s", file, name)
+		}
+		if err := cs.copyFile(path.Join(SnapshotsDir, name, file), file); err != nil {
+			return fmt.Errorf("failed to restore %s from snapshot %s: %w", file, name, err)
+		}
+		if tableName, ok := tableNameFromFile(file); ok {
+			cs.trackWrite(tableName)
+		}
+	}
+	return nil
+}
+
+// tableFileNames returns the names of the table files of the store, including
+// companion tables, partitions, and segments.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tableFileNames() ([]string, error) {
+	names, err := cs.storeFiles()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(names, func(name string) bool {
+		_, ok := tableNameFromFile(name)
+		return !ok
+	}), nil
+}
+
+// copyFile copies the contents of the file src of the store to dst, replacing dst.
+// The caller must hold cs.mu.
+func (cs *CSVStore) copyFile(src, dst string) error {
+	in, err := cs.fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := cs.fs.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,109 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSnapshots(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("orders", []string{"id", "status"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("orders", CSVRecord{"id": "1", "status": "open"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	info, err := store.Snapshot("before-batch")
+	if err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+	if len(info.Files) != 1 || info.Files[0] != "orders.csv" {
+		t.Errorf("Expected the orders table in the snapshot, got %v", info.Files)
+	}
+	if _, err := store.Snapshot("before-batch"); err == nil {
+		t.Error("Expected error taking a snapshot under an existing name")
+	}
+	if _, err := store.Snapshot("../escape"); err == nil {
+		t.Error("Expected error for an invalid snapshot name")
+	}
+
+	// The risky batch job
+	if _, err := store.Update("orders", CSVRecord{"status": "lost"}, nil); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	if err := store.CreateTable("scratch", []string{"id"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	if err := store.RestoreSnapshot("before-batch"); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	result, err := store.Query("orders", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["status"] != "open" {
+		t.Errorf("Expected the orders as they were, got %v", result.Records)
+	}
+	if store.CheckTableExists("scratch") {
+		t.Error("Expected the table created after the snapshot to be removed")
+	}
+	tables, err := store.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Errorf("Expected snapshots to stay out of the tables, got %v", tables)
+	}
+
+	snapshots, err := store.ListSnapshots()
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Name != "before-batch" || snapshots[0].CreatedAt.IsZero() {
+		t.Errorf("Expected the snapshot to be listed, got %v", snapshots)
+	}
+	if err := store.DeleteSnapshot("before-batch"); err != nil {
+		t.Fatalf("Failed to delete snapshot: %v", err)
+	}
+	if err := store.DeleteSnapshot("before-batch"); err == nil {
+		t.Error("Expected error deleting a missing snapshot")
+	}
+	if snapshots, err := store.ListSnapshots(); err != nil || len(snapshots) != 0 {
+		t.Errorf("Expected no snapshots, got %v (%v)", snapshots, err)
+	}
+}
+
+func TestSnapshotsInMemory(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.CreateTable("orders", []string{"id", "status"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Snapshot("empty"); err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+	if _, err := store.Insert("orders", CSVRecord{"id": "1"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if err := store.RestoreSnapshot("empty"); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	result, err := store.Query("orders", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 0 {
+		t.Errorf("Expected the empty table of the snapshot, got %v", result.Records)
+	}
+}
@@ -0,0 +1,274 @@
+package csvstore
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StatsLatencyBuckets are the upper bounds of the operation latency histograms of Stats
+var StatsLatencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// OperationStats counts the runs of an operation on a table
+type OperationStats struct {
+	Operation string
+	Table     string // Empty for store-wide operations
+	Count     uint64
+	Errors    uint64
+	// Duration is the total time spent in the operation, middleware included
+	Duration time.Duration
+	// BucketCounts holds, for every bound of StatsLatencyBuckets, the number of
+	// runs that took at most that long
+	BucketCounts []uint64
+}
+
+// TableStats counts the rows read from and the bytes written to a table file
+type TableStats struct {
+	Table        string
+	RowsScanned  uint64
+	BytesWritten uint64 // As stored, after compression and encryption
+}
+
+// Stats is a snapshot of the statistics of a store opened with WithStats
+type Stats struct {
+	Operations []OperationStats // Ordered by operation and table
+	Tables     []TableStats     // Ordered by table
+	// CacheHits and CacheMisses count the lookups of the caches of the store,
+	// such as the object cache of NewObjectFS and the query cache of
+	// WithQueryCache
+	CacheHits   uint64
+	CacheMisses uint64
+	// LockAcquisitions and LockWait count the acquisitions of the store lock
+	// and the total time spent waiting for it
+	LockAcquisitions uint64
+	LockWait         time.Duration
+}
+
+// WithStats makes the store collect statistics about its operations, read with
+// Stats, e.g. to export them to a monitoring system
+func WithStats() Option {
+	return func(cs *CSVStore) {
+		cs.stats = newStoreStats()
+	}
+}
+
+// Stats returns a snapshot of the statistics of the store, or zero statistics
+// for a store opened without WithStats. Stats itself is not counted as an
+// operation.
+func (cs *CSVStore) Stats() Stats {
+	return cs.stats.snapshot()
+}
+
+// operationKey identifies an operation on a table
+type operationKey struct {
+	operation string
+	table     string
+}
+
+// storeStats collects the statistics of a store. Its methods do nothing on a
+// nil receiver, so call sites need not check whether statistics are enabled.
+type storeStats struct {
+	mu         sync.Mutex
+	operations map[operationKey]*OperationStats
+	tables     map[string]*TableStats
+
+	cacheHits        atomic.Uint64
+	cacheMisses      atomic.Uint64
+	lockAcquisitions atomic.Uint64
+	lockWait         atomic.Int64
+}
+
+// newStoreStats returns empty statistics
+func newStoreStats() *storeStats {
+	return &storeStats{
+		operations: make(map[operationKey]*OperationStats),
+		tables:     make(map[string]*TableStats),
+	}
+}
+
+// recordOperation counts a run of an operation
+func (s *storeStats) recordOperation(op *Operation, duration time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := operationKey{operation: op.Name, table: op.Table}
+	stats, ok := s.operations[key]
+	if !ok {
+		stats = &OperationStats{
+			Operation:    op.Name,
+			Table:        op.Table,
+			BucketCounts: make([]uint64, len(StatsLatencyBuckets)),
+		}
+		s.operations[key] = stats
+	}
+	stats.Count++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.Duration += duration
+	for i, bound := range StatsLatencyBuckets {
+		if duration <= bound {
+			stats.BucketCounts[i]++
+		}
+	}
+}
+
+// table returns the statistics of a table.
+// The caller must hold s.mu.
+func (s *storeStats) table(tableName string) *TableStats {
+	stats, ok := s.tables[tableName]
+	if !ok {
+		stats = &TableStats{Table: tableName}
+		s.tables[tableName] = stats
+	}
+	return stats
+}
+
+// addRowsScanned counts rows read from a table file
+func (s *storeStats) addRowsScanned(tableName string, rows int) {
+	if s == nil || rows == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.table(tableName).RowsScanned += uint64(rows)
+}
+
+// addBytesWritten counts bytes written to a table file
+func (s *storeStats) addBytesWritten(tableName string, n int) {
+	if s == nil || n == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.table(tableName).BytesWritten += uint64(n)
+}
+
+// addCacheLookup counts a cache hit or miss
+func (s *storeStats) addCacheLookup(hit bool) {
+	if s == nil {
+		return
+	}
+	if hit {
+		s.cacheHits.Add(1)
+	} else {
+		s.cacheMisses.Add(1)
+	}
+}
+
+// addLockWait counts an acquisition of the store lock
+func (s *storeStats) addLockWait(wait time.Duration) {
+	if s == nil {
+		return
+	}
+	s.lockAcquisitions.Add(1)
+	s.lockWait.Add(int64(wait))
+}
+
+// snapshot copies the statistics
+func (s *storeStats) snapshot() Stats {
+	if s == nil {
+		return Stats{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := Stats{
+		Operations:       make([]OperationStats, 0, len(s.operations)),
+		Tables:           make([]TableStats, 0, len(s.tables)),
+		CacheHits:        s.cacheHits.Load(),
+		CacheMisses:      s.cacheMisses.Load(),
+		LockAcquisitions: s.lockAcquisitions.Load(),
+		LockWait:         time.Duration(s.lockWait.Load()),
+	}
+	for _, op := range s.operations {
+		op := *op
+		op.BucketCounts = slices.Clone(op.BucketCounts)
+		stats.Operations = append(stats.Operations, op)
+	}
+	slices.SortFunc(stats.Operations, func(a, b OperationStats) int {
+		if c := strings.Compare(a.Operation, b.Operation); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Table, b.Table)
+	})
+	for _, tableName := range slices.Sorted(maps.Keys(s.tables)) {
+		stats.Tables = append(stats.Tables, *s.tables[tableName])
+	}
+	return stats
+}
+
+// countingWriter counts the bytes written to a table file
+type countingWriter struct {
+	io.WriteCloser
+	stats     *storeStats
+	tableName string
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	w.stats.addBytesWritten(w.tableName, n)
+	return n, err
+}
+
+// countWrites wraps a table file opened for writing to count the bytes written
+// to it, when statistics are enabled
+func (s *storeStats) countWrites(tableName string, file io.WriteCloser) io.WriteCloser {
+	if s == nil {
+		return file
+	}
+	return &countingWriter{WriteCloser: file, stats: s, tableName: tableName}
+}
+
+// cacheObserver is implemented by file systems with a cache, so the store can
+// count their hits and misses
+type cacheObserver interface {
+	observeCache(observe func(hit bool))
+}
+
+// storeMutex is the lock of a store, timing the waits for it when statistics
+// are enabled
+type storeMutex struct {
+	sync.RWMutex
+	stats *storeStats
+}
+
+func (m *storeMutex) Lock() {
+	if m.stats == nil {
+		m.RWMutex.Lock()
+		return
+	}
+	start := time.Now()
+	m.RWMutex.Lock()
+	m.stats.addLockWait(time.Since(start))
+}
+
+func (m *storeMutex) RLock() {
+	if m.stats == nil {
+		m.RWMutex.RLock()
+		return
+	}
+	start := time.Now()
+	m.RWMutex.RLock()
+	m.stats.addLockWait(time.Since(start))
+}
@@ -0,0 +1,96 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir, WithStats())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if _, err := store.Insert("users", CSVRecord{"name": name}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := store.Query("users", []QueryCondition{{Column: "name", Operator: "=", Value: "bob"}}); err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if _, err := store.Query("missing", nil); err == nil {
+		t.Fatal("Expected error querying a missing table")
+	}
+
+	stats := store.Stats()
+	operations := make(map[string]OperationStats)
+	for _, op := range stats.Operations {
+		operations[op.Operation+"/"+op.Table] = op
+	}
+	if insert := operations["Insert/users"]; insert.Count != 3 || insert.Errors != 0 || insert.Duration <= 0 {
+		t.Errorf("Unexpected Insert stats: %+v", insert)
+	}
+	if query := operations["Query/missing"]; query.Count != 1 || query.Errors != 1 {
+		t.Errorf("Unexpected Query stats for missing table: %+v", query)
+	}
+	query := operations["Query/users"]
+	if len(query.BucketCounts) != len(StatsLatencyBuckets) || query.BucketCounts[len(query.BucketCounts)-1] != 1 {
+		t.Errorf("Expected the query in the last latency bucket, got %+v", query)
+	}
+
+	if len(stats.Tables) != 1 || stats.Tables[0].Table != "users" {
+		t.Fatalf("Expected stats for table users, got %+v", stats.Tables)
+	}
+	if stats.Tables[0].RowsScanned < 3 {
+		t.Errorf("Expected at least 3 rows scanned, got %d", stats.Tables[0].RowsScanned)
+	}
+	if stats.Tables[0].BytesWritten == 0 {
+		t.Error("Expected bytes written to be counted")
+	}
+	if stats.LockAcquisitions == 0 {
+		t.Error("Expected lock acquisitions to be counted")
+	}
+}
+
+func TestStatsCache(t *testing.T) {
+	storage := newFakeObjectStorage()
+	store, err := NewCSVStore("", WithFS(NewObjectFS(storage, ObjectFSOptions{})), WithStats())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for range 2 {
+		if _, err := store.Query("users", nil); err != nil {
+			t.Fatalf("Failed to query: %v", err)
+		}
+	}
+
+	stats := store.Stats()
+	if stats.CacheHits == 0 {
+		t.Errorf("Expected cache hits, got %+v", stats)
+	}
+}
+
+func TestStatsDisabled(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if stats := store.Stats(); len(stats.Operations) != 0 || len(stats.Tables) != 0 {
+		t.Errorf("Expected no stats without WithStats, got %+v", stats)
+	}
+}
@@ -0,0 +1,34 @@
+package csvstore
+
+import "fmt"
+
+// Store is the table API shared by file-backed and in-memory stores, so code can
+// be tested against NewMemoryStore and run against NewCSVStore
+type Store interface {
+	CheckTableExists(tableName string) bool
+	CreateTable(tableName string, headers []string, opts ...CreateTableOption) error
+	ListTables() ([]string, error)
+	Query(tableName string, conditions []QueryCondition) (*QueryResult, error)
+	QuerySortedRange(tableName string, sortField string, sortBy string, limit int) (*QueryResult, error)
+	Select(tableName string, columns []string, conditions []QueryCondition) (*QueryResult, error)
+	Insert(tableName string, record CSVRecord) (CSVRecord, error)
+	Update(tableName string, updates CSVRecord, conditions []QueryCondition) (*QueryResult, error)
+	Delete(tableName string, conditions []QueryCondition) (*QueryResult, error)
+	Close() error
+}
+
+var _ Store = (*CSVStore)(nil)
+
+// NewMemoryStore creates a store that keeps all tables in memory and never
+// touches the disk, for unit tests and scratch tables. It supports the full
+// API of NewCSVStore; its contents are lost when it is dropped. Like
+// regexp.MustCompile, it panics when the store cannot be created, e.g. when a
+// migration fails; use NewCSVStore with WithFS(NewMemFS()) to handle the error.
+func NewMemoryStore(opts ...Option) *CSVStore {
+	opts = append([]Option{WithFS(NewMemFS())}, opts...)
+	cs, err := NewCSVStore("", opts...)
+	if err != nil {
+		panic(fmt.Sprintf("csvstore: NewMemoryStore: %v", err))
+	}
+	return cs
+}
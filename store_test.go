@@ -0,0 +1,185 @@
+package csvstore
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	var store Store = NewMemoryStore()
+	defer store.Close()
+
+	if err := store.CreateTable("scratch", []string{"id", "value"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if !store.CheckTableExists("scratch") {
+		t.Errorf("Expected table to exist")
+	}
+	for _, value := range []string{"3", "1", "2"} {
+		if _, err := store.Insert("scratch", CSVRecord{"value": value}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := store.Update("scratch", CSVRecord{"value": "10"}, []QueryCondition{
+		{Column: "value", Operator: "=", Value: "1"},
+	}); err != nil {
+		t.Fatalf("Failed to update records: %v", err)
+	}
+
+	sorted, err := store.QuerySortedRange("scratch", "value", "asc", 2)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if sorted.Count != 2 || sorted.Records[0]["value"] != "2" || sorted.Records[1]["value"] != "3" {
+		t.Errorf("Unexpected records: %v", sorted.Records)
+	}
+
+	// Memory stores are independent of each other
+	other := NewMemoryStore()
+	if other.CheckTableExists("scratch") {
+		t.Errorf("Expected a separate memory store to be empty")
+	}
+}
+
+func TestMemoryStoreIsolation(t *testing.T) {
+	first := NewMemoryStore()
+	defer first.Close()
+	second := NewMemoryStore()
+	defer second.Close()
+
+	for _, store := range []Store{first, second} {
+		if err := store.CreateTable("shared", []string{"id", "value"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+	if _, err := first.Insert("shared", CSVRecord{"value": "first"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		store Store
+		want  int
+	}{
+		{"store with the insert", first, 1},
+		{"store without the insert", second, 0},
+	}
+	for _, tt := range tests {
+		result, err := tt.store.Query("shared", nil)
+		if err != nil {
+			t.Fatalf("%s: Failed to query: %v", tt.name, err)
+		}
+		if result.Count != tt.want {
+			t.Errorf("%s: Expected %d records, got %d", tt.name, tt.want, result.Count)
+		}
+	}
+}
+
+func TestMemoryStoreReopen(t *testing.T) {
+	fsys := NewMemFS()
+	store, err := NewCSVStore("", WithFS(fsys))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if err := store.CreateTable("notes", []string{"id", "text"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("notes", CSVRecord{"id": "1", "text": "kept"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+	if _, err := store.Query("notes", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed querying a closed store, got %v", err)
+	}
+
+	// A store reopened over the same file system sees the tables
+	reopened, err := NewCSVStore("", WithFS(fsys))
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+	result, err := reopened.Query("notes", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["text"] != "kept" {
+		t.Errorf("Expected the record written before reopening, got %v", result.Records)
+	}
+
+	// A new memory store starts empty
+	if NewMemoryStore().CheckTableExists("notes") {
+		t.Errorf("Expected a new memory store to be empty")
+	}
+}
+
+func TestMemoryStoreErrors(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+	if err := store.CreateTable("items", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		op   func() error
+	}{
+		{"create an existing table", func() error {
+			return store.CreateTable("items", []string{"id", "name"})
+		}},
+		{"query a missing table", func() error {
+			_, err := store.Query("missing", nil)
+			return err
+		}},
+		{"select from a missing table", func() error {
+			_, err := store.Select("missing", []string{"id"}, nil)
+			return err
+		}},
+		{"insert into a missing table", func() error {
+			_, err := store.Insert("missing", CSVRecord{"id": "1"})
+			return err
+		}},
+		{"update a missing table", func() error {
+			_, err := store.Update("missing", CSVRecord{"name": "x"}, nil)
+			return err
+		}},
+		{"delete from a missing table", func() error {
+			_, err := store.Delete("missing", nil)
+			return err
+		}},
+		{"sort by an invalid order", func() error {
+			_, err := store.QuerySortedRange("items", "name", "sideways", 1)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		if err := tt.op(); err == nil {
+			t.Errorf("%s: Expected an error", tt.name)
+		}
+	}
+}
+
+func TestNewMemoryStoreFailingOption(t *testing.T) {
+	failing := Migration{Version: 1, Up: []SchemaChange{func(*MigrationTx) error {
+		return errors.New("boom")
+	}}}
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{"failing migration", WithMigrations(failing)},
+		{"replication without change log", WithReplication(0, nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected NewMemoryStore to panic")
+				}
+			}()
+			store := NewMemoryStore(tt.opt)
+			store.Close()
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package csvstore
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// tableScanner reads the records of a table one at a time
+type tableScanner struct {
+	file    *tableFile
+	reader  *csv.Reader
+	headers []string
+	// fileHeaders are the headers of the file being read. They differ from
+	// headers in later files of a table spanning several files, such as the
+	// partitions of a partitioned table, written before a schema change.
+	fileHeaders []string
+	// pending lists the files of the table left to read, opened with openFile
+	pending  []string
+	openFile func(name string) (*tableFile, *csv.Reader, error)
+	// normalize is applied to every cell when not nil
+	normalize func(string) string
+	// unverified skips the verification of row checksums
+	unverified bool
+	// tolerant skips unparseable rows, recording them in skipped
+	tolerant bool
+	skipped  []SkippedRow
+}
+
+// openTable opens a table for reading record by record. Headers are nil for an
+// empty table file. The caller must hold cs.mu and close the scanner.
+func (cs *CSVStore) openTable(tableName string) (*tableScanner, error) {
+	return cs.openTableMatching(tableName, nil)
+}
+
+// openTableMatching opens a table for reading record by record, skipping the
+// files of the table that cannot hold records matching conditions, such as
+// the partitions of other months. Segments are read before the table file.
+// Records are not filtered.
+// The caller must hold cs.mu and close the scanner.
+func (cs *CSVStore) openTableMatching(tableName string, conditions []QueryCondition) (*tableScanner, error) {
+	openFile := func(name string) (*tableFile, *csv.Reader, error) {
+		file, err := cs.openTableFileNamed(tableName, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		reader := cs.newTableReader(tableName, file)
+		reader.ReuseRecord = true
+		return file, reader, nil
+	}
+	leading, trailing, err := cs.tableFiles(tableName, conditions)
+	if err != nil {
+		return nil, err
+	}
+	name := cs.getTableFile(tableName)
+	file, reader, err := openFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := &tableScanner{
+		file:      file,
+		reader:    reader,
+		pending:   trailing,
+		openFile:  openFile,
+		normalize: cs.tableConfig(tableName).cellNormalizer(),
+	}
+
+	headers, err := scanner.reader.Read()
+	if err != nil && !errors.Is(err, io.EOF) {
+		file.Close()
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	scanner.headers = append([]string(nil), headers...)
+	scanner.fileHeaders = scanner.headers
+
+	if len(leading) > 0 && scanner.headers != nil {
+		// The headers come from the table file, the rows from the oldest file
+		scanner.pending = slices.Concat(leading, []string{name}, trailing)
+		if err := scanner.nextFile(); err != nil {
+			scanner.close()
+			return nil, err
+		}
+	}
+
+	return scanner, nil
+}
+
+// tableFiles returns the files holding rows of a table besides the table file:
+// its segments, read before it, and its partitions that may hold records
+// matching conditions, read after it.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tableFiles(tableName string, conditions []QueryCondition) ([]string, []string, error) {
+	segments, err := cs.tableSegments(tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var leading []string
+	for _, segment := range segments {
+		leading = append(leading, segment.file)
+	}
+	trailing, err := cs.partitionFiles(tableName, conditions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return leading, trailing, nil
+}
+
+// next returns the next record, or io.EOF after the last one
+func (s *tableScanner) next() (CSVRecord, error) {
+	if s.headers == nil {
+		return nil, io.EOF
+	}
+
+	row, err := s.reader.Read()
+	for {
+		for s.tolerant && err != nil && s.skip(err) {
+			row, err = s.reader.Read()
+		}
+		if !errors.Is(err, io.EOF) || len(s.pending) == 0 {
+			break
+		}
+		if err := s.nextFile(); err != nil {
+			return nil, err
+		}
+		row, err = s.reader.Read()
+	}
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	record := make(CSVRecord, len(s.headers))
+	for i, value := range row {
+		if i >= len(s.fileHeaders) {
+			break
+		}
+		if s.normalize != nil {
+			value = s.normalize(value)
+		}
+		record[s.fileHeaders[i]] = value
+	}
+	// Rows may be short when variable field counts are allowed
+	for _, header := range s.headers {
+		if _, ok := record[header]; !ok {
+			record[header] = ""
+		}
+	}
+	return record, nil
+}
+
+// nextFile closes the file being read and opens the next pending one
+func (s *tableScanner) nextFile() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close table file: %w", err)
+	}
+	name := s.pending[0]
+	s.pending = s.pending[1:]
+
+	file, reader, err := s.openFile(name)
+	if err != nil {
+		// Keep a file to close
+		s.file = &tableFile{}
+		return err
+	}
+	s.file, s.reader = file, reader
+
+	headers, err := reader.Read()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read CSV: %w", err)
+	}
+	s.fileHeaders = append([]string(nil), headers...)
+	return nil
+}
+
+// close closes the underlying table file
+func (s *tableScanner) close() error {
+	return s.file.Close()
+}
+
+// scanTable calls fn with every record of a table matching conditions, after
+// calling onHeaders once with the table's headers. onHeaders may be nil.
+// The caller must hold cs.mu.
+func (cs *CSVStore) scanTable(
+	tableName string,
+	conditions []QueryCondition,
+	onHeaders func(headers []string) error,
+	fn func(record CSVRecord) error,
+) error {
+	scanner, err := cs.openTableMatching(tableName, conditions)
+	if err != nil {
+		return err
+	}
+	defer scanner.close()
+
+	return cs.scanRecords(tableName, scanner, conditions, onHeaders, fn)
+}
+
+// scanRecords calls fn with every record read by scanner matching conditions,
+// after calling onHeaders once with the table's headers. onHeaders may be nil.
+// The caller must hold cs.mu.
+func (cs *CSVStore) scanRecords(
+	tableName string,
+	scanner *tableScanner,
+	conditions []QueryCondition,
+	onHeaders func(headers []string) error,
+	fn func(record CSVRecord) error,
+) error {
+	if onHeaders != nil {
+		if err := onHeaders(scanner.headers); err != nil {
+			return err
+		}
+	}
+	if cs.tableConfig(tableName).caseInsensitiveColumns {
+		conditions = resolveConditions(conditions, headerResolver(scanner.headers))
+	}
+
+	matcher := cs.newConditionMatcher(tableName)
+	if err := matcher.checkValues(conditions); err != nil {
+		return err
+	}
+	var checksum func(CSVRecord) string
+	if !scanner.unverified {
+		checksum = cs.rowChecksummer(tableName, scanner.headers)
+	}
+	var corrupt []CSVRecord
+	scanned := 0
+	defer func() {
+		cs.stats.addRowsScanned(tableName, scanned)
+		cs.slowLog.addRowsScanned(scanned)
+	}()
+	for {
+		record, err := scanner.next()
+		if errors.Is(err, io.EOF) {
+			if len(corrupt) > 0 {
+				return &RowChecksumError{Table: tableName, Records: corrupt}
+			}
+			return matcher.err(tableName)
+		}
+		if err != nil {
+			return err
+		}
+		scanned++
+		if checksum != nil && record[RowChecksumColumn] != checksum(record) {
+			corrupt = append(corrupt, record)
+			continue
+		}
+		if !matcher.matchesConditions(record, conditions) {
+			continue
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// QueryToCSV writes the records of a table matching conditions to w as CSV,
+// headers first. Records are streamed from the table file without loading the
+// whole result, which suits large downloads.
+func (cs *CSVStore) QueryToCSV(tableName string, conditions []QueryCondition, w io.Writer) error {
+	_, err := runOperation(cs, Operation{Name: OpQueryToCSV, Table: tableName, Payload: conditions},
+		func() (any, error) {
+			cs.mu.RLock()
+			defer cs.mu.RUnlock()
+
+			return nil, cs.queryToCSV(tableName, conditions, w)
+		})
+	return err
+}
+
+// queryToCSV streams matching records of a table to w as CSV.
+// The caller must hold cs.mu.
+func (cs *CSVStore) queryToCSV(tableName string, conditions []QueryCondition, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	var headers []string
+	row := make([]string, 0)
+	mask := cs.recordMasker(tableName)
+	err := cs.scanTable(tableName, conditions,
+		func(h []string) error {
+			headers = h
+			return writer.Write(headers)
+		},
+		func(record CSVRecord) error {
+			record = mask(record)
+			row = row[:0]
+			for _, header := range headers {
+				row = append(row, record[header])
+			}
+			return writer.Write(row)
+		})
+	if err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return nil
+}
+
+// QueryToJSON writes the records of a table matching conditions to w as a JSON
+// array of objects, streamed like with QueryToCSV. Use ExportJSON for other layouts.
+func (cs *CSVStore) QueryToJSON(tableName string, conditions []QueryCondition, w io.Writer) error {
+	_, err := runOperation(cs, Operation{Name: OpQueryToJSON, Table: tableName, Payload: conditions},
+		func() (any, error) {
+			cs.mu.RLock()
+			defer cs.mu.RUnlock()
+
+			return nil, cs.exportJSON(tableName, w, conditions, JSONExportOptions{Format: JSONArray})
+		})
+	return err
+}
@@ -0,0 +1,60 @@
+package csvstore
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestQueryToCSVAndJSON(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "products"
+	if err := store.CreateTable(tableName, []string{"name", "price"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, record := range []CSVRecord{
+		{"name": "Laptop", "price": "999.99"},
+		{"name": "Book, Paperback", "price": "19.99"},
+		{"name": "Pen", "price": "1.50"},
+	} {
+		if _, err := store.Insert(tableName, record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	conditions := []QueryCondition{{Column: "price", Operator: ">", Value: "10"}}
+
+	var csvOut strings.Builder
+	if err := store.QueryToCSV(tableName, conditions, &csvOut); err != nil {
+		t.Fatalf("Failed to stream CSV: %v", err)
+	}
+	expected := "name,price\nLaptop,999.99\n\"Book, Paperback\",19.99\n"
+	if csvOut.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s\nexpected:\n%s", csvOut.String(), expected)
+	}
+
+	var jsonOut strings.Builder
+	if err := store.QueryToJSON(tableName, conditions, &jsonOut); err != nil {
+		t.Fatalf("Failed to stream JSON: %v", err)
+	}
+	expected = "[\n" +
+		`  {"name":"Laptop","price":"999.99"},` + "\n" +
+		`  {"name":"Book, Paperback","price":"19.99"}` + "\n" +
+		"]\n"
+	if jsonOut.String() != expected {
+		t.Errorf("Unexpected JSON:\n%s\nexpected:\n%s", jsonOut.String(), expected)
+	}
+
+	var missing strings.Builder
+	if err := store.QueryToCSV("missing", nil, &missing); err == nil {
+		t.Errorf("Expected an error when streaming a missing table")
+	}
+	if missing.Len() != 0 {
+		t.Errorf("Expected nothing to be written for a missing table, got %q", missing.String())
+	}
+}
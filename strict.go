@@ -0,0 +1,120 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrNotNumeric is returned in strict numeric mode when >, <, >=, or <= meets an
+// operand that is not a number, and by numeric aggregates of cells that are not numbers
+var ErrNotNumeric = errors.New("operand is not numeric")
+
+// WithStrictNumeric makes the >, <, >=, and <= operators compare numbers only.
+// A condition value that is not a number fails the operation, and so do cells
+// that are not numbers, with an *EvaluationError listing every such row, instead
+// of silently comparing them as strings. Null cells (see WithNull) never match
+// and are not failures.
+func WithStrictNumeric() TableOption {
+	return func(c *tableConfig) {
+		c.strictNumeric = true
+	}
+}
+
+// EvaluationFailure describes a record on which a condition could not be evaluated
+type EvaluationFailure struct {
+	Record    CSVRecord
+	Condition QueryCondition
+	Err       error
+}
+
+// EvaluationError lists the records of a table on which conditions could not be
+// evaluated. Use errors.As to inspect it.
+type EvaluationError struct {
+	Table    string
+	Failures []EvaluationFailure
+}
+
+func (e *EvaluationError) Error() string {
+	first := e.Failures[0]
+	return fmt.Sprintf("failed to evaluate conditions on %d records of table %s: column %s value %q: %v",
+		len(e.Failures), e.Table, first.Condition.Column, first.Record[first.Condition.Column], first.Err)
+}
+
+// Unwrap returns the errors of the failures
+func (e *EvaluationError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// isOrderingOperator reports whether an operator compares values by order
+func isOrderingOperator(operator string) bool {
+	switch operator {
+	case ">", "<", ">=", "<=":
+		return true
+	default:
+		return false
+	}
+}
+
+// order compares a cell with the value of an ordering condition. In strict
+// numeric mode it reports false and records a failure when the cell is not a number.
+func (m *conditionMatcher) order(record CSVRecord, condition QueryCondition, value string) (int, bool) {
+	if !m.strictNumeric {
+		return m.compare(value, condition.Value), true
+	}
+
+	numValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		m.failures = append(m.failures, EvaluationFailure{
+			Record:    record,
+			Condition: condition,
+			Err:       ErrNotNumeric,
+		})
+		return 0, false
+	}
+	numCondition, err := strconv.ParseFloat(condition.Value, 64)
+	if err != nil {
+		// Reported by checkValues
+		return 0, false
+	}
+	return compareFloats(numValue, numCondition), true
+}
+
+// checkValues checks the values of ordering conditions in strict numeric mode
+func (m *conditionMatcher) checkValues(conditions []QueryCondition) error {
+	if !m.strictNumeric {
+		return nil
+	}
+	for _, condition := range conditions {
+		if !isOrderingOperator(condition.Operator) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(condition.Value, 64); err != nil {
+			return fmt.Errorf("condition %s %s %q: %w",
+				condition.Column, condition.Operator, condition.Value, ErrNotNumeric)
+		}
+	}
+	return nil
+}
+
+// err returns the failures recorded while matching records of a table, if any
+func (m *conditionMatcher) err(tableName string) error {
+	if len(m.failures) == 0 {
+		return nil
+	}
+	return &EvaluationError{Table: tableName, Failures: m.failures}
+}
+
+// checkConditions evaluates conditions on every record of a table in strict
+// numeric mode, so write operations fail before changing anything.
+// The caller must hold cs.mu.
+func (cs *CSVStore) checkConditions(tableName string, conditions []QueryCondition) error {
+	if !cs.tableConfig(tableName).strictNumeric {
+		return nil
+	}
+	return cs.scanTable(tableName, conditions, nil, func(CSVRecord) error { return nil })
+}
@@ -0,0 +1,67 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestStrictNumeric(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("prices", []string{"id", "price"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, price := range []string{"100", "abc", "5", "n/a"} {
+		if _, err := store.Insert("prices", CSVRecord{"price": price}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	// Without strict mode, "abc" and "n/a" compare as strings
+	loose, err := store.Query("prices", []QueryCondition{{Column: "price", Operator: ">", Value: "100"}})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if loose.Count != 2 {
+		t.Errorf("Expected 2 loose matches, got %v", loose.Records)
+	}
+
+	store.ConfigureTable("prices", WithStrictNumeric())
+
+	_, err = store.Query("prices", []QueryCondition{{Column: "price", Operator: ">", Value: "ten"}})
+	if !errors.Is(err, ErrNotNumeric) {
+		t.Errorf("Expected ErrNotNumeric for a non-numeric value, got %v", err)
+	}
+
+	_, err = store.Query("prices", []QueryCondition{{Column: "price", Operator: ">", Value: "10"}})
+	var evalErr *EvaluationError
+	if !errors.As(err, &evalErr) {
+		t.Fatalf("Expected an EvaluationError, got %v", err)
+	}
+	if len(evalErr.Failures) != 2 || evalErr.Failures[0].Record["price"] != "abc" {
+		t.Errorf("Unexpected failures: %v", evalErr.Failures)
+	}
+	if !errors.Is(err, ErrNotNumeric) {
+		t.Errorf("Expected the EvaluationError to wrap ErrNotNumeric")
+	}
+
+	// Writes fail before changing anything
+	if _, err := store.Delete("prices", []QueryCondition{{Column: "price", Operator: "<", Value: "50"}}); err == nil {
+		t.Errorf("Expected delete to fail")
+	}
+	result, err := store.Query("prices", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 4 {
+		t.Errorf("Expected 4 records after the failed delete, got %d", result.Count)
+	}
+}
@@ -0,0 +1,91 @@
+package csvstore
+
+import "sync/atomic"
+
+// subscriptionBufferSize is the number of change events buffered per subscriber
+const subscriptionBufferSize = 256
+
+// subscription is a registered change event listener
+type subscription struct {
+	tableName string
+	events    chan ChangeEvent
+	dropped   atomic.Uint64 // Events dropped because the buffer was full
+}
+
+// Subscribe returns a channel receiving a ChangeEvent for every record inserted, updated,
+// or deleted in tableName, or in any table if tableName is empty, together with a function
+// that cancels the subscription and closes the channel. Closing the store closes
+// the channels of all subscriptions.
+// Events are delivered in order. The channel is buffered; events are dropped for a
+// subscriber that falls more than its buffer size behind, so consumers that cannot
+// tolerate gaps should check DroppedEvents and reload when it grows. Sequence
+// numbers are shared by all tables, so they have gaps for subscriptions to one
+// table even when nothing was dropped.
+func (cs *CSVStore) Subscribe(tableName string) (<-chan ChangeEvent, func()) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	sub := &subscription{
+		tableName: tableName,
+		events:    make(chan ChangeEvent, subscriptionBufferSize),
+	}
+	if cs.closed.Load() {
+		close(sub.events)
+		return sub.events, func() {}
+	}
+	cs.subscribers = append(cs.subscribers, sub)
+
+	cancelled := false
+	cancel := func() {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		if cancelled {
+			return
+		}
+		cancelled = true
+
+		for i, s := range cs.subscribers {
+			if s == sub {
+				cs.subscribers = append(cs.subscribers[:i], cs.subscribers[i+1:]...)
+				close(sub.events)
+				break
+			}
+		}
+	}
+
+	return sub.events, cancel
+}
+
+// DroppedEvents returns the number of events dropped for the subscription
+// receiving on events because it fell behind, or 0 for channels not returned by
+// Subscribe or whose subscription was cancelled
+func (cs *CSVStore) DroppedEvents(events <-chan ChangeEvent) uint64 {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	for _, sub := range cs.subscribers {
+		if sub.events == events {
+			return sub.dropped.Load()
+		}
+	}
+	return 0
+}
+
+// notifySubscribers delivers events to matching subscribers without blocking.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) notifySubscribers(events []ChangeEvent) {
+	for _, sub := range cs.subscribers {
+		for _, event := range events {
+			if sub.tableName != "" && sub.tableName != event.Table {
+				continue
+			}
+			select {
+			case sub.events <- event:
+			default:
+				// Subscriber is not keeping up, drop the event
+				sub.dropped.Add(1)
+			}
+		}
+	}
+}
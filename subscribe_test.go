@@ -0,0 +1,95 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	for _, tableName := range []string{"users", "orders"} {
+		if err := store.CreateTable(tableName, []string{"id", "name"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+
+	userEvents, cancelUsers := store.Subscribe("users")
+	allEvents, cancelAll := store.Subscribe("")
+	defer cancelAll()
+
+	if _, err := store.Insert("users", CSVRecord{"id": "1", "name": "John"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Insert("orders", CSVRecord{"id": "1", "name": "Order"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	conditions := []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}
+	if _, err := store.Update("users", CSVRecord{"name": "Johnny"}, conditions); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if _, err := store.Delete("users", conditions); err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+
+	expectedOps := []string{ChangeInsert, ChangeUpdate, ChangeDelete}
+	for i, op := range expectedOps {
+		event := <-userEvents
+		if event.Operation != op || event.Table != "users" {
+			t.Errorf("Event %d: expected %s on users, got %s on %s", i, op, event.Operation, event.Table)
+		}
+	}
+	if len(allEvents) != 4 {
+		t.Errorf("Expected 4 events for the all-tables subscriber, got %d", len(allEvents))
+	}
+
+	// Cancelling closes the channel and stops delivery
+	cancelUsers()
+	cancelUsers()
+	if _, err := store.Insert("users", CSVRecord{"id": "2", "name": "Jane"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, ok := <-userEvents; ok {
+		t.Error("Expected channel to be closed after cancel")
+	}
+}
+
+func TestSubscribeDroppedEvents(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := store.CreateTable("orders", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	userEvents, cancel := store.Subscribe("users")
+	defer cancel()
+
+	// Events for other tables leave sequence gaps but are not drops
+	if _, err := store.Insert("orders", CSVRecord{"id": "1", "name": "Order"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	for i := 0; i < subscriptionBufferSize+3; i++ {
+		if _, err := store.Insert("users", CSVRecord{"name": "John"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	if dropped := store.DroppedEvents(userEvents); dropped != 3 {
+		t.Errorf("Expected 3 dropped events, got %d", dropped)
+	}
+	if len(userEvents) != subscriptionBufferSize {
+		t.Errorf("Expected %d buffered events, got %d", subscriptionBufferSize, len(userEvents))
+	}
+
+	cancel()
+	if dropped := store.DroppedEvents(userEvents); dropped != 0 {
+		t.Errorf("Expected 0 dropped events after cancel, got %d", dropped)
+	}
+}
@@ -0,0 +1,417 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ConflictPolicy selects which version of a row Sync keeps when the two stores
+// hold different versions
+type ConflictPolicy int
+
+const (
+	// SyncNewestWins keeps the most recently updated version, by the updated_at
+	// column, or the created_at column for rows never updated; the local version
+	// wins ties
+	SyncNewestWins ConflictPolicy = iota
+	// SyncLocalWins keeps the version of the store Sync is called on
+	SyncLocalWins
+	// SyncRemoteWins keeps the version of the other store
+	SyncRemoteWins
+)
+
+// SyncOptions controls Sync
+type SyncOptions struct {
+	// Tables lists the tables to sync. When empty, every table of either store
+	// is synced except history, tombstone, and migration tables.
+	Tables    []string
+	Conflicts ConflictPolicy
+}
+
+// SyncConflict is a row whose versions differed between the two stores
+type SyncConflict struct {
+	Table  string
+	Local  CSVRecord
+	Remote CSVRecord
+	Winner string // "local" or "remote"
+}
+
+// SyncSchemaMismatch is a table whose columns differ between the two stores.
+// Only the columns of both are compared and copied.
+type SyncSchemaMismatch struct {
+	Table      string
+	LocalOnly  []string
+	RemoteOnly []string
+}
+
+// SyncReport describes the result of Sync
+type SyncReport struct {
+	Pushed           int // Rows copied to the other store
+	Pulled           int // Rows copied from the other store
+	PushedDeletes    int // Rows deleted from the other store
+	PulledDeletes    int // Rows deleted from this store
+	Conflicts        []SyncConflict
+	Skipped          []string // Tables without an id column, which cannot be synced
+	SchemaMismatches []SyncSchemaMismatch
+}
+
+// add adds the results of another report to the report
+func (r *SyncReport) add(other *SyncReport) {
+	r.Pushed += other.Pushed
+	r.Pulled += other.Pulled
+	r.PushedDeletes += other.PushedDeletes
+	r.PulledDeletes += other.PulledDeletes
+	r.Conflicts = append(r.Conflicts, other.Conflicts...)
+	r.Skipped = append(r.Skipped, other.Skipped...)
+	r.SchemaMismatches = append(r.SchemaMismatches, other.SchemaMismatches...)
+}
+
+// SyncError reports a Sync that failed part way. The tables are synced one at
+// a time and each store is written separately, so the stores may hold part of
+// the changes of Table: LocalApplied tells whether the changes to the store
+// Sync was called on had been written. Report holds the results of the tables
+// synced completely before Table.
+type SyncError struct {
+	Table        string
+	LocalApplied bool
+	Report       *SyncReport
+	Err          error
+}
+
+func (e *SyncError) Error() string {
+	if e.LocalApplied {
+		return fmt.Sprintf("failed to sync table %s after writing its changes to the local store: %v", e.Table, e.Err)
+	}
+	return fmt.Sprintf("failed to sync table %s: %v", e.Table, e.Err)
+}
+
+func (e *SyncError) Unwrap() error {
+	return e.Err
+}
+
+// syncMu serializes Sync calls, which lock two stores
+var syncMu sync.Mutex
+
+// Sync reconciles tables between the store and other in both directions, e.g. a
+// laptop-local store and a shared copy on the network. Rows are matched by id.
+// A row missing from one store is copied to it, unless that store holds a
+// tombstone for the row at least as recent as the row's last update, in which
+// case the deletion is applied to the other store instead; deleted rows are only
+// recognized with WithTombstones. Rows that differ are resolved according to
+// opts.Conflicts. Tombstones are exchanged as well. Changes are written as they
+// are, keeping their timestamps, and reach the change logs and subscribers of
+// the stores, but do not run hooks or triggers. When the columns of a table
+// differ between the stores, only the columns of both are compared and copied,
+// and the table is listed in the report's SchemaMismatches.
+// Sync is not atomic: when it fails part way, it returns a *SyncError telling
+// which changes were written.
+func (cs *CSVStore) Sync(other *CSVStore, opts SyncOptions) (*SyncReport, error) {
+	return runOperation(cs, Operation{Name: OpSync, Payload: opts}, func() (*SyncReport, error) {
+		if other == cs {
+			return nil, errors.New("cannot sync a store with itself")
+		}
+		if other.readOnly {
+			return nil, ErrReadOnly
+		}
+
+		syncMu.Lock()
+		defer syncMu.Unlock()
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+		other.mu.Lock()
+		defer other.mu.Unlock()
+
+		if other.closed.Load() {
+			return nil, ErrClosed
+		}
+		return cs.sync(other, opts)
+	})
+}
+
+// sync reconciles tables between the store and other.
+// The caller must hold cs.mu and other.mu for writing.
+func (cs *CSVStore) sync(other *CSVStore, opts SyncOptions) (*SyncReport, error) {
+	tables := opts.Tables
+	if len(tables) == 0 {
+		local, err := cs.listTables()
+		if err != nil {
+			return nil, err
+		}
+		remote, err := other.listTables()
+		if err != nil {
+			return nil, err
+		}
+		for _, tableName := range slices.Concat(local, remote) {
+			if isHistoryTable(tableName) || isTombstoneTable(tableName) || tableName == MigrationsTableName {
+				continue
+			}
+			if !slices.Contains(tables, tableName) {
+				tables = append(tables, tableName)
+			}
+		}
+		slices.Sort(tables)
+	}
+
+	report := newSyncReport()
+	for _, tableName := range tables {
+		tableReport := newSyncReport()
+		localApplied, err := cs.syncTable(other, tableName, opts.Conflicts, tableReport)
+		if err != nil {
+			return nil, &SyncError{Table: tableName, LocalApplied: localApplied, Report: report, Err: err}
+		}
+		report.add(tableReport)
+	}
+	return report, nil
+}
+
+// newSyncReport returns an empty report
+func newSyncReport() *SyncReport {
+	return &SyncReport{Conflicts: []SyncConflict{}, Skipped: []string{}, SchemaMismatches: []SyncSchemaMismatch{}}
+}
+
+// syncSide holds the state of a table in one of the stores being synced
+type syncSide struct {
+	cs         *CSVStore
+	headers    []string
+	records    []CSVRecord
+	byID       map[string]CSVRecord
+	tombstones map[string]time.Time
+
+	upserts       map[string]CSVRecord // Rows to insert or replace, by id
+	deletes       map[string]bool      // Ids of rows to delete
+	newTombstones map[string]string    // Tombstones to add, by id
+}
+
+// loadSyncSide reads a table for syncing, creating it with headers when it does not exist.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) loadSyncSide(tableName string, headers []string) (*syncSide, error) {
+	if !cs.tableExists(tableName) {
+		if err := cs.saveTable(tableName, headers, nil); err != nil {
+			return nil, err
+		}
+		event := ChangeEvent{Operation: ChangeCreateTable, Table: tableName, Headers: headers}
+		if err := cs.emitEvents([]ChangeEvent{event}); err != nil {
+			return nil, err
+		}
+	}
+
+	side := &syncSide{
+		cs:            cs,
+		byID:          make(map[string]CSVRecord),
+		upserts:       make(map[string]CSVRecord),
+		deletes:       make(map[string]bool),
+		newTombstones: make(map[string]string),
+	}
+	idColumn := cs.reservedColumns(tableName).ID
+	err := cs.scanTable(tableName, nil, func(h []string) error {
+		side.headers = h
+		return nil
+	}, func(record CSVRecord) error {
+		side.records = append(side.records, record)
+		side.byID[record[idColumn]] = record
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	side.tombstones, err = cs.tombstones(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return side, nil
+}
+
+// syncTable reconciles a table between the store and other, reporting whether
+// the changes to the store were written.
+// The caller must hold cs.mu and other.mu for writing.
+func (cs *CSVStore) syncTable(
+	other *CSVStore,
+	tableName string,
+	policy ConflictPolicy,
+	report *SyncReport,
+) (bool, error) {
+	var headers []string
+	var err error
+	if cs.tableExists(tableName) {
+		headers, err = cs.getHeaders(tableName)
+	} else {
+		headers, err = other.getHeaders(tableName)
+	}
+	if err != nil {
+		return false, err
+	}
+	idColumn := cs.reservedColumns(tableName).ID
+	if !slices.Contains(headers, idColumn) || other.reservedColumns(tableName).ID != idColumn {
+		report.Skipped = append(report.Skipped, tableName)
+		return false, nil
+	}
+
+	local, err := cs.loadSyncSide(tableName, headers)
+	if err != nil {
+		return false, err
+	}
+	remote, err := other.loadSyncSide(tableName, local.headers)
+	if err != nil {
+		return false, err
+	}
+	if !slices.Contains(remote.headers, idColumn) {
+		report.Skipped = append(report.Skipped, tableName)
+		return false, nil
+	}
+
+	localOnly := missingColumns(local.headers, remote.headers)
+	remoteOnly := missingColumns(remote.headers, local.headers)
+	shared := slices.DeleteFunc(slices.Clone(local.headers), func(header string) bool {
+		return slices.Contains(localOnly, header)
+	})
+	if len(localOnly) > 0 || len(remoteOnly) > 0 {
+		report.SchemaMismatches = append(report.SchemaMismatches, SyncSchemaMismatch{
+			Table:      tableName,
+			LocalOnly:  localOnly,
+			RemoteOnly: remoteOnly,
+		})
+	}
+
+	reserved := cs.reservedColumns(tableName)
+	ids := slices.Sorted(maps.Keys(local.byID))
+	for _, id := range slices.Sorted(maps.Keys(remote.byID)) {
+		if _, exists := local.byID[id]; !exists {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		localRecord, inLocal := local.byID[id]
+		remoteRecord, inRemote := remote.byID[id]
+		switch {
+		case inLocal && inRemote:
+			if equalColumns(localRecord, remoteRecord, shared) {
+				continue
+			}
+			remoteWins := policy == SyncRemoteWins || (policy == SyncNewestWins &&
+				reserved.lastModified(remoteRecord).After(reserved.lastModified(localRecord)))
+			conflict := SyncConflict{Table: tableName, Local: localRecord, Remote: remoteRecord, Winner: "local"}
+			if remoteWins {
+				conflict.Winner = "remote"
+				local.upserts[id] = syncedRecord(localRecord, remoteRecord, shared)
+				report.Pulled++
+			} else {
+				remote.upserts[id] = syncedRecord(remoteRecord, localRecord, shared)
+				report.Pushed++
+			}
+			report.Conflicts = append(report.Conflicts, conflict)
+		case inLocal:
+			if deletedAt, deleted := remote.tombstones[id]; deleted && !deletedAt.Before(reserved.lastModified(localRecord)) {
+				local.deletes[id] = true
+				report.PulledDeletes++
+			} else {
+				remote.upserts[id] = syncedRecord(nil, localRecord, shared)
+				report.Pushed++
+			}
+		case inRemote:
+			if deletedAt, deleted := local.tombstones[id]; deleted && !deletedAt.Before(reserved.lastModified(remoteRecord)) {
+				remote.deletes[id] = true
+				report.PushedDeletes++
+			} else {
+				local.upserts[id] = syncedRecord(nil, remoteRecord, shared)
+				report.Pulled++
+			}
+		}
+	}
+
+	// Exchange tombstones, keeping the latest deletion of every id
+	for _, sides := range [][2]*syncSide{{local, remote}, {remote, local}} {
+		from, to := sides[0], sides[1]
+		for id, deletedAt := range from.tombstones {
+			if current, exists := to.tombstones[id]; !exists || deletedAt.After(current) {
+				to.newTombstones[id] = deletedAt.Format(time.RFC3339Nano)
+			}
+		}
+	}
+
+	if err := local.apply(tableName, idColumn); err != nil {
+		return false, err
+	}
+	return true, remote.apply(tableName, other.reservedColumns(tableName).ID)
+}
+
+// missingColumns returns the columns of headers missing from other
+func missingColumns(headers, other []string) []string {
+	var missing []string
+	for _, header := range headers {
+		if !slices.Contains(other, header) {
+			missing = append(missing, header)
+		}
+	}
+	return missing
+}
+
+// equalColumns reports whether two records hold the same values in columns
+func equalColumns(a, b CSVRecord, columns []string) bool {
+	for _, column := range columns {
+		if a[column] != b[column] {
+			return false
+		}
+	}
+	return true
+}
+
+// syncedRecord returns target with the columns taken from source. target is
+// nil for rows missing from the store being written, whose other columns are
+// left empty.
+func syncedRecord(target, source CSVRecord, columns []string) CSVRecord {
+	record := maps.Clone(target)
+	if record == nil {
+		record = make(CSVRecord, len(columns))
+	}
+	for _, column := range columns {
+		record[column] = source[column]
+	}
+	return record
+}
+
+// apply writes the upserts, deletes, and tombstones of a side to its table.
+// The caller must hold the lock of the side's store for writing.
+func (s *syncSide) apply(tableName string, idColumn string) error {
+	if len(s.upserts) == 0 && len(s.deletes) == 0 && len(s.newTombstones) == 0 {
+		return nil
+	}
+
+	records := make([]CSVRecord, 0, len(s.records)+len(s.upserts))
+	var inserted, updated, deleted []CSVRecord
+	for _, record := range s.records {
+		id := record[idColumn]
+		if s.deletes[id] {
+			deleted = append(deleted, record)
+			continue
+		}
+		if upsert, exists := s.upserts[id]; exists {
+			record = upsert
+			updated = append(updated, record)
+			delete(s.upserts, id)
+		}
+		records = append(records, record)
+	}
+	for _, id := range slices.Sorted(maps.Keys(s.upserts)) {
+		records = append(records, s.upserts[id])
+		inserted = append(inserted, s.upserts[id])
+	}
+
+	if len(inserted) > 0 || len(updated) > 0 || len(deleted) > 0 {
+		if err := s.cs.saveTable(tableName, s.headers, records); err != nil {
+			return err
+		}
+	}
+	for _, change := range []struct {
+		op      string
+		records []CSVRecord
+	}{{ChangeInsert, inserted}, {ChangeUpdate, updated}, {ChangeDelete, deleted}} {
+		if err := s.cs.emitChanges(change.op, tableName, change.records); err != nil {
+			return err
+		}
+	}
+	return s.cs.addTombstones(tableName, s.headers, s.newTombstones)
+}
@@ -0,0 +1,213 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestSync(t *testing.T) {
+	localDir := getTestDir()
+	defer os.RemoveAll(localDir)
+	remoteDir := getTestDir() + "-remote"
+	defer os.RemoveAll(remoteDir)
+
+	local, err := NewCSVStore(localDir, WithTableDefaults(WithTombstones()))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer local.Close()
+	remote, err := NewCSVStore(remoteDir, WithTableDefaults(WithTombstones()))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer remote.Close()
+
+	headers := []string{"id", "name", "updated_at"}
+	for _, store := range []*CSVStore{local, remote} {
+		if err := store.CreateTable("users", headers); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+	if err := remote.CreateTable("notes", []string{"id", "text"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	inserts := []struct {
+		store  *CSVStore
+		table  string
+		record CSVRecord
+	}{
+		{local, "users", CSVRecord{"id": "1", "name": "alice", "updated_at": "2024-01-01T00:00:00Z"}},
+		{local, "users", CSVRecord{"id": "2", "name": "bob", "updated_at": "2024-01-01T00:00:00Z"}},
+		{remote, "users", CSVRecord{"id": "2", "name": "robert", "updated_at": "2024-02-01T00:00:00Z"}},
+		{remote, "users", CSVRecord{"id": "3", "name": "carol", "updated_at": "2024-01-01T00:00:00Z"}},
+		{remote, "notes", CSVRecord{"id": "n1", "text": "hello"}},
+	}
+	for _, insert := range inserts {
+		if _, err := insert.store.Insert(insert.table, insert.record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	report, err := local.Sync(remote, SyncOptions{})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if report.Pushed != 1 || report.Pulled != 3 || len(report.Conflicts) != 1 {
+		t.Errorf("Expected 1 pushed row, 3 pulled rows, and 1 conflict, got %+v", report)
+	}
+	if report.Conflicts[0].Winner != "remote" {
+		t.Errorf("Expected the newer remote row to win, got %+v", report.Conflicts[0])
+	}
+
+	for _, store := range []*CSVStore{local, remote} {
+		result, err := store.Query("users", nil)
+		if err != nil {
+			t.Fatalf("Failed to query users: %v", err)
+		}
+		names := make(map[string]string)
+		for _, record := range result.Records {
+			names[record["id"]] = record["name"]
+		}
+		if len(names) != 3 || names["1"] != "alice" || names["2"] != "robert" || names["3"] != "carol" {
+			t.Errorf("Expected synced users, got %v", result.Records)
+		}
+	}
+	if !local.CheckTableExists("notes") {
+		t.Errorf("Expected table notes to be pulled")
+	}
+
+	// A deletion on one side propagates instead of the row being copied back
+	if _, err := remote.Delete("users", []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}); err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+	report, err = local.Sync(remote, SyncOptions{Tables: []string{"users"}})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if report.PulledDeletes != 1 || report.Pushed != 0 {
+		t.Errorf("Expected 1 pulled delete, got %+v", report)
+	}
+	result, err := local.Query("users", []QueryCondition{{Column: "id", Operator: "=", Value: "1"}})
+	if err != nil {
+		t.Fatalf("Failed to query users: %v", err)
+	}
+	if result.Count != 0 {
+		t.Errorf("Expected user 1 to be deleted locally, got %v", result.Records)
+	}
+	tombstones, err := local.Query(TombstoneTableName("users"), nil)
+	if err != nil {
+		t.Fatalf("Failed to query tombstones: %v", err)
+	}
+	if tombstones.Count != 1 || tombstones.Records[0]["id"] != "1" {
+		t.Errorf("Expected the tombstone to be copied, got %v", tombstones.Records)
+	}
+
+	// Syncing again changes nothing
+	report, err = local.Sync(remote, SyncOptions{})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if report.Pushed+report.Pulled+report.PushedDeletes+report.PulledDeletes != 0 {
+		t.Errorf("Expected nothing to sync, got %+v", report)
+	}
+
+	if _, err := local.Sync(local, SyncOptions{}); err == nil {
+		t.Errorf("Expected an error when syncing a store with itself")
+	}
+}
+
+func TestSyncSchemaMismatch(t *testing.T) {
+	local := NewMemoryStore()
+	remote := NewMemoryStore()
+	if err := local.CreateTable("users", []string{"id", "name", "email"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := remote.CreateTable("users", []string{"id", "name", "phone"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	inserts := []struct {
+		store  *CSVStore
+		record CSVRecord
+	}{
+		{local, CSVRecord{"id": "1", "name": "alice", "email": "alice@example.com"}},
+		{remote, CSVRecord{"id": "1", "name": "alice", "phone": "555-0100"}},
+		{remote, CSVRecord{"id": "2", "name": "bob", "phone": "555-0101"}},
+	}
+	for _, insert := range inserts {
+		if _, err := insert.store.Insert("users", insert.record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	report, err := local.Sync(remote, SyncOptions{Conflicts: SyncLocalWins})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if len(report.Conflicts) != 0 || report.Pulled != 1 || report.Pushed != 0 {
+		t.Errorf("Expected only row 2 to be pulled, got %+v", report)
+	}
+	if len(report.SchemaMismatches) != 1 {
+		t.Fatalf("Expected 1 schema mismatch, got %+v", report.SchemaMismatches)
+	}
+	mismatch := report.SchemaMismatches[0]
+	if mismatch.Table != "users" || !slices.Equal(mismatch.LocalOnly, []string{"email"}) ||
+		!slices.Equal(mismatch.RemoteOnly, []string{"phone"}) {
+		t.Errorf("Unexpected schema mismatch: %+v", mismatch)
+	}
+
+	// Updating a shared column keeps the columns of the other store
+	conditions := []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}
+	if _, err := local.Update("users", CSVRecord{"name": "alicia"}, conditions); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if _, err := local.Sync(remote, SyncOptions{Conflicts: SyncLocalWins}); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	result, err := remote.Query("users", conditions)
+	if err != nil {
+		t.Fatalf("Failed to query users: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["name"] != "alicia" || result.Records[0]["phone"] != "555-0100" {
+		t.Errorf("Expected the remote row to keep its phone, got %v", result.Records)
+	}
+}
+
+func TestSyncPartialFailure(t *testing.T) {
+	local := NewMemoryStore()
+	remote := NewMemoryStore(WithQuota(Quota{MaxRows: 1}))
+	for _, store := range []*CSVStore{local, remote} {
+		if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+	for _, id := range []string{"1", "2"} {
+		if _, err := local.Insert("users", CSVRecord{"id": id, "name": "local"}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := remote.Insert("users", CSVRecord{"id": "3", "name": "remote"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	// The local store pulls row 3, then pushing rows 1 and 2 exceeds the quota
+	_, err := local.Sync(remote, SyncOptions{})
+	var syncErr *SyncError
+	if !errors.As(err, &syncErr) {
+		t.Fatalf("Expected SyncError, got %v", err)
+	}
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected the quota error to be wrapped, got %v", err)
+	}
+	if syncErr.Table != "users" || !syncErr.LocalApplied {
+		t.Errorf("Expected the local changes of users to be reported as written, got %+v", syncErr)
+	}
+	result, err := local.Query("users", nil)
+	if err != nil {
+		t.Fatalf("Failed to query users: %v", err)
+	}
+	if result.Count != 3 {
+		t.Errorf("Expected 3 local rows, got %d", result.Count)
+	}
+}
@@ -0,0 +1,527 @@
+package csvstore
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Compression selects how table files are compressed on disk
+type Compression int
+
+const (
+	// NoCompression stores tables as plain table.csv files
+	NoCompression Compression = iota
+	// GzipCompression stores tables as table.csv.gz files
+	GzipCompression
+	// ZstdCompression stores tables as table.csv.zst files. It compresses and
+	// decompresses much faster than gzip, which pays off for large tables that
+	// Update and Delete rewrite often.
+	ZstdCompression
+)
+
+// compressionExtensions lists the file extensions of compressed table files,
+// in order of preference when a table has several files
+var compressionExtensions = []struct {
+	compression Compression
+	extension   string
+}{
+	{NoCompression, ""},
+	{GzipCompression, ".gz"},
+	{ZstdCompression, ".zst"},
+}
+
+// utf8BOM is the byte order mark some programs, such as Excel, put at the start
+// of UTF-8 files
+const utf8BOM = "\ufeff"
+
+// encryptedExtension is appended to the file names of encrypted tables
+const encryptedExtension = ".enc"
+
+// tableFormat describes how a table file is stored
+type tableFormat struct {
+	compression Compression
+	encrypted   bool
+}
+
+// WithCompression stores new files of a table compressed. Existing table files
+// keep the format they were written in; the format of a table file is always
+// recognized from its extension, whatever the configuration.
+func WithCompression(compression Compression) TableOption {
+	return func(c *tableConfig) {
+		c.compression = compression
+	}
+}
+
+// WithBOM starts the files of a table with a UTF-8 byte order mark, so Excel
+// recognizes them as UTF-8. Byte order marks are skipped when reading table
+// files, whatever the configuration.
+func WithBOM() TableOption {
+	return func(c *tableConfig) {
+		c.bom = true
+	}
+}
+
+// WithEncoding reads and writes the files of a table in a character encoding other
+// than UTF-8, e.g. charmap.ISO8859_1, charmap.Windows1252, or
+// unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM) from golang.org/x/text.
+// Values are UTF-8 strings in the store either way; writing a character the
+// encoding cannot represent fails. Encoders that add a byte order mark would add
+// one on every Insert, so prefer the ExpectBOM or IgnoreBOM policies for UTF-16.
+func WithEncoding(enc encoding.Encoding) TableOption {
+	return func(c *tableConfig) {
+		c.encoding = enc
+	}
+}
+
+// tableFileName returns the file name of a table stored in format
+func tableFileName(tableName string, format tableFormat) string {
+	name := tableName + ".csv"
+	for _, entry := range compressionExtensions {
+		if entry.compression == format.compression {
+			name += entry.extension
+		}
+	}
+	if format.encrypted {
+		name += encryptedExtension
+	}
+	return name
+}
+
+// parseTableFileName returns the table stored in a file of the store directory
+// and the format of the file
+func parseTableFileName(name string) (string, tableFormat, bool) {
+	var format tableFormat
+	name, format.encrypted = strings.CutSuffix(name, encryptedExtension)
+	for _, entry := range compressionExtensions {
+		if entry.extension == "" {
+			continue
+		}
+		if trimmed, found := strings.CutSuffix(name, entry.extension); found {
+			name = trimmed
+			format.compression = entry.compression
+			break
+		}
+	}
+
+	tableName, found := strings.CutSuffix(name, ".csv")
+	if !found || tableName == "" {
+		return "", tableFormat{}, false
+	}
+	return tableName, format, true
+}
+
+// tableNameFromFile returns the table stored in a file of the store directory
+func tableNameFromFile(name string) (string, bool) {
+	tableName, _, ok := parseTableFileName(name)
+	return tableName, ok
+}
+
+// resolveTableFile returns the name of the existing file of a table, or the name
+// a new file of the table gets when there is none.
+// The caller must hold cs.mu.
+func (cs *CSVStore) resolveTableFile(tableName string) string {
+	config := cs.tableConfig(tableName)
+	preferred := tableFileName(tableName, tableFormat{
+		compression: config.compression,
+		encrypted:   config.keyProvider != nil,
+	})
+	if cs.fileExists(preferred) {
+		return preferred
+	}
+
+	for _, encrypted := range []bool{false, true} {
+		for _, entry := range compressionExtensions {
+			name := tableFileName(tableName, tableFormat{
+				compression: entry.compression,
+				encrypted:   encrypted,
+			})
+			if cs.fileExists(name) {
+				return name
+			}
+		}
+	}
+
+	return preferred
+}
+
+// tableFile is a table file wrapped in the layers of its format; closing it
+// closes the layers from the outermost inwards
+type tableFile struct {
+	io.Reader
+	io.Writer
+	closers []io.Closer
+	closed  bool
+}
+
+// Close closes every layer of the table file and returns the first error.
+// Closing a closed table file does nothing.
+func (f *tableFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	var firstErr error
+	for _, closer := range f.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// wrapReader adds a reading layer on top of the table file
+func (f *tableFile) wrapReader(r io.Reader, closer io.Closer) {
+	f.Reader = r
+	if closer != nil {
+		f.closers = append([]io.Closer{closer}, f.closers...)
+	}
+}
+
+// wrapWriter adds a writing layer on top of the table file
+func (f *tableFile) wrapWriter(w io.WriteCloser) {
+	f.Writer = w
+	f.closers = append([]io.Closer{w}, f.closers...)
+}
+
+// openTableFile opens a table file for reading its CSV contents.
+// The caller must hold cs.mu and close the file.
+func (cs *CSVStore) openTableFile(tableName string) (*tableFile, error) {
+	return cs.openTableFileNamed(tableName, cs.getTableFile(tableName))
+}
+
+// openTableFileNamed opens the file name holding rows of a table, such as a
+// partition, for reading its CSV contents in the format of the table.
+// The caller must hold cs.mu and close the file.
+func (cs *CSVStore) openTableFileNamed(tableName string, name string) (*tableFile, error) {
+	_, format, _ := parseTableFileName(name)
+
+	file, err := cs.fs.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open table file: %w", err)
+	}
+	tf := &tableFile{Reader: file, closers: []io.Closer{file}}
+
+	if format.encrypted {
+		aead, err := cs.tableCipher(tableName)
+		if err != nil {
+			tf.Close()
+			return nil, err
+		}
+		tf.wrapReader(newDecryptingReader(file, aead, encryptionScope(tableName, name)), nil)
+	}
+
+	switch format.compression {
+	case GzipCompression:
+		reader, err := gzip.NewReader(bufio.NewReader(tf.Reader))
+		if errors.Is(err, io.EOF) {
+			// Empty file
+			tf.wrapReader(eofReader{}, nil)
+			return tf, nil
+		}
+		if err != nil {
+			tf.Close()
+			return nil, fmt.Errorf("failed to decompress table file: %w", err)
+		}
+		tf.wrapReader(reader, reader)
+	case ZstdCompression:
+		decoder, err := zstd.NewReader(tf.Reader)
+		if err != nil {
+			tf.Close()
+			return nil, fmt.Errorf("failed to decompress table file: %w", err)
+		}
+		reader := decoder.IOReadCloser()
+		tf.wrapReader(reader, reader)
+	}
+
+	if enc := cs.tableConfig(tableName).encoding; enc != nil {
+		tf.wrapReader(transform.NewReader(tf.Reader, enc.NewDecoder()), nil)
+	}
+
+	tf.wrapReader(skipBOM(tf.Reader), nil)
+	return tf, nil
+}
+
+// skipBOM returns a reader of r without its leading UTF-8 byte order mark, if any
+func skipBOM(r io.Reader) io.Reader {
+	reader := bufio.NewReader(r)
+	if prefix, err := reader.Peek(len(utf8BOM)); err == nil && string(prefix) == utf8BOM {
+		reader.Discard(len(utf8BOM))
+	}
+	return reader
+}
+
+// createTableFile creates or truncates a table file for writing its CSV contents.
+// The caller must hold cs.mu for writing and close the file.
+func (cs *CSVStore) createTableFile(tableName string) (*tableFile, error) {
+	return cs.createTableFileNamed(tableName, cs.getTableFile(tableName))
+}
+
+// createTableFileNamed creates or truncates the file name holding rows of a
+// table, such as a partition, for writing CSV contents in the format of the table.
+// The caller must hold cs.mu for writing and close the file.
+func (cs *CSVStore) createTableFileNamed(tableName string, name string) (*tableFile, error) {
+	return cs.createTableFileAt(tableName, name, name)
+}
+
+// createTableFileAt creates the file at path for writing the contents of the file
+// name of a table, to be renamed to name once complete. The contents are written
+// in the format of name, and encrypted for name.
+// The caller must hold cs.mu for writing and close the file.
+func (cs *CSVStore) createTableFileAt(tableName, name, path string) (*tableFile, error) {
+	file, err := cs.fs.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table file: %w", err)
+	}
+	tf, err := cs.wrapTableWriter(tableName, name, file, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if cs.tableConfig(tableName).bom {
+		if _, err := io.WriteString(tf, utf8BOM); err != nil {
+			tf.Close()
+			return nil, fmt.Errorf("failed to write table file: %w", err)
+		}
+	}
+	return tf, nil
+}
+
+// appendTableFile opens a table file for appending CSV rows. Compressed files get
+// a new compressed member, which readers see as a continuation of the file, and
+// encrypted files a new encrypted segment.
+// The caller must hold cs.mu for writing and close the file.
+func (cs *CSVStore) appendTableFile(tableName string) (*tableFile, error) {
+	return cs.appendTableFileNamed(tableName, cs.getTableFile(tableName))
+}
+
+// appendTableFileNamed opens the file name holding rows of a table, such as a
+// partition, for appending CSV rows in the format of the table.
+// The caller must hold cs.mu for writing and close the file.
+func (cs *CSVStore) appendTableFileNamed(tableName string, name string) (*tableFile, error) {
+	if !cs.fileExists(name) {
+		return nil, fmt.Errorf("failed to open table file: %w", fs.ErrNotExist)
+	}
+	_, format, _ := parseTableFileName(name)
+
+	var segments uint64
+	if format.encrypted {
+		var err error
+		if segments, err = countEncryptedSegments(cs.fs, name); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := cs.fs.Append(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open table file: %w", err)
+	}
+	return cs.wrapTableWriter(tableName, name, file, segments)
+}
+
+// wrapTableWriter wraps a table file opened for writing in the layers of its
+// format. segments is the number of encrypted segments already in the file.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) wrapTableWriter(
+	tableName string,
+	name string,
+	file io.WriteCloser,
+	segments uint64,
+) (*tableFile, error) {
+	_, format, _ := parseTableFileName(name)
+	file = cs.stats.countWrites(tableName, file)
+	tf := &tableFile{Writer: file, closers: []io.Closer{file}}
+
+	if format.encrypted {
+		aead, err := cs.tableCipher(tableName)
+		if err != nil {
+			tf.Close()
+			return nil, err
+		}
+		tf.wrapWriter(newEncryptingWriter(file, aead, encryptionScope(tableName, name), segments))
+	}
+
+	switch format.compression {
+	case GzipCompression:
+		tf.wrapWriter(gzip.NewWriter(tf.Writer))
+	case ZstdCompression:
+		writer, err := zstd.NewWriter(tf.Writer)
+		if err != nil {
+			tf.Close()
+			return nil, fmt.Errorf("failed to compress table file: %w", err)
+		}
+		tf.wrapWriter(writer)
+	}
+
+	if enc := cs.tableConfig(tableName).encoding; enc != nil {
+		tf.wrapWriter(transform.NewWriter(tf.Writer, enc.NewEncoder()))
+	}
+
+	return tf, nil
+}
+
+// eofReader is an empty reader
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+// WithTrimSpace removes leading and trailing whitespace from the cells of a table
+// as they are read, so " Electronics" in a hand-edited file matches "Electronics".
+// Rows rewritten by Update and Delete are saved trimmed. Headers are not changed.
+func WithTrimSpace() TableOption {
+	return func(c *tableConfig) {
+		c.trimSpace = true
+	}
+}
+
+// WithCollapseSpace trims the cells of a table like WithTrimSpace and also
+// replaces every run of whitespace inside them with a single space
+func WithCollapseSpace() TableOption {
+	return func(c *tableConfig) {
+		c.trimSpace = true
+		c.collapseSpace = true
+	}
+}
+
+// cellNormalizer returns the function applied to the cells of a table as they
+// are read, or nil when cells are read as stored
+func (c *tableConfig) cellNormalizer() func(string) string {
+	switch {
+	case c.collapseSpace:
+		return func(value string) string {
+			return strings.Join(strings.Fields(value), " ")
+		}
+	case c.trimSpace:
+		return strings.TrimSpace
+	default:
+		return nil
+	}
+}
+
+// ReaderOptions relaxes the parsing of table files, so slightly malformed CSV
+// files can still be loaded. See csv.Reader for the meaning of each field.
+type ReaderOptions struct {
+	// LazyQuotes allows quotes in unquoted fields and stray quotes in quoted fields
+	LazyQuotes bool
+	// FieldsPerRecord is the number of fields every row must have. Zero requires
+	// as many fields as the header row and a negative value allows any number;
+	// missing fields read as empty and extra fields are ignored.
+	FieldsPerRecord int
+	// TrimLeadingSpace ignores leading whitespace in fields
+	TrimLeadingSpace bool
+}
+
+// WithReaderOptions sets how leniently the files of a table are parsed
+func WithReaderOptions(opts ReaderOptions) TableOption {
+	return func(c *tableConfig) {
+		c.readerOptions = opts
+	}
+}
+
+// WithComment skips the lines of table files starting with the comment character,
+// e.g. '#'. The comment lines at the top of a file are kept when Update and
+// Delete rewrite the table; comment lines between rows are dropped.
+func WithComment(comment rune) TableOption {
+	return func(c *tableConfig) {
+		c.comment = comment
+	}
+}
+
+// QuoteMode selects which fields are quoted when table files are written
+type QuoteMode int
+
+const (
+	// QuoteWhenNeeded quotes only fields containing delimiters, quotes, line
+	// breaks, or leading whitespace
+	QuoteWhenNeeded QuoteMode = iota
+	// QuoteAll quotes every field
+	QuoteAll
+)
+
+// WriterOptions controls the formatting of table files
+type WriterOptions struct {
+	Quote QuoteMode
+	// UseCRLF ends lines with \r\n instead of \n, as Excel and many legacy
+	// consumers expect
+	UseCRLF bool
+}
+
+// WithWriterOptions sets how the files of a table are formatted. Existing rows
+// take the new format when Update or Delete rewrites the table.
+func WithWriterOptions(opts WriterOptions) TableOption {
+	return func(c *tableConfig) {
+		c.writerOptions = opts
+	}
+}
+
+// leadingComments returns the comment lines at the top of a table file, without
+// their line endings. The caller must hold cs.mu.
+func (cs *CSVStore) leadingComments(tableName string) ([]string, error) {
+	comment := cs.tableConfig(tableName).comment
+	if comment == 0 || !cs.tableExists(tableName) {
+		return nil, nil
+	}
+
+	file, err := cs.openTableFile(tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var comments []string
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if !strings.HasPrefix(line, string(comment)) {
+			break
+		}
+		comments = append(comments, strings.TrimRight(line, "\r\n"))
+		if err != nil {
+			break
+		}
+	}
+	return comments, nil
+}
+
+// newTableReader returns a CSV reader for the contents of a table file,
+// configured for the table. The caller must hold cs.mu.
+func (cs *CSVStore) newTableReader(tableName string, r io.Reader) *csv.Reader {
+	config := cs.tableConfig(tableName)
+
+	reader := csv.NewReader(r)
+	if config.delimiter != 0 {
+		reader.Comma = config.delimiter
+	}
+	reader.LazyQuotes = config.readerOptions.LazyQuotes
+	reader.FieldsPerRecord = config.readerOptions.FieldsPerRecord
+	reader.TrimLeadingSpace = config.readerOptions.TrimLeadingSpace
+	reader.Comment = config.comment
+	return reader
+}
+
+// newTableWriter returns a CSV writer for the contents of a table file,
+// configured for the table. The caller must hold cs.mu.
+func (cs *CSVStore) newTableWriter(tableName string, w io.Writer) *tableWriter {
+	config := cs.tableConfig(tableName)
+
+	writer := newCSVWriter(w)
+	if config.delimiter != 0 {
+		writer.comma = config.delimiter
+	}
+	writer.comment = config.comment
+	writer.quoteAll = config.writerOptions.Quote == QuoteAll
+	writer.useCRLF = config.writerOptions.UseCRLF
+	return writer
+}
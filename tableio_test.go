@@ -0,0 +1,278 @@
+package csvstore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestTrimSpace(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	contents := "id,category,name\n1, Electronics ,\"  big   tv \"\n2,Books,novel\n"
+	for _, table := range []string{"trimmed", "collapsed"} {
+		if err := os.WriteFile(filepath.Join(testDir, table+".csv"), []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to write table file: %v", err)
+		}
+	}
+	store.ConfigureTable("trimmed", WithTrimSpace())
+	store.ConfigureTable("collapsed", WithCollapseSpace())
+
+	tests := []struct {
+		table string
+		name  string
+	}{
+		{"trimmed", "big   tv"},
+		{"collapsed", "big tv"},
+	}
+	for _, tt := range tests {
+		result, err := store.Query(tt.table, []QueryCondition{
+			{Column: "category", Operator: "=", Value: "Electronics"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to query records: %v", err)
+		}
+		if result.Count != 1 {
+			t.Fatalf("Expected 1 record in %s, got %d", tt.table, result.Count)
+		}
+		if result.Records[0]["name"] != tt.name {
+			t.Errorf("Expected name %q in %s, got %q", tt.name, tt.table, result.Records[0]["name"])
+		}
+	}
+}
+
+func TestReaderOptions(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	contents := "id,name,note\n1,a \"quoted\" name,  ok\n2,short\n3,long,row,extra\n"
+	if err := os.WriteFile(filepath.Join(testDir, "messy.csv"), []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+
+	if _, err := store.Query("messy", nil); err == nil {
+		t.Fatalf("Expected strict parsing to fail")
+	}
+
+	store.ConfigureTable("messy", WithReaderOptions(ReaderOptions{
+		LazyQuotes:       true,
+		FieldsPerRecord:  -1,
+		TrimLeadingSpace: true,
+	}))
+	result, err := store.Query("messy", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 3 {
+		t.Fatalf("Expected 3 records, got %d", result.Count)
+	}
+	if result.Records[0]["name"] != `a "quoted" name` {
+		t.Errorf("Unexpected name: %q", result.Records[0]["name"])
+	}
+	if result.Records[0]["note"] != "ok" {
+		t.Errorf("Expected leading space to be trimmed, got %q", result.Records[0]["note"])
+	}
+	if note, exists := result.Records[1]["note"]; !exists || note != "" {
+		t.Errorf("Expected empty note for short row, got %q", note)
+	}
+	if result.Records[2]["note"] != "row" {
+		t.Errorf("Unexpected note for long row: %q", result.Records[2]["note"])
+	}
+}
+
+func TestComment(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir, WithTableDefaults(WithComment('#')))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	contents := "# generated by exporter\n# do not edit\nid,name\n1,a\n# note\n2,b\n"
+	path := filepath.Join(testDir, "notes.csv")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+
+	result, err := store.Query("notes", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 2 {
+		t.Fatalf("Expected 2 records, got %d", result.Count)
+	}
+
+	// Values starting with the comment character are quoted
+	if _, err := store.Insert("notes", CSVRecord{"id": "#3", "name": "c"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Delete("notes", []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	expected := "# generated by exporter\n# do not edit\nid,name\n2,b\n\"#3\",c\n"
+	if string(data) != expected {
+		t.Errorf("Expected file contents %q, got %q", expected, string(data))
+	}
+}
+
+func TestWriterOptions(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	store.ConfigureTable("excel", WithWriterOptions(WriterOptions{Quote: QuoteAll, UseCRLF: true}))
+	if err := store.CreateTable("excel", []string{"name", "note"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("excel", CSVRecord{"name": "a", "note": "line 1\nline 2"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(testDir, "excel.csv"))
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	expected := "\"name\",\"note\"\r\n\"a\",\"line 1\r\nline 2\"\r\n"
+	if string(data) != expected {
+		t.Errorf("Expected file contents %q, got %q", expected, string(data))
+	}
+
+	result, err := store.Query("excel", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["note"] != "line 1\nline 2" {
+		t.Errorf("Unexpected records: %v", result.Records)
+	}
+}
+
+func TestBOM(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	// Excel exports start with a byte order mark
+	if err := os.WriteFile(filepath.Join(testDir, "export.csv"), []byte("\ufeffid,name\n1,a\n"), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+	result, err := store.Query("export", []QueryCondition{{Column: "id", Operator: "=", Value: "1"}})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Expected the id column to match, got %v", result.Records)
+	}
+
+	store.ConfigureTable("excel", WithBOM())
+	if err := store.CreateTable("excel", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("excel", CSVRecord{"id": "1", "name": "a"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Update("excel", CSVRecord{"name": "b"}, nil); err != nil {
+		t.Fatalf("Failed to update records: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(testDir, "excel.csv"))
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "\ufeffid,name\n") || strings.Count(string(data), "\ufeff") != 1 {
+		t.Errorf("Expected a single byte order mark, got %q", string(data))
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	// "café" in Latin-1
+	legacy := []byte("id,name\n1,caf\xe9\n")
+	if err := os.WriteFile(filepath.Join(testDir, "legacy.csv"), legacy, 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+	store.ConfigureTable("legacy", WithEncoding(charmap.ISO8859_1))
+
+	result, err := store.Query("legacy", []QueryCondition{{Column: "name", Operator: "=", Value: "café"}})
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Expected 1 record, got %d", result.Count)
+	}
+
+	if _, err := store.Insert("legacy", CSVRecord{"id": "2", "name": "naïve"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(testDir, "legacy.csv"))
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	if expected := "id,name\n1,caf\xe9\n2,na\xefve\n"; string(data) != expected {
+		t.Errorf("Expected file contents %q, got %q", expected, string(data))
+	}
+
+	// UTF-16 round trip
+	store.ConfigureTable("wide", WithEncoding(unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)))
+	if err := store.CreateTable("wide", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("wide", CSVRecord{"id": "1", "name": "日本"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	wide, err := store.Query("wide", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if wide.Count != 1 || wide.Records[0]["name"] != "日本" {
+		t.Errorf("Unexpected records: %v", wide.Records)
+	}
+	data, err = os.ReadFile(filepath.Join(testDir, "wide.csv"))
+	if err != nil {
+		t.Fatalf("Failed to read table file: %v", err)
+	}
+	if len(data) == 0 || data[1] != 0 {
+		t.Errorf("Expected a UTF-16 file, got %q", string(data))
+	}
+}
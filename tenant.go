@@ -0,0 +1,171 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+// tenantsDir is the directory of the store holding the tenant sub-stores
+const tenantsDir = "tenants"
+
+// tenantIDPattern matches valid tenant ids, which are used as directory names
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// Tenant returns the store of a tenant, whose tables live in the directory
+// tenants/<id> of the store and are invisible to the store and other tenants.
+// The tenant store is created with opts on first use and reused afterwards, so
+// later opts are ignored; it is closed with the store. Tenant ids contain
+// letters, digits, '.', '_', and '-', and do not start with '.'.
+func (cs *CSVStore) Tenant(id string, opts ...Option) (*CSVStore, error) {
+	if !tenantIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid tenant id %q", id)
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.closed.Load() {
+		return nil, ErrClosed
+	}
+	if tenant, ok := cs.tenants[id]; ok {
+		return tenant, nil
+	}
+
+	dir := path.Join(tenantsDir, id)
+	if !cs.readOnly {
+		for _, name := range []string{tenantsDir, dir} {
+			if err := cs.fs.Mkdir(name); err != nil && !errors.Is(err, fs.ErrExist) {
+				return nil, fmt.Errorf("failed to create tenant directory: %w", err)
+			}
+		}
+	}
+
+	basePath := filepath.Join(cs.basePath, tenantsDir, id)
+	var fsys FS
+	if osfs, ok := cs.fs.(*osFS); ok {
+		// Keep an OS file system, which WatchExternalChanges requires
+		fsys = OSFS(osfs.path(dir))
+	} else {
+		fsys = &subFS{fsys: cs.fs, dir: dir}
+	}
+	opts = append(opts, WithFS(fsys))
+	if cs.readOnly {
+		opts = append(opts, func(tenant *CSVStore) { tenant.readOnly = true })
+	}
+	tenant, err := NewCSVStore(basePath, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tenant %s: %w", id, err)
+	}
+
+	if cs.tenants == nil {
+		cs.tenants = make(map[string]*CSVStore)
+	}
+	cs.tenants[id] = tenant
+	cs.addCloser(func() error {
+		return tenant.Close()
+	})
+	return tenant, nil
+}
+
+// ListTenants returns the ids of the tenants of the store, sorted
+func (cs *CSVStore) ListTenants() ([]string, error) {
+	return runOperation(cs, Operation{Name: OpListTenants}, func() ([]string, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		entries, err := cs.fs.ReadDir(tenantsDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tenant directory: %w", err)
+		}
+
+		tenants := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if entry.IsDir() && tenantIDPattern.MatchString(entry.Name()) {
+				tenants = append(tenants, entry.Name())
+			}
+		}
+		slices.Sort(tenants)
+		return tenants, nil
+	})
+}
+
+// DeleteTenant closes the store of a tenant and removes its directory with
+// all its tables. Handles returned by Tenant for it return ErrClosed
+// afterwards.
+func (cs *CSVStore) DeleteTenant(id string) error {
+	op := Operation{Name: OpDeleteTenant, Payload: id}
+	_, err := runOperation(cs, op, func() (struct{}, error) {
+		if !tenantIDPattern.MatchString(id) {
+			return struct{}{}, fmt.Errorf("invalid tenant id %q", id)
+		}
+
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		if tenant, ok := cs.tenants[id]; ok {
+			if err := tenant.Close(); err != nil {
+				return struct{}{}, fmt.Errorf("failed to close tenant %s: %w", id, err)
+			}
+			delete(cs.tenants, id)
+		}
+		if err := cs.fs.Remove(path.Join(tenantsDir, id)); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return struct{}{}, fmt.Errorf("tenant %s does not exist", id)
+			}
+			return struct{}{}, fmt.Errorf("failed to delete tenant %s: %w", id, err)
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// subFS is the FS of a directory of another FS
+type subFS struct {
+	fsys FS
+	dir  string
+}
+
+func (f *subFS) path(name string) string {
+	return path.Join(f.dir, name)
+}
+
+func (f *subFS) Open(name string) (io.ReadCloser, error) {
+	return f.fsys.Open(f.path(name))
+}
+
+func (f *subFS) Create(name string) (io.WriteCloser, error) {
+	return f.fsys.Create(f.path(name))
+}
+
+func (f *subFS) Append(name string) (io.WriteCloser, error) {
+	return f.fsys.Append(f.path(name))
+}
+
+func (f *subFS) Rename(oldName, newName string) error {
+	return f.fsys.Rename(f.path(oldName), f.path(newName))
+}
+
+func (f *subFS) Remove(name string) error {
+	return f.fsys.Remove(f.path(name))
+}
+
+func (f *subFS) Stat(name string) (fs.FileInfo, error) {
+	return f.fsys.Stat(f.path(name))
+}
+
+func (f *subFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return f.fsys.ReadDir(f.path(name))
+}
+
+func (f *subFS) Mkdir(name string) error {
+	return f.fsys.Mkdir(f.path(name))
+}
@@ -0,0 +1,127 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTenant(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("plans", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	acme, err := store.Tenant("acme")
+	if err != nil {
+		t.Fatalf("Failed to open tenant: %v", err)
+	}
+	if err := acme.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create tenant table: %v", err)
+	}
+	if _, err := acme.Insert("users", CSVRecord{"name": "alice"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(testDir, "tenants", "acme", "users.csv")); err != nil {
+		t.Errorf("Expected the tenant table under tenants/acme: %v", err)
+	}
+
+	// Tenants are isolated from each other and from the store
+	globex, err := store.Tenant("globex")
+	if err != nil {
+		t.Fatalf("Failed to open tenant: %v", err)
+	}
+	if globex.CheckTableExists("users") || store.CheckTableExists("users") {
+		t.Error("Expected the tenant table to be invisible outside the tenant")
+	}
+	if acme.CheckTableExists("plans") {
+		t.Error("Expected the store table to be invisible in the tenant")
+	}
+	tables, err := store.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if !reflect.DeepEqual(tables, []string{"plans"}) {
+		t.Errorf("Expected only the store tables, got %v", tables)
+	}
+
+	// The same handle is returned for a tenant
+	again, err := store.Tenant("acme")
+	if err != nil || again != acme {
+		t.Errorf("Expected the same tenant store, got %p (%v)", again, err)
+	}
+
+	for _, id := range []string{"", "..", "a/b", ".hidden", `a\b`} {
+		if _, err := store.Tenant(id); err == nil {
+			t.Errorf("Expected error for tenant id %q", id)
+		}
+	}
+
+	tenants, err := store.ListTenants()
+	if err != nil {
+		t.Fatalf("Failed to list tenants: %v", err)
+	}
+	if !reflect.DeepEqual(tenants, []string{"acme", "globex"}) {
+		t.Errorf("Expected tenants acme and globex, got %v", tenants)
+	}
+
+	if err := store.DeleteTenant("acme"); err != nil {
+		t.Fatalf("Failed to delete tenant: %v", err)
+	}
+	if _, err := acme.Query("users", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from a deleted tenant, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(testDir, "tenants", "acme")); !os.IsNotExist(err) {
+		t.Errorf("Expected the tenant directory to be removed, got %v", err)
+	}
+	if err := store.DeleteTenant("acme"); err == nil {
+		t.Error("Expected error deleting a missing tenant")
+	}
+
+	// A deleted tenant starts empty when opened again
+	acme, err = store.Tenant("acme")
+	if err != nil {
+		t.Fatalf("Failed to reopen tenant: %v", err)
+	}
+	if acme.CheckTableExists("users") {
+		t.Error("Expected a recreated tenant to be empty")
+	}
+
+	store.Close()
+	if _, err := globex.ListTables(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected tenants to be closed with the store, got %v", err)
+	}
+}
+
+func TestTenantMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	tenant, err := store.Tenant("acme", WithTableDefaults(WithoutTimestamps()))
+	if err != nil {
+		t.Fatalf("Failed to open tenant: %v", err)
+	}
+	if err := tenant.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create tenant table: %v", err)
+	}
+	record, err := tenant.Insert("users", CSVRecord{"name": "alice"})
+	if err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, ok := record["created_at"]; ok {
+		t.Errorf("Expected the tenant options to apply, got %v", record)
+	}
+	if !store.fileExists("tenants/acme/users.csv") {
+		t.Error("Expected the tenant table in the memory store under tenants/acme")
+	}
+}
@@ -0,0 +1,70 @@
+package csvstore
+
+import (
+	"encoding/csv"
+	"errors"
+)
+
+// SkippedRow describes a row QueryTolerant could not parse
+type SkippedRow struct {
+	Line   int    // Line of the table file where the row starts, from 1
+	Reason string // Why the row could not be parsed, e.g. "wrong number of fields"
+}
+
+// QueryTolerant executes a query on the CSV table like Query, but skips rows that
+// cannot be parsed, such as rows with the wrong number of fields or broken quoting,
+// instead of failing. The skipped rows are listed in QueryResult.Skipped.
+func (cs *CSVStore) QueryTolerant(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	op := Operation{Name: OpQueryTolerant, Table: tableName, Payload: conditions}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		result, err := cs.queryTolerant(tableName, conditions)
+		if err != nil {
+			return nil, err
+		}
+		return cs.maskResult(tableName, result), nil
+	})
+}
+
+// queryTolerant executes a query on the CSV table, skipping unparseable rows.
+// The caller must hold cs.mu.
+func (cs *CSVStore) queryTolerant(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	scanner, err := cs.openTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.close()
+	scanner.tolerant = true
+
+	conditions = resolveConditions(conditions, cs.columnResolver(tableName))
+	records := make([]CSVRecord, 0)
+	err = cs.scanRecords(tableName, scanner, conditions, nil, func(record CSVRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryResult{
+		Records: records,
+		Count:   len(records),
+		Skipped: scanner.skipped,
+	}, nil
+}
+
+// skip records a row the scanner could not parse and reports whether reading
+// can go on past it
+func (s *tableScanner) skip(err error) bool {
+	var parseErr *csv.ParseError
+	if !errors.As(err, &parseErr) {
+		return false
+	}
+	s.skipped = append(s.skipped, SkippedRow{
+		Line:   parseErr.StartLine,
+		Reason: parseErr.Err.Error(),
+	})
+	return true
+}
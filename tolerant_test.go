@@ -0,0 +1,59 @@
+package csvstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueryTolerant(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	contents := "id,name\n" +
+		"1,alice\n" +
+		"2,bob,extra\n" +
+		"3,car\"ol\n" +
+		"4,dave\n" +
+		"5,\"unterminated\n"
+	if err := os.WriteFile(filepath.Join(testDir, "people.csv"), []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+
+	if _, err := store.Query("people", nil); err == nil {
+		t.Errorf("Expected Query to fail on malformed rows")
+	}
+
+	result, err := store.QueryTolerant("people", nil)
+	if err != nil {
+		t.Fatalf("Failed to query tolerantly: %v", err)
+	}
+	if result.Count != 2 || result.Records[0]["name"] != "alice" || result.Records[1]["name"] != "dave" {
+		t.Errorf("Expected alice and dave, got %v", result.Records)
+	}
+	expectedLines := []int{3, 4, 6}
+	if len(result.Skipped) != len(expectedLines) {
+		t.Fatalf("Expected %d skipped rows, got %+v", len(expectedLines), result.Skipped)
+	}
+	for i, skipped := range result.Skipped {
+		if skipped.Line != expectedLines[i] || skipped.Reason == "" {
+			t.Errorf("Expected a skipped row at line %d, got %+v", expectedLines[i], skipped)
+		}
+	}
+
+	result, err = store.QueryTolerant("people", []QueryCondition{
+		{Column: "id", Operator: "=", Value: "4"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to query tolerantly: %v", err)
+	}
+	if result.Count != 1 || len(result.Skipped) != 3 {
+		t.Errorf("Expected 1 record and 3 skipped rows, got %v and %+v", result.Records, result.Skipped)
+	}
+}
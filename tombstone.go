@@ -0,0 +1,121 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// tombstoneSuffix is appended to a table name to form its tombstone table name
+	tombstoneSuffix = "__tombstones"
+
+	// TombstoneDeletedAtColumn holds the time a row was deleted
+	TombstoneDeletedAtColumn = "deleted_at"
+)
+
+// TombstoneTableName returns the name of the tombstone table for a table
+func TombstoneTableName(tableName string) string {
+	return tableName + tombstoneSuffix
+}
+
+// isTombstoneTable reports whether a table is a tombstone table
+func isTombstoneTable(tableName string) bool {
+	return strings.HasSuffix(tableName, tombstoneSuffix)
+}
+
+// WithTombstones records the id and deletion time of every deleted row in a
+// companion tombstone table, so Sync can tell a row deleted in one store from a
+// row never copied to it. The table needs an id column.
+func WithTombstones() TableOption {
+	return func(c *tableConfig) {
+		c.tombstones = true
+	}
+}
+
+// recordTombstones appends tombstones for deleted records to the tombstone table of
+// tableName, creating it when needed. It does nothing if tombstones are disabled
+// for the table or the table has no id column.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) recordTombstones(tableName string, headers []string, records []CSVRecord) error {
+	if len(records) == 0 || isTombstoneTable(tableName) || !cs.tableConfig(tableName).tombstones {
+		return nil
+	}
+	deletedAt := time.Now().Format(time.RFC3339Nano)
+	tombstones := make(map[string]string, len(records))
+	for _, record := range records {
+		tombstones[record[cs.reservedColumns(tableName).ID]] = deletedAt
+	}
+	return cs.addTombstones(tableName, headers, tombstones)
+}
+
+// addTombstones appends tombstones, deletion times by id, to the tombstone table
+// of tableName, creating it when needed. It does nothing if the table has no id column.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) addTombstones(tableName string, headers []string, tombstones map[string]string) error {
+	idColumn := cs.reservedColumns(tableName).ID
+	if len(tombstones) == 0 || !slices.Contains(headers, idColumn) {
+		return nil
+	}
+
+	tombstoneTable := TombstoneTableName(tableName)
+	tombstoneHeaders, err := cs.getHeaders(tombstoneTable)
+	if errors.Is(err, fs.ErrNotExist) {
+		tombstoneHeaders = []string{idColumn, TombstoneDeletedAtColumn}
+		if err := cs.saveTable(tombstoneTable, tombstoneHeaders, nil); err != nil {
+			return fmt.Errorf("failed to create tombstone table %s: %w", tombstoneTable, err)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(tombstones))
+	for _, id := range slices.Sorted(maps.Keys(tombstones)) {
+		row := make([]string, len(tombstoneHeaders))
+		for i, header := range tombstoneHeaders {
+			switch header {
+			case idColumn:
+				row[i] = id
+			case TombstoneDeletedAtColumn:
+				row[i] = tombstones[id]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if err := cs.appendRows(tombstoneTable, rows); err != nil {
+		return fmt.Errorf("failed to write tombstones for table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// tombstones returns the latest deletion time of every id in the tombstone table
+// of tableName, or none when there is no tombstone table.
+// The caller must hold cs.mu.
+func (cs *CSVStore) tombstones(tableName string) (map[string]time.Time, error) {
+	tombstones := make(map[string]time.Time)
+	tombstoneTable := TombstoneTableName(tableName)
+	if !cs.fileExists(cs.getTableFile(tombstoneTable)) {
+		return tombstones, nil
+	}
+
+	idColumn := cs.reservedColumns(tableName).ID
+	err := cs.scanTable(tombstoneTable, nil, nil, func(record CSVRecord) error {
+		deletedAt, err := time.Parse(time.RFC3339Nano, record[TombstoneDeletedAtColumn])
+		if err != nil {
+			return fmt.Errorf("invalid tombstone for id %s of table %s: %w", record[idColumn], tableName, err)
+		}
+		if deletedAt.After(tombstones[record[idColumn]]) {
+			tombstones[record[idColumn]] = deletedAt
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tombstones, nil
+}
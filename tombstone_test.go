@@ -0,0 +1,43 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTombstones(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	store.ConfigureTable("users", WithTombstones())
+
+	for _, table := range []string{"users", "tags"} {
+		if err := store.CreateTable(table, []string{"id", "name"}); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		for _, id := range []string{"1", "2"} {
+			if _, err := store.Insert(table, CSVRecord{"id": id, "name": "n" + id}); err != nil {
+				t.Fatalf("Failed to insert record: %v", err)
+			}
+		}
+		if _, err := store.Delete(table, []QueryCondition{{Column: "id", Operator: "=", Value: "2"}}); err != nil {
+			t.Fatalf("Failed to delete record: %v", err)
+		}
+	}
+
+	tombstones, err := store.Query(TombstoneTableName("users"), nil)
+	if err != nil {
+		t.Fatalf("Failed to query tombstones: %v", err)
+	}
+	if tombstones.Count != 1 || tombstones.Records[0]["id"] != "2" || tombstones.Records[0][TombstoneDeletedAtColumn] == "" {
+		t.Errorf("Expected a tombstone for id 2, got %v", tombstones.Records)
+	}
+	if store.CheckTableExists(TombstoneTableName("tags")) {
+		t.Errorf("Expected no tombstones for a table without WithTombstones")
+	}
+}
@@ -0,0 +1,94 @@
+package csvstore
+
+import (
+	"fmt"
+	"slices"
+)
+
+// QueryTopNPerGroup returns up to n records of every group of records matching
+// conditions, e.g. the 3 most expensive products of each category. Records are
+// grouped by the value of groupBy and ordered within their group by sortField,
+// "asc" or "desc" according to sortBy. Groups come in the order of their top
+// record.
+func (cs *CSVStore) QueryTopNPerGroup(
+	tableName string,
+	groupBy string,
+	sortField string,
+	sortBy string,
+	n int,
+	conditions []QueryCondition,
+) (*QueryResult, error) {
+	op := Operation{Name: OpQueryTopNPerGroup, Table: tableName, Payload: conditions}
+	return runOperation(cs, op, func() (*QueryResult, error) {
+		cs.mu.RLock()
+		defer cs.mu.RUnlock()
+
+		result, err := cs.queryTopNPerGroup(tableName, groupBy, sortField, sortBy, n, conditions)
+		if err != nil {
+			return nil, err
+		}
+		return cs.maskResult(tableName, result), nil
+	})
+}
+
+// queryTopNPerGroup returns up to n sorted records of every group.
+// The caller must hold cs.mu.
+func (cs *CSVStore) queryTopNPerGroup(
+	tableName string,
+	groupBy string,
+	sortField string,
+	sortBy string,
+	n int,
+	conditions []QueryCondition,
+) (*QueryResult, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("n (%d) cannot be negative", n)
+	}
+	if sortBy != "asc" && sortBy != "desc" {
+		return nil, fmt.Errorf("sortBy must be either 'asc' or 'desc', got '%s'", sortBy)
+	}
+	resolve := cs.columnResolver(tableName)
+	groupBy = resolve(groupBy)
+	sortField = resolve(sortField)
+	conditions = resolveConditions(conditions, resolve)
+
+	onHeaders := func(headers []string) error {
+		for _, column := range []string{groupBy, sortField} {
+			if !slices.Contains(headers, column) {
+				return fmt.Errorf("column %s does not exist in table %s", column, tableName)
+			}
+		}
+		return nil
+	}
+	records := make([]CSVRecord, 0)
+	err := cs.scanTable(tableName, conditions, onHeaders, func(record CSVRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(records, cs.recordComparer(tableName, sortField, sortBy))
+
+	var order []string
+	groups := make(map[string][]CSVRecord)
+	for _, record := range records {
+		key := record[groupBy]
+		if _, seen := groups[key]; !seen {
+			order = append(order, key)
+			groups[key] = nil
+		}
+		if len(groups[key]) < n {
+			groups[key] = append(groups[key], record)
+		}
+	}
+
+	topRecords := make([]CSVRecord, 0)
+	for _, key := range order {
+		topRecords = append(topRecords, groups[key]...)
+	}
+	return &QueryResult{
+		Records: topRecords,
+		Count:   len(topRecords),
+	}, nil
+}
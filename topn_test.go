@@ -0,0 +1,67 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+)
+
+func TestQueryTopNPerGroup(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("products", []string{"id", "name", "category", "price"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	products := []CSVRecord{
+		{"name": "pen", "category": "office", "price": "3"},
+		{"name": "laptop", "category": "tech", "price": "1200"},
+		{"name": "desk", "category": "office", "price": "250"},
+		{"name": "mouse", "category": "tech", "price": "25"},
+		{"name": "chair", "category": "office", "price": "180"},
+		{"name": "phone", "category": "tech", "price": "900"},
+		{"name": "stapler", "category": "office", "price": "12"},
+		{"name": "apple", "category": "food", "price": "1"},
+	}
+	for _, product := range products {
+		if _, err := store.Insert("products", product); err != nil {
+			t.Fatalf("Failed to insert product: %v", err)
+		}
+	}
+
+	result, err := store.QueryTopNPerGroup("products", "category", "price", "desc", 2, nil)
+	if err != nil {
+		t.Fatalf("Failed to query top records: %v", err)
+	}
+	expected := []string{"laptop", "phone", "desk", "chair", "apple"}
+	if result.Count != len(expected) {
+		t.Fatalf("Expected %d records, got %v", len(expected), result.Records)
+	}
+	for i, name := range expected {
+		if result.Records[i]["name"] != name {
+			t.Errorf("Expected %s at position %d, got %s", name, i, result.Records[i]["name"])
+		}
+	}
+
+	result, err = store.QueryTopNPerGroup("products", "category", "price", "asc", 1, []QueryCondition{
+		{Column: "category", Operator: "!=", Value: "food"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to query top records: %v", err)
+	}
+	if result.Count != 2 || result.Records[0]["name"] != "pen" || result.Records[1]["name"] != "mouse" {
+		t.Errorf("Expected pen and mouse, got %v", result.Records)
+	}
+
+	if _, err := store.QueryTopNPerGroup("products", "missing", "price", "asc", 1, nil); err == nil {
+		t.Errorf("Expected an error for a missing group column")
+	}
+	if _, err := store.QueryTopNPerGroup("products", "category", "price", "up", 1, nil); err == nil {
+		t.Errorf("Expected an error for an invalid sort order")
+	}
+}
@@ -0,0 +1,207 @@
+package csvstore
+
+import (
+	"context"
+	"time"
+)
+
+// Span attribute keys set on the spans of traced operations
+const (
+	SpanAttrTable      = "csvstore.table"
+	SpanAttrConditions = "csvstore.conditions"
+	SpanAttrRows       = "csvstore.rows"
+	SpanAttrDuration   = "csvstore.duration"
+)
+
+// tracedOperations lists the operations run inside a span when a tracer is set
+var tracedOperations = map[string]bool{
+	OpQuery:            true,
+	OpSelect:           true,
+	OpQueryWithOptions: true,
+	OpInsert:           true,
+	OpUpdate:           true,
+	OpDelete:           true,
+}
+
+// Tracer starts spans around store operations. Implement it to report store
+// latency to a tracing system; the tracing package adapts OpenTelemetry.
+type Tracer interface {
+	// Start starts a span named name as a child of the span in ctx, if any
+	Start(ctx context.Context, name string) Span
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	SetAttribute(key string, value any)
+	// End ends the span, recording err when the operation failed
+	End(err error)
+}
+
+// WithTracer runs Query, Select, QueryWithOptions, Insert, Update, and Delete
+// inside spans of the given tracer. The spans carry the table, the number of
+// conditions, the number of rows matched or inserted, and the duration.
+// Operations run through WithContext are children of the span in its context.
+func WithTracer(tracer Tracer) Option {
+	return func(cs *CSVStore) {
+		cs.tracer = tracer
+	}
+}
+
+// startSpan starts the span of an operation, or returns nil when the store has
+// no tracer or the operation is not traced
+func (cs *CSVStore) startSpan(op *Operation) Span {
+	if cs.tracer == nil || !tracedOperations[op.Name] {
+		return nil
+	}
+	ctx := op.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	span := cs.tracer.Start(ctx, "csvstore."+op.Name)
+	span.SetAttribute(SpanAttrTable, op.Table)
+	span.SetAttribute(SpanAttrConditions, len(payloadConditions(op.Payload)))
+	return span
+}
+
+// endSpan records the outcome of an operation on its span
+func endSpan(span Span, result any, duration time.Duration, err error) {
+	if span == nil {
+		return
+	}
+	if err == nil {
+		switch result := result.(type) {
+		case *QueryResult:
+			rows := result.Count
+			if result.Records != nil {
+				rows = len(result.Records)
+			}
+			span.SetAttribute(SpanAttrRows, rows)
+		case CSVRecord:
+			span.SetAttribute(SpanAttrRows, 1)
+		}
+	}
+	span.SetAttribute(SpanAttrDuration, duration)
+	span.End(err)
+}
+
+// payloadConditions returns the conditions of an operation payload
+func payloadConditions(payload any) []QueryCondition {
+	switch payload := payload.(type) {
+	case []QueryCondition:
+		return payload
+	case SelectPayload:
+		return payload.Conditions
+	case UpdatePayload:
+		return payload.Conditions
+	case QueryOptions:
+		return payload.Conditions
+	}
+	return nil
+}
+
+// ContextView runs operations with a context, so their spans join the trace
+// of the caller (see WithTracer)
+type ContextView struct {
+	cs  *CSVStore
+	ctx context.Context
+}
+
+// WithContext returns a view of the store whose operations carry ctx in
+// Operation.Context
+func (cs *CSVStore) WithContext(ctx context.Context) *ContextView {
+	return &ContextView{cs: cs, ctx: ctx}
+}
+
+// Query executes a query on the CSV table
+func (v *ContextView) Query(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	op := Operation{Name: OpQuery, Table: tableName, Payload: conditions, Context: v.ctx}
+	return runOperation(v.cs, op, func() (*QueryResult, error) {
+		v.cs.mu.RLock()
+		defer v.cs.mu.RUnlock()
+
+		result, err := v.cs.query(tableName, conditions)
+		if err != nil {
+			return nil, err
+		}
+		return v.cs.maskResult(tableName, result), nil
+	})
+}
+
+// Select retrieves specific columns from query results
+func (v *ContextView) Select(
+	tableName string,
+	columns []string,
+	conditions []QueryCondition,
+) (*QueryResult, error) {
+	op := Operation{
+		Name:    OpSelect,
+		Table:   tableName,
+		Payload: SelectPayload{Columns: columns, Conditions: conditions},
+		Context: v.ctx,
+	}
+	return runOperation(v.cs, op, func() (*QueryResult, error) {
+		v.cs.mu.RLock()
+		defer v.cs.mu.RUnlock()
+
+		result, err := v.cs.query(tableName, conditions)
+		if err != nil {
+			return nil, err
+		}
+		// Mask before projecting, so computed columns see masked values
+		return v.cs.projectColumns(tableName, v.cs.maskResult(tableName, result), columns)
+	})
+}
+
+// Insert adds a new record to the table
+func (v *ContextView) Insert(tableName string, record CSVRecord) (CSVRecord, error) {
+	op := Operation{Name: OpInsert, Table: tableName, Payload: record, Context: v.ctx}
+	return runOperation(v.cs, op, func() (CSVRecord, error) {
+		v.cs.mu.Lock()
+		defer v.cs.mu.Unlock()
+
+		return v.cs.insert(tableName, record)
+	})
+}
+
+// Update updates records matching conditions
+func (v *ContextView) Update(
+	tableName string,
+	updates CSVRecord,
+	conditions []QueryCondition,
+) (*QueryResult, error) {
+	op := Operation{
+		Name:    OpUpdate,
+		Table:   tableName,
+		Payload: UpdatePayload{Updates: updates, Conditions: conditions},
+		Context: v.ctx,
+	}
+	return runOperation(v.cs, op, func() (*QueryResult, error) {
+		v.cs.mu.Lock()
+		defer v.cs.mu.Unlock()
+
+		return v.cs.update(tableName, updates, conditions)
+	})
+}
+
+// Delete removes records matching conditions
+func (v *ContextView) Delete(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	op := Operation{Name: OpDelete, Table: tableName, Payload: conditions, Context: v.ctx}
+	return runOperation(v.cs, op, func() (*QueryResult, error) {
+		v.cs.mu.Lock()
+		defer v.cs.mu.Unlock()
+
+		return v.cs.delete(tableName, conditions)
+	})
+}
+
+// QueryWithOptions executes a query on the CSV table, then sorts, pages, and
+// projects the matching records according to opts
+func (v *ContextView) QueryWithOptions(tableName string, opts QueryOptions) (*QueryResult, error) {
+	op := Operation{Name: OpQueryWithOptions, Table: tableName, Payload: opts, Context: v.ctx}
+	return runOperation(v.cs, op, func() (*QueryResult, error) {
+		v.cs.mu.RLock()
+		defer v.cs.mu.RUnlock()
+
+		return v.cs.queryWithOptions(tableName, opts)
+	})
+}
@@ -0,0 +1,67 @@
+// Package tracing adapts an OpenTelemetry tracer to csvstore.Tracer, so store
+// operations show up as spans in existing traces.
+//
+//	store, err := csvstore.NewCSVStore(dir, csvstore.WithTracer(tracing.NewTracer(otel.Tracer("csvstore"))))
+//	...
+//	result, err := store.WithContext(ctx).Query("users", conditions)
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jiyeol-lee/csvstore"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer starts OpenTelemetry spans
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a csvstore.Tracer starting spans with t
+func NewTracer(t trace.Tracer) csvstore.Tracer {
+	return &tracer{tracer: t}
+}
+
+func (t *tracer) Start(ctx context.Context, name string) csvstore.Span {
+	_, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal))
+	return &otelSpan{span: span}
+}
+
+// otelSpan records attributes and errors on an OpenTelemetry span
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SetAttribute(key string, value any) {
+	s.span.SetAttributes(attributeOf(key, value))
+}
+
+func (s *otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// attributeOf converts an attribute value set by the store
+func attributeOf(key string, value any) attribute.KeyValue {
+	switch value := value.(type) {
+	case string:
+		return attribute.String(key, value)
+	case int:
+		return attribute.Int(key, value)
+	case bool:
+		return attribute.Bool(key, value)
+	case time.Duration:
+		// Durations are recorded in milliseconds, the unit of most trace viewers
+		return attribute.Float64(key+"_ms", float64(value)/float64(time.Millisecond))
+	default:
+		return attribute.String(key, fmt.Sprint(value))
+	}
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jiyeol-lee/csvstore"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+
+	store := csvstore.NewMemoryStore(csvstore.WithTracer(NewTracer(provider.Tracer("csvstore"))))
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	view := store.WithContext(ctx)
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := view.Insert("users", csvstore.CSVRecord{"name": name}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	conditions := []csvstore.QueryCondition{{Column: "name", Operator: "=", Value: "bob"}}
+	if _, err := view.Query("users", conditions); err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if _, err := view.Query("missing", nil); err == nil {
+		t.Fatal("Expected error querying a missing table")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 5 {
+		t.Fatalf("Expected 5 spans, got %d", len(spans))
+	}
+	query := spans[2]
+	if query.Name() != "csvstore.Query" || query.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Expected Query span under the request span, got %s", query.Name())
+	}
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range query.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	if attrs[csvstore.SpanAttrTable].AsString() != "users" ||
+		attrs[csvstore.SpanAttrConditions].AsInt64() != 1 ||
+		attrs[csvstore.SpanAttrRows].AsInt64() != 1 {
+		t.Errorf("Unexpected Query span attributes: %v", query.Attributes())
+	}
+	if _, ok := attrs[csvstore.SpanAttrDuration+"_ms"]; !ok {
+		t.Errorf("Expected a duration attribute, got %v", query.Attributes())
+	}
+
+	failed := spans[3]
+	if failed.Status().Code != codes.Error || len(failed.Events()) != 1 {
+		t.Errorf("Expected the failed query to record an error, got %+v", failed.Status())
+	}
+}
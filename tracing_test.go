@@ -0,0 +1,119 @@
+package csvstore
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+type traceKey struct{}
+
+type testSpan struct {
+	name   string
+	parent any
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+func (s *testSpan) SetAttribute(key string, value any) {
+	s.attrs[key] = value
+}
+
+func (s *testSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) Span {
+	span := &testSpan{name: name, parent: ctx.Value(traceKey{}), attrs: make(map[string]any)}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+func TestTracer(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	tracer := &testTracer{}
+	store, err := NewCSVStore(testDir, WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("users", []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if _, err := store.Insert("users", CSVRecord{"name": name}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "request")
+	view := store.WithContext(ctx)
+	conditions := []QueryCondition{
+		{Column: "name", Operator: "!=", Value: "bob"},
+		{Column: "id", Operator: "is_not_null"},
+	}
+	if _, err := view.Query("users", conditions); err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if _, err := view.Update("users", CSVRecord{"name": "dave"}, conditions[:1]); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	if _, err := store.Delete("missing", nil); err == nil {
+		t.Fatal("Expected error deleting from a missing table")
+	}
+	if _, err := store.ListTables(); err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+
+	// CreateTable and ListTables are not traced
+	if len(tracer.spans) != 6 {
+		t.Fatalf("Expected 6 spans, got %d", len(tracer.spans))
+	}
+	for _, span := range tracer.spans {
+		if !span.ended {
+			t.Errorf("Expected span %s to be ended", span.name)
+		}
+		if duration, _ := span.attrs[SpanAttrDuration].(time.Duration); duration <= 0 {
+			t.Errorf("Expected a duration on span %s, got %v", span.name, span.attrs[SpanAttrDuration])
+		}
+	}
+
+	insert := tracer.spans[0]
+	if insert.name != "csvstore.Insert" || insert.attrs[SpanAttrTable] != "users" || insert.attrs[SpanAttrRows] != 1 {
+		t.Errorf("Unexpected Insert span: %+v", insert)
+	}
+	if insert.parent != nil {
+		t.Errorf("Expected a root span outside WithContext, got parent %v", insert.parent)
+	}
+
+	query := tracer.spans[3]
+	if query.name != "csvstore.Query" || query.parent != "request" {
+		t.Errorf("Expected Query span under the request, got %+v", query)
+	}
+	if query.attrs[SpanAttrConditions] != 2 || query.attrs[SpanAttrRows] != 2 {
+		t.Errorf("Expected 2 conditions and 2 rows, got %v", query.attrs)
+	}
+
+	update := tracer.spans[4]
+	if update.name != "csvstore.Update" || update.attrs[SpanAttrConditions] != 1 || update.attrs[SpanAttrRows] != 2 {
+		t.Errorf("Unexpected Update span: %+v", update)
+	}
+
+	del := tracer.spans[5]
+	if del.name != "csvstore.Delete" || del.err == nil {
+		t.Errorf("Expected failed Delete span, got %+v", del)
+	}
+	if _, ok := del.attrs[SpanAttrRows]; ok {
+		t.Errorf("Expected no rows on a failed span, got %v", del.attrs)
+	}
+}
@@ -0,0 +1,142 @@
+package csvstore
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// maxTriggerDepth bounds how deeply triggers may cascade into each other
+const maxTriggerDepth = 8
+
+// Trigger declares a row to insert into another table when a row of the
+// configured table changes, e.g. "when an order gets status=shipped, insert
+// a row into shipments". Triggers run in the same write cycle, while the
+// store is still locked, right after the triggering change has been written.
+// Triggers are not atomic with that change: when one fails, the triggering
+// change stays written and the write returns a *TriggerError.
+type Trigger struct {
+	Name string
+	// Events lists the changes that fire the trigger (ChangeInsert, ChangeUpdate,
+	// ChangeDelete). An empty list fires on every change.
+	Events []string
+	// When holds the conditions the row must match. For ChangeUpdate the trigger
+	// fires only when the updated row matches and the row before the update did
+	// not; for ChangeDelete the deleted row is matched.
+	When []QueryCondition
+	// InsertInto is the table receiving the new row
+	InsertInto string
+	// Values holds literal values of the new row
+	Values CSVRecord
+	// CopyColumns maps columns of the new row to columns of the triggering row
+	CopyColumns map[string]string
+}
+
+// TriggerError reports a trigger that failed after the change firing it had
+// already been written. The triggering write succeeded; only the rows of the
+// failed trigger, and of the triggers after it, are missing.
+type TriggerError struct {
+	Table   string
+	Trigger string
+	Err     error
+}
+
+func (e *TriggerError) Error() string {
+	return fmt.Sprintf("trigger %s on table %s failed after the change to %s was written: %v",
+		e.Trigger, e.Table, e.Table, e.Err)
+}
+
+func (e *TriggerError) Unwrap() error {
+	return e.Err
+}
+
+// WithTrigger registers a trigger on the table
+func WithTrigger(trigger Trigger) TableOption {
+	return func(c *tableConfig) {
+		c.triggers = append(c.triggers, trigger)
+	}
+}
+
+// fires reports whether the trigger fires for a change, evaluating its conditions with matcher
+func (cs *CSVStore) fires(
+	matcher *conditionMatcher,
+	trigger Trigger,
+	event string,
+	before, after CSVRecord,
+) bool {
+	if len(trigger.Events) > 0 && !slices.Contains(trigger.Events, event) {
+		return false
+	}
+
+	switch event {
+	case ChangeInsert:
+		return matcher.matchesConditions(after, trigger.When)
+	case ChangeUpdate:
+		if len(trigger.When) == 0 {
+			return true
+		}
+		return matcher.matchesConditions(after, trigger.When) &&
+			!matcher.matchesConditions(before, trigger.When)
+	case ChangeDelete:
+		return matcher.matchesConditions(before, trigger.When)
+	default:
+		return false
+	}
+}
+
+// runTriggers runs the triggers of a table for a set of changes. before and after
+// hold the row versions before and after each change; one of them is nil for
+// inserts and deletes. The changes must already be written; a failing trigger
+// returns a *TriggerError.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) runTriggers(
+	tableName string,
+	config *tableConfig,
+	event string,
+	before, after []CSVRecord,
+) error {
+	if len(config.triggers) == 0 {
+		return nil
+	}
+	if cs.triggerDepth >= maxTriggerDepth {
+		return fmt.Errorf("triggers on table %s exceed the maximum depth of %d", tableName, maxTriggerDepth)
+	}
+
+	cs.triggerDepth++
+	defer func() { cs.triggerDepth-- }()
+
+	matcher := cs.newConditionMatcher(tableName)
+	count := max(len(before), len(after))
+	for i := range count {
+		var beforeRecord, afterRecord CSVRecord
+		if i < len(before) {
+			beforeRecord = before[i]
+		}
+		if i < len(after) {
+			afterRecord = after[i]
+		}
+		source := afterRecord
+		if source == nil {
+			source = beforeRecord
+		}
+
+		for _, trigger := range config.triggers {
+			if !cs.fires(matcher, trigger, event, beforeRecord, afterRecord) {
+				continue
+			}
+
+			record := maps.Clone(trigger.Values)
+			if record == nil {
+				record = make(CSVRecord)
+			}
+			for target, column := range trigger.CopyColumns {
+				record[target] = source[column]
+			}
+			if _, err := cs.insert(trigger.InsertInto, record); err != nil {
+				return &TriggerError{Table: tableName, Trigger: trigger.Name, Err: err}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package csvstore
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestTriggers(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	if err := store.CreateTable("orders", []string{"id", "status"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := store.CreateTable("shipments", []string{"id", "order_id", "carrier"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	store.ConfigureTable("orders", WithTrigger(Trigger{
+		Name:        "ship_order",
+		Events:      []string{ChangeUpdate},
+		When:        []QueryCondition{{Column: "status", Operator: "=", Value: "shipped"}},
+		InsertInto:  "shipments",
+		Values:      CSVRecord{"carrier": "post"},
+		CopyColumns: map[string]string{"order_id": "id"},
+	}))
+
+	if _, err := store.Insert("orders", CSVRecord{"id": "1", "status": "new"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Insert("orders", CSVRecord{"id": "2", "status": "new"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	order1 := []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}
+	if _, err := store.Update("orders", CSVRecord{"status": "shipped"}, order1); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	// Updating an already shipped order must not fire again
+	if _, err := store.Update("orders", CSVRecord{"status": "shipped"}, order1); err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+
+	result, err := store.Query("shipments", nil)
+	if err != nil {
+		t.Fatalf("Failed to query shipments: %v", err)
+	}
+	if result.Count != 1 {
+		t.Fatalf("Expected 1 shipment, got %d", result.Count)
+	}
+	shipment := result.Records[0]
+	if shipment["order_id"] != "1" || shipment["carrier"] != "post" {
+		t.Errorf("Unexpected shipment row: %v", shipment)
+	}
+	if shipment["id"] == "" {
+		t.Error("Expected trigger insert to get a generated id")
+	}
+
+	// A trigger into a missing table fails the write cycle, but the triggering
+	// row stays written
+	store.ConfigureTable("orders", WithTrigger(Trigger{
+		Name:       "broken",
+		Events:     []string{ChangeInsert},
+		InsertInto: "missing",
+	}))
+	_, err = store.Insert("orders", CSVRecord{"id": "3", "status": "new"})
+	var triggerErr *TriggerError
+	if !errors.As(err, &triggerErr) {
+		t.Fatalf("Expected TriggerError from trigger inserting into a missing table, got %v", err)
+	}
+	if triggerErr.Table != "orders" || triggerErr.Trigger != "broken" {
+		t.Errorf("Unexpected trigger error: %v", triggerErr)
+	}
+	result, err = store.Query("orders", []QueryCondition{{Column: "id", Operator: "=", Value: "3"}})
+	if err != nil {
+		t.Fatalf("Failed to query orders: %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("Expected the triggering row to be written, got %d rows", result.Count)
+	}
+}
@@ -0,0 +1,140 @@
+package csvstore
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithTTL expires rows once the timestamp in column is older than ttl.
+// column defaults to the creation timestamp column when empty. Rows whose timestamp is empty or
+// not in RFC 3339 format never expire. Expired rows are removed by ExpireNow
+// or by a sweeper started with StartExpirySweeper.
+func WithTTL(ttl time.Duration, column string) TableOption {
+	return func(c *tableConfig) {
+		c.ttl = ttl
+		c.ttlColumn = column
+	}
+}
+
+// ExpireNow removes the expired rows of a table configured with WithTTL
+// and returns the removed records
+func (cs *CSVStore) ExpireNow(tableName string) (*QueryResult, error) {
+	return runOperation(cs, Operation{Name: OpExpireNow, Table: tableName},
+		func() (*QueryResult, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			return cs.expire(tableName, time.Now())
+		})
+}
+
+// expire removes the rows of a table that expired before now.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) expire(tableName string, now time.Time) (*QueryResult, error) {
+	config := cs.tableConfig(tableName)
+	if config.ttl <= 0 {
+		return nil, fmt.Errorf("no TTL configured for table %s", tableName)
+	}
+
+	column := config.ttlColumn
+	if column == "" {
+		column = config.reservedColumns().CreatedAt
+	}
+
+	cutoff := now.Add(-config.ttl)
+	return cs.deleteMatching(tableName, func(record CSVRecord) bool {
+		timestamp, err := time.Parse(time.RFC3339Nano, record[column])
+		return err == nil && timestamp.Before(cutoff)
+	})
+}
+
+// StartExpirySweeper removes expired rows from every table configured with WithTTL,
+// and partitions past the retention of WithRotation, once per interval, in the background. Errors are passed to onError when it is not nil.
+// The returned function stops the sweeper; closing the store stops it as well.
+// The interval must be positive.
+func (cs *CSVStore) StartExpirySweeper(interval time.Duration, onError func(error)) (func(), error) {
+	return cs.startPeriodic(interval, cs.sweepExpired, onError)
+}
+
+// startPeriodic calls run once per interval, in the background, passing its
+// errors to onError when it is not nil. The returned function stops it;
+// closing the store stops it as well.
+func (cs *CSVStore) startPeriodic(
+	interval time.Duration,
+	run func(now time.Time) error,
+	onError func(error),
+) (func(), error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval (%v) must be positive", interval)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				if err := run(now); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	stopRunning := func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+		})
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.closed.Load() {
+		go stopRunning()
+		return func() {}, nil
+	}
+	cs.addCloser(func() error {
+		stopRunning()
+		return nil
+	})
+
+	return stopRunning, nil
+}
+
+// sweepExpired removes expired rows from every table with a TTL, and expired
+// partitions from every table with a retention
+func (cs *CSVStore) sweepExpired(now time.Time) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	tables, err := cs.listTables()
+	if err != nil {
+		return err
+	}
+
+	for _, tableName := range tables {
+		if _, err := cs.applyRetention(tableName, now); err != nil {
+			return fmt.Errorf("failed to apply retention to table %s: %w", tableName, err)
+		}
+		if cs.tableConfig(tableName).ttl <= 0 {
+			continue
+		}
+		if _, err := cs.expire(tableName, now); err != nil {
+			return fmt.Errorf("failed to expire rows of table %s: %w", tableName, err)
+		}
+	}
+
+	return nil
+}
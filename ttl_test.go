@@ -0,0 +1,102 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExpireNow(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "sessions"
+	err = store.CreateTable(tableName, []string{"id", "token", "created_at"})
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	if _, err := store.ExpireNow(tableName); err == nil {
+		t.Error("Expected error when no TTL is configured")
+	}
+	store.ConfigureTable(tableName, WithTTL(time.Hour, ""))
+
+	old := time.Now().Add(-2 * time.Hour).Format(time.RFC3339Nano)
+	records := []CSVRecord{
+		{"id": "1", "token": "old", "created_at": old},
+		{"id": "2", "token": "fresh"},
+		{"id": "3", "token": "unparseable", "created_at": "yesterday"},
+	}
+	for _, record := range records {
+		if _, err := store.Insert(tableName, record); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	expired, err := store.ExpireNow(tableName)
+	if err != nil {
+		t.Fatalf("Failed to expire rows: %v", err)
+	}
+	if expired.Count != 1 || expired.Records[0]["token"] != "old" {
+		t.Errorf("Expected only the old session to expire, got %v", expired.Records)
+	}
+
+	result, err := store.Query(tableName, nil)
+	if err != nil {
+		t.Fatalf("Failed to query table: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected 2 remaining sessions, got %d", result.Count)
+	}
+}
+
+func TestExpirySweeper(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir, WithTableDefaults(WithTTL(time.Minute, "seen_at")))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "cache"
+	if err := store.CreateTable(tableName, []string{"id", "seen_at"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	old := time.Now().Add(-time.Hour).Format(time.RFC3339Nano)
+	if _, err := store.Insert(tableName, CSVRecord{"id": "1", "seen_at": old}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	stop, err := store.StartExpirySweeper(10*time.Millisecond, func(err error) {
+		t.Errorf("Sweeper failed: %v", err)
+	})
+	if err != nil {
+		t.Fatalf("Failed to start sweeper: %v", err)
+	}
+	defer stop()
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := store.StartExpirySweeper(interval, nil); err == nil {
+			t.Errorf("Expected error starting a sweeper with interval %v", interval)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		result, err := store.Query(tableName, nil)
+		if err != nil {
+			t.Fatalf("Failed to query table: %v", err)
+		}
+		if result.Count == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the sweeper to expire rows")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,149 @@
+package csvstore
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TypeConverter converts the cells of a column type to and from Go values
+type TypeConverter struct {
+	// Parse converts a cell to a Go value, and fails on cells that do not have
+	// the type
+	Parse func(value string) (any, error)
+	// Format converts a Go value to a cell. Without it, values are formatted
+	// from their kind (see RecordFromStruct).
+	Format func(value any) (string, error)
+	// Compare orders two parsed values and returns -1, 0, or 1. Without it,
+	// cells of the type are ordered like cells of untyped columns.
+	Compare func(a, b any) int
+}
+
+var (
+	typeRegistryMu sync.RWMutex
+	// typeRegistry holds the converters of column types by type
+	typeRegistry = map[ColumnType]TypeConverter{
+		TypeString: {
+			Parse: func(value string) (any, error) { return value, nil },
+		},
+		TypeInt: {
+			Parse:   func(value string) (any, error) { return strconv.ParseInt(value, 10, 64) },
+			Compare: func(a, b any) int { return cmp.Compare(a.(int64), b.(int64)) },
+		},
+		TypeFloat: {
+			Parse:   func(value string) (any, error) { return strconv.ParseFloat(value, 64) },
+			Compare: func(a, b any) int { return cmp.Compare(a.(float64), b.(float64)) },
+		},
+		TypeBool: {
+			Parse: func(value string) (any, error) { return strconv.ParseBool(value) },
+			Compare: func(a, b any) int {
+				return cmp.Compare(boolOrder(a.(bool)), boolOrder(b.(bool)))
+			},
+		},
+		TypeTime: {
+			Parse:   func(value string) (any, error) { return time.Parse(time.RFC3339Nano, value) },
+			Compare: func(a, b any) int { return a.(time.Time).Compare(b.(time.Time)) },
+		},
+	}
+)
+
+// RegisterType registers the converter of a column type, e.g. "money",
+// "duration", or "countrycode", replacing the converter of a built-in type with
+// the same name. Columns declared with the type (see WithColumnTypes) use it to
+// validate written values, order sorted queries, and parse values in
+// TypedValue and ScanRecords. The converter must have a Parse function.
+func RegisterType(columnType ColumnType, converter TypeConverter) {
+	if converter.Parse == nil {
+		panic(fmt.Sprintf("csvstore: converter of type %s has no Parse function", columnType))
+	}
+
+	typeRegistryMu.Lock()
+	defer typeRegistryMu.Unlock()
+
+	typeRegistry[columnType] = converter
+}
+
+// LookupType returns the converter of a column type, if it is built in or
+// registered with RegisterType
+func LookupType(columnType ColumnType) (TypeConverter, bool) {
+	typeRegistryMu.RLock()
+	defer typeRegistryMu.RUnlock()
+
+	converter, ok := typeRegistry[columnType]
+	return converter, ok
+}
+
+// boolOrder orders false before true
+func boolOrder(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// columnComparer returns the function comparing two cells of a column, which
+// returns -1, 0, or 1. Cells of a column declared with a type whose converter
+// has a Compare function are compared parsed, unless one does not parse. The
+// function must not be used concurrently.
+func (c *tableConfig) columnComparer(column string) func(a, b string) int {
+	compare := c.valueComparer()
+	converter, ok := LookupType(c.columnTypes[column])
+	if !ok || converter.Compare == nil {
+		return compare
+	}
+	return func(a, b string) int {
+		valA, errA := converter.Parse(a)
+		valB, errB := converter.Parse(b)
+		if errA != nil || errB != nil {
+			return compare(a, b)
+		}
+		return converter.Compare(valA, valB)
+	}
+}
+
+// TypedValue returns the value of a column of a record of a table, parsed by
+// the converter of the declared type of the column (see WithColumnTypes and
+// RegisterType). Cells of columns without a declared or registered type are
+// returned as strings, and null cells as nil.
+func (cs *CSVStore) TypedValue(tableName string, record CSVRecord, column string) (any, error) {
+	cs.mu.RLock()
+	config := cs.tableConfig(tableName)
+	column = cs.columnResolver(tableName)(column)
+	cs.mu.RUnlock()
+
+	value, exists := record[column]
+	if !exists || value == config.null {
+		return nil, nil
+	}
+	converter, ok := LookupType(config.columnTypes[column])
+	if !ok {
+		return value, nil
+	}
+	parsed, err := converter.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse column %s value %q as %s: %w",
+			column, value, config.columnTypes[column], err)
+	}
+	return parsed, nil
+}
+
+// FormatValue converts a Go value to a cell of a column of a table, like the
+// fields of structs in RecordFromStruct. nil is formatted as the null value.
+func (cs *CSVStore) FormatValue(tableName string, column string, value any) (string, error) {
+	cs.mu.RLock()
+	config := cs.tableConfig(tableName)
+	column = cs.columnResolver(tableName)(column)
+	cs.mu.RUnlock()
+
+	if value == nil {
+		return config.null, nil
+	}
+	cell, err := config.formatField(reflect.ValueOf(value), column)
+	if err != nil {
+		return "", fmt.Errorf("failed to format column %s: %w", column, err)
+	}
+	return cell, nil
+}
@@ -0,0 +1,100 @@
+package csvstore
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// money is a test column type holding amounts like "USD 12.50", parsed to cents
+const money ColumnType = "money"
+
+func init() {
+	RegisterType(money, TypeConverter{
+		Parse: func(value string) (any, error) {
+			amount, ok := strings.CutPrefix(value, "USD ")
+			if !ok {
+				return nil, fmt.Errorf("amount %q is not in USD", value)
+			}
+			f, err := strconv.ParseFloat(amount, 64)
+			if err != nil {
+				return nil, err
+			}
+			return int64(f*100 + 0.5), nil
+		},
+		Format: func(value any) (string, error) {
+			cents, ok := value.(int64)
+			if !ok {
+				return "", fmt.Errorf("amount of type %T is not in cents", value)
+			}
+			return fmt.Sprintf("USD %d.%02d", cents/100, cents%100), nil
+		},
+		Compare: func(a, b any) int { return cmp.Compare(a.(int64), b.(int64)) },
+	})
+}
+
+func TestTypeConverters(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+	store.ConfigureTable("orders", WithColumnTypes(ColumnDef{Name: "total", Type: money}))
+
+	if err := store.CreateTable("orders", []string{"id", "total"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, total := range []string{"USD 9.50", "USD 10.00", "USD 2.25"} {
+		if _, err := store.Insert("orders", CSVRecord{"total": total}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+	if _, err := store.Insert("orders", CSVRecord{"total": "EUR 3.00"}); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch inserting a total in EUR, got %v", err)
+	}
+
+	// Sorting orders the parsed values
+	result, err := store.QueryWithOptions("orders", QueryOptions{SortField: "total", SortBy: "desc"})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	totals := make([]string, len(result.Records))
+	for i, record := range result.Records {
+		totals[i] = record["total"]
+	}
+	if strings.Join(totals, ",") != "USD 10.00,USD 9.50,USD 2.25" {
+		t.Errorf("Expected totals ordered by amount, got %v", totals)
+	}
+
+	value, err := store.TypedValue("orders", result.Records[0], "total")
+	if err != nil {
+		t.Fatalf("Failed to get typed value: %v", err)
+	}
+	if value != int64(1000) {
+		t.Errorf("Expected 1000 cents, got %v (%T)", value, value)
+	}
+	if value, err := store.TypedValue("orders", result.Records[0], "id"); err != nil || value != result.Records[0]["id"] {
+		t.Errorf("Expected the untyped id as is, got %v, %v", value, err)
+	}
+	if _, err := store.TypedValue("orders", CSVRecord{"total": "free"}, "total"); err == nil {
+		t.Error("Expected error parsing an invalid total")
+	}
+
+	cell, err := store.FormatValue("orders", "total", int64(1999))
+	if err != nil || cell != "USD 19.99" {
+		t.Errorf("Expected USD 19.99, got %q, %v", cell, err)
+	}
+	if cell, err := store.FormatValue("orders", "id", 42); err != nil || cell != "42" {
+		t.Errorf("Expected 42, got %q, %v", cell, err)
+	}
+	if _, err := store.FormatValue("orders", "total", 19.99); err == nil {
+		t.Error("Expected error formatting a float total")
+	}
+
+	if _, ok := LookupType(money); !ok {
+		t.Error("Expected the money type to be registered")
+	}
+	if _, ok := LookupType("unknown"); ok {
+		t.Error("Expected the unknown type not to be registered")
+	}
+}
@@ -0,0 +1,52 @@
+package csvstore
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Validator enforces rules on the records written to a table, such as an
+// end_date after the start_date. Returning an error vetoes the whole write
+// operation before anything is written.
+//
+// Validators receive copies of the records, after BeforeInsert and
+// BeforeUpdate hooks ran and before hashed columns are hashed. They run while
+// the store is locked and must not call back into the store.
+type Validator interface {
+	// ValidateInsert checks a record about to be inserted, with the ids and
+	// timestamps the store fills in
+	ValidateInsert(record CSVRecord) error
+	// ValidateUpdate checks a record about to be updated, with all of its
+	// columns, given the record before the update
+	ValidateUpdate(original, updated CSVRecord) error
+}
+
+// WithValidator registers a validator of the records written to a table.
+// Validators run in registration order.
+func WithValidator(validator Validator) TableOption {
+	return func(c *tableConfig) {
+		c.validators = append(c.validators, validator)
+	}
+}
+
+// validateInsert runs the validators of a table against a record to insert,
+// stopping at the first error
+func (c *tableConfig) validateInsert(tableName string, record CSVRecord) error {
+	for _, validator := range c.validators {
+		if err := validator.ValidateInsert(maps.Clone(record)); err != nil {
+			return fmt.Errorf("validation failed for table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// validateUpdate runs the validators of a table against an updated record,
+// stopping at the first error
+func (c *tableConfig) validateUpdate(tableName string, original, updated CSVRecord) error {
+	for _, validator := range c.validators {
+		if err := validator.ValidateUpdate(maps.Clone(original), maps.Clone(updated)); err != nil {
+			return fmt.Errorf("validation failed for table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
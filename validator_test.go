@@ -0,0 +1,64 @@
+package csvstore
+
+import (
+	"errors"
+	"testing"
+)
+
+var errEndBeforeStart = errors.New("end_date is before start_date")
+
+// dateRangeValidator requires end_date not to be before start_date, and the
+// start of a booking not to change once confirmed
+type dateRangeValidator struct{}
+
+func (dateRangeValidator) ValidateInsert(record CSVRecord) error {
+	if record["end_date"] < record["start_date"] {
+		return errEndBeforeStart
+	}
+	return nil
+}
+
+func (v dateRangeValidator) ValidateUpdate(original, updated CSVRecord) error {
+	if original["status"] == "confirmed" && updated["start_date"] != original["start_date"] {
+		return errors.New("start_date of a confirmed booking cannot change")
+	}
+	return v.ValidateInsert(updated)
+}
+
+func TestValidator(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+	store.ConfigureTable("bookings", WithValidator(dateRangeValidator{}))
+
+	if err := store.CreateTable("bookings", []string{"id", "start_date", "end_date", "status"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	booking := CSVRecord{"id": "1", "start_date": "2024-06-01", "end_date": "2024-06-05", "status": "confirmed"}
+	if _, err := store.Insert("bookings", booking); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	invalid := CSVRecord{"id": "2", "start_date": "2024-06-10", "end_date": "2024-06-01"}
+	if _, err := store.Insert("bookings", invalid); !errors.Is(err, errEndBeforeStart) {
+		t.Errorf("Expected errEndBeforeStart inserting an invalid booking, got %v", err)
+	}
+
+	// Updates are validated with all columns of the record
+	conditions := []QueryCondition{{Column: "id", Operator: "=", Value: "1"}}
+	if _, err := store.Update("bookings", CSVRecord{"end_date": "2024-05-01"}, conditions); !errors.Is(err, errEndBeforeStart) {
+		t.Errorf("Expected errEndBeforeStart moving the end before the start, got %v", err)
+	}
+	if _, err := store.Update("bookings", CSVRecord{"start_date": "2024-06-02"}, conditions); err == nil {
+		t.Error("Expected error moving the start of a confirmed booking")
+	}
+	if _, err := store.Update("bookings", CSVRecord{"end_date": "2024-06-07"}, conditions); err != nil {
+		t.Errorf("Failed to update record: %v", err)
+	}
+
+	result, err := store.Query("bookings", nil)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if result.Count != 1 || result.Records[0]["end_date"] != "2024-06-07" {
+		t.Errorf("Expected only the valid writes, got %v", result.Records)
+	}
+}
@@ -0,0 +1,89 @@
+package csvstore
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// MaterializedView is a query over one or more tables whose result is persisted
+// as a table of its own, so expensive queries such as aggregations can be read
+// cheaply. The view is queried like any other table.
+type MaterializedView struct {
+	Name    string   // Name of the table holding the result
+	Headers []string // Columns of the result
+	// Sources lists the tables the view reads. With RefreshOnWrite, inserts,
+	// updates, and deletes on any of them refresh the view.
+	Sources []string
+	// Query computes the rows of the view. Columns missing from Headers are dropped.
+	Query func(r *ViewReader) ([]CSVRecord, error)
+	// RefreshOnWrite refreshes the view in the same operation as every write to
+	// its sources. Otherwise the view is refreshed only by Refresh.
+	RefreshOnWrite bool
+}
+
+// ViewReader reads the source tables of a materialized view while it is refreshed
+type ViewReader struct {
+	cs *CSVStore
+}
+
+// Query executes a query on a table
+func (r *ViewReader) Query(tableName string, conditions []QueryCondition) (*QueryResult, error) {
+	return r.cs.query(tableName, conditions)
+}
+
+// Select retrieves specific columns of the records of a table matching conditions
+func (r *ViewReader) Select(tableName string, columns []string, conditions []QueryCondition) (*QueryResult, error) {
+	return r.cs.selectColumns(tableName, columns, conditions)
+}
+
+// WithMaterializedView registers a materialized view. Its table is written by
+// the first refresh, either Refresh or a write to a source with RefreshOnWrite.
+func WithMaterializedView(view MaterializedView) Option {
+	return func(cs *CSVStore) {
+		if cs.views == nil {
+			cs.views = make(map[string]MaterializedView)
+		}
+		cs.views[view.Name] = view
+	}
+}
+
+// Refresh recomputes a materialized view and rewrites its table
+func (cs *CSVStore) Refresh(viewName string) error {
+	_, err := runOperation(cs, Operation{Name: OpRefresh, Table: viewName},
+		func() (any, error) {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+
+			view, ok := cs.views[viewName]
+			if !ok {
+				return nil, fmt.Errorf("materialized view %s is not registered", viewName)
+			}
+			return nil, cs.refresh(view)
+		})
+	return err
+}
+
+// refresh recomputes a materialized view.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) refresh(view MaterializedView) error {
+	records, err := view.Query(&ViewReader{cs: cs})
+	if err != nil {
+		return fmt.Errorf("failed to refresh materialized view %s: %w", view.Name, err)
+	}
+	return cs.saveTable(view.Name, view.Headers, records)
+}
+
+// refreshViews refreshes the materialized views reading a table that refresh on write.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) refreshViews(tableName string) error {
+	for _, name := range slices.Sorted(maps.Keys(cs.views)) {
+		view := cs.views[name]
+		if view.RefreshOnWrite && slices.Contains(view.Sources, tableName) {
+			if err := cs.refresh(view); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package csvstore
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestMaterializedView(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	totals := func(r *ViewReader) ([]CSVRecord, error) {
+		orders, err := r.Query("orders", nil)
+		if err != nil {
+			return nil, err
+		}
+		sums := make(map[string]int)
+		var customers []string
+		for _, order := range orders.Records {
+			amount, err := strconv.Atoi(order["amount"])
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := sums[order["customer"]]; !ok {
+				customers = append(customers, order["customer"])
+			}
+			sums[order["customer"]] += amount
+		}
+		records := make([]CSVRecord, 0, len(customers))
+		for _, customer := range customers {
+			records = append(records, CSVRecord{"customer": customer, "total": strconv.Itoa(sums[customer])})
+		}
+		return records, nil
+	}
+
+	store, err := NewCSVStore(testDir,
+		WithMaterializedView(MaterializedView{
+			Name:    "order_totals",
+			Headers: []string{"customer", "total"},
+			Sources: []string{"orders"},
+			Query:   totals,
+		}),
+		WithMaterializedView(MaterializedView{
+			Name:           "live_totals",
+			Headers:        []string{"customer", "total"},
+			Sources:        []string{"orders"},
+			Query:          totals,
+			RefreshOnWrite: true,
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateTable("orders", []string{"id", "customer", "amount"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for _, order := range []CSVRecord{
+		{"customer": "alice", "amount": "10"},
+		{"customer": "bob", "amount": "5"},
+		{"customer": "alice", "amount": "7"},
+	} {
+		if _, err := store.Insert("orders", order); err != nil {
+			t.Fatalf("Failed to insert order: %v", err)
+		}
+	}
+
+	if store.CheckTableExists("order_totals") {
+		t.Errorf("Expected the view without refresh on write to wait for Refresh")
+	}
+	if err := store.Refresh("order_totals"); err != nil {
+		t.Fatalf("Failed to refresh view: %v", err)
+	}
+	for _, view := range []string{"order_totals", "live_totals"} {
+		result, err := store.Query(view, []QueryCondition{{Column: "customer", Operator: "=", Value: "alice"}})
+		if err != nil {
+			t.Fatalf("Failed to query view %s: %v", view, err)
+		}
+		if result.Count != 1 || result.Records[0]["total"] != "17" {
+			t.Errorf("Expected alice's total of 17 in %s, got %v", view, result.Records)
+		}
+	}
+
+	_, err = store.Delete("orders", []QueryCondition{{Column: "amount", Operator: "=", Value: "10"}})
+	if err != nil {
+		t.Fatalf("Failed to delete order: %v", err)
+	}
+	live, err := store.Query("live_totals", []QueryCondition{{Column: "customer", Operator: "=", Value: "alice"}})
+	if err != nil {
+		t.Fatalf("Failed to query view: %v", err)
+	}
+	if live.Records[0]["total"] != "7" {
+		t.Errorf("Expected the view to be refreshed on write, got %v", live.Records)
+	}
+	stale, err := store.Query("order_totals", []QueryCondition{{Column: "customer", Operator: "=", Value: "alice"}})
+	if err != nil {
+		t.Fatalf("Failed to query view: %v", err)
+	}
+	if stale.Records[0]["total"] != "17" {
+		t.Errorf("Expected the view to keep its last refresh, got %v", stale.Records)
+	}
+
+	if err := store.Refresh("missing"); err == nil {
+		t.Errorf("Expected an error for an unregistered view")
+	}
+}
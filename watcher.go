@@ -0,0 +1,163 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ChangeExternal is the ChangeEvent operation emitted when a table file
+// is modified outside the store, e.g. by a spreadsheet or text editor.
+// External change events carry no record.
+const ChangeExternal = "external"
+
+// fileState identifies a version of a table file written by the store
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// statFile returns the current state of a file of fsys
+func statFile(fsys FS, name string) fileState {
+	info, err := fsys.Stat(name)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// WatchExternalChanges starts watching the store directory for table files modified
+// by other programs. Every external modification is delivered to subscribers (see
+// Subscribe) as a ChangeEvent with the ChangeExternal operation.
+// The returned function stops the watcher; closing the store stops it as well.
+func (cs *CSVStore) WatchExternalChanges() (func() error, error) {
+	local, ok := cs.fs.(*osFS)
+	if !ok {
+		return nil, errors.New("watching external changes requires the local file system")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(local.root); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch storage directory: %w", err)
+	}
+
+	cs.mu.Lock()
+	if cs.closed.Load() {
+		cs.mu.Unlock()
+		watcher.Close()
+		return nil, ErrClosed
+	}
+	if cs.fileStates == nil {
+		tables, err := cs.listTables()
+		if err != nil {
+			cs.mu.Unlock()
+			watcher.Close()
+			return nil, err
+		}
+		cs.fileStates = make(map[string]fileState)
+		for _, tableName := range tables {
+			cs.trackWrite(tableName)
+		}
+	}
+	cs.watchers++
+	cs.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				cs.handleFileEvent(event)
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop := func() error {
+		var err error
+		once.Do(func() {
+			err = watcher.Close()
+			<-done
+
+			cs.mu.Lock()
+			cs.watchers--
+			if cs.watchers == 0 {
+				cs.fileStates = nil
+			}
+			cs.mu.Unlock()
+		})
+		return err
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.closed.Load() {
+		// Closed while starting; stop needs the lock
+		go stop()
+		return nil, ErrClosed
+	}
+	cs.addCloser(stop)
+
+	return stop, nil
+}
+
+// handleFileEvent checks whether a file system event comes from an external modification
+func (cs *CSVStore) handleFileEvent(event fsnotify.Event) {
+	name := filepath.Base(event.Name)
+	tableName, ok := tableNameFromFile(name)
+	if !ok {
+		return
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.fileStates == nil {
+		return
+	}
+
+	current := statFile(cs.fs, name)
+	if current == cs.fileStates[tableName] {
+		// Written by the store itself, or already reported
+		return
+	}
+	cs.fileStates[tableName] = current
+	cs.queryCache.invalidate(tableName)
+
+	cs.notifySubscribers([]ChangeEvent{{
+		Operation: ChangeExternal,
+		Table:     tableName,
+		Timestamp: time.Now(),
+	}})
+}
+
+// trackWrite remembers the state of a table file written by the store, so the
+// watcher can tell the store's own writes from external modifications, and
+// updates its checksum.
+// The caller must hold cs.mu for writing.
+func (cs *CSVStore) trackWrite(tableName string) {
+	cs.updateChecksum(tableName)
+	cs.queryCache.invalidate(tableName)
+	if cs.fileStates == nil {
+		return
+	}
+	cs.fileStates[tableName] = statFile(cs.fs, cs.getTableFile(tableName))
+}
@@ -0,0 +1,79 @@
+package csvstore
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatchExternalChanges(t *testing.T) {
+	testDir := getTestDir()
+	store, err := NewCSVStore(testDir, WithQueryCache(10))
+	if err != nil {
+		t.Fatalf("Failed to create CSVStore: %v", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	tableName := "users"
+	if err := store.CreateTable(tableName, []string{"id", "name"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	stop, err := store.WatchExternalChanges()
+	if err != nil {
+		t.Fatalf("Failed to start watcher: %v", err)
+	}
+	defer stop()
+
+	events, cancel := store.Subscribe(tableName)
+	defer cancel()
+
+	// Writes made by the store are not reported as external
+	if _, err := store.Insert(tableName, CSVRecord{"id": "1", "name": "John"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if event := <-events; event.Operation != ChangeInsert {
+		t.Fatalf("Expected insert event, got %s", event.Operation)
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("Expected no event for the store's own write, got %s", event.Operation)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Cache the result so the external edit must invalidate it
+	if result, err := store.Query(tableName, nil); err != nil || result.Count != 1 {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+
+	// Simulate an edit made in another program
+	file, err := os.OpenFile(store.GetTablePath(tableName), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open table file: %v", err)
+	}
+	if _, err := file.WriteString("2,Jane\n"); err != nil {
+		t.Fatalf("Failed to write table file: %v", err)
+	}
+	file.Close()
+
+	select {
+	case event := <-events:
+		if event.Operation != ChangeExternal || event.Table != tableName {
+			t.Errorf("Expected external change on %s, got %s on %s", tableName, event.Operation, event.Table)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for external change event")
+	}
+
+	result, err := store.Query(tableName, nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected 2 records after the external change, got %d", result.Count)
+	}
+
+	if err := stop(); err != nil {
+		t.Errorf("Failed to stop watcher: %v", err)
+	}
+}
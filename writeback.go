@@ -0,0 +1,362 @@
+package csvstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithWriteBack keeps the files of the store in memory and writes changed files
+// to disk once per interval and when the store is closed, trading durability for
+// speed on tables with a high write rate, such as metrics buffers. Changes made
+// since the last flush are lost if the process dies. An interval of zero flushes
+// only on Flush and Close. Errors of background flushes are passed to onError when
+// it is not nil; failed files are retried on the next flush.
+func WithWriteBack(interval time.Duration, onError func(error)) Option {
+	return func(cs *CSVStore) {
+		cs.writeBack = &writeBackConfig{interval: interval, onError: onError}
+	}
+}
+
+// writeBackConfig holds the settings of WithWriteBack
+type writeBackConfig struct {
+	interval time.Duration
+	onError  func(error)
+}
+
+// startWriteBack wraps the file system of the store in a write-back cache and
+// starts flushing it in the background
+func (cs *CSVStore) startWriteBack() {
+	cs.fs = newWriteBackFS(cs.fs)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if cs.writeBack.interval <= 0 {
+			<-stop
+			return
+		}
+
+		ticker := time.NewTicker(cs.writeBack.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := cs.flush(); err != nil && cs.writeBack.onError != nil {
+					cs.writeBack.onError(err)
+				}
+			}
+		}
+	}()
+
+	cs.addCloser(func() error {
+		close(stop)
+		<-done
+		return cs.flush()
+	})
+}
+
+// Flush writes the files changed since the last flush to disk. It does nothing
+// for stores without WithWriteBack.
+func (cs *CSVStore) Flush() error {
+	_, err := runOperation(cs, Operation{Name: OpFlush}, func() (any, error) {
+		return nil, cs.flush()
+	})
+	return err
+}
+
+// flush writes the changed files of a write-back store to disk
+func (cs *CSVStore) flush() error {
+	// A read lock is enough to keep the files consistent with each other, and
+	// keeps migrations from swapping the file system
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	writeBack, ok := cs.fs.(*writeBackFS)
+	if !ok {
+		return nil
+	}
+	return writeBack.flush()
+}
+
+// writeBackFS is an FS caching another FS in memory and writing changes back on flush
+type writeBackFS struct {
+	mu    sync.Mutex
+	disk  FS
+	mem   *memFS
+	known map[string]bool // Names whose state is held by mem, present or not
+	dirty map[string]bool // Names to write or remove on flush
+}
+
+// newWriteBackFS returns a write-back cache over disk
+func newWriteBackFS(disk FS) *writeBackFS {
+	return &writeBackFS{
+		disk:  disk,
+		mem:   NewMemFS().(*memFS),
+		known: map[string]bool{".": true},
+		dirty: make(map[string]bool),
+	}
+}
+
+// load copies a file or directory from disk into memory on first use.
+// The caller must hold w.mu.
+func (w *writeBackFS) load(name string) error {
+	if w.known[name] {
+		return nil
+	}
+	if dir := path.Dir(name); dir != "." {
+		if err := w.load(dir); err != nil {
+			return err
+		}
+	}
+
+	info, err := w.disk.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		w.known[name] = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if !info.IsDir() {
+		file, err := w.disk.Open(name)
+		if err != nil {
+			return err
+		}
+		data, err = io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	w.mem.mu.Lock()
+	if info.IsDir() {
+		w.mem.dirs[name] = true
+	} else {
+		w.mem.files[name] = &memFile{data: data, modTime: info.ModTime()}
+	}
+	w.mem.mu.Unlock()
+	w.known[name] = true
+	return nil
+}
+
+func (w *writeBackFS) Open(name string) (io.ReadCloser, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.load(name); err != nil {
+		return nil, err
+	}
+	return w.mem.Open(name)
+}
+
+func (w *writeBackFS) Create(name string) (io.WriteCloser, error) {
+	return w.write(name, w.mem.Create)
+}
+
+func (w *writeBackFS) Append(name string) (io.WriteCloser, error) {
+	return w.write(name, w.mem.Append)
+}
+
+// write opens a file of the memory layer for writing and marks it dirty
+func (w *writeBackFS) write(name string, open func(string) (io.WriteCloser, error)) (io.WriteCloser, error) {
+	w.mu.Lock()
+	if err := w.load(name); err != nil {
+		w.mu.Unlock()
+		return nil, err
+	}
+	w.dirty[name] = true
+	w.mu.Unlock()
+
+	return open(name)
+}
+
+func (w *writeBackFS) Rename(oldName, newName string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.load(oldName); err != nil {
+		return err
+	}
+	if err := w.load(newName); err != nil {
+		return err
+	}
+	moved := w.namesUnder(oldName)
+	if err := w.mem.Rename(oldName, newName); err != nil {
+		return err
+	}
+	for _, name := range moved {
+		target := newName + strings.TrimPrefix(name, oldName)
+		w.known[target] = true
+		w.dirty[name] = true
+		w.dirty[target] = true
+	}
+	return nil
+}
+
+// namesUnder returns name and the names of the files and directories it contains
+// in the memory layer. The caller must hold w.mu.
+func (w *writeBackFS) namesUnder(name string) []string {
+	w.mem.mu.Lock()
+	defer w.mem.mu.Unlock()
+
+	names := []string{name}
+	prefix := name + "/"
+	for fileName := range w.mem.files {
+		if strings.HasPrefix(fileName, prefix) {
+			names = append(names, fileName)
+		}
+	}
+	for dirName := range w.mem.dirs {
+		if strings.HasPrefix(dirName, prefix) {
+			names = append(names, dirName)
+		}
+	}
+	return names
+}
+
+func (w *writeBackFS) Remove(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.load(name); err != nil {
+		return err
+	}
+	removed := w.namesUnder(name)
+	if err := w.mem.Remove(name); err != nil {
+		return err
+	}
+	for _, removedName := range removed {
+		w.known[removedName] = true
+		w.dirty[removedName] = true
+	}
+	return nil
+}
+
+func (w *writeBackFS) Stat(name string) (fs.FileInfo, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.load(name); err != nil {
+		return nil, err
+	}
+	return w.mem.Stat(name)
+}
+
+func (w *writeBackFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.load(name); err != nil {
+		return nil, err
+	}
+	diskEntries, err := w.disk.ReadDir(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range diskEntries {
+		if err := w.load(path.Join(name, entry.Name())); err != nil {
+			return nil, err
+		}
+	}
+	return w.mem.ReadDir(name)
+}
+
+func (w *writeBackFS) Mkdir(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.load(name); err != nil {
+		return err
+	}
+	if err := w.mem.Mkdir(name); err != nil {
+		return err
+	}
+	w.dirty[name] = true
+	return nil
+}
+
+// flush writes dirty files to disk and removes the ones deleted from memory
+func (w *writeBackFS) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	names := make([]string, 0, len(w.dirty))
+	for name := range w.dirty {
+		names = append(names, name)
+	}
+	// Parents sort before their children
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := w.flushName(name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush %s: %w", name, err))
+			continue
+		}
+		delete(w.dirty, name)
+	}
+	return errors.Join(errs...)
+}
+
+// flushName brings the disk state of a name in line with memory.
+// The caller must hold w.mu.
+func (w *writeBackFS) flushName(name string) error {
+	w.mem.mu.Lock()
+	isDir := w.mem.dirs[name]
+	var data []byte
+	file, exists := w.mem.files[name]
+	if exists {
+		data = file.data[:len(file.data):len(file.data)]
+	}
+	w.mem.mu.Unlock()
+
+	if isDir {
+		err := w.disk.Mkdir(name)
+		if errors.Is(err, fs.ErrExist) {
+			return nil
+		}
+		return err
+	}
+
+	if !exists {
+		err := w.disk.Remove(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	// Write a temporary file first, so a failed flush keeps the previous version
+	tempName := path.Join(path.Dir(name), ".flush-"+randomSuffix())
+	writer, err := w.disk.Create(tempName)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		w.disk.Remove(tempName)
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		w.disk.Remove(tempName)
+		return err
+	}
+	if err := w.disk.Rename(tempName, name); err != nil {
+		w.disk.Remove(tempName)
+		return err
+	}
+	return nil
+}
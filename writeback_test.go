@@ -0,0 +1,88 @@
+package csvstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteBack(t *testing.T) {
+	testDir := getTestDir()
+	defer os.RemoveAll(testDir)
+
+	// Tables written before write-back was enabled are read from disk
+	setup, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if err := setup.CreateTable("old", []string{"id", "value"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := setup.Insert("old", CSVRecord{"value": "a"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	setup.Close()
+
+	store, err := NewCSVStore(testDir, WithWriteBack(0, nil))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if err := store.CreateTable("metrics", []string{"id", "value"}); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.Insert("metrics", CSVRecord{"value": "1"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if _, err := store.Delete("old", nil); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+
+	tables, err := store.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Errorf("Expected 2 tables, got %v", tables)
+	}
+
+	// Nothing reaches the disk before a flush
+	if _, err := os.Stat(filepath.Join(testDir, "metrics.csv")); !os.IsNotExist(err) {
+		t.Errorf("Expected metrics table to stay in memory, got %v", err)
+	}
+
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Failed to flush store: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(testDir, "metrics.csv")); err != nil {
+		t.Errorf("Expected metrics table on disk after flush: %v", err)
+	}
+
+	// Close flushes the remaining changes
+	if _, err := store.Insert("metrics", CSVRecord{"value": "2"}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+
+	reopened, err := NewCSVStore(testDir)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+
+	metrics, err := reopened.Query("metrics", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if metrics.Count != 2 {
+		t.Errorf("Expected 2 flushed records, got %d", metrics.Count)
+	}
+	old, err := reopened.Query("old", nil)
+	if err != nil {
+		t.Fatalf("Failed to query records: %v", err)
+	}
+	if old.Count != 0 {
+		t.Errorf("Expected flushed delete, got %d records", old.Count)
+	}
+}